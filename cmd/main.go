@@ -3,8 +3,10 @@ package main
 import (
 	"api_techstore/internal/config"
 	"api_techstore/internal/container"
+	"api_techstore/internal/jobs"
 	"api_techstore/internal/models"
 	"api_techstore/internal/routes"
+	"context"
 	"log"
 
 	_ "api_techstore/docs" // Import docs generated by swag
@@ -51,10 +53,39 @@ func main() {
 		&models.Cart{},
 		&models.CartItem{},
 		&models.ProductImage{},
+		&models.ProductImageVariant{},
+		&models.ProductVariant{},
+		&models.ProductVariantOption{},
+		&models.CategoryAttribute{},
+		&models.ProductAttributeValue{},
+		&models.SlugRedirect{},
+		&models.Review{},
+		&models.ReviewPhoto{},
+		&models.ReviewVote{},
+		&models.ProductQuestion{},
+		&models.ProductAnswer{},
+		&models.Notification{},
+		&models.ProductRecommendation{},
+		&models.ImportJob{},
+		&models.ScheduledPrice{},
+		&models.PriceHistory{},
+		&models.FlashSale{},
+		&models.BundleItem{},
+		&models.SoldUnit{},
+		&models.WarrantyClaim{},
+		&models.WarrantyClaimNote{},
+		&models.WarrantyClaimAttachment{},
+		&models.ProductCompatibility{},
+		&models.CompatibilityRule{},
+		&models.TradeInPriceRule{},
+		&models.TradeIn{},
 	); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
+	// background jobs (recommendations, scheduled prices, ...)
+	jobs.Start(context.Background(), ctn)
+
 	// init router
 	r := gin.Default()
 	routes.SetupRouter(r, ctn)
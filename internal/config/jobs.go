@@ -0,0 +1,34 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+type JobsConfig struct {
+	RecommendationRefreshInterval time.Duration
+	PriceScheduleInterval         time.Duration
+	TrashPurgeInterval            time.Duration
+	ImportWatchdogInterval        time.Duration
+	// TrashRetention is how long deleted products, brands and categories can be restored
+	TrashRetention time.Duration
+}
+
+func GetJobsConfig() JobsConfig {
+	return JobsConfig{
+		RecommendationRefreshInterval: getDuration("RECOMMENDATION_REFRESH_INTERVAL", 6*time.Hour),
+		PriceScheduleInterval:         getDuration("PRICE_SCHEDULE_INTERVAL", time.Minute),
+		TrashPurgeInterval:            getDuration("TRASH_PURGE_INTERVAL", 24*time.Hour),
+		ImportWatchdogInterval:        getDuration("IMPORT_WATCHDOG_INTERVAL", 5*time.Minute),
+		TrashRetention:                getDuration("TRASH_RETENTION", 30*24*time.Hour),
+	}
+}
+
+// getDuration reads a duration such as "30m" or "6h" from the environment
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
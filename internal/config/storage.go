@@ -0,0 +1,66 @@
+package config
+
+import (
+	"api_techstore/pkg/storage"
+	"os"
+)
+
+type StorageConfig struct {
+	Driver string // local | s3
+
+	LocalDir       string
+	LocalURLPrefix string
+	PublicBaseURL  string
+
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3PublicURL string
+}
+
+func GetStorageConfig() StorageConfig {
+	LoadEnvVar()
+
+	driver := os.Getenv("STORAGE_DRIVER")
+	if driver == "" {
+		driver = "local"
+	}
+	localDir := os.Getenv("STORAGE_LOCAL_DIR")
+	if localDir == "" {
+		localDir = "./uploads" // Default upload directory
+	}
+	urlPrefix := os.Getenv("STORAGE_URL_PREFIX")
+	if urlPrefix == "" {
+		urlPrefix = "/uploads"
+	}
+
+	return StorageConfig{
+		Driver:         driver,
+		LocalDir:       localDir,
+		LocalURLPrefix: urlPrefix,
+		PublicBaseURL:  os.Getenv("STORAGE_PUBLIC_BASE_URL"),
+		S3Endpoint:     os.Getenv("S3_ENDPOINT"),
+		S3Region:       os.Getenv("S3_REGION"),
+		S3Bucket:       os.Getenv("S3_BUCKET"),
+		S3AccessKey:    os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:    os.Getenv("S3_SECRET_KEY"),
+		S3PublicURL:    os.Getenv("S3_PUBLIC_URL"),
+	}
+}
+
+// NewStorage builds the configured upload backend
+func (c StorageConfig) NewStorage() storage.Storage {
+	if c.Driver == "s3" {
+		return storage.NewS3Storage(storage.S3Options{
+			Endpoint:  c.S3Endpoint,
+			Region:    c.S3Region,
+			Bucket:    c.S3Bucket,
+			AccessKey: c.S3AccessKey,
+			SecretKey: c.S3SecretKey,
+			PublicURL: c.S3PublicURL,
+		})
+	}
+	return storage.NewLocalStorage(c.LocalDir, c.LocalURLPrefix, c.PublicBaseURL)
+}
@@ -1,10 +1,12 @@
 package container
 
 import (
+	"api_techstore/internal/config"
 	"api_techstore/internal/database"
 	"api_techstore/internal/services"
 	"api_techstore/pkg/jwt"
 	"api_techstore/pkg/logger"
+	"api_techstore/pkg/storage"
 	"log"
 
 	"github.com/go-redis/redis/v8"
@@ -17,6 +19,7 @@ type Container struct {
 	Redis     *redis.Client
 	JWTConfig *jwt.JWTConfig
 	Logger    *logrus.Logger
+	Storage   storage.Storage
 
 	// Khai báo các service để sử dụng DI
 	CategoryService services.CategoryService
@@ -28,6 +31,28 @@ type Container struct {
 	PaymentService  services.PaymentService
 	CartService     services.CartService
 	CartItemService services.CartItemService
+	SearchService   services.SearchService
+
+	ProductImageService   services.ProductImageService
+	ProductVariantService services.ProductVariantService
+	AttributeService      services.AttributeService
+	ComparisonService     services.ComparisonService
+	ReviewService         services.ReviewService
+	QuestionService       services.QuestionService
+	NotificationService   services.NotificationService
+	RecommendationService services.RecommendationService
+	ProductViewService    services.ProductViewService
+	ProductImportService  services.ProductImportService
+	PriceService          services.PriceService
+	FlashSaleService      services.FlashSaleService
+	BundleService         services.BundleService
+	WarrantyService       services.WarrantyService
+	WarrantyClaimService  services.WarrantyClaimService
+	CompatibilityService  services.CompatibilityService
+	TradeInService        services.TradeInService
+	BackorderService      services.BackorderService
+	CatalogService        services.CatalogService
+	TrashService          services.TrashService
 }
 
 func NewContainer() *Container {
@@ -45,8 +70,10 @@ func NewContainer() *Container {
 
 	logger.InitLogger()
 
+	fileStorage := config.GetStorageConfig().NewStorage()
+
 	categoryService := services.NewCategoryService(dbConn.DB)
-	brandService := services.NewBrandService(dbConn.DB)
+	brandService := services.NewBrandService(dbConn.DB, fileStorage)
 	productService := services.NewProductService(dbConn.DB)
 	orderService := services.NewOrderService(dbConn.DB)
 	addressService := services.NewAddressService(dbConn.DB)
@@ -54,12 +81,34 @@ func NewContainer() *Container {
 	paymentService := services.NewPaymentService(dbConn.DB)
 	cartService := services.NewCartService(dbConn.DB)
 	cartItemService := services.NewCartItemService(dbConn.DB)
+	searchService := services.NewSearchService(dbConn.DB)
+	productImageService := services.NewProductImageService(dbConn.DB, fileStorage)
+	productVariantService := services.NewProductVariantService(dbConn.DB)
+	attributeService := services.NewAttributeService(dbConn.DB)
+	comparisonService := services.NewComparisonService(dbConn.DB)
+	reviewService := services.NewReviewService(dbConn.DB, fileStorage)
+	questionService := services.NewQuestionService(dbConn.DB)
+	notificationService := services.NewNotificationService(dbConn.DB)
+	recommendationService := services.NewRecommendationService(dbConn.DB)
+	productViewService := services.NewProductViewService(dbConn.DB, redisClient)
+	productImportService := services.NewProductImportService(dbConn.DB)
+	priceService := services.NewPriceService(dbConn.DB)
+	flashSaleService := services.NewFlashSaleService(dbConn.DB, redisClient)
+	bundleService := services.NewBundleService(dbConn.DB)
+	warrantyService := services.NewWarrantyService(dbConn.DB)
+	warrantyClaimService := services.NewWarrantyClaimService(dbConn.DB, fileStorage)
+	compatibilityService := services.NewCompatibilityService(dbConn.DB)
+	tradeInService := services.NewTradeInService(dbConn.DB)
+	backorderService := services.NewBackorderService(dbConn.DB)
+	catalogService := services.NewCatalogService(dbConn.DB)
+	trashService := services.NewTrashService(dbConn.DB, fileStorage, config.GetJobsConfig().TrashRetention)
 
 	return &Container{
 		DB:        dbConn.DB,
 		Redis:     redisClient,
 		JWTConfig: jwtCfg,
 		Logger:    logger.Log,
+		Storage:   fileStorage,
 
 		CategoryService: categoryService,
 		BrandService:    brandService,
@@ -70,5 +119,27 @@ func NewContainer() *Container {
 		PaymentService:  paymentService,
 		CartService:     cartService,
 		CartItemService: cartItemService,
+		SearchService:   searchService,
+
+		ProductImageService:   productImageService,
+		ProductVariantService: productVariantService,
+		AttributeService:      attributeService,
+		ComparisonService:     comparisonService,
+		ReviewService:         reviewService,
+		QuestionService:       questionService,
+		NotificationService:   notificationService,
+		RecommendationService: recommendationService,
+		ProductViewService:    productViewService,
+		ProductImportService:  productImportService,
+		PriceService:          priceService,
+		FlashSaleService:      flashSaleService,
+		BundleService:         bundleService,
+		WarrantyService:       warrantyService,
+		WarrantyClaimService:  warrantyClaimService,
+		CompatibilityService:  compatibilityService,
+		TradeInService:        tradeInService,
+		BackorderService:      backorderService,
+		CatalogService:        catalogService,
+		TrashService:          trashService,
 	}
 }
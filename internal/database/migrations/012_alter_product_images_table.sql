@@ -0,0 +1,20 @@
+--- +migrate up
+ALTER TABLE product_images
+    ADD COLUMN IF NOT EXISTS storage_key VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS content_type VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS file_size BIGINT DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_product_images_product_id ON product_images (product_id);
+
+-- at most one main image per product
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_images_main
+    ON product_images (product_id)
+    WHERE is_main = true AND deleted_at IS NULL;
+
+--- +migrate down
+DROP INDEX IF EXISTS idx_product_images_main;
+DROP INDEX IF EXISTS idx_product_images_product_id;
+ALTER TABLE product_images
+    DROP COLUMN IF EXISTS storage_key,
+    DROP COLUMN IF EXISTS content_type,
+    DROP COLUMN IF EXISTS file_size;
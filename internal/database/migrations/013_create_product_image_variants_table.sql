@@ -0,0 +1,28 @@
+--- +migrate up
+ALTER TABLE product_images
+    ADD COLUMN IF NOT EXISTS width INT DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS height INT DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS product_image_variants (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    product_image_id INT NOT NULL,
+    name VARCHAR(20) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    image_url VARCHAR(255) NOT NULL,
+    storage_key VARCHAR(255),
+    width INT DEFAULT 0,
+    height INT DEFAULT 0,
+    file_size BIGINT DEFAULT 0,
+    FOREIGN KEY (product_image_id) REFERENCES product_images(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_image_variants_product_image_id ON product_image_variants (product_image_id);
+
+--- +migrate down
+DROP TABLE IF EXISTS product_image_variants;
+ALTER TABLE product_images
+    DROP COLUMN IF EXISTS width,
+    DROP COLUMN IF EXISTS height;
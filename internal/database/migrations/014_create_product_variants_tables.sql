@@ -0,0 +1,46 @@
+--- +migrate up
+CREATE TABLE IF NOT EXISTS product_variants (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    product_id INT NOT NULL,
+    sku VARCHAR(64) NOT NULL,
+    option_key VARCHAR(500) NOT NULL,
+    price NUMERIC(12,2) NOT NULL,
+    quantity INT NOT NULL DEFAULT 0,
+    is_active BOOLEAN DEFAULT TRUE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_variants_product_id ON product_variants (product_id);
+-- SKUs and option combinations (per product) are unique among live variants
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_variants_sku ON product_variants (sku) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_variants_options ON product_variants (product_id, option_key) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS product_variant_options (
+    id SERIAL PRIMARY KEY,
+    variant_id INT NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    value VARCHAR(100) NOT NULL,
+    FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_variant_options_variant_id ON product_variant_options (variant_id);
+
+ALTER TABLE product_images ADD COLUMN IF NOT EXISTS variant_id INT REFERENCES product_variants(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_product_images_variant_id ON product_images (variant_id);
+
+ALTER TABLE cart_items ADD COLUMN IF NOT EXISTS variant_id INT REFERENCES product_variants(id);
+ALTER TABLE order_items
+    ADD COLUMN IF NOT EXISTS variant_id INT REFERENCES product_variants(id),
+    ADD COLUMN IF NOT EXISTS sku VARCHAR(64);
+
+--- +migrate down
+ALTER TABLE order_items
+    DROP COLUMN IF EXISTS variant_id,
+    DROP COLUMN IF EXISTS sku;
+ALTER TABLE cart_items DROP COLUMN IF EXISTS variant_id;
+ALTER TABLE product_images DROP COLUMN IF EXISTS variant_id;
+DROP TABLE IF EXISTS product_variant_options;
+DROP TABLE IF EXISTS product_variants;
@@ -0,0 +1,38 @@
+--- +migrate up
+CREATE TABLE IF NOT EXISTS category_attributes (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    category_id INT NOT NULL,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    unit VARCHAR(20),
+    allowed_values TEXT, -- JSON array, enum attributes only
+    is_required BOOLEAN DEFAULT FALSE,
+    is_filterable BOOLEAN DEFAULT FALSE,
+    sort_order INT DEFAULT 0,
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_attributes_category_id ON category_attributes (category_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_category_attributes_code ON category_attributes (category_id, code) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS product_attribute_values (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    attribute_id INT NOT NULL,
+    value_text VARCHAR(255),
+    value_number DOUBLE PRECISION,
+    value_bool BOOLEAN,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (attribute_id) REFERENCES category_attributes(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_attribute_values_attribute ON product_attribute_values (product_id, attribute_id);
+CREATE INDEX IF NOT EXISTS idx_product_attribute_values_attribute_id ON product_attribute_values (attribute_id);
+
+--- +migrate down
+DROP TABLE IF EXISTS product_attribute_values;
+DROP TABLE IF EXISTS category_attributes;
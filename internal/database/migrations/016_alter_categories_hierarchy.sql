@@ -0,0 +1,20 @@
+--- +migrate up
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS parent_id INT REFERENCES categories(id),
+    ADD COLUMN IF NOT EXISTS path VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS depth INT DEFAULT 0;
+
+-- existing categories become top level ones
+UPDATE categories SET path = '/' || id || '/', depth = 0 WHERE path IS NULL OR path = '';
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
+-- text_pattern_ops so subtree lookups (path LIKE '/1/4/%') can use the index
+CREATE INDEX IF NOT EXISTS idx_categories_path ON categories (path text_pattern_ops);
+
+--- +migrate down
+DROP INDEX IF EXISTS idx_categories_path;
+DROP INDEX IF EXISTS idx_categories_parent_id;
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS parent_id,
+    DROP COLUMN IF EXISTS path,
+    DROP COLUMN IF EXISTS depth;
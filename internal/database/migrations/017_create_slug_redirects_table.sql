@@ -0,0 +1,14 @@
+--- +migrate up
+CREATE TABLE IF NOT EXISTS slug_redirects (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    entity_type VARCHAR(20) NOT NULL,
+    slug VARCHAR(100) NOT NULL,
+    entity_id INT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_slug_redirects_slug ON slug_redirects (entity_type, slug);
+CREATE INDEX IF NOT EXISTS idx_slug_redirects_entity_id ON slug_redirects (entity_id);
+
+--- +migrate down
+DROP TABLE IF EXISTS slug_redirects;
@@ -0,0 +1,57 @@
+--- +migrate up
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS rating_average NUMERIC(3,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_histogram TEXT;
+
+CREATE TABLE IF NOT EXISTS reviews (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    product_id INT NOT NULL REFERENCES products(id),
+    user_id INT NOT NULL REFERENCES users(id),
+    order_item_id INT NOT NULL REFERENCES order_items(id),
+    rating INT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    title VARCHAR(150),
+    content TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    moderation_note VARCHAR(500),
+    moderated_at TIMESTAMP WITH TIME ZONE,
+    helpful_count INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviews_deleted_at ON reviews (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_reviews_product_id ON reviews (product_id);
+CREATE INDEX IF NOT EXISTS idx_reviews_user_id ON reviews (user_id);
+CREATE INDEX IF NOT EXISTS idx_reviews_status ON reviews (status);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reviews_product_user ON reviews (product_id, user_id) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS review_photos (
+    id SERIAL PRIMARY KEY,
+    review_id INT NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    image_url TEXT NOT NULL,
+    storage_key TEXT,
+    content_type VARCHAR(50),
+    sort_order INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_photos_review_id ON review_photos (review_id);
+
+CREATE TABLE IF NOT EXISTS review_votes (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    review_id INT NOT NULL REFERENCES reviews(id),
+    user_id INT NOT NULL REFERENCES users(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_review_votes_review_user ON review_votes (review_id, user_id);
+
+--- +migrate down
+DROP TABLE IF EXISTS review_votes;
+DROP TABLE IF EXISTS review_photos;
+DROP TABLE IF EXISTS reviews;
+ALTER TABLE products
+    DROP COLUMN IF EXISTS rating_histogram,
+    DROP COLUMN IF EXISTS rating_count,
+    DROP COLUMN IF EXISTS rating_average;
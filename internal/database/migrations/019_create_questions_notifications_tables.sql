@@ -0,0 +1,54 @@
+--- +migrate up
+CREATE TABLE IF NOT EXISTS product_questions (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    product_id INT NOT NULL REFERENCES products(id),
+    user_id INT NOT NULL REFERENCES users(id),
+    content VARCHAR(1000) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    moderation_note VARCHAR(500)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_questions_deleted_at ON product_questions (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_product_questions_product_id ON product_questions (product_id);
+CREATE INDEX IF NOT EXISTS idx_product_questions_user_id ON product_questions (user_id);
+CREATE INDEX IF NOT EXISTS idx_product_questions_status ON product_questions (status);
+
+CREATE TABLE IF NOT EXISTS product_answers (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    question_id INT NOT NULL REFERENCES product_questions(id),
+    user_id INT NOT NULL REFERENCES users(id),
+    content VARCHAR(2000) NOT NULL,
+    author_type VARCHAR(20) NOT NULL CHECK (author_type IN ('staff', 'verified_buyer', 'customer')),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    moderation_note VARCHAR(500)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_answers_deleted_at ON product_answers (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_product_answers_question_id ON product_answers (question_id);
+CREATE INDEX IF NOT EXISTS idx_product_answers_user_id ON product_answers (user_id);
+CREATE INDEX IF NOT EXISTS idx_product_answers_status ON product_answers (status);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    user_id INT NOT NULL REFERENCES users(id),
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    message TEXT,
+    entity_type VARCHAR(20),
+    entity_id INT,
+    read_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications (user_id);
+
+--- +migrate down
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS product_answers;
+DROP TABLE IF EXISTS product_questions;
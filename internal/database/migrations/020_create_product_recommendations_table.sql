@@ -0,0 +1,16 @@
+--- +migrate up
+CREATE TABLE IF NOT EXISTS product_recommendations (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    kind VARCHAR(30) NOT NULL,
+    recommended_id INT NOT NULL,
+    rank INT NOT NULL,
+    score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    source VARCHAR(20) NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_recommendations_entry ON product_recommendations (product_id, kind, recommended_id);
+
+--- +migrate down
+DROP TABLE IF EXISTS product_recommendations;
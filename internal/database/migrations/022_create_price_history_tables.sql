@@ -0,0 +1,43 @@
+--- +migrate up
+CREATE TABLE IF NOT EXISTS scheduled_prices (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    product_id INT NOT NULL,
+    price NUMERIC(12,2) NOT NULL CHECK (price > 0),
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'active', 'completed', 'cancelled')),
+    previous_price NUMERIC(12,2),
+    note VARCHAR(255),
+    created_by INT NOT NULL REFERENCES users(id),
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_prices_deleted_at ON scheduled_prices (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_scheduled_prices_product_id ON scheduled_prices (product_id);
+CREATE INDEX IF NOT EXISTS idx_scheduled_prices_status ON scheduled_prices (status);
+CREATE INDEX IF NOT EXISTS idx_scheduled_prices_starts_at ON scheduled_prices (starts_at);
+
+CREATE TABLE IF NOT EXISTS price_history (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    old_price NUMERIC(12,2),
+    new_price NUMERIC(12,2) NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    scheduled_price_id INT REFERENCES scheduled_prices(id) ON DELETE SET NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_history_product_changed ON price_history (product_id, changed_at);
+
+-- the current price of existing products starts their history
+INSERT INTO price_history (product_id, new_price, source, changed_at)
+SELECT id, price, 'manual', COALESCE(updated_at, CURRENT_TIMESTAMP) FROM products;
+
+--- +migrate down
+DROP TABLE IF EXISTS price_history;
+DROP TABLE IF EXISTS scheduled_prices;
@@ -0,0 +1,33 @@
+--- +migrate up
+CREATE TABLE IF NOT EXISTS flash_sales (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    product_id INT NOT NULL,
+    variant_id INT REFERENCES product_variants(id),
+    sale_price NUMERIC(12,2) NOT NULL CHECK (sale_price > 0),
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    total_quantity INT NOT NULL CHECK (total_quantity > 0),
+    sold_quantity INT NOT NULL DEFAULT 0 CHECK (sold_quantity >= 0),
+    per_user_limit INT NOT NULL CHECK (per_user_limit > 0),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by INT NOT NULL REFERENCES users(id),
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_flash_sales_deleted_at ON flash_sales (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_flash_sales_product_id ON flash_sales (product_id);
+CREATE INDEX IF NOT EXISTS idx_flash_sales_starts_at ON flash_sales (starts_at);
+CREATE INDEX IF NOT EXISTS idx_flash_sales_ends_at ON flash_sales (ends_at);
+
+-- order lines bought at a flash sale price
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS flash_sale_id INT REFERENCES flash_sales(id);
+CREATE INDEX IF NOT EXISTS idx_order_items_flash_sale_id ON order_items (flash_sale_id);
+
+--- +migrate down
+DROP INDEX IF EXISTS idx_order_items_flash_sale_id;
+ALTER TABLE order_items DROP COLUMN IF EXISTS flash_sale_id;
+DROP TABLE IF EXISTS flash_sales;
@@ -0,0 +1,26 @@
+--- +migrate up
+ALTER TABLE products ADD COLUMN IF NOT EXISTS type VARCHAR(20) NOT NULL DEFAULT 'simple' CHECK (type IN ('simple', 'bundle'));
+
+CREATE TABLE IF NOT EXISTS bundle_items (
+    id SERIAL PRIMARY KEY,
+    bundle_id INT NOT NULL,
+    component_id INT NOT NULL,
+    component_variant_id INT REFERENCES product_variants(id),
+    quantity INT NOT NULL CHECK (quantity > 0),
+    sort_order INT NOT NULL DEFAULT 0,
+    FOREIGN KEY (bundle_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (component_id) REFERENCES products(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bundle_items_bundle_id ON bundle_items (bundle_id);
+CREATE INDEX IF NOT EXISTS idx_bundle_items_component_id ON bundle_items (component_id);
+
+-- component lines of an ordered bundle point to the bundle
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS bundle_product_id INT REFERENCES products(id);
+CREATE INDEX IF NOT EXISTS idx_order_items_bundle_product_id ON order_items (bundle_product_id);
+
+--- +migrate down
+DROP INDEX IF EXISTS idx_order_items_bundle_product_id;
+ALTER TABLE order_items DROP COLUMN IF EXISTS bundle_product_id;
+DROP TABLE IF EXISTS bundle_items;
+ALTER TABLE products DROP COLUMN IF EXISTS type;
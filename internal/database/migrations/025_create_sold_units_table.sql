@@ -0,0 +1,30 @@
+--- +migrate up
+ALTER TABLE brands ADD COLUMN IF NOT EXISTS warranty_months INT CHECK (warranty_months >= 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS warranty_months INT CHECK (warranty_months >= 0);
+
+CREATE TABLE IF NOT EXISTS sold_units (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    order_item_id INT NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    product_id INT NOT NULL REFERENCES products(id),
+    variant_id INT REFERENCES product_variants(id),
+    serial_number VARCHAR(100) NOT NULL,
+    warranty_months INT NOT NULL DEFAULT 0,
+    warranty_starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    warranty_ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    recorded_by INT NOT NULL REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sold_units_deleted_at ON sold_units (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_sold_units_order_item_id ON sold_units (order_item_id);
+CREATE INDEX IF NOT EXISTS idx_sold_units_product_id ON sold_units (product_id);
+CREATE INDEX IF NOT EXISTS idx_sold_units_warranty_ends_at ON sold_units (warranty_ends_at);
+-- a serial number identifies one unit among the live records
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sold_units_serial_number ON sold_units (serial_number) WHERE deleted_at IS NULL;
+
+--- +migrate down
+DROP TABLE IF EXISTS sold_units;
+ALTER TABLE products DROP COLUMN IF EXISTS warranty_months;
+ALTER TABLE brands DROP COLUMN IF EXISTS warranty_months;
@@ -0,0 +1,49 @@
+--- +migrate up
+CREATE TABLE IF NOT EXISTS warranty_claims (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    user_id INT NOT NULL REFERENCES users(id),
+    order_item_id INT NOT NULL REFERENCES order_items(id),
+    sold_unit_id INT REFERENCES sold_units(id),
+    description TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'submitted' CHECK (status IN ('submitted', 'received', 'diagnosing', 'repaired', 'replaced', 'rejected')),
+    under_warranty BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS idx_warranty_claims_deleted_at ON warranty_claims (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_warranty_claims_user_id ON warranty_claims (user_id);
+CREATE INDEX IF NOT EXISTS idx_warranty_claims_order_item_id ON warranty_claims (order_item_id);
+CREATE INDEX IF NOT EXISTS idx_warranty_claims_sold_unit_id ON warranty_claims (sold_unit_id);
+CREATE INDEX IF NOT EXISTS idx_warranty_claims_status ON warranty_claims (status);
+
+CREATE TABLE IF NOT EXISTS warranty_claim_notes (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    claim_id INT NOT NULL REFERENCES warranty_claims(id) ON DELETE CASCADE,
+    author_id INT NOT NULL REFERENCES users(id),
+    content TEXT,
+    status VARCHAR(20),
+    internal BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS idx_warranty_claim_notes_claim_id ON warranty_claim_notes (claim_id);
+
+CREATE TABLE IF NOT EXISTS warranty_claim_attachments (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    claim_id INT NOT NULL REFERENCES warranty_claims(id) ON DELETE CASCADE,
+    uploaded_by INT NOT NULL REFERENCES users(id),
+    file_name VARCHAR(255),
+    file_url TEXT NOT NULL,
+    storage_key TEXT,
+    content_type VARCHAR(50)
+);
+
+CREATE INDEX IF NOT EXISTS idx_warranty_claim_attachments_claim_id ON warranty_claim_attachments (claim_id);
+
+--- +migrate down
+DROP TABLE IF EXISTS warranty_claim_attachments;
+DROP TABLE IF EXISTS warranty_claim_notes;
+DROP TABLE IF EXISTS warranty_claims;
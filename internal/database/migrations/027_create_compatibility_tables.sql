@@ -0,0 +1,28 @@
+--- +migrate up
+CREATE TABLE IF NOT EXISTS product_compatibilities (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    accessory_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    device_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    CHECK (accessory_id <> device_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_compatibilities_pair ON product_compatibilities (accessory_id, device_id);
+CREATE INDEX IF NOT EXISTS idx_product_compatibilities_device_id ON product_compatibilities (device_id);
+
+CREATE TABLE IF NOT EXISTS compatibility_rules (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    accessory_attribute_id INT NOT NULL REFERENCES category_attributes(id),
+    device_attribute_id INT NOT NULL REFERENCES category_attributes(id),
+    description VARCHAR(255)
+);
+
+CREATE INDEX IF NOT EXISTS idx_compatibility_rules_deleted_at ON compatibility_rules (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_compatibility_rules_attributes ON compatibility_rules (accessory_attribute_id, device_attribute_id) WHERE deleted_at IS NULL;
+
+--- +migrate down
+DROP TABLE IF EXISTS compatibility_rules;
+DROP TABLE IF EXISTS product_compatibilities;
@@ -0,0 +1,57 @@
+--- +migrate up
+CREATE TABLE IF NOT EXISTS trade_in_price_rules (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    brand_id INT NOT NULL REFERENCES brands(id),
+    model VARCHAR(150) NOT NULL,
+    base_value NUMERIC(12,2) NOT NULL CHECK (base_value > 0),
+    good_percent INT NOT NULL DEFAULT 85 CHECK (good_percent BETWEEN 0 AND 100),
+    fair_percent INT NOT NULL DEFAULT 65 CHECK (fair_percent BETWEEN 0 AND 100),
+    poor_percent INT NOT NULL DEFAULT 40 CHECK (poor_percent BETWEEN 0 AND 100),
+    screen_damage_deduction NUMERIC(12,2) NOT NULL DEFAULT 0,
+    body_damage_deduction NUMERIC(12,2) NOT NULL DEFAULT 0,
+    no_power_value NUMERIC(12,2) NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_trade_in_price_rules_deleted_at ON trade_in_price_rules (deleted_at);
+-- a model is priced once per brand, ignoring case
+CREATE UNIQUE INDEX IF NOT EXISTS idx_trade_in_price_rules_model ON trade_in_price_rules (brand_id, LOWER(model)) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS trade_ins (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    user_id INT NOT NULL REFERENCES users(id),
+    brand_id INT NOT NULL REFERENCES brands(id),
+    model VARCHAR(150) NOT NULL,
+    condition VARCHAR(20) NOT NULL CHECK (condition IN ('excellent', 'good', 'fair', 'poor')),
+    powers_on BOOLEAN NOT NULL,
+    screen_damaged BOOLEAN NOT NULL,
+    body_damaged BOOLEAN NOT NULL,
+    description TEXT,
+    price_rule_id INT NOT NULL REFERENCES trade_in_price_rules(id),
+    quoted_value NUMERIC(12,2) NOT NULL,
+    quote_expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'quoted' CHECK (status IN ('quoted', 'accepted', 'declined')),
+    final_value NUMERIC(12,2),
+    inspection_note VARCHAR(1000),
+    inspected_by INT REFERENCES users(id),
+    inspected_at TIMESTAMP WITH TIME ZONE,
+    order_id INT REFERENCES orders(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_trade_ins_deleted_at ON trade_ins (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_trade_ins_user_id ON trade_ins (user_id);
+CREATE INDEX IF NOT EXISTS idx_trade_ins_status ON trade_ins (status);
+CREATE INDEX IF NOT EXISTS idx_trade_ins_order_id ON trade_ins (order_id);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS trade_in_credit NUMERIC(12,2) NOT NULL DEFAULT 0;
+
+--- +migrate down
+ALTER TABLE orders DROP COLUMN IF EXISTS trade_in_credit;
+DROP TABLE IF EXISTS trade_ins;
+DROP TABLE IF EXISTS trade_in_price_rules;
@@ -0,0 +1,25 @@
+--- +migrate up
+ALTER TABLE products ADD COLUMN IF NOT EXISTS stock_policy VARCHAR(20) NOT NULL DEFAULT 'normal' CHECK (stock_policy IN ('normal', 'preorder', 'backorder'));
+ALTER TABLE products ADD COLUMN IF NOT EXISTS expected_available_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS backorder_limit INT NOT NULL DEFAULT 0 CHECK (backorder_limit >= 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS backordered_quantity INT NOT NULL DEFAULT 0 CHECK (backordered_quantity >= 0);
+
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS backordered_quantity INT NOT NULL DEFAULT 0 CHECK (backordered_quantity >= 0);
+-- set while the units of a line are taken from stock, so a cancellation knows what to give back
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS stock_taken BOOLEAN NOT NULL DEFAULT FALSE;
+-- the lines waiting for stock are allocated oldest order first
+CREATE INDEX IF NOT EXISTS idx_order_items_backordered ON order_items (product_id) WHERE backordered_quantity > 0;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS chk_orders_status;
+ALTER TABLE orders ADD CONSTRAINT chk_orders_status CHECK (status IN ('pending', 'awaiting_stock', 'confirmed', 'processing', 'shipped', 'delivered', 'cancelled'));
+
+--- +migrate down
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS chk_orders_status;
+ALTER TABLE orders ADD CONSTRAINT chk_orders_status CHECK (status IN ('pending', 'confirmed', 'processing', 'shipped', 'delivered', 'cancelled'));
+DROP INDEX IF EXISTS idx_order_items_backordered;
+ALTER TABLE order_items DROP COLUMN IF EXISTS stock_taken;
+ALTER TABLE order_items DROP COLUMN IF EXISTS backordered_quantity;
+ALTER TABLE products DROP COLUMN IF EXISTS backordered_quantity;
+ALTER TABLE products DROP COLUMN IF EXISTS backorder_limit;
+ALTER TABLE products DROP COLUMN IF EXISTS expected_available_at;
+ALTER TABLE products DROP COLUMN IF EXISTS stock_policy;
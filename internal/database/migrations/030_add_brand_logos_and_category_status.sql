@@ -0,0 +1,11 @@
+--- +migrate up
+ALTER TABLE brands ADD COLUMN IF NOT EXISTS logo_url VARCHAR(500);
+ALTER TABLE brands ADD COLUMN IF NOT EXISTS logo_storage_key VARCHAR(500);
+
+-- an inactive category hides the products of its whole subtree
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT TRUE;
+
+--- +migrate down
+ALTER TABLE categories DROP COLUMN IF EXISTS is_active;
+ALTER TABLE brands DROP COLUMN IF EXISTS logo_storage_key;
+ALTER TABLE brands DROP COLUMN IF EXISTS logo_url;
@@ -0,0 +1,32 @@
+--- +migrate up
+-- slugs and category names are only unique among live rows, so deleted rows no longer reserve them
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_slug_key;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS uni_products_slug;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_slug ON products (slug) WHERE deleted_at IS NULL;
+
+ALTER TABLE brands DROP CONSTRAINT IF EXISTS brands_slug_key;
+ALTER TABLE brands DROP CONSTRAINT IF EXISTS uni_brands_slug;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_brands_slug ON brands (slug) WHERE deleted_at IS NULL;
+
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_slug_key;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS uni_categories_slug;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_name_key;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS uni_categories_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_slug ON categories (slug) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_name ON categories (name) WHERE deleted_at IS NULL;
+
+-- the trash lists and purges by deletion time
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_brands_deleted_at ON brands (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories (deleted_at);
+
+--- +migrate down
+-- fails while a deleted row shares a slug or name with a live one
+DROP INDEX IF EXISTS idx_categories_name;
+DROP INDEX IF EXISTS idx_categories_slug;
+DROP INDEX IF EXISTS idx_brands_slug;
+DROP INDEX IF EXISTS idx_products_slug;
+ALTER TABLE categories ADD CONSTRAINT categories_name_key UNIQUE (name);
+ALTER TABLE categories ADD CONSTRAINT categories_slug_key UNIQUE (slug);
+ALTER TABLE brands ADD CONSTRAINT brands_slug_key UNIQUE (slug);
+ALTER TABLE products ADD CONSTRAINT products_slug_key UNIQUE (slug);
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+	"api_techstore/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCategoryAttributes godoc
+// @Summary Get category attributes
+// @Description List the spec attribute definitions of a category
+// @Tags category-attributes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Category ID"
+// @Success 200 {object} response.Response{data=[]models.SwaggerCategoryAttribute} "Category attributes retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 404 {object} response.Response "Category not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /categories/{id}/attributes [get]
+func GetCategoryAttributes(c *gin.Context, ctn *container.Container) {
+	categoryID, ok := parseUintParam(c, "id", "Invalid category id")
+	if !ok {
+		return
+	}
+
+	attributes, err := ctn.AttributeService.GetCategoryAttributes(categoryID)
+	if err != nil {
+		handleServiceError(c, err, "Category")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Category attributes retrieved successfully", attributes)
+}
+
+// CreateCategoryAttribute godoc
+// @Summary Create category attribute
+// @Description Define a spec attribute (string, number, boolean or enum) for the products of a category (Admin only)
+// @Tags category-attributes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Category ID"
+// @Param request body models.CategoryAttributeCreateRequest true "Attribute definition"
+// @Success 201 {object} response.Response{data=models.SwaggerCategoryAttribute} "Category attribute created successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Category not found"
+// @Failure 409 {object} response.Response "Attribute code already exists"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /categories/{id}/attributes [post]
+func CreateCategoryAttribute(c *gin.Context, ctn *container.Container) {
+	categoryID, ok := parseUintParam(c, "id", "Invalid category id")
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.CategoryAttributeCreateRequest)
+
+	attribute := models.CategoryAttribute{
+		CategoryID:    categoryID,
+		Code:          req.Code,
+		Name:          req.Name,
+		Type:          req.Type,
+		Unit:          req.Unit,
+		AllowedValues: req.AllowedValues,
+		SortOrder:     req.SortOrder,
+	}
+	if req.IsRequired != nil {
+		attribute.IsRequired = *req.IsRequired
+	}
+	if req.IsFilterable != nil {
+		attribute.IsFilterable = *req.IsFilterable
+	}
+
+	newAttribute, err := ctn.AttributeService.CreateCategoryAttribute(attribute)
+	if err != nil {
+		handleServiceError(c, err, "Category")
+		return
+	}
+	response.SuccessResponse(c, http.StatusCreated, "Category attribute created successfully", newAttribute)
+}
+
+// UpdateCategoryAttribute godoc
+// @Summary Update category attribute
+// @Description Update a spec attribute definition; code and type cannot be changed (Admin only)
+// @Tags category-attributes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Category ID"
+// @Param attributeId path string true "Attribute ID"
+// @Param request body models.CategoryAttributeUpdateRequest true "Attribute update data"
+// @Success 200 {object} response.Response{data=models.SwaggerCategoryAttribute} "Category attribute updated successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Category attribute not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /categories/{id}/attributes/{attributeId} [put]
+func UpdateCategoryAttribute(c *gin.Context, ctn *container.Container) {
+	categoryID, ok := parseUintParam(c, "id", "Invalid category id")
+	if !ok {
+		return
+	}
+	attributeID, ok := parseUintParam(c, "attributeId", "Invalid attribute id")
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.CategoryAttributeUpdateRequest)
+
+	attribute, err := ctn.AttributeService.GetCategoryAttribute(categoryID, attributeID)
+	if err != nil {
+		handleServiceError(c, err, "Category attribute")
+		return
+	}
+
+	// Chỉ update các trường có trong request
+	if req.Name != "" {
+		attribute.Name = req.Name
+	}
+	if req.Unit != nil {
+		attribute.Unit = *req.Unit
+	}
+	if req.AllowedValues != nil {
+		attribute.AllowedValues = req.AllowedValues
+	}
+	if req.IsRequired != nil {
+		attribute.IsRequired = *req.IsRequired
+	}
+	if req.IsFilterable != nil {
+		attribute.IsFilterable = *req.IsFilterable
+	}
+	if req.SortOrder != nil {
+		attribute.SortOrder = *req.SortOrder
+	}
+
+	updatedAttribute, err := ctn.AttributeService.UpdateCategoryAttribute(categoryID, attributeID, attribute)
+	if err != nil {
+		handleServiceError(c, err, "Category attribute")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Category attribute updated successfully", updatedAttribute)
+}
+
+// DeleteCategoryAttribute godoc
+// @Summary Delete category attribute
+// @Description Delete a spec attribute definition together with the product values for it (Admin only)
+// @Tags category-attributes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Category ID"
+// @Param attributeId path string true "Attribute ID"
+// @Success 200 {object} response.Response "Category attribute deleted successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Category attribute not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /categories/{id}/attributes/{attributeId} [delete]
+func DeleteCategoryAttribute(c *gin.Context, ctn *container.Container) {
+	categoryID, ok := parseUintParam(c, "id", "Invalid category id")
+	if !ok {
+		return
+	}
+	attributeID, ok := parseUintParam(c, "attributeId", "Invalid attribute id")
+	if !ok {
+		return
+	}
+
+	if err := ctn.AttributeService.DeleteCategoryAttribute(categoryID, attributeID); err != nil {
+		handleServiceError(c, err, "Category attribute")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Category attribute deleted successfully", nil)
+}
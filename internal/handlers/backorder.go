@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+	"api_techstore/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetProductStockPolicy godoc
+// @Summary Set product stock policy
+// @Description Let a product be pre-ordered or backordered past its stock, up to backorder_limit units waiting at once, with an expected availability date (required for pre-orders); or turn it back to normal. Bundles cannot be pre-ordered (Admin only)
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body models.ProductStockPolicyRequest true "Stock policy"
+// @Success 200 {object} response.Response{data=models.SwaggerProduct} "Stock policy updated successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/stock-policy [put]
+func SetProductStockPolicy(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.ProductStockPolicyRequest)
+
+	product, err := ctn.BackorderService.SetStockPolicy(productID, req.StockPolicy, req.ExpectedAvailableAt, req.BackorderLimit)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Stock policy updated successfully", product)
+}
+
+// ReceiveProductStock godoc
+// @Summary Receive product stock
+// @Description Record units received for a product, or for one of its variants. They go to the units waiting for stock first, oldest order first; orders with nothing left waiting go back to pending and their customer is notified. The rest is added to the stock (Admin only)
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body models.StockReceiptRequest true "Received units"
+// @Success 200 {object} response.Response{data=models.SwaggerStockReceipt} "Stock received successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/stock-receipts [post]
+func ReceiveProductStock(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.StockReceiptRequest)
+
+	receipt, err := ctn.BackorderService.ReceiveStock(productID, req.VariantID, req.Quantity)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Stock received successfully", receipt)
+}
@@ -64,6 +64,95 @@ func GetBrandById(c *gin.Context, ctn *container.Container) {
 	response.SuccessResponse(c, http.StatusOK, "Brand retrieved successfully", brand)
 }
 
+// GetBrandBySlug godoc
+// @Summary Get brand by slug
+// @Description Retrieve a brand by its slug; an old slug answers with a 301 redirect to the current one
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Brand slug"
+// @Success 200 {object} response.Response{data=models.SwaggerBrand} "Brand retrieved successfully"
+// @Success 301 "Moved to the current slug"
+// @Failure 404 {object} response.Response "Brand not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /brands/slug/{slug} [get]
+func GetBrandBySlug(c *gin.Context, ctn *container.Container) {
+	slug := c.Param("slug")
+	brand, err := ctn.BrandService.GetBrandBySlug(slug)
+	if err != nil {
+		handleServiceError(c, err, "Brand")
+		return
+	}
+	if redirectToCurrentSlug(c, slug, brand.Slug) {
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Brand retrieved successfully", brand)
+}
+
+// GetBrandPage godoc
+// @Summary Get brand page
+// @Description Retrieve the landing data of an active brand: how many products the catalog lists for it, in total and per category, and its best sellers. An old slug answers with a 301 redirect to the current one. Numeric values are looked up as brand IDs
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Brand slug"
+// @Success 200 {object} response.Response{data=models.SwaggerBrandPage} "Brand page retrieved successfully"
+// @Success 301 "Moved to the current slug"
+// @Failure 404 {object} response.Response "Brand not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /brands/{slug} [get]
+func GetBrandPage(c *gin.Context, ctn *container.Container) {
+	slug := c.Param("slug")
+	page, err := ctn.BrandService.GetBrandPage(slug)
+	if err != nil {
+		handleServiceError(c, err, "Brand")
+		return
+	}
+	if redirectToCurrentSlug(c, slug, page.Brand.Slug) {
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Brand page retrieved successfully", page)
+}
+
+// UploadBrandLogo godoc
+// @Summary Upload brand logo
+// @Description Upload the logo of a brand (JPEG, PNG or WebP, max 25MB; larger than 5MB or 16 megapixels it is scaled down), replacing the current one (Admin only)
+// @Tags brands
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Brand ID"
+// @Param logo formData file true "Logo image"
+// @Success 200 {object} response.Response{data=models.SwaggerBrand} "Brand logo uploaded successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Brand not found"
+// @Failure 413 {object} response.Response "Image too large"
+// @Failure 415 {object} response.Response "Unsupported image type"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /brands/{id}/logo [post]
+func UploadBrandLogo(c *gin.Context, ctn *container.Container) {
+	brandID, ok := parseUintParam(c, "id", "Invalid brand id")
+	if !ok {
+		return
+	}
+	file, err := c.FormFile("logo")
+	if err != nil {
+		response.NewErrorResponse(c, apperrors.NewValidationFailed("logo file is required"))
+		return
+	}
+
+	brand, err := ctn.BrandService.SetBrandLogo(c.Request.Context(), brandID, file)
+	if err != nil {
+		handleServiceError(c, err, "Brand")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Brand logo uploaded successfully", brand)
+}
+
 // CreateBrand godoc
 // @Summary Create new brand
 // @Description Create a new brand (Admin only)
@@ -82,10 +171,11 @@ func CreateBrand(c *gin.Context, ctn *container.Container) {
 	req := middlewares.GetValidatedModel(c).(*models.BrandCreateRequest)
 
 	brand := models.Brand{
-		Name:        req.Name,
-		Description: req.Description,
-		Slug:        req.Slug,
-		IsActive:    false,
+		Name:           req.Name,
+		Description:    req.Description,
+		Slug:           req.Slug,
+		IsActive:       false,
+		WarrantyMonths: req.WarrantyMonths,
 	}
 
 	if req.IsActive != nil {
@@ -94,7 +184,7 @@ func CreateBrand(c *gin.Context, ctn *container.Container) {
 
 	newBrand, err := ctn.BrandService.CreateBrand(brand)
 	if err != nil {
-		response.DatabaseErrorResponse(c, err)
+		handleServiceError(c, err, "Brand")
 		return
 	}
 
@@ -103,7 +193,7 @@ func CreateBrand(c *gin.Context, ctn *container.Container) {
 
 // UpdateBrand godoc
 // @Summary Update brand
-// @Description Update brand information (Admin only)
+// @Description Update brand information. Deactivating a brand hides its products from the catalog and from carts (Admin only)
 // @Tags brands
 // @Accept json
 // @Produce json
@@ -136,21 +226,29 @@ func UpdateBrand(c *gin.Context, ctn *container.Container) {
 	}
 
 	req := middlewares.GetValidatedModel(c).(*models.BrandUpdateRequest)
+	if req.ClearWarrantyMonths && req.WarrantyMonths != nil {
+		response.NewErrorResponse(c, apperrors.NewValidationFailed("warranty_months and clear_warranty_months cannot be combined"))
+		return
+	}
 
 	updateBrand := models.Brand{
-		Name:        req.Name,
-		Description: req.Description,
-		Slug:        req.Slug,
-		IsActive:    brand.IsActive, // Keep existing value if not provided
+		Name:           req.Name,
+		Description:    req.Description,
+		Slug:           req.Slug,
+		IsActive:       brand.IsActive,       // Keep existing value if not provided
+		WarrantyMonths: brand.WarrantyMonths, // Keep existing value if not provided
 	}
 
 	if req.IsActive != nil {
 		updateBrand.IsActive = *req.IsActive
 	}
+	if req.WarrantyMonths != nil || req.ClearWarrantyMonths {
+		updateBrand.WarrantyMonths = req.WarrantyMonths
+	}
 
 	updatedBrand, err := ctn.BrandService.UpdateBrand(id, updateBrand)
 	if err != nil {
-		response.DatabaseErrorResponse(c, err)
+		handleServiceError(c, err, "Brand")
 		return
 	}
 
@@ -159,17 +257,19 @@ func UpdateBrand(c *gin.Context, ctn *container.Container) {
 
 // DeleteBrand godoc
 // @Summary Delete brand
-// @Description Delete a brand (Admin only)
+// @Description Move a brand to the trash, from which it can be restored until it is purged; brands that products or trade-in price rules still refer to are rejected, unless reassign_to names the active brand the products move to (Admin only)
 // @Tags brands
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Brand ID"
+// @Param reassign_to query int false "Brand the products are moved to"
 // @Success 200 {object} response.Response "Brand deleted successfully"
 // @Failure 400 {object} response.Response "Invalid request"
 // @Failure 401 {object} response.Response "Unauthorized"
 // @Failure 403 {object} response.Response "Forbidden"
 // @Failure 404 {object} response.Response "Brand not found"
+// @Failure 409 {object} response.Response "Brand is in use"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /brands/{id} [delete]
 func DeleteBrand(c *gin.Context, ctn *container.Container) {
@@ -179,20 +279,19 @@ func DeleteBrand(c *gin.Context, ctn *container.Container) {
 		return
 	}
 
-	// Check if brand exists
-	_, err := ctn.BrandService.GetBrandById(id)
+	reassignTo, err := optionalPositiveInt(c, "reassign_to")
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			response.NotFoundResponse(c, "Brand")
-			return
-		}
-		response.DatabaseErrorResponse(c, err)
+		response.NewErrorResponse(c, apperrors.NewValidationFailed("reassign_to must be a brand id"))
 		return
 	}
 
-	err = ctn.BrandService.DeleteBrand(id)
+	if reassignTo > 0 {
+		err = ctn.BrandService.ReassignAndDeleteBrand(id, uint(reassignTo))
+	} else {
+		err = ctn.BrandService.DeleteBrand(id)
+	}
 	if err != nil {
-		response.DatabaseErrorResponse(c, err)
+		handleServiceError(c, err, "Brand")
 		return
 	}
 
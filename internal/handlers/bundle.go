@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+	"api_techstore/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetBundleItems godoc
+// @Summary Set bundle components
+// @Description Replace the components of a bundle product. Each component is a product, or one of its variants when it has some, with the quantity included in one bundle. The bundle's quantity is the number of bundles the components' stock allows (Admin only)
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Bundle product ID"
+// @Param request body models.BundleItemsRequest true "Bundle components"
+// @Success 200 {object} response.Response{data=models.SwaggerProduct} "Bundle components updated successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/bundle-items [put]
+func SetBundleItems(c *gin.Context, ctn *container.Container) {
+	bundleID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.BundleItemsRequest)
+
+	items := make([]models.BundleItem, 0, len(req.Items))
+	for _, input := range req.Items {
+		items = append(items, models.BundleItem{
+			ComponentID:        input.ProductID,
+			ComponentVariantID: input.VariantID,
+			Quantity:           input.Quantity,
+		})
+	}
+	bundle, err := ctn.BundleService.SetBundleItems(bundleID, items)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Bundle components updated successfully", bundle)
+}
@@ -16,7 +16,7 @@ import (
 
 // GetCart godoc
 // @Summary Get cart
-// @Description Retrieve the current user's cart
+// @Description Retrieve the current user's cart, with a warning for each accessory that does not fit a device in the cart
 // @Tags cart
 // @Accept json
 // @Produce json
@@ -69,12 +69,17 @@ func GetCart(c *gin.Context, ctn *container.Container) {
 	}
 
 	cart.Items = items
+	cart.Warnings, err = ctn.CompatibilityService.CheckCart(items)
+	if err != nil {
+		response.DatabaseErrorResponse(c, err)
+		return
+	}
 	response.SuccessResponse(c, http.StatusOK, "Cart retrieved successfully", cart)
 }
 
 // AddItemToCart godoc
 // @Summary Add item to cart
-// @Description Add a product to the current user's cart
+// @Description Add a product to the current user's cart. Inactive products and those of inactive brands or categories cannot be added
 // @Tags cart
 // @Accept json
 // @Produce json
@@ -130,7 +135,27 @@ func AddItemToCart(c *gin.Context, ctn *container.Container) {
 		response.DatabaseErrorResponse(c, err)
 		return
 	}
-	if req.Quantity > product.Quantity {
+
+	// Sản phẩm có biến thể thì phải chọn biến thể, tồn kho tính theo biến thể
+	stock := product.Quantity
+	if len(product.Variants) > 0 || req.VariantID != nil {
+		if req.VariantID == nil {
+			response.NewErrorResponse(c, apperrors.NewValidationFailed("variant_id is required for this product"))
+			return
+		}
+		variant, err := ctn.ProductVariantService.GetVariantByID(req.ProductID, *req.VariantID)
+		if err != nil {
+			handleServiceError(c, err, "Product variant")
+			return
+		}
+		if !variant.IsActive {
+			response.NewErrorResponse(c, apperrors.NewValidationFailed("Product variant is not available"))
+			return
+		}
+		stock = variant.Quantity
+	}
+	// pre-order and backorder products can be ordered past the stock, up to their limit
+	if req.Quantity > stock+product.BackorderAllowance() {
 		response.ErrorResponse(c, http.StatusBadRequest, "Not enough product in stock")
 		return
 	}
@@ -138,12 +163,13 @@ func AddItemToCart(c *gin.Context, ctn *container.Container) {
 	cartItem := models.CartItem{
 		CartID:    cart.ID,
 		ProductID: req.ProductID,
+		VariantID: req.VariantID,
 		Quantity:  req.Quantity,
 	}
 
 	newItem, err := ctn.CartItemService.AddItemToCart(cartItem)
 	if err != nil {
-		response.DatabaseErrorResponse(c, err)
+		handleServiceError(c, err, "Product")
 		return
 	}
 
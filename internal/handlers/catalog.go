@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/models"
+	"api_techstore/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCatalogProducts godoc
+// @Summary List catalog products
+// @Description Public listing and search of the catalog. Only active products of active brands and categories are shown, without stock levels or internal fields. Takes the filters of /search/filters
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Param q query string false "Keyword"
+// @Param category_id query []int false "Category IDs" collectionFormat(multi)
+// @Param brand_id query []int false "Brand IDs" collectionFormat(multi)
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param in_stock query boolean false "Only in-stock (true) or out-of-stock (false) products"
+// @Param attr[code] query string false "Spec attribute filter, e.g. attr[ram_gb]=16,32 or attr[screen_inch]=13..15"
+// @Param sort query string false "Sort order" Enums(newest, price_asc, price_desc, rating)
+// @Param page query int false "Page, from 1"
+// @Param limit query int false "Products per page (default 20, max 100)"
+// @Success 200 {object} response.Response{data=models.CatalogProductList} "Catalog products retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /catalog/products [get]
+func GetCatalogProducts(c *gin.Context, ctn *container.Container) {
+	var query models.CatalogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ValidationErrorResponse(c, "Invalid catalog query")
+		return
+	}
+	query.Attributes = c.QueryMap("attr")
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice {
+		response.ValidationErrorResponse(c, "min_price must not be greater than max_price")
+		return
+	}
+
+	products, err := ctn.CatalogService.ListProducts(query)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Catalog products retrieved successfully", products)
+}
+
+// GetCatalogProduct godoc
+// @Summary Get catalog product
+// @Description Public detail of a listed product by slug; an old slug answers with a 301 redirect to the current one
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Param slug path string true "Product slug"
+// @Success 200 {object} response.Response{data=models.CatalogProduct} "Catalog product retrieved successfully"
+// @Success 301 "Moved to the current slug"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /catalog/products/{slug} [get]
+func GetCatalogProduct(c *gin.Context, ctn *container.Container) {
+	slug := c.Param("slug")
+	product, err := ctn.CatalogService.GetProduct(slug)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	if redirectToCurrentSlug(c, slug, product.Slug) {
+		return
+	}
+	recordProductView(c, ctn, product.ID)
+	response.SuccessResponse(c, http.StatusOK, "Catalog product retrieved successfully", product)
+}
+
+// GetCatalogCategories godoc
+// @Summary Get catalog categories
+// @Description Public tree of the active categories; an inactive category is left out with its subcategories
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]models.CatalogCategory} "Catalog categories retrieved successfully"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /catalog/categories [get]
+func GetCatalogCategories(c *gin.Context, ctn *container.Container) {
+	categories, err := ctn.CatalogService.GetCategoryTree()
+	if err != nil {
+		handleServiceError(c, err, "Category")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Catalog categories retrieved successfully", categories)
+}
+
+// GetCatalogBrands godoc
+// @Summary Get catalog brands
+// @Description Public list of the active brands
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]models.CatalogBrand} "Catalog brands retrieved successfully"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /catalog/brands [get]
+func GetCatalogBrands(c *gin.Context, ctn *container.Container) {
+	brands, err := ctn.CatalogService.GetBrands()
+	if err != nil {
+		handleServiceError(c, err, "Brand")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Catalog brands retrieved successfully", brands)
+}
+
+// GetCatalogBrand godoc
+// @Summary Get catalog brand page
+// @Description Public landing data of an active brand: product counts in total and per category, and its best sellers. An old slug answers with a 301 redirect to the current one
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Param slug path string true "Brand slug"
+// @Success 200 {object} response.Response{data=models.CatalogBrandPage} "Catalog brand retrieved successfully"
+// @Success 301 "Moved to the current slug"
+// @Failure 404 {object} response.Response "Brand not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /catalog/brands/{slug} [get]
+func GetCatalogBrand(c *gin.Context, ctn *container.Container) {
+	slug := c.Param("slug")
+	page, err := ctn.CatalogService.GetBrandPage(slug)
+	if err != nil {
+		handleServiceError(c, err, "Brand")
+		return
+	}
+	if redirectToCurrentSlug(c, slug, page.Brand.Slug) {
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Catalog brand retrieved successfully", page)
+}
@@ -6,6 +6,9 @@ import (
 	"api_techstore/internal/models"
 	"api_techstore/pkg/response"
 	"net/http"
+	"strconv"
+
+	apperrors "api_techstore/pkg/errors"
 
 	"github.com/gin-gonic/gin"
 )
@@ -50,6 +53,32 @@ func GetCategoryById(c *gin.Context, ctn *container.Container) {
 	response.SuccessResponse(c, http.StatusOK, "Category retrieved successfully", category)
 }
 
+// GetCategoryBySlug godoc
+// @Summary Get category by slug
+// @Description Retrieve a category by its slug; an old slug answers with a 301 redirect to the current one
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Category slug"
+// @Success 200 {object} response.Response{data=models.SwaggerCategory} "Category retrieved successfully"
+// @Success 301 "Moved to the current slug"
+// @Failure 404 {object} response.Response "Category not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /categories/slug/{slug} [get]
+func GetCategoryBySlug(c *gin.Context, ctn *container.Container) {
+	slug := c.Param("slug")
+	category, err := ctn.CategoryService.GetCategoryBySlug(slug)
+	if err != nil {
+		handleServiceError(c, err, "Category")
+		return
+	}
+	if redirectToCurrentSlug(c, slug, category.Slug) {
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Category retrieved successfully", category)
+}
+
 // CreateCategory godoc
 // @Summary Create new category
 // @Description Create a new category (Admin only)
@@ -68,13 +97,18 @@ func CreateCategory(c *gin.Context, ctn *container.Container) {
 	req := middlewares.GetValidatedModel(c).(*models.CategoryCreateRequest)
 
 	category := models.Category{
-		Name: req.Name,
-		Slug: req.Slug,
+		Name:     req.Name,
+		Slug:     req.Slug,
+		ParentID: req.ParentID,
+		IsActive: true,
+	}
+	if req.IsActive != nil {
+		category.IsActive = *req.IsActive
 	}
 
 	newCategory, err := ctn.CategoryService.CreateCategory(category)
 	if err != nil {
-		response.DatabaseErrorResponse(c, err)
+		handleServiceError(c, err, "Category")
 		return
 	}
 
@@ -83,7 +117,7 @@ func CreateCategory(c *gin.Context, ctn *container.Container) {
 
 // UpdateCategory godoc
 // @Summary Update category
-// @Description Update category information (Admin only)
+// @Description Update category information; parent_id moves the category with its subcategories, 0 moves it to the top level. Deactivating a category hides the products of its whole subtree from the catalog and from carts (Admin only)
 // @Tags categories
 // @Accept json
 // @Produce json
@@ -94,46 +128,127 @@ func CreateCategory(c *gin.Context, ctn *container.Container) {
 // @Failure 400 {object} response.Response "Invalid request"
 // @Failure 401 {object} response.Response "Unauthorized"
 // @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Category not found"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /categories/{id} [put]
 func UpdateCategory(c *gin.Context, ctn *container.Container) {
 	id := c.Param("id")
 	req := middlewares.GetValidatedModel(c).(*models.CategoryUpdateRequest)
 
+	current, err := ctn.CategoryService.GetCategoryById(id)
+	if err != nil {
+		handleServiceError(c, err, "Category")
+		return
+	}
+
 	category := models.Category{
-		Name: req.Name,
-		Slug: req.Slug,
+		Name:     req.Name,
+		Slug:     req.Slug,
+		IsActive: current.IsActive, // Keep existing value if not provided
+	}
+	if req.IsActive != nil {
+		category.IsActive = *req.IsActive
 	}
 
 	updatedCategory, err := ctn.CategoryService.UpdateCategory(id, category)
 	if err != nil {
-		response.DatabaseErrorResponse(c, err)
+		handleServiceError(c, err, "Category")
 		return
 	}
 
+	// Di chuyển category (cùng cây con) khi có parent_id, 0 = lên cấp cao nhất
+	if req.ParentID != nil {
+		var parentID *uint
+		if *req.ParentID != 0 {
+			parentID = req.ParentID
+		}
+		updatedCategory, err = ctn.CategoryService.MoveCategory(id, parentID)
+		if err != nil {
+			handleServiceError(c, err, "Category")
+			return
+		}
+	}
+
 	response.SuccessResponse(c, http.StatusOK, "Category updated successfully", updatedCategory)
 }
 
 // DeleteCategory godoc
 // @Summary Delete category
-// @Description Delete a category (Admin only)
+// @Description Move a category to the trash, from which it can be restored until it is purged; categories that still have subcategories or products are rejected, unless reassign_to names the active category their products move to. Spec values move to the target's attributes of the same code; the move is rejected when others would be lost, unless drop_attributes is set (Admin only)
 // @Tags categories
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Category ID"
+// @Param reassign_to query int false "Category the products are moved to"
+// @Param drop_attributes query bool false "Delete the spec values that do not fit the reassign_to category"
 // @Success 200 {object} response.Response "Category deleted successfully"
+// @Failure 400 {object} response.Response "Invalid reassign_to"
 // @Failure 401 {object} response.Response "Unauthorized"
 // @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Category not found"
+// @Failure 409 {object} response.Response "Category is not empty, or spec values would be lost"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /categories/{id} [delete]
 func DeleteCategory(c *gin.Context, ctn *container.Container) {
 	id := c.Param("id")
-	err := ctn.CategoryService.DeleteCategory(id)
+	reassignTo, err := optionalPositiveInt(c, "reassign_to")
 	if err != nil {
-		response.DatabaseErrorResponse(c, err)
+		response.NewErrorResponse(c, apperrors.NewValidationFailed("reassign_to must be a category id"))
+		return
+	}
+
+	if reassignTo > 0 {
+		dropAttributes, _ := strconv.ParseBool(c.Query("drop_attributes"))
+		err = ctn.CategoryService.ReassignAndDeleteCategory(id, uint(reassignTo), dropAttributes)
+	} else {
+		err = ctn.CategoryService.DeleteCategory(id)
+	}
+	if err != nil {
+		handleServiceError(c, err, "Category")
 		return
 	}
 
 	response.SuccessResponse(c, http.StatusOK, "Category deleted successfully", nil)
 }
+
+// GetCategoryTree godoc
+// @Summary Get category tree
+// @Description Retrieve all categories as a tree, subcategories nested in children
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.SwaggerCategoryNode} "Category tree retrieved successfully"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /categories/tree [get]
+func GetCategoryTree(c *gin.Context, ctn *container.Container) {
+	tree, err := ctn.CategoryService.GetCategoryTree()
+	if err != nil {
+		response.DatabaseErrorResponse(c, err)
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Category tree retrieved successfully", tree)
+}
+
+// GetCategoryProducts godoc
+// @Summary Get category products
+// @Description List the active products of a category including all its subcategories
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Category ID"
+// @Success 200 {object} response.Response{data=[]models.SwaggerProduct} "Category products retrieved successfully"
+// @Failure 404 {object} response.Response "Category not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /categories/{id}/products [get]
+func GetCategoryProducts(c *gin.Context, ctn *container.Container) {
+	id := c.Param("id")
+	products, err := ctn.CategoryService.GetCategoryProducts(id)
+	if err != nil {
+		handleServiceError(c, err, "Category")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Category products retrieved successfully", products)
+}
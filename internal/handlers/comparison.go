@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/pkg/response"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CompareProducts godoc
+// @Summary Compare products
+// @Description Compare 2-4 products of the same category side by side: price, brand, availability and spec attributes aligned into rows, with rows whose values differ flagged
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param ids query string true "Comma separated product IDs, e.g. 12,15,21"
+// @Success 200 {object} response.Response{data=models.ProductComparison} "Products compared successfully"
+// @Failure 400 {object} response.Response "Invalid request or products of different categories"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/compare [get]
+func CompareProducts(c *gin.Context, ctn *container.Container) {
+	// ids có thể truyền dạng ids=1,2,3 hoặc ids=1&ids=2
+	var ids []uint
+	for _, raw := range c.QueryArray("ids") {
+		for _, part := range strings.Split(raw, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			id, err := strconv.ParseUint(part, 10, 32)
+			if err != nil {
+				response.ValidationErrorResponse(c, "Invalid product id: "+part)
+				return
+			}
+			ids = append(ids, uint(id))
+		}
+	}
+
+	comparison, err := ctn.ComparisonService.CompareProducts(ids)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Products compared successfully", comparison)
+}
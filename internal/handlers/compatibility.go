@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+	"api_techstore/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCompatibleAccessories godoc
+// @Summary Get compatible accessories
+// @Description List the active accessories that fit a device: those linked to it and those whose specs match it by the compatibility rules of its category
+// @Tags compatibility
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Device product ID"
+// @Success 200 {object} response.Response{data=[]models.SwaggerProduct} "Compatible accessories retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/compatible-accessories [get]
+func GetCompatibleAccessories(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	products, err := ctn.CompatibilityService.GetCompatibleAccessories(productID)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Compatible accessories retrieved successfully", products)
+}
+
+// GetCompatibleDevices godoc
+// @Summary Get compatible devices
+// @Description List the devices an accessory is explicitly linked to
+// @Tags compatibility
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Accessory product ID"
+// @Success 200 {object} response.Response{data=[]models.SwaggerProduct} "Compatible devices retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/compatible-devices [get]
+func GetCompatibleDevices(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	products, err := ctn.CompatibilityService.GetCompatibleDevices(productID)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Compatible devices retrieved successfully", products)
+}
+
+// SetCompatibleDevices godoc
+// @Summary Set compatible devices
+// @Description Replace the devices an accessory is explicitly linked to. A link makes the accessory fit the device whatever the compatibility rules say; without rules between their categories, an accessory linked to devices of a category fits only those (Admin only)
+// @Tags compatibility
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Accessory product ID"
+// @Param request body models.CompatibleDevicesRequest true "Devices"
+// @Success 200 {object} response.Response{data=[]models.SwaggerProduct} "Compatible devices updated successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/compatible-devices [put]
+func SetCompatibleDevices(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.CompatibleDevicesRequest)
+
+	products, err := ctn.CompatibilityService.SetCompatibleDevices(productID, req.DeviceIDs)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Compatible devices updated successfully", products)
+}
+
+// GetCompatibilityRules godoc
+// @Summary Get compatibility rules
+// @Description List the spec rules matching accessories to devices (Admin only)
+// @Tags compatibility
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.SwaggerCompatibilityRule} "Compatibility rules retrieved successfully"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /compatibility-rules [get]
+func GetCompatibilityRules(c *gin.Context, ctn *container.Container) {
+	rules, err := ctn.CompatibilityService.GetRules()
+	if err != nil {
+		handleServiceError(c, err, "Compatibility rule")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Compatibility rules retrieved successfully", rules)
+}
+
+// CreateCompatibilityRule godoc
+// @Summary Create compatibility rule
+// @Description Match accessories of the category of one attribute to devices of the category of another, e.g. RAM memory type to laptop memory type. An accessory fits a device when the values are equal (ignoring case) for every rule between their categories (Admin only)
+// @Tags compatibility
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CompatibilityRuleCreateRequest true "Rule"
+// @Success 201 {object} response.Response{data=models.SwaggerCompatibilityRule} "Compatibility rule created successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Attribute not found"
+// @Failure 409 {object} response.Response "Rule already exists"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /compatibility-rules [post]
+func CreateCompatibilityRule(c *gin.Context, ctn *container.Container) {
+	req := middlewares.GetValidatedModel(c).(*models.CompatibilityRuleCreateRequest)
+
+	rule := models.CompatibilityRule{
+		AccessoryAttributeID: req.AccessoryAttributeID,
+		DeviceAttributeID:    req.DeviceAttributeID,
+		Description:          req.Description,
+	}
+	newRule, err := ctn.CompatibilityService.CreateRule(rule)
+	if err != nil {
+		handleServiceError(c, err, "Attribute")
+		return
+	}
+	response.SuccessResponse(c, http.StatusCreated, "Compatibility rule created successfully", newRule)
+}
+
+// DeleteCompatibilityRule godoc
+// @Summary Delete compatibility rule
+// @Description Delete a compatibility rule (Admin only)
+// @Tags compatibility
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param ruleId path string true "Rule ID"
+// @Success 200 {object} response.Response "Compatibility rule deleted successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Compatibility rule not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /compatibility-rules/{ruleId} [delete]
+func DeleteCompatibilityRule(c *gin.Context, ctn *container.Container) {
+	ruleID, ok := parseUintParam(c, "ruleId", "Invalid rule id")
+	if !ok {
+		return
+	}
+	if err := ctn.CompatibilityService.DeleteRule(ruleID); err != nil {
+		handleServiceError(c, err, "Compatibility rule")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Compatibility rule deleted successfully", nil)
+}
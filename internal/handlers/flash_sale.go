@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+	"api_techstore/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetFlashSales godoc
+// @Summary Get flash sales
+// @Description List the live and upcoming flash sales, soonest first, with the sale stock left
+// @Tags flash-sales
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.SwaggerFlashSale} "Flash sales retrieved successfully"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /flash-sales [get]
+func GetFlashSales(c *gin.Context, ctn *container.Container) {
+	sales, err := ctn.FlashSaleService.GetFlashSales(c.Request.Context())
+	if err != nil {
+		handleServiceError(c, err, "Flash sale")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Flash sales retrieved successfully", sales)
+}
+
+// GetFlashSaleByID godoc
+// @Summary Get flash sale
+// @Description Get a flash sale with the sale stock left
+// @Tags flash-sales
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Flash sale ID"
+// @Success 200 {object} response.Response{data=models.SwaggerFlashSale} "Flash sale retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Flash sale not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /flash-sales/{id} [get]
+func GetFlashSaleByID(c *gin.Context, ctn *container.Container) {
+	id, ok := parseUintParam(c, "id", "Invalid flash sale id")
+	if !ok {
+		return
+	}
+	sale, err := ctn.FlashSaleService.GetFlashSaleByID(c.Request.Context(), id)
+	if err != nil {
+		handleServiceError(c, err, "Flash sale")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Flash sale retrieved successfully", sale)
+}
+
+// CreateFlashSale godoc
+// @Summary Create flash sale
+// @Description Sell a limited quantity of a product, or of one of its variants, at a sale price during a time window, with a per-customer limit. The product must be active. Cart items of the product are ordered at the sale price while stock is left (Admin only)
+// @Tags flash-sales
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.FlashSaleCreateRequest true "Flash sale"
+// @Success 201 {object} response.Response{data=models.SwaggerFlashSale} "Flash sale created successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 409 {object} response.Response "Overlapping flash sale"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /flash-sales [post]
+func CreateFlashSale(c *gin.Context, ctn *container.Container) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.FlashSaleCreateRequest)
+
+	sale := models.FlashSale{
+		ProductID:     req.ProductID,
+		VariantID:     req.VariantID,
+		SalePrice:     req.SalePrice,
+		StartsAt:      req.StartsAt,
+		EndsAt:        req.EndsAt,
+		TotalQuantity: req.TotalQuantity,
+		PerUserLimit:  req.PerUserLimit,
+		CreatedBy:     userID,
+	}
+	newSale, err := ctn.FlashSaleService.CreateFlashSale(c.Request.Context(), sale)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusCreated, "Flash sale created successfully", newSale)
+}
+
+// CancelFlashSale godoc
+// @Summary Cancel flash sale
+// @Description Stop a flash sale; orders already placed keep the sale price (Admin only)
+// @Tags flash-sales
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Flash sale ID"
+// @Success 200 {object} response.Response "Flash sale cancelled successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Flash sale not found"
+// @Failure 409 {object} response.Response "Flash sale already cancelled"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /flash-sales/{id} [delete]
+func CancelFlashSale(c *gin.Context, ctn *container.Container) {
+	id, ok := parseUintParam(c, "id", "Invalid flash sale id")
+	if !ok {
+		return
+	}
+	if err := ctn.FlashSaleService.CancelFlashSale(c.Request.Context(), id); err != nil {
+		handleServiceError(c, err, "Flash sale")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Flash sale cancelled successfully", nil)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"api_techstore/pkg/response"
+	"net/http"
+	"strconv"
+	"strings"
+
+	apperrors "api_techstore/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// handleServiceError maps a service error to a response: missing records become
+// NOT_FOUND for the given resource, AppErrors are returned as is and anything
+// else is reported as a database error
+func handleServiceError(c *gin.Context, err error, resource string) {
+	if err == gorm.ErrRecordNotFound {
+		response.NotFoundResponse(c, resource)
+		return
+	}
+	if appErr := apperrors.GetAppError(err); appErr != nil {
+		response.NewErrorResponse(c, appErr)
+		return
+	}
+	response.DatabaseErrorResponse(c, err)
+}
+
+// parseUintParam reads a numeric path param, writing a validation error when it is invalid
+func parseUintParam(c *gin.Context, name, message string) (uint, bool) {
+	value, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		response.NewErrorResponse(c, apperrors.NewValidationFailed(message))
+		return 0, false
+	}
+	return uint(value), true
+}
+
+// redirectToCurrentSlug answers a lookup by an old slug with a permanent redirect to the
+// same URL carrying the current slug. It reports whether a redirect was written.
+func redirectToCurrentSlug(c *gin.Context, requested, current string) bool {
+	if requested == current {
+		return false
+	}
+	location := strings.TrimSuffix(c.Request.URL.Path, requested) + current
+	if c.Request.URL.RawQuery != "" {
+		location += "?" + c.Request.URL.RawQuery
+	}
+	c.Redirect(http.StatusMovedPermanently, location)
+	return true
+}
+
+// currentUserID returns the id of the authenticated user, writing an unauthorized error when it is missing
+func currentUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.NewErrorResponse(c, apperrors.NewUnauthorized())
+		return 0, false
+	}
+	id, ok := userID.(uint)
+	if !ok {
+		response.HandleError(c, apperrors.New(apperrors.ErrCodeInvalidInput, "Invalid user id type", http.StatusInternalServerError))
+		return 0, false
+	}
+	return id, true
+}
+
+// optionalPositiveInt reads an optional numeric query param, 0 when it is absent
+func optionalPositiveInt(c *gin.Context, name string) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err == nil && parsed < 1 {
+		err = strconv.ErrRange
+	}
+	return parsed, err
+}
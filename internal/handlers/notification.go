@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetNotifications godoc
+// @Summary Get my notifications
+// @Description List the current user's notifications, newest first
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param unread query bool false "Only unread notifications"
+// @Success 200 {object} response.Response{data=[]models.SwaggerNotification} "Notifications retrieved successfully"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /notifications [get]
+func GetNotifications(c *gin.Context, ctn *container.Container) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	unreadOnly, _ := strconv.ParseBool(c.Query("unread"))
+
+	notifications, err := ctn.NotificationService.GetNotifications(userID, unreadOnly)
+	if err != nil {
+		response.DatabaseErrorResponse(c, err)
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Notifications retrieved successfully", notifications)
+}
+
+// GetUnreadNotificationCount godoc
+// @Summary Count unread notifications
+// @Description Number of unread notifications of the current user
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=map[string]int64} "Unread notifications counted successfully"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /notifications/unread-count [get]
+func GetUnreadNotificationCount(c *gin.Context, ctn *container.Container) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	count, err := ctn.NotificationService.CountUnread(userID)
+	if err != nil {
+		response.DatabaseErrorResponse(c, err)
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Unread notifications counted successfully", gin.H{"unread": count})
+}
+
+// MarkNotificationAsRead godoc
+// @Summary Mark notification as read
+// @Description Mark one of the current user's notifications as read
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param notificationId path string true "Notification ID"
+// @Success 200 {object} response.Response{data=models.SwaggerNotification} "Notification marked as read"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Notification not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /notifications/{notificationId}/read [put]
+func MarkNotificationAsRead(c *gin.Context, ctn *container.Container) {
+	notificationID, ok := parseUintParam(c, "notificationId", "Invalid notification id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	notification, err := ctn.NotificationService.MarkAsRead(userID, notificationID)
+	if err != nil {
+		handleServiceError(c, err, "Notification")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Notification marked as read", notification)
+}
+
+// MarkAllNotificationsAsRead godoc
+// @Summary Mark all notifications as read
+// @Description Mark every unread notification of the current user as read
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response "Notifications marked as read"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /notifications/read-all [put]
+func MarkAllNotificationsAsRead(c *gin.Context, ctn *container.Container) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if err := ctn.NotificationService.MarkAllAsRead(userID); err != nil {
+		response.DatabaseErrorResponse(c, err)
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Notifications marked as read", nil)
+}
@@ -4,6 +4,7 @@ import (
 	"api_techstore/internal/container"
 	"api_techstore/internal/middlewares"
 	"api_techstore/internal/models"
+	"api_techstore/internal/services"
 	apperrors "api_techstore/pkg/errors"
 	"api_techstore/pkg/response"
 	"net/http"
@@ -54,7 +55,7 @@ func GetOrderByID(c *gin.Context, ctn *container.Container) {
 
 // CreateOrder godoc
 // @Summary Create new order
-// @Description Create a new order (User/Admin only)
+// @Description Create a new order from the user's cart (User/Admin only). Every line takes its units from stock. Items on a live flash sale are bought at the sale price, within the sale stock and the per-customer limit. Bundles are split into one line per component and take the components' stock. The value of an accepted trade-in given by trade_in_id is deducted from the total. Pre-order and backorder products can be bought past their stock up to their backorder limit, and the order then waits in awaiting_stock until the stock is received.
 // @Tags orders
 // @Accept json
 // @Produce json
@@ -64,6 +65,8 @@ func GetOrderByID(c *gin.Context, ctn *container.Container) {
 // @Failure 400 {object} response.Response "Invalid request"
 // @Failure 401 {object} response.Response "Unauthorized"
 // @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Trade-in not found"
+// @Failure 409 {object} response.Response "Flash sale sold out, per-customer limit reached, product out of stock or trade-in not available"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /order [post]
 func CreateOrder(c *gin.Context, ctn *container.Container) {
@@ -96,13 +99,29 @@ func CreateOrder(c *gin.Context, ctn *container.Container) {
 		return
 	}
 
-	totalAmount := 0.0
 	for _, item := range items {
 		if item.Product.ID == 0 {
 			response.ErrorResponse(c, http.StatusBadRequest, "Product not found for cart item")
 			return
 		}
-		totalAmount += float64(item.Quantity) * item.Product.Price
+	}
+
+	// flash sale stock is held in Redis until the order is saved, and given back if it is not
+	ctx := c.Request.Context()
+	reservations, err := ctn.FlashSaleService.Reserve(ctx, userIDUint, items)
+	if err != nil {
+		handleServiceError(c, err, "Flash sale")
+		return
+	}
+	releaseReservations := func() {
+		if err := ctn.FlashSaleService.Release(ctx, userIDUint, reservations); err != nil {
+			ctn.Logger.WithError(err).WithField("user_id", userIDUint).Error("Failed to release flash sale reservations")
+		}
+	}
+
+	totalAmount := 0.0
+	for i, item := range items {
+		totalAmount += float64(item.Quantity) * orderItemPrice(item, reservations[i])
 	}
 
 	order := models.Order{
@@ -116,28 +135,41 @@ func CreateOrder(c *gin.Context, ctn *container.Container) {
 		order.Status = "pending"
 	}
 
-	newOrder, err := ctn.OrderService.CreateOrder(order)
-	if err != nil {
-		response.DatabaseErrorResponse(c, err)
-		return
-	}
-
 	// Tạo order_items từ cart_items
 	var orderItems []models.OrderItem
-	for _, item := range items {
-		orderItems = append(orderItems, models.OrderItem{
-			OrderID:   newOrder.ID,
+	for i, item := range items {
+		orderItem := models.OrderItem{
 			ProductID: item.ProductID,
+			VariantID: item.VariantID,
 			Quantity:  item.Quantity,
-			UnitPrice: item.Product.Price,
-		})
-	}
-	if len(orderItems) > 0 {
-		db := ctn.DB
-		if err := db.Create(&orderItems).Error; err != nil {
-			response.DatabaseErrorResponse(c, err)
-			return
+			UnitPrice: orderItemPrice(item, reservations[i]),
+		}
+		if item.Variant != nil {
+			orderItem.SKU = item.Variant.SKU
 		}
+		if reservations[i] != nil {
+			orderItem.FlashSaleID = &reservations[i].FlashSaleID
+		}
+		orderItems = append(orderItems, orderItem)
+	}
+	// bundles are fulfilled from their components, whose stock is taken with the lines
+	orderItems, err = ctn.BundleService.ExpandOrderItems(orderItems)
+	if err != nil {
+		releaseReservations()
+		handleServiceError(c, err, "Product")
+		return
+	}
+
+	// the order, its trade-in credit and its lines are saved in one transaction
+	newOrder, err := ctn.OrderService.CreateOrder(order, orderItems, req.TradeInID)
+	if err != nil {
+		releaseReservations()
+		handleServiceError(c, err, "Product")
+		return
+	}
+	// the sold counts are recomputed from the order lines, so a failure only delays them
+	if err := ctn.FlashSaleService.SyncSold(ctx, reservations); err != nil {
+		ctn.Logger.WithError(err).WithField("order_id", newOrder.ID).Warn("Failed to synchronize flash sale sold quantities")
 	}
 
 	// Reload order để trả về kèm order_items
@@ -153,7 +185,7 @@ func CreateOrder(c *gin.Context, ctn *container.Container) {
 
 // UpdateOrder godoc
 // @Summary Update order
-// @Description Update order information (User/Admin only) - Only status and shipping_address_id can be updated
+// @Description Update order information (User/Admin only) - Only status and shipping_address_id can be updated. A customer can only cancel their order; the other status changes are for admins. Cancelling an order gives back the stock its lines took
 // @Tags orders
 // @Accept json
 // @Produce json
@@ -165,6 +197,7 @@ func CreateOrder(c *gin.Context, ctn *container.Container) {
 // @Failure 401 {object} response.Response "Unauthorized"
 // @Failure 403 {object} response.Response "Forbidden"
 // @Failure 404 {object} response.Response "Order not found"
+// @Failure 409 {object} response.Response "Order has units waiting for stock, is cancelled or has shipped"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /order/{id} [put]
 func UpdateOrder(c *gin.Context, ctn *container.Container) {
@@ -183,6 +216,12 @@ func UpdateOrder(c *gin.Context, ctn *container.Container) {
 
 	req := middlewares.GetValidatedModel(c).(*models.OrderUpdateRequest)
 
+	// customers may cancel their order, moving it through fulfilment is for the staff
+	if role, _ := c.Get("role"); role != "admin" && req.Status != "" && req.Status != currentOrder.Status && req.Status != "cancelled" {
+		response.ForbiddenResponse(c)
+		return
+	}
+
 	// Chỉ update các trường được phép, giữ nguyên user_id và total_amount
 	order := models.Order{
 		UserID:            currentOrder.UserID,      // Giữ nguyên user_id hiện tại
@@ -203,7 +242,7 @@ func UpdateOrder(c *gin.Context, ctn *container.Container) {
 
 	updatedOrder, err := ctn.OrderService.UpdateOrder(id, order)
 	if err != nil {
-		response.DatabaseErrorResponse(c, err)
+		handleServiceError(c, err, "Order")
 		return
 	}
 
@@ -212,7 +251,7 @@ func UpdateOrder(c *gin.Context, ctn *container.Container) {
 
 // DeleteOrder godoc
 // @Summary Delete order
-// @Description Delete an order (User/Admin only)
+// @Description Delete an order (User/Admin only); the stock its lines took is given back
 // @Tags orders
 // @Accept json
 // @Produce json
@@ -262,3 +301,20 @@ func GetOrdersByUserID(c *gin.Context, ctn *container.Container) {
 
 	response.SuccessResponse(c, http.StatusOK, "Orders retrieved successfully", orders)
 }
+
+// orderItemPrice is the unit price a cart item is ordered at: the flash sale price when the
+// item was reserved on a sale, its regular price otherwise
+func orderItemPrice(item models.CartItem, reservation *services.FlashSaleReservation) float64 {
+	if reservation != nil {
+		return reservation.SalePrice
+	}
+	return cartItemPrice(item)
+}
+
+// cartItemPrice is the unit price of a cart item: the variant's price when a variant was chosen
+func cartItemPrice(item models.CartItem) float64 {
+	if item.Variant != nil {
+		return item.Variant.Price
+	}
+	return item.Product.Price
+}
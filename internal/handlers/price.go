@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+	"api_techstore/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetProductPriceHistory godoc
+// @Summary Get product price history
+// @Description List the price changes of a product, newest first, with the lowest price of the 30 days before the current price took effect: the only price a reduction may be advertised against (Admin only)
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} response.Response{data=models.SwaggerProductPriceHistory} "Price history retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/price-history [get]
+func GetProductPriceHistory(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	history, err := ctn.PriceService.GetPriceHistory(productID)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Price history retrieved successfully", history)
+}
+
+// GetScheduledPrices godoc
+// @Summary Get scheduled prices
+// @Description List the scheduled price changes of a product, latest start first (Admin only)
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} response.Response{data=[]models.SwaggerScheduledPrice} "Scheduled prices retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/scheduled-prices [get]
+func GetScheduledPrices(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	schedules, err := ctn.PriceService.GetScheduledPrices(productID)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Scheduled prices retrieved successfully", schedules)
+}
+
+// CreateScheduledPrice godoc
+// @Summary Schedule a price
+// @Description Set the price of a product at starts_at; with ends_at the previous price is restored at the end, unless the price was changed by hand meanwhile. Periods of the same product may not overlap (Admin only)
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body models.ScheduledPriceCreateRequest true "Scheduled price"
+// @Success 201 {object} response.Response{data=models.SwaggerScheduledPrice} "Price scheduled successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 409 {object} response.Response "Overlapping scheduled price"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/scheduled-prices [post]
+func CreateScheduledPrice(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.ScheduledPriceCreateRequest)
+
+	schedule := models.ScheduledPrice{
+		Price:     req.Price,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		Note:      req.Note,
+		CreatedBy: userID,
+	}
+	newSchedule, err := ctn.PriceService.SchedulePrice(productID, schedule)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusCreated, "Price scheduled successfully", newSchedule)
+}
+
+// CancelScheduledPrice godoc
+// @Summary Cancel a scheduled price
+// @Description Cancel a pending scheduled price, or end an active one now and restore the previous price (Admin only)
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param scheduleId path string true "Scheduled price ID"
+// @Success 200 {object} response.Response{data=models.SwaggerScheduledPrice} "Scheduled price cancelled successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Scheduled price not found"
+// @Failure 409 {object} response.Response "Scheduled price already completed or cancelled"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/scheduled-prices/{scheduleId} [delete]
+func CancelScheduledPrice(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	scheduleID, ok := parseUintParam(c, "scheduleId", "Invalid scheduled price id")
+	if !ok {
+		return
+	}
+	schedule, err := ctn.PriceService.CancelScheduledPrice(productID, scheduleID)
+	if err != nil {
+		handleServiceError(c, err, "Scheduled price")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Scheduled price cancelled successfully", schedule)
+}
@@ -4,6 +4,7 @@ import (
 	"api_techstore/internal/container"
 	"api_techstore/internal/middlewares"
 	"api_techstore/internal/models"
+	apperrors "api_techstore/pkg/errors"
 	"api_techstore/pkg/response"
 	"net/http"
 
@@ -13,7 +14,7 @@ import (
 
 // GetAllProducts godoc
 // @Summary Get all products
-// @Description Retrieve all products
+// @Description Retrieve the products of the catalog: inactive products and those of inactive brands or categories are left out, except for admins
 // @Tags products
 // @Accept json
 // @Produce json
@@ -22,7 +23,12 @@ import (
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /products [get]
 func GetAllProducts(c *gin.Context, ctn *container.Container) {
-	products, err := ctn.ProductService.GetAllProducts()
+	getProducts := ctn.ProductService.GetAllProducts
+	// admins also see the products hidden from the catalog
+	if role, _ := c.Get("role"); role == "admin" {
+		getProducts = ctn.ProductService.GetAllProductsIncludingHidden
+	}
+	products, err := getProducts()
 	if err != nil {
 		response.DatabaseErrorResponse(c, err)
 		return
@@ -53,6 +59,33 @@ func GetProductById(c *gin.Context, ctn *container.Container) {
 		response.DatabaseErrorResponse(c, err)
 		return
 	}
+	recordProductView(c, ctn, product.ID)
+	response.SuccessResponse(c, http.StatusOK, "Product retrieved successfully", product)
+}
+
+// GetProductBySlug godoc
+// @Summary Get product by slug
+// @Description Retrieve a product by its slug; an old slug answers with a 301 redirect to the current one
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Product slug"
+// @Success 200 {object} response.Response{data=models.SwaggerProduct} "Product retrieved successfully"
+// @Success 301 "Moved to the current slug"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/slug/{slug} [get]
+func GetProductBySlug(c *gin.Context, ctn *container.Container) {
+	slug := c.Param("slug")
+	product, err := ctn.ProductService.GetProductBySlug(slug)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	if redirectToCurrentSlug(c, slug, product.Slug) {
+		return
+	}
 	response.SuccessResponse(c, http.StatusOK, "Product retrieved successfully", product)
 }
 
@@ -73,21 +106,37 @@ func GetProductById(c *gin.Context, ctn *container.Container) {
 func CreateProduct(c *gin.Context, ctn *container.Container) {
 	req := middlewares.GetValidatedModel(c).(*models.ProductCreateRequest)
 	productModel := models.Product{
-		Name:        req.Name,
-		Description: req.Description,
-		Price:       req.Price,
-		Quantity:    req.Quantity,
-		CategoryID:  req.CategoryID,
-		BrandID:     req.BrandID,
-		Slug:        req.Slug,
-		IsActive:    false,
+		Name:           req.Name,
+		Description:    req.Description,
+		Price:          req.Price,
+		Quantity:       req.Quantity,
+		CategoryID:     req.CategoryID,
+		BrandID:        req.BrandID,
+		Slug:           req.Slug,
+		IsActive:       false,
+		Type:           models.ProductTypeSimple,
+		WarrantyMonths: req.WarrantyMonths,
 	}
 	if req.IsActive != nil {
 		productModel.IsActive = *req.IsActive
 	}
+	// the stock of a bundle is the one of its components
+	if req.Type == models.ProductTypeBundle {
+		productModel.Type = models.ProductTypeBundle
+		productModel.Quantity = 0
+	}
+
+	// Validate spec attributes against the category definitions
+	attributes, err := ctn.AttributeService.BuildProductAttributes(req.CategoryID, nil, req.Attributes)
+	if err != nil {
+		handleServiceError(c, err, "Category")
+		return
+	}
+	productModel.Attributes = attributes
+
 	newProduct, err := ctn.ProductService.CreateProduct(productModel)
 	if err != nil {
-		response.DatabaseErrorResponse(c, err)
+		handleServiceError(c, err, "Product")
 		return
 	}
 	response.SuccessResponse(c, http.StatusCreated, "Product created successfully", newProduct)
@@ -95,7 +144,7 @@ func CreateProduct(c *gin.Context, ctn *container.Container) {
 
 // UpdateProduct godoc
 // @Summary Update product
-// @Description Update product information (Admin only)
+// @Description Update product information; a quantity increase first goes to the pre-ordered and backordered units waiting for stock (Admin only)
 // @Tags products
 // @Accept json
 // @Produce json
@@ -112,26 +161,50 @@ func CreateProduct(c *gin.Context, ctn *container.Container) {
 func UpdateProduct(c *gin.Context, ctn *container.Container) {
 	id := c.Param("id")
 	req := middlewares.GetValidatedModel(c).(*models.ProductUpdateRequest)
+	if req.ClearWarrantyMonths && req.WarrantyMonths != nil {
+		response.NewErrorResponse(c, apperrors.NewValidationFailed("warranty_months and clear_warranty_months cannot be combined"))
+		return
+	}
+	current, err := ctn.ProductService.GetProductById(id)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
 	productModel := models.Product{
-		Name:        req.Name,
-		Description: req.Description,
-		Price:       req.Price,
-		Quantity:    req.Quantity,
-		CategoryID:  req.CategoryID,
-		BrandID:     req.BrandID,
-		Slug:        req.Slug,
-		IsActive:    false,
+		Name:           req.Name,
+		Description:    req.Description,
+		Price:          req.Price,
+		Quantity:       req.Quantity,
+		CategoryID:     req.CategoryID,
+		BrandID:        req.BrandID,
+		Slug:           req.Slug,
+		IsActive:       false,
+		WarrantyMonths: current.WarrantyMonths, // Keep existing value if not provided
 	}
 	if req.IsActive != nil {
 		productModel.IsActive = *req.IsActive
 	}
-	updatedProduct, err := ctn.ProductService.UpdateProduct(id, productModel)
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			response.NotFoundResponse(c, "Product")
+	if req.WarrantyMonths != nil || req.ClearWarrantyMonths {
+		productModel.WarrantyMonths = req.WarrantyMonths
+	}
+
+	// Spec attributes are revalidated when they are changed or the product moves to another category
+	categoryID := current.CategoryID
+	if req.CategoryID != 0 {
+		categoryID = req.CategoryID
+	}
+	if req.Attributes != nil || categoryID != current.CategoryID {
+		attributes, err := ctn.AttributeService.BuildProductAttributes(categoryID, current.Attributes, req.Attributes)
+		if err != nil {
+			handleServiceError(c, err, "Category")
 			return
 		}
-		response.DatabaseErrorResponse(c, err)
+		productModel.Attributes = attributes
+	}
+
+	updatedProduct, err := ctn.ProductService.UpdateProduct(id, productModel)
+	if err != nil {
+		handleServiceError(c, err, "Product")
 		return
 	}
 	response.SuccessResponse(c, http.StatusOK, "Product updated successfully", updatedProduct)
@@ -139,7 +212,7 @@ func UpdateProduct(c *gin.Context, ctn *container.Container) {
 
 // DeleteProduct godoc
 // @Summary Delete product
-// @Description Delete a product (Admin only)
+// @Description Move a product to the trash, from which it can be restored until it is purged (Admin only)
 // @Tags products
 // @Accept json
 // @Produce json
@@ -2,50 +2,183 @@ package handlers
 
 import (
 	"api_techstore/internal/container"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
 	"api_techstore/pkg/response"
 	"net/http"
+	"strconv"
+
+	apperrors "api_techstore/pkg/errors"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+// GetProductImages godoc
+// @Summary Get product images
+// @Description List the images of a product, main image first then by sort order
+// @Tags product-images
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} response.Response{data=[]models.SwaggerProductImage} "Product images retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/images [get]
+func GetProductImages(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+
+	images, err := ctn.ProductImageService.GetImagesByProductID(productID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			response.NotFoundResponse(c, "Product")
+			return
+		}
+		response.DatabaseErrorResponse(c, err)
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Product images retrieved successfully", images)
+}
+
 // AddProductImage godoc
 // @Summary Add product image
-// @Description Add an image to a product (Admin only)
+// @Description Upload an image (JPEG, PNG or WebP, max 25MB) for a product; an image larger than 5MB or 16 megapixels is scaled down. Upright thumbnail, medium and large variants are generated in WebP and in JPEG for photos or PNG for images with transparency (Admin only)
 // @Tags product-images
-// @Accept json
+// @Accept multipart/form-data
 // @Produce json
 // @Security BearerAuth
-// @Param product_id path string true "Product ID"
-// @Param image_url formData string true "Image URL"
+// @Param id path string true "Product ID"
+// @Param image formData file true "Image file"
 // @Param is_main formData boolean false "Is main image"
-// @Success 201 {object} response.Response "Product image added successfully"
+// @Success 201 {object} response.Response{data=models.SwaggerProductImage} "Product image added successfully"
 // @Failure 400 {object} response.Response "Invalid request"
 // @Failure 401 {object} response.Response "Unauthorized"
 // @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 413 {object} response.Response "Image too large"
+// @Failure 415 {object} response.Response "Unsupported image type"
 // @Failure 500 {object} response.Response "Internal server error"
-// @Router /products/{product_id}/images [post]
+// @Router /products/{id}/images [post]
 func AddProductImage(c *gin.Context, ctn *container.Container) {
-	// TODO: Implement product image upload logic
-	response.SuccessResponse(c, http.StatusCreated, "Product image added successfully", nil)
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+
+	file, err := c.FormFile("image")
+	if err != nil {
+		response.NewErrorResponse(c, apperrors.NewValidationFailed("image file is required"))
+		return
+	}
+	isMain, _ := strconv.ParseBool(c.PostForm("is_main"))
+
+	image, err := ctn.ProductImageService.AddProductImage(c.Request.Context(), productID, file, isMain)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusCreated, "Product image added successfully", image)
+}
+
+// SetMainProductImage godoc
+// @Summary Set main product image
+// @Description Mark an image as the product's main image (Admin only)
+// @Tags product-images
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param imageId path string true "Image ID"
+// @Success 200 {object} response.Response{data=models.SwaggerProductImage} "Main image updated successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Product image not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/images/{imageId}/main [put]
+func SetMainProductImage(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	imageID, ok := parseUintParam(c, "imageId", "Invalid image id")
+	if !ok {
+		return
+	}
+
+	image, err := ctn.ProductImageService.SetMainImage(productID, imageID)
+	if err != nil {
+		handleServiceError(c, err, "Product image")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Main image updated successfully", image)
+}
+
+// ReorderProductImages godoc
+// @Summary Reorder product images
+// @Description Set the display order of all images of a product (Admin only)
+// @Tags product-images
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body models.ProductImageReorderRequest true "Image IDs in display order"
+// @Success 200 {object} response.Response{data=[]models.SwaggerProductImage} "Product images reordered successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/images/order [put]
+func ReorderProductImages(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.ProductImageReorderRequest)
+
+	images, err := ctn.ProductImageService.ReorderImages(productID, req.ImageIDs)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Product images reordered successfully", images)
 }
 
 // DeleteProductImage godoc
 // @Summary Delete product image
-// @Description Delete a product image (Admin only)
+// @Description Delete a product image and its stored file (Admin only)
 // @Tags product-images
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param product_id path string true "Product ID"
-// @Param image_id path string true "Image ID"
+// @Param id path string true "Product ID"
+// @Param imageId path string true "Image ID"
 // @Success 200 {object} response.Response "Product image deleted successfully"
 // @Failure 400 {object} response.Response "Invalid request"
 // @Failure 401 {object} response.Response "Unauthorized"
 // @Failure 403 {object} response.Response "Forbidden"
 // @Failure 404 {object} response.Response "Product image not found"
 // @Failure 500 {object} response.Response "Internal server error"
-// @Router /products/{product_id}/images/{image_id} [delete]
+// @Router /products/{id}/images/{imageId} [delete]
 func DeleteProductImage(c *gin.Context, ctn *container.Container) {
-	// TODO: Implement product image deletion logic
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	imageID, ok := parseUintParam(c, "imageId", "Invalid image id")
+	if !ok {
+		return
+	}
+
+	err := ctn.ProductImageService.DeleteProductImage(c.Request.Context(), productID, imageID)
+	if err != nil {
+		handleServiceError(c, err, "Product image")
+		return
+	}
 	response.SuccessResponse(c, http.StatusOK, "Product image deleted successfully", nil)
 }
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/services"
+	"api_techstore/pkg/response"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	apperrors "api_techstore/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportProducts godoc
+// @Summary Import products
+// @Description Upload a CSV or XLSX file of products (max 10MB, 10000 rows) processed in the background. Columns: slug, name, description, price, quantity, category_slug, brand_slug, is_active and attr:<code> for spec values; name, price, quantity and category_slug are required. Rows whose slug matches a product update it, others create a product. Poll the returned job for progress and the per-row error report (Admin only)
+// @Tags product-imports
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV or XLSX file"
+// @Success 202 {object} response.Response{data=models.SwaggerImportJob} "Product import started successfully"
+// @Failure 400 {object} response.Response "Invalid file"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 413 {object} response.Response "File too large"
+// @Failure 415 {object} response.Response "Unsupported file type"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/imports [post]
+func ImportProducts(c *gin.Context, ctn *container.Container) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		response.NewErrorResponse(c, apperrors.NewValidationFailed("file is required"))
+		return
+	}
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+	if format != services.ProductFileCSV && format != services.ProductFileXLSX {
+		response.NewErrorResponse(c, apperrors.New(apperrors.ErrCodeUnsupportedFileType,
+			"Only CSV and XLSX files are allowed", http.StatusUnsupportedMediaType))
+		return
+	}
+	tooLarge := apperrors.New(apperrors.ErrCodeFileTooLarge,
+		fmt.Sprintf("File must not exceed %dMB", services.MaxImportFileSize>>20), http.StatusRequestEntityTooLarge)
+	if file.Size > services.MaxImportFileSize {
+		response.NewErrorResponse(c, tooLarge)
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		response.NewErrorResponse(c, apperrors.NewValidationFailed("Unable to read uploaded file"))
+		return
+	}
+	defer src.Close()
+	data, err := io.ReadAll(io.LimitReader(src, services.MaxImportFileSize+1))
+	if err != nil {
+		response.NewErrorResponse(c, apperrors.NewValidationFailed("Unable to read uploaded file"))
+		return
+	}
+	if len(data) > services.MaxImportFileSize {
+		response.NewErrorResponse(c, tooLarge)
+		return
+	}
+
+	job, err := ctn.ProductImportService.StartImport(filepath.Base(file.Filename), format, data, userID)
+	if err != nil {
+		handleServiceError(c, err, "Import job")
+		return
+	}
+	response.SuccessResponse(c, http.StatusAccepted, "Product import started successfully", job)
+}
+
+// GetImportJobs godoc
+// @Summary Get product imports
+// @Description List the 50 latest product import jobs, without their row reports (Admin only)
+// @Tags product-imports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.SwaggerImportJob} "Import jobs retrieved successfully"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/imports [get]
+func GetImportJobs(c *gin.Context, ctn *container.Container) {
+	jobs, err := ctn.ProductImportService.GetImportJobs()
+	if err != nil {
+		response.DatabaseErrorResponse(c, err)
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Import jobs retrieved successfully", jobs)
+}
+
+// GetImportJob godoc
+// @Summary Get product import
+// @Description Progress of a product import job with the errors of the rejected rows (Admin only)
+// @Tags product-imports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param jobId path string true "Import job ID"
+// @Success 200 {object} response.Response{data=models.SwaggerImportJob} "Import job retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Import job not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/imports/{jobId} [get]
+func GetImportJob(c *gin.Context, ctn *container.Container) {
+	jobID, ok := parseUintParam(c, "jobId", "Invalid import job id")
+	if !ok {
+		return
+	}
+	job, err := ctn.ProductImportService.GetImportJob(jobID)
+	if err != nil {
+		handleServiceError(c, err, "Import job")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Import job retrieved successfully", job)
+}
+
+// ExportProducts godoc
+// @Summary Export products
+// @Description Download every product in the import layout, spec values as attr:<code> columns (Admin only)
+// @Tags product-imports
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param format query string false "File format" Enums(csv, xlsx) default(csv)
+// @Success 200 {file} file "Product spreadsheet"
+// @Failure 400 {object} response.Response "Invalid format"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/export [get]
+func ExportProducts(c *gin.Context, ctn *container.Container) {
+	format := strings.ToLower(c.DefaultQuery("format", services.ProductFileCSV))
+	data, err := ctn.ProductImportService.ExportProducts(format)
+	if err != nil {
+		handleServiceError(c, err, "Products")
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == services.ProductFileXLSX {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	fileName := fmt.Sprintf("products-%s.%s", time.Now().Format("20060102-150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.Data(http.StatusOK, contentType, data)
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+	"api_techstore/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetProductVariants godoc
+// @Summary Get product variants
+// @Description List the variants of a product with their options and images
+// @Tags product-variants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} response.Response{data=[]models.SwaggerProductVariant} "Product variants retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/variants [get]
+func GetProductVariants(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+
+	variants, err := ctn.ProductVariantService.GetVariantsByProductID(productID)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Product variants retrieved successfully", variants)
+}
+
+// GetProductVariant godoc
+// @Summary Get product variant
+// @Description Retrieve a single variant of a product
+// @Tags product-variants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Success 200 {object} response.Response{data=models.SwaggerProductVariant} "Product variant retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 404 {object} response.Response "Product variant not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/variants/{variantId} [get]
+func GetProductVariant(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	variantID, ok := parseUintParam(c, "variantId", "Invalid variant id")
+	if !ok {
+		return
+	}
+
+	variant, err := ctn.ProductVariantService.GetVariantByID(productID, variantID)
+	if err != nil {
+		handleServiceError(c, err, "Product variant")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Product variant retrieved successfully", variant)
+}
+
+// CreateProductVariant godoc
+// @Summary Create product variant
+// @Description Add a variant with its own SKU, price, stock and option values; the option combination must be unique within the product (Admin only)
+// @Tags product-variants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body models.ProductVariantCreateRequest true "Variant data"
+// @Success 201 {object} response.Response{data=models.SwaggerProductVariant} "Product variant created successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 409 {object} response.Response "SKU or option combination already exists"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/variants [post]
+func CreateProductVariant(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.ProductVariantCreateRequest)
+
+	variant := models.ProductVariant{
+		SKU:      req.SKU,
+		Price:    req.Price,
+		Quantity: req.Quantity,
+		IsActive: true,
+		Options:  variantOptions(req.Options),
+	}
+	if req.IsActive != nil {
+		variant.IsActive = *req.IsActive
+	}
+
+	newVariant, err := ctn.ProductVariantService.CreateVariant(productID, variant, req.ImageIDs)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusCreated, "Product variant created successfully", newVariant)
+}
+
+// UpdateProductVariant godoc
+// @Summary Update product variant
+// @Description Update a variant; omitted fields keep their value and image_ids, when given, replaces the variant's images; a quantity increase first goes to the units waiting for stock (Admin only)
+// @Tags product-variants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Param request body models.ProductVariantUpdateRequest true "Variant update data"
+// @Success 200 {object} response.Response{data=models.SwaggerProductVariant} "Product variant updated successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Product variant not found"
+// @Failure 409 {object} response.Response "SKU or option combination already exists"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/variants/{variantId} [put]
+func UpdateProductVariant(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	variantID, ok := parseUintParam(c, "variantId", "Invalid variant id")
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.ProductVariantUpdateRequest)
+
+	current, err := ctn.ProductVariantService.GetVariantByID(productID, variantID)
+	if err != nil {
+		handleServiceError(c, err, "Product variant")
+		return
+	}
+
+	// Giữ nguyên các trường không có trong request
+	variant := models.ProductVariant{
+		SKU:      current.SKU,
+		Price:    current.Price,
+		Quantity: current.Quantity,
+		IsActive: current.IsActive,
+		Options:  current.Options,
+	}
+	if req.SKU != "" {
+		variant.SKU = req.SKU
+	}
+	if req.Price > 0 {
+		variant.Price = req.Price
+	}
+	if req.Quantity != nil {
+		variant.Quantity = *req.Quantity
+	}
+	if req.IsActive != nil {
+		variant.IsActive = *req.IsActive
+	}
+	if len(req.Options) > 0 {
+		variant.Options = variantOptions(req.Options)
+	}
+
+	updatedVariant, err := ctn.ProductVariantService.UpdateVariant(productID, variantID, variant, req.ImageIDs)
+	if err != nil {
+		handleServiceError(c, err, "Product variant")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Product variant updated successfully", updatedVariant)
+}
+
+// DeleteProductVariant godoc
+// @Summary Delete product variant
+// @Description Delete a variant; its images stay on the product and it is removed from carts (Admin only)
+// @Tags product-variants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Success 200 {object} response.Response "Product variant deleted successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Product variant not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/variants/{variantId} [delete]
+func DeleteProductVariant(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	variantID, ok := parseUintParam(c, "variantId", "Invalid variant id")
+	if !ok {
+		return
+	}
+
+	if err := ctn.ProductVariantService.DeleteVariant(productID, variantID); err != nil {
+		handleServiceError(c, err, "Product variant")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Product variant deleted successfully", nil)
+}
+
+func variantOptions(inputs []models.ProductVariantOptionInput) []models.ProductVariantOption {
+	options := make([]models.ProductVariantOption, 0, len(inputs))
+	for _, input := range inputs {
+		options = append(options, models.ProductVariantOption{Name: input.Name, Value: input.Value})
+	}
+	return options
+}
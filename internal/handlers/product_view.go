@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/pkg/response"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	apperrors "api_techstore/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionIDHeader identifies a guest's browsing session
+const SessionIDHeader = "X-Session-ID"
+
+var sessionIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{8,64}$`)
+
+// viewerKey identifies whose browsing history a request belongs to: the authenticated user,
+// otherwise the guest session from the X-Session-ID header. It is empty when neither is known.
+func viewerKey(c *gin.Context) string {
+	if userID, exists := c.Get("user_id"); exists {
+		if id, ok := userID.(uint); ok {
+			return "user:" + strconv.FormatUint(uint64(id), 10)
+		}
+	}
+	if sessionID := c.GetHeader(SessionIDHeader); sessionIDPattern.MatchString(sessionID) {
+		return "session:" + sessionID
+	}
+	return ""
+}
+
+// recordProductView tracks a product page view; failures are logged and never fail the request
+func recordProductView(c *gin.Context, ctn *container.Container, productID uint) {
+	if err := ctn.ProductViewService.RecordView(c.Request.Context(), viewerKey(c), productID); err != nil {
+		ctn.Logger.WithError(err).WithField("product_id", productID).Warn("Failed to record product view")
+	}
+}
+
+// GetRecentlyViewed godoc
+// @Summary Get recently viewed products
+// @Description Products the current user (or guest session, from the X-Session-ID header) viewed most recently, latest first. Guests call it without a token
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Session-ID header string false "Guest session id"
+// @Param limit query int false "Number of products (default and max 50)"
+// @Success 200 {object} response.Response{data=[]models.SwaggerProduct} "Recently viewed products retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /me/recently-viewed [get]
+func GetRecentlyViewed(c *gin.Context, ctn *container.Container) {
+	viewer := viewerKey(c)
+	if viewer == "" {
+		response.NewErrorResponse(c, apperrors.NewValidationFailed("A logged in user or an X-Session-ID header is required"))
+		return
+	}
+	limit, err := optionalPositiveInt(c, "limit")
+	if err != nil {
+		response.NewErrorResponse(c, apperrors.NewValidationFailed("limit must be a positive number"))
+		return
+	}
+
+	products, err := ctn.ProductViewService.GetRecentlyViewed(c.Request.Context(), viewer, limit)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Recently viewed products retrieved successfully", products)
+}
+
+// ClearRecentlyViewed godoc
+// @Summary Clear recently viewed products
+// @Description Forget the browsing history of the current user or guest session. Guests call it without a token
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Session-ID header string false "Guest session id"
+// @Success 200 {object} response.Response "Recently viewed products cleared successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /me/recently-viewed [delete]
+func ClearRecentlyViewed(c *gin.Context, ctn *container.Container) {
+	viewer := viewerKey(c)
+	if viewer == "" {
+		response.NewErrorResponse(c, apperrors.NewValidationFailed("A logged in user or an X-Session-ID header is required"))
+		return
+	}
+	if err := ctn.ProductViewService.ClearRecentlyViewed(c.Request.Context(), viewer); err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Recently viewed products cleared successfully", nil)
+}
+
+// GetTrendingProducts godoc
+// @Summary Get trending products
+// @Description Most viewed active products of the last days, with their view counts
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Period in days (default 7, max 30)"
+// @Param limit query int false "Number of products (default 8, max 20)"
+// @Success 200 {object} response.Response{data=[]models.SwaggerTrendingProduct} "Trending products retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/trending [get]
+func GetTrendingProducts(c *gin.Context, ctn *container.Container) {
+	days, err := optionalPositiveInt(c, "days")
+	if err != nil {
+		response.NewErrorResponse(c, apperrors.NewValidationFailed("days must be a positive number"))
+		return
+	}
+	limit, err := optionalPositiveInt(c, "limit")
+	if err != nil {
+		response.NewErrorResponse(c, apperrors.NewValidationFailed("limit must be a positive number"))
+		return
+	}
+
+	products, err := ctn.ProductViewService.GetTrending(c.Request.Context(), days, limit)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Trending products retrieved successfully", products)
+}
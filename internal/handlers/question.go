@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+	"api_techstore/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetProductQuestions godoc
+// @Summary Get product questions
+// @Description List the approved questions of a product with their approved answers; staff answers come first
+// @Tags questions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} response.Response{data=[]models.SwaggerProductQuestion} "Questions retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/questions [get]
+func GetProductQuestions(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	questions, err := ctn.QuestionService.GetProductQuestions(productID)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Questions retrieved successfully", questions)
+}
+
+// CreateProductQuestion godoc
+// @Summary Ask a question
+// @Description Ask a question about a product; it is published after moderation
+// @Tags questions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body models.ProductQuestionCreateRequest true "Question"
+// @Success 201 {object} response.Response{data=models.SwaggerProductQuestion} "Question submitted successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/questions [post]
+func CreateProductQuestion(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.ProductQuestionCreateRequest)
+
+	question, err := ctn.QuestionService.CreateQuestion(productID, userID, req.Content)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusCreated, "Question submitted successfully", question)
+}
+
+// CreateProductAnswer godoc
+// @Summary Answer a question
+// @Description Answer an approved question. Answers from admins are marked as staff and published immediately, notifying the asker; answers from customers who received the product are marked as verified buyer and published after moderation.
+// @Tags questions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param questionId path string true "Question ID"
+// @Param request body models.ProductAnswerCreateRequest true "Answer"
+// @Success 201 {object} response.Response{data=models.SwaggerProductAnswer} "Answer submitted successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Question not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/questions/{questionId}/answers [post]
+func CreateProductAnswer(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	questionID, ok := parseUintParam(c, "questionId", "Invalid question id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.ProductAnswerCreateRequest)
+	role, _ := c.Get("role")
+
+	answer, err := ctn.QuestionService.CreateAnswer(productID, questionID, userID, req.Content, role == "admin")
+	if err != nil {
+		handleServiceError(c, err, "Question")
+		return
+	}
+	response.SuccessResponse(c, http.StatusCreated, "Answer submitted successfully", answer)
+}
+
+// GetQuestions godoc
+// @Summary Get questions for moderation
+// @Description List questions of all products with all their answers, oldest first, optionally filtered by moderation status (Admin only)
+// @Tags questions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "pending, approved or rejected"
+// @Success 200 {object} response.Response{data=[]models.SwaggerProductQuestion} "Questions retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /questions [get]
+func GetQuestions(c *gin.Context, ctn *container.Container) {
+	questions, err := ctn.QuestionService.GetQuestions(c.Query("status"))
+	if err != nil {
+		handleServiceError(c, err, "Question")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Questions retrieved successfully", questions)
+}
+
+// ModerateQuestion godoc
+// @Summary Moderate question
+// @Description Approve or reject a question (Admin only)
+// @Tags questions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param questionId path string true "Question ID"
+// @Param request body models.QAModerateRequest true "Moderation decision"
+// @Success 200 {object} response.Response{data=models.SwaggerProductQuestion} "Question moderated successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Question not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /questions/{questionId}/moderation [put]
+func ModerateQuestion(c *gin.Context, ctn *container.Container) {
+	questionID, ok := parseUintParam(c, "questionId", "Invalid question id")
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.QAModerateRequest)
+
+	question, err := ctn.QuestionService.ModerateQuestion(questionID, req.Status, req.Note)
+	if err != nil {
+		handleServiceError(c, err, "Question")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Question moderated successfully", question)
+}
+
+// DeleteQuestion godoc
+// @Summary Delete question
+// @Description Delete a question with its answers (Admin only)
+// @Tags questions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param questionId path string true "Question ID"
+// @Success 200 {object} response.Response "Question deleted successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Question not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /questions/{questionId} [delete]
+func DeleteQuestion(c *gin.Context, ctn *container.Container) {
+	questionID, ok := parseUintParam(c, "questionId", "Invalid question id")
+	if !ok {
+		return
+	}
+	if err := ctn.QuestionService.DeleteQuestion(questionID); err != nil {
+		handleServiceError(c, err, "Question")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Question deleted successfully", nil)
+}
+
+// GetAnswers godoc
+// @Summary Get answers for moderation
+// @Description List answers of all questions, oldest first, optionally filtered by moderation status (Admin only)
+// @Tags questions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "pending, approved or rejected"
+// @Success 200 {object} response.Response{data=[]models.SwaggerProductAnswer} "Answers retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /questions/answers [get]
+func GetAnswers(c *gin.Context, ctn *container.Container) {
+	answers, err := ctn.QuestionService.GetAnswers(c.Query("status"))
+	if err != nil {
+		handleServiceError(c, err, "Answer")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Answers retrieved successfully", answers)
+}
+
+// ModerateAnswer godoc
+// @Summary Moderate answer
+// @Description Approve or reject an answer; the asker is notified when an answer is approved (Admin only)
+// @Tags questions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param answerId path string true "Answer ID"
+// @Param request body models.QAModerateRequest true "Moderation decision"
+// @Success 200 {object} response.Response{data=models.SwaggerProductAnswer} "Answer moderated successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Answer not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /questions/answers/{answerId}/moderation [put]
+func ModerateAnswer(c *gin.Context, ctn *container.Container) {
+	answerID, ok := parseUintParam(c, "answerId", "Invalid answer id")
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.QAModerateRequest)
+
+	answer, err := ctn.QuestionService.ModerateAnswer(answerID, req.Status, req.Note)
+	if err != nil {
+		handleServiceError(c, err, "Answer")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Answer moderated successfully", answer)
+}
+
+// DeleteAnswer godoc
+// @Summary Delete answer
+// @Description Delete an answer (Admin only)
+// @Tags questions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param answerId path string true "Answer ID"
+// @Success 200 {object} response.Response "Answer deleted successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Answer not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /questions/answers/{answerId} [delete]
+func DeleteAnswer(c *gin.Context, ctn *container.Container) {
+	answerID, ok := parseUintParam(c, "answerId", "Invalid answer id")
+	if !ok {
+		return
+	}
+	if err := ctn.QuestionService.DeleteAnswer(answerID); err != nil {
+		handleServiceError(c, err, "Answer")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Answer deleted successfully", nil)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/pkg/response"
+	"net/http"
+
+	apperrors "api_techstore/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRelatedProducts godoc
+// @Summary Get related products
+// @Description Products often bought with this one, topped up with products of the same category and brand closest in price
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param limit query int false "Number of products (default 8, max 20)"
+// @Success 200 {object} response.Response{data=[]models.SwaggerProduct} "Related products retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/related [get]
+func GetRelatedProducts(c *gin.Context, ctn *container.Container) {
+	productID, limit, ok := recommendationParams(c)
+	if !ok {
+		return
+	}
+	products, err := ctn.RecommendationService.GetRelatedProducts(productID, limit)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Related products retrieved successfully", products)
+}
+
+// GetFrequentlyBoughtTogether godoc
+// @Summary Get frequently bought together products
+// @Description Products most often ordered together with this one
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param limit query int false "Number of products (default 8, max 20)"
+// @Success 200 {object} response.Response{data=[]models.SwaggerProduct} "Frequently bought together products retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/frequently-bought-together [get]
+func GetFrequentlyBoughtTogether(c *gin.Context, ctn *container.Container) {
+	productID, limit, ok := recommendationParams(c)
+	if !ok {
+		return
+	}
+	products, err := ctn.RecommendationService.GetFrequentlyBoughtTogether(productID, limit)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Frequently bought together products retrieved successfully", products)
+}
+
+func recommendationParams(c *gin.Context) (uint, int, bool) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return 0, 0, false
+	}
+	limit, err := optionalPositiveInt(c, "limit")
+	if err != nil {
+		response.NewErrorResponse(c, apperrors.NewValidationFailed("limit must be a positive number"))
+		return 0, 0, false
+	}
+	return productID, limit, true
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+	"api_techstore/pkg/response"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	apperrors "api_techstore/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetProductReviews godoc
+// @Summary Get product reviews
+// @Description List the approved reviews of a product
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param rating query int false "Only reviews with this rating (1-5)"
+// @Param sort query string false "newest (default), helpful, rating_high or rating_low"
+// @Success 200 {object} response.Response{data=[]models.SwaggerReview} "Reviews retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/reviews [get]
+func GetProductReviews(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	rating := 0
+	if value := c.Query("rating"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			response.NewErrorResponse(c, apperrors.NewValidationFailed("rating must be between 1 and 5"))
+			return
+		}
+		rating = parsed
+	}
+
+	reviews, err := ctn.ReviewService.GetProductReviews(productID, rating, c.Query("sort"))
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Reviews retrieved successfully", reviews)
+}
+
+// CreateProductReview godoc
+// @Summary Review a product
+// @Description Rate a product delivered to the current user, with optional text and up to 5 photos (JPEG, PNG or WebP). The review is published after moderation.
+// @Tags reviews
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param rating formData int true "Rating from 1 to 5"
+// @Param title formData string false "Title"
+// @Param content formData string false "Review text"
+// @Param photos formData file false "Photos"
+// @Success 201 {object} response.Response{data=models.SwaggerReview} "Review submitted successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Product was not delivered to the user"
+// @Failure 404 {object} response.Response "Product not found"
+// @Failure 409 {object} response.Response "Product already reviewed"
+// @Failure 413 {object} response.Response "Photo too large"
+// @Failure 415 {object} response.Response "Unsupported photo type"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/reviews [post]
+func CreateProductReview(c *gin.Context, ctn *container.Container) {
+	productID, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ReviewCreateRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.NewErrorResponse(c, apperrors.NewValidationFailed(err.Error()))
+		return
+	}
+	var photos []*multipart.FileHeader
+	if form, err := c.MultipartForm(); err == nil {
+		photos = form.File["photos"]
+	}
+
+	review := models.Review{
+		Rating:  req.Rating,
+		Title:   req.Title,
+		Content: req.Content,
+	}
+	newReview, err := ctn.ReviewService.CreateReview(c.Request.Context(), productID, userID, review, photos)
+	if err != nil {
+		handleServiceError(c, err, "Product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusCreated, "Review submitted successfully", newReview)
+}
+
+// VoteReviewHelpful godoc
+// @Summary Mark review as helpful
+// @Description Count the current user's helpful vote for a review; voting twice has no effect
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param reviewId path string true "Review ID"
+// @Success 200 {object} response.Response{data=models.SwaggerReview} "Vote recorded successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Review not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/reviews/{reviewId}/helpful [post]
+func VoteReviewHelpful(c *gin.Context, ctn *container.Container) {
+	productID, reviewID, userID, ok := reviewVoteParams(c)
+	if !ok {
+		return
+	}
+	review, err := ctn.ReviewService.VoteHelpful(productID, reviewID, userID)
+	if err != nil {
+		handleServiceError(c, err, "Review")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Vote recorded successfully", review)
+}
+
+// RemoveReviewHelpfulVote godoc
+// @Summary Remove helpful vote
+// @Description Withdraw the current user's helpful vote for a review
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param reviewId path string true "Review ID"
+// @Success 200 {object} response.Response{data=models.SwaggerReview} "Vote removed successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Review not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/reviews/{reviewId}/helpful [delete]
+func RemoveReviewHelpfulVote(c *gin.Context, ctn *container.Container) {
+	productID, reviewID, userID, ok := reviewVoteParams(c)
+	if !ok {
+		return
+	}
+	review, err := ctn.ReviewService.RemoveHelpfulVote(productID, reviewID, userID)
+	if err != nil {
+		handleServiceError(c, err, "Review")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Vote removed successfully", review)
+}
+
+// GetReviews godoc
+// @Summary Get reviews for moderation
+// @Description List reviews of all products, oldest first, optionally filtered by moderation status (Admin only)
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "pending, approved or rejected"
+// @Success 200 {object} response.Response{data=[]models.SwaggerReview} "Reviews retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /reviews [get]
+func GetReviews(c *gin.Context, ctn *container.Container) {
+	reviews, err := ctn.ReviewService.GetReviews(c.Query("status"))
+	if err != nil {
+		handleServiceError(c, err, "Review")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Reviews retrieved successfully", reviews)
+}
+
+// ModerateReview godoc
+// @Summary Moderate review
+// @Description Approve or reject a review; the product rating is recalculated from the approved reviews (Admin only)
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param reviewId path string true "Review ID"
+// @Param request body models.ReviewModerateRequest true "Moderation decision"
+// @Success 200 {object} response.Response{data=models.SwaggerReview} "Review moderated successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Review not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /reviews/{reviewId}/moderation [put]
+func ModerateReview(c *gin.Context, ctn *container.Container) {
+	reviewID, ok := parseUintParam(c, "reviewId", "Invalid review id")
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.ReviewModerateRequest)
+
+	review, err := ctn.ReviewService.ModerateReview(reviewID, req.Status, req.Note)
+	if err != nil {
+		handleServiceError(c, err, "Review")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Review moderated successfully", review)
+}
+
+// DeleteReview godoc
+// @Summary Delete review
+// @Description Delete a review with its photos and votes (Admin only)
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param reviewId path string true "Review ID"
+// @Success 200 {object} response.Response "Review deleted successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Review not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /reviews/{reviewId} [delete]
+func DeleteReview(c *gin.Context, ctn *container.Container) {
+	reviewID, ok := parseUintParam(c, "reviewId", "Invalid review id")
+	if !ok {
+		return
+	}
+	if err := ctn.ReviewService.DeleteReview(c.Request.Context(), reviewID); err != nil {
+		handleServiceError(c, err, "Review")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Review deleted successfully", nil)
+}
+
+func reviewVoteParams(c *gin.Context) (productID, reviewID, userID uint, ok bool) {
+	if productID, ok = parseUintParam(c, "id", "Invalid product id"); !ok {
+		return
+	}
+	if reviewID, ok = parseUintParam(c, "reviewId", "Invalid review id"); !ok {
+		return
+	}
+	userID, ok = currentUserID(c)
+	return
+}
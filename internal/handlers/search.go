@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/models"
+	"api_techstore/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSearchFilters godoc
+// @Summary Get search facets
+// @Description Facet counts (categories, brands, price ranges, availability, filterable spec attributes) for the current search. Each facet ignores its own selection so the other options keep their counts.
+// @Tags search
+// @Accept json
+// @Produce json
+// @Param q query string false "Keyword"
+// @Param category_id query []int false "Selected category IDs" collectionFormat(multi)
+// @Param brand_id query []int false "Selected brand IDs" collectionFormat(multi)
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param in_stock query boolean false "Only in-stock (true) or out-of-stock (false) products"
+// @Param attr[code] query string false "Spec attribute filter, e.g. attr[ram_gb]=16,32 or attr[screen_inch]=13..15"
+// @Success 200 {object} response.Response{data=models.SearchFacets} "Search filters retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /search/filters [get]
+func GetSearchFilters(c *gin.Context, ctn *container.Container) {
+	var query models.SearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ValidationErrorResponse(c, "Invalid search query")
+		return
+	}
+	query.Attributes = c.QueryMap("attr")
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice {
+		response.ValidationErrorResponse(c, "min_price must not be greater than max_price")
+		return
+	}
+
+	facets, err := ctn.SearchService.GetFilters(query)
+	if err != nil {
+		response.DatabaseErrorResponse(c, err)
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Search filters retrieved successfully", facets)
+}
@@ -0,0 +1,397 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+	"api_techstore/pkg/response"
+	"net/http"
+	"strconv"
+
+	apperrors "api_techstore/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTradeInPriceRules godoc
+// @Summary Get trade-in price rules
+// @Description List the pricing rules of used devices, optionally of one brand (Admin only)
+// @Tags trade-ins
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param brand_id query int false "Brand ID"
+// @Success 200 {object} response.Response{data=[]models.SwaggerTradeInPriceRule} "Trade-in price rules retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /trade-in-price-rules [get]
+func GetTradeInPriceRules(c *gin.Context, ctn *container.Container) {
+	var brandID uint
+	if value := c.Query("brand_id"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			response.NewErrorResponse(c, apperrors.NewValidationFailed("Invalid brand id"))
+			return
+		}
+		brandID = uint(parsed)
+	}
+	rules, err := ctn.TradeInService.GetPriceRules(brandID)
+	if err != nil {
+		handleServiceError(c, err, "Trade-in price rule")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Trade-in price rules retrieved successfully", rules)
+}
+
+// CreateTradeInPriceRule godoc
+// @Summary Create trade-in price rule
+// @Description Price used devices of a brand and model. The base value is paid in excellent condition, good, fair and poor conditions get a percentage of it (85, 65 and 40 by default), screen and body damage are deducted, and a device that does not power on is worth the no-power value (Admin only)
+// @Tags trade-ins
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TradeInPriceRuleCreateRequest true "Price rule"
+// @Success 201 {object} response.Response{data=models.SwaggerTradeInPriceRule} "Trade-in price rule created successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Brand not found"
+// @Failure 409 {object} response.Response "Model already priced"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /trade-in-price-rules [post]
+func CreateTradeInPriceRule(c *gin.Context, ctn *container.Container) {
+	req := middlewares.GetValidatedModel(c).(*models.TradeInPriceRuleCreateRequest)
+
+	rule := models.TradeInPriceRule{
+		BrandID:               req.BrandID,
+		Model:                 req.Model,
+		BaseValue:             req.BaseValue,
+		GoodPercent:           85,
+		FairPercent:           65,
+		PoorPercent:           40,
+		ScreenDamageDeduction: req.ScreenDamageDeduction,
+		BodyDamageDeduction:   req.BodyDamageDeduction,
+		NoPowerValue:          req.NoPowerValue,
+		IsActive:              true,
+	}
+	if req.GoodPercent != nil {
+		rule.GoodPercent = *req.GoodPercent
+	}
+	if req.FairPercent != nil {
+		rule.FairPercent = *req.FairPercent
+	}
+	if req.PoorPercent != nil {
+		rule.PoorPercent = *req.PoorPercent
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	newRule, err := ctn.TradeInService.CreatePriceRule(rule)
+	if err != nil {
+		handleServiceError(c, err, "Brand")
+		return
+	}
+	response.SuccessResponse(c, http.StatusCreated, "Trade-in price rule created successfully", newRule)
+}
+
+// UpdateTradeInPriceRule godoc
+// @Summary Update trade-in price rule
+// @Description Change the pricing of a model; quotes already given keep their value (Admin only)
+// @Tags trade-ins
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param ruleId path string true "Price rule ID"
+// @Param request body models.TradeInPriceRuleUpdateRequest true "Price rule fields"
+// @Success 200 {object} response.Response{data=models.SwaggerTradeInPriceRule} "Trade-in price rule updated successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Trade-in price rule not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /trade-in-price-rules/{ruleId} [put]
+func UpdateTradeInPriceRule(c *gin.Context, ctn *container.Container) {
+	ruleID, ok := parseUintParam(c, "ruleId", "Invalid rule id")
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.TradeInPriceRuleUpdateRequest)
+
+	updates := map[string]interface{}{}
+	if req.BaseValue != nil {
+		updates["base_value"] = *req.BaseValue
+	}
+	if req.GoodPercent != nil {
+		updates["good_percent"] = *req.GoodPercent
+	}
+	if req.FairPercent != nil {
+		updates["fair_percent"] = *req.FairPercent
+	}
+	if req.PoorPercent != nil {
+		updates["poor_percent"] = *req.PoorPercent
+	}
+	if req.ScreenDamageDeduction != nil {
+		updates["screen_damage_deduction"] = *req.ScreenDamageDeduction
+	}
+	if req.BodyDamageDeduction != nil {
+		updates["body_damage_deduction"] = *req.BodyDamageDeduction
+	}
+	if req.NoPowerValue != nil {
+		updates["no_power_value"] = *req.NoPowerValue
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+
+	rule, err := ctn.TradeInService.UpdatePriceRule(ruleID, updates)
+	if err != nil {
+		handleServiceError(c, err, "Trade-in price rule")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Trade-in price rule updated successfully", rule)
+}
+
+// DeleteTradeInPriceRule godoc
+// @Summary Delete trade-in price rule
+// @Description Stop quoting a model; quotes already given keep their value (Admin only)
+// @Tags trade-ins
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param ruleId path string true "Price rule ID"
+// @Success 200 {object} response.Response "Trade-in price rule deleted successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Trade-in price rule not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /trade-in-price-rules/{ruleId} [delete]
+func DeleteTradeInPriceRule(c *gin.Context, ctn *container.Container) {
+	ruleID, ok := parseUintParam(c, "ruleId", "Invalid rule id")
+	if !ok {
+		return
+	}
+	if err := ctn.TradeInService.DeletePriceRule(ruleID); err != nil {
+		handleServiceError(c, err, "Trade-in price rule")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Trade-in price rule deleted successfully", nil)
+}
+
+// RequestTradeInQuote godoc
+// @Summary Request a trade-in quote
+// @Description Get a quote for a used device from its brand, model and condition questionnaire. The quote can be accepted for 14 days.
+// @Tags trade-ins
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TradeInQuoteRequest true "Device and condition"
+// @Success 201 {object} response.Response{data=models.SwaggerTradeIn} "Trade-in quoted successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Model not bought back"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /trade-ins [post]
+func RequestTradeInQuote(c *gin.Context, ctn *container.Container) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.TradeInQuoteRequest)
+
+	tradeIn := models.TradeIn{
+		UserID:        userID,
+		BrandID:       req.BrandID,
+		Model:         req.Model,
+		Condition:     req.Condition,
+		PowersOn:      *req.PowersOn,
+		ScreenDamaged: *req.ScreenDamaged,
+		BodyDamaged:   *req.BodyDamaged,
+		Description:   req.Description,
+	}
+	quoted, err := ctn.TradeInService.RequestQuote(tradeIn)
+	if err != nil {
+		handleServiceError(c, err, "Trade-in")
+		return
+	}
+	response.SuccessResponse(c, http.StatusCreated, "Trade-in quoted successfully", quoted)
+}
+
+// GetMyTradeIns godoc
+// @Summary Get my trade-ins
+// @Description List the trade-ins of the current user, newest first
+// @Tags trade-ins
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.SwaggerTradeIn} "Trade-ins retrieved successfully"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /me/trade-ins [get]
+func GetMyTradeIns(c *gin.Context, ctn *container.Container) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	tradeIns, err := ctn.TradeInService.GetUserTradeIns(userID)
+	if err != nil {
+		handleServiceError(c, err, "Trade-in")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Trade-ins retrieved successfully", tradeIns)
+}
+
+// GetTradeIns godoc
+// @Summary Get trade-ins
+// @Description List the trade-ins of all customers, oldest first, optionally filtered by status (Admin only)
+// @Tags trade-ins
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "quoted, accepted or declined"
+// @Success 200 {object} response.Response{data=[]models.SwaggerTradeIn} "Trade-ins retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /trade-ins [get]
+func GetTradeIns(c *gin.Context, ctn *container.Container) {
+	tradeIns, err := ctn.TradeInService.GetTradeIns(c.Query("status"))
+	if err != nil {
+		handleServiceError(c, err, "Trade-in")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Trade-ins retrieved successfully", tradeIns)
+}
+
+// GetTradeIn godoc
+// @Summary Get trade-in
+// @Description Get a trade-in of the current user, or any trade-in for admins
+// @Tags trade-ins
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Trade-in ID"
+// @Success 200 {object} response.Response{data=models.SwaggerTradeIn} "Trade-in retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Trade-in not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /trade-ins/{id} [get]
+func GetTradeIn(c *gin.Context, ctn *container.Container) {
+	tradeInID, ok := parseUintParam(c, "id", "Invalid trade-in id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	role, _ := c.Get("role")
+
+	tradeIn, err := ctn.TradeInService.GetTradeIn(tradeInID, userID, role == "admin")
+	if err != nil {
+		handleServiceError(c, err, "Trade-in")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Trade-in retrieved successfully", tradeIn)
+}
+
+// AcceptTradeInQuote godoc
+// @Summary Accept a trade-in quote
+// @Description Accept a quote of the current user before it expires; its value can then be credited to a new order with trade_in_id
+// @Tags trade-ins
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Trade-in ID"
+// @Success 200 {object} response.Response{data=models.SwaggerTradeIn} "Trade-in quote accepted successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Trade-in not found"
+// @Failure 409 {object} response.Response "Quote already answered or expired"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /trade-ins/{id}/accept [post]
+func AcceptTradeInQuote(c *gin.Context, ctn *container.Container) {
+	answerTradeInQuote(c, ctn, true)
+}
+
+// DeclineTradeInQuote godoc
+// @Summary Decline a trade-in quote
+// @Description Decline a quote of the current user
+// @Tags trade-ins
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Trade-in ID"
+// @Success 200 {object} response.Response{data=models.SwaggerTradeIn} "Trade-in quote declined successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Trade-in not found"
+// @Failure 409 {object} response.Response "Quote already answered"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /trade-ins/{id}/decline [post]
+func DeclineTradeInQuote(c *gin.Context, ctn *container.Container) {
+	answerTradeInQuote(c, ctn, false)
+}
+
+func answerTradeInQuote(c *gin.Context, ctn *container.Container, accept bool) {
+	tradeInID, ok := parseUintParam(c, "id", "Invalid trade-in id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	answer, message := ctn.TradeInService.DeclineQuote, "Trade-in quote declined successfully"
+	if accept {
+		answer, message = ctn.TradeInService.AcceptQuote, "Trade-in quote accepted successfully"
+	}
+	tradeIn, err := answer(tradeInID, userID)
+	if err != nil {
+		handleServiceError(c, err, "Trade-in")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, message, tradeIn)
+}
+
+// InspectTradeIn godoc
+// @Summary Inspect a trade-in
+// @Description Set the final value of an accepted trade-in once the device is received. When it is credited to an order, the order's credit and total are adjusted, which is refused once the order is paid or past pending (Admin only)
+// @Tags trade-ins
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Trade-in ID"
+// @Param request body models.TradeInInspectionRequest true "Final value"
+// @Success 200 {object} response.Response{data=models.SwaggerTradeIn} "Trade-in inspected successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Trade-in not found"
+// @Failure 409 {object} response.Response "Trade-in not accepted, or its order can no longer change"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /trade-ins/{id}/inspection [put]
+func InspectTradeIn(c *gin.Context, ctn *container.Container) {
+	tradeInID, ok := parseUintParam(c, "id", "Invalid trade-in id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.TradeInInspectionRequest)
+
+	tradeIn, err := ctn.TradeInService.Inspect(tradeInID, userID, *req.FinalValue, req.Note)
+	if err != nil {
+		handleServiceError(c, err, "Trade-in")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Trade-in inspected successfully", tradeIn)
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDeletedProducts godoc
+// @Summary Get deleted products
+// @Description List the deleted products, latest first, with the time each one is purged for good unless restored (Admin only)
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.TrashEntry} "Deleted products retrieved successfully"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/trash [get]
+func GetDeletedProducts(c *gin.Context, ctn *container.Container) {
+	entries, err := ctn.TrashService.GetDeletedProducts()
+	if err != nil {
+		response.DatabaseErrorResponse(c, err)
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Deleted products retrieved successfully", entries)
+}
+
+// RestoreProduct godoc
+// @Summary Restore product
+// @Description Restore a deleted product; its category and brand must be restored first. A slug taken in the meantime is replaced by a new one generated from the name (Admin only)
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} response.Response{data=models.SwaggerProduct} "Product restored successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Deleted product not found"
+// @Failure 409 {object} response.Response "Product cannot be restored yet"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /products/{id}/restore [post]
+func RestoreProduct(c *gin.Context, ctn *container.Container) {
+	id, ok := parseUintParam(c, "id", "Invalid product id")
+	if !ok {
+		return
+	}
+	product, err := ctn.TrashService.RestoreProduct(id)
+	if err != nil {
+		handleServiceError(c, err, "Deleted product")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Product restored successfully", product)
+}
+
+// GetDeletedBrands godoc
+// @Summary Get deleted brands
+// @Description List the deleted brands, latest first, with the time each one is purged for good unless restored (Admin only)
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.TrashEntry} "Deleted brands retrieved successfully"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /brands/trash [get]
+func GetDeletedBrands(c *gin.Context, ctn *container.Container) {
+	entries, err := ctn.TrashService.GetDeletedBrands()
+	if err != nil {
+		response.DatabaseErrorResponse(c, err)
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Deleted brands retrieved successfully", entries)
+}
+
+// RestoreBrand godoc
+// @Summary Restore brand
+// @Description Restore a deleted brand; a slug taken in the meantime is replaced by a new one generated from the name (Admin only)
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Brand ID"
+// @Success 200 {object} response.Response{data=models.SwaggerBrand} "Brand restored successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Deleted brand not found"
+// @Failure 409 {object} response.Response "Brand cannot be restored yet"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /brands/{id}/restore [post]
+func RestoreBrand(c *gin.Context, ctn *container.Container) {
+	id, ok := parseUintParam(c, "id", "Invalid brand id")
+	if !ok {
+		return
+	}
+	brand, err := ctn.TrashService.RestoreBrand(id)
+	if err != nil {
+		handleServiceError(c, err, "Deleted brand")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Brand restored successfully", brand)
+}
+
+// GetDeletedCategories godoc
+// @Summary Get deleted categories
+// @Description List the deleted categories, latest first, with the time each one is purged for good unless restored (Admin only)
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.TrashEntry} "Deleted categories retrieved successfully"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /categories/trash [get]
+func GetDeletedCategories(c *gin.Context, ctn *container.Container) {
+	entries, err := ctn.TrashService.GetDeletedCategories()
+	if err != nil {
+		response.DatabaseErrorResponse(c, err)
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Deleted categories retrieved successfully", entries)
+}
+
+// RestoreCategory godoc
+// @Summary Restore category
+// @Description Restore a deleted category; its parent must be restored first and its name must still be free. A slug taken in the meantime is replaced by a new one generated from the name (Admin only)
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Category ID"
+// @Success 200 {object} response.Response{data=models.SwaggerCategory} "Category restored successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Deleted category not found"
+// @Failure 409 {object} response.Response "Category cannot be restored yet"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /categories/{id}/restore [post]
+func RestoreCategory(c *gin.Context, ctn *container.Container) {
+	id, ok := parseUintParam(c, "id", "Invalid category id")
+	if !ok {
+		return
+	}
+	category, err := ctn.TrashService.RestoreCategory(id)
+	if err != nil {
+		handleServiceError(c, err, "Deleted category")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Category restored successfully", category)
+}
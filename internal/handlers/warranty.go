@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+	"api_techstore/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecordSerialNumbers godoc
+// @Summary Record serial numbers
+// @Description Record the serial numbers of the units shipped for an order line, at most its quantity, once the order is shipped or delivered. Their warranty starts now, for the period of the product or else of its brand (Admin only)
+// @Tags warranties
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Param itemId path string true "Order item ID"
+// @Param request body models.SerialNumbersRequest true "Serial numbers"
+// @Success 201 {object} response.Response{data=[]models.SwaggerSoldUnit} "Serial numbers recorded successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Order item not found"
+// @Failure 409 {object} response.Response "Serial number already recorded or order not shipped yet"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /orders/{id}/items/{itemId}/serial-numbers [post]
+func RecordSerialNumbers(c *gin.Context, ctn *container.Container) {
+	orderID, ok := parseUintParam(c, "id", "Invalid order id")
+	if !ok {
+		return
+	}
+	itemID, ok := parseUintParam(c, "itemId", "Invalid order item id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.SerialNumbersRequest)
+
+	units, err := ctn.WarrantyService.RecordSerialNumbers(orderID, itemID, req.SerialNumbers, userID)
+	if err != nil {
+		handleServiceError(c, err, "Order item")
+		return
+	}
+	response.SuccessResponse(c, http.StatusCreated, "Serial numbers recorded successfully", units)
+}
+
+// GetMyWarranties godoc
+// @Summary Get my warranties
+// @Description List the units bought by the current user with their serial number and warranty, latest warranty end first
+// @Tags warranties
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.SwaggerSoldUnit} "Warranties retrieved successfully"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /me/warranties [get]
+func GetMyWarranties(c *gin.Context, ctn *container.Container) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	units, err := ctn.WarrantyService.GetUserWarranties(userID)
+	if err != nil {
+		handleServiceError(c, err, "Warranty")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Warranties retrieved successfully", units)
+}
+
+// GetWarrantyBySerialNumber godoc
+// @Summary Look up a warranty
+// @Description Find a sold unit by its serial number, with its order line and warranty (Admin only)
+// @Tags warranties
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param serial path string true "Serial number"
+// @Success 200 {object} response.Response{data=models.SwaggerSoldUnit} "Warranty retrieved successfully"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Serial number not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /warranties/{serial} [get]
+func GetWarrantyBySerialNumber(c *gin.Context, ctn *container.Container) {
+	unit, err := ctn.WarrantyService.GetBySerialNumber(c.Param("serial"))
+	if err != nil {
+		handleServiceError(c, err, "Serial number")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Warranty retrieved successfully", unit)
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+	"api_techstore/pkg/response"
+	"mime/multipart"
+	"net/http"
+
+	apperrors "api_techstore/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// claimAttachments reads the files sent as "attachments"
+func claimAttachments(c *gin.Context) []*multipart.FileHeader {
+	if form, err := c.MultipartForm(); err == nil {
+		return form.File["attachments"]
+	}
+	return nil
+}
+
+// CreateWarrantyClaim godoc
+// @Summary Open a warranty claim
+// @Description Open a warranty or repair claim for an order line delivered to the current user, optionally for one unit by its serial number, with up to 10 photos or documents (JPEG, PNG, WebP or PDF, max 10MB each)
+// @Tags warranty-claims
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param order_item_id formData int true "Order item ID"
+// @Param serial_number formData string false "Serial number of the unit"
+// @Param description formData string true "What is wrong"
+// @Param attachments formData file false "Attachments"
+// @Success 201 {object} response.Response{data=models.SwaggerWarrantyClaim} "Warranty claim submitted successfully"
+// @Failure 400 {object} response.Response "Invalid request or order not delivered"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Order item or serial number not found"
+// @Failure 409 {object} response.Response "An open claim already exists for this item"
+// @Failure 413 {object} response.Response "Attachment too large"
+// @Failure 415 {object} response.Response "Unsupported attachment type"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /warranty-claims [post]
+func CreateWarrantyClaim(c *gin.Context, ctn *container.Container) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req models.WarrantyClaimCreateRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.NewErrorResponse(c, apperrors.NewValidationFailed(err.Error()))
+		return
+	}
+
+	claim := models.WarrantyClaim{
+		OrderItemID: req.OrderItemID,
+		Description: req.Description,
+	}
+	newClaim, err := ctn.WarrantyClaimService.CreateClaim(c.Request.Context(), userID, claim, req.SerialNumber, claimAttachments(c))
+	if err != nil {
+		handleServiceError(c, err, "Order item")
+		return
+	}
+	response.SuccessResponse(c, http.StatusCreated, "Warranty claim submitted successfully", newClaim)
+}
+
+// GetMyWarrantyClaims godoc
+// @Summary Get my warranty claims
+// @Description List the warranty claims of the current user, newest first, with their notes and attachments
+// @Tags warranty-claims
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.SwaggerWarrantyClaim} "Warranty claims retrieved successfully"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /me/warranty-claims [get]
+func GetMyWarrantyClaims(c *gin.Context, ctn *container.Container) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	claims, err := ctn.WarrantyClaimService.GetUserClaims(userID)
+	if err != nil {
+		handleServiceError(c, err, "Warranty claim")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Warranty claims retrieved successfully", claims)
+}
+
+// GetWarrantyClaims godoc
+// @Summary Get warranty claims
+// @Description The service-center queue: claims of all customers, oldest first, optionally filtered by status (Admin only)
+// @Tags warranty-claims
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "submitted, received, diagnosing, repaired, replaced or rejected"
+// @Success 200 {object} response.Response{data=[]models.SwaggerWarrantyClaim} "Warranty claims retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /warranty-claims [get]
+func GetWarrantyClaims(c *gin.Context, ctn *container.Container) {
+	claims, err := ctn.WarrantyClaimService.GetClaims(c.Query("status"))
+	if err != nil {
+		handleServiceError(c, err, "Warranty claim")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Warranty claims retrieved successfully", claims)
+}
+
+// GetWarrantyClaim godoc
+// @Summary Get warranty claim
+// @Description Get a claim of the current user, or any claim for admins; internal staff notes are only shown to admins
+// @Tags warranty-claims
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Warranty claim ID"
+// @Success 200 {object} response.Response{data=models.SwaggerWarrantyClaim} "Warranty claim retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Warranty claim not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /warranty-claims/{id} [get]
+func GetWarrantyClaim(c *gin.Context, ctn *container.Container) {
+	claimID, ok := parseUintParam(c, "id", "Invalid warranty claim id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	role, _ := c.Get("role")
+
+	claim, err := ctn.WarrantyClaimService.GetClaim(claimID, userID, role == "admin")
+	if err != nil {
+		handleServiceError(c, err, "Warranty claim")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Warranty claim retrieved successfully", claim)
+}
+
+// AddWarrantyClaimAttachments godoc
+// @Summary Attach files to a warranty claim
+// @Description Add photos or documents (JPEG, PNG, WebP or PDF, max 10MB each) to an open claim of the current user, or to any open claim for admins; a claim has at most 10 attachments
+// @Tags warranty-claims
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Warranty claim ID"
+// @Param attachments formData file true "Attachments"
+// @Success 201 {object} response.Response{data=models.SwaggerWarrantyClaim} "Attachments added successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Warranty claim not found"
+// @Failure 409 {object} response.Response "Claim is closed"
+// @Failure 413 {object} response.Response "Attachment too large"
+// @Failure 415 {object} response.Response "Unsupported attachment type"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /warranty-claims/{id}/attachments [post]
+func AddWarrantyClaimAttachments(c *gin.Context, ctn *container.Container) {
+	claimID, ok := parseUintParam(c, "id", "Invalid warranty claim id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	role, _ := c.Get("role")
+
+	claim, err := ctn.WarrantyClaimService.AddAttachments(c.Request.Context(), claimID, userID, role == "admin", claimAttachments(c))
+	if err != nil {
+		handleServiceError(c, err, "Warranty claim")
+		return
+	}
+	response.SuccessResponse(c, http.StatusCreated, "Attachments added successfully", claim)
+}
+
+// AddWarrantyClaimNote godoc
+// @Summary Add a note to a warranty claim
+// @Description Add a staff note to a claim; internal notes are hidden from the customer (Admin only)
+// @Tags warranty-claims
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Warranty claim ID"
+// @Param request body models.WarrantyClaimNoteRequest true "Note"
+// @Success 201 {object} response.Response{data=models.SwaggerWarrantyClaim} "Note added successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Warranty claim not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /warranty-claims/{id}/notes [post]
+func AddWarrantyClaimNote(c *gin.Context, ctn *container.Container) {
+	claimID, ok := parseUintParam(c, "id", "Invalid warranty claim id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.WarrantyClaimNoteRequest)
+
+	claim, err := ctn.WarrantyClaimService.AddNote(claimID, userID, req.Content, req.Internal)
+	if err != nil {
+		handleServiceError(c, err, "Warranty claim")
+		return
+	}
+	response.SuccessResponse(c, http.StatusCreated, "Note added successfully", claim)
+}
+
+// UpdateWarrantyClaimStatus godoc
+// @Summary Update warranty claim status
+// @Description Move a claim along the workflow: submitted to received, received to diagnosing, diagnosing to repaired or replaced, and any open claim to rejected. The change is recorded with the optional note and the customer is notified (Admin only)
+// @Tags warranty-claims
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Warranty claim ID"
+// @Param request body models.WarrantyClaimStatusRequest true "New status"
+// @Success 200 {object} response.Response{data=models.SwaggerWarrantyClaim} "Warranty claim updated successfully"
+// @Failure 400 {object} response.Response "Invalid request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Warranty claim not found"
+// @Failure 409 {object} response.Response "Transition not allowed"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /warranty-claims/{id}/status [put]
+func UpdateWarrantyClaimStatus(c *gin.Context, ctn *container.Container) {
+	claimID, ok := parseUintParam(c, "id", "Invalid warranty claim id")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	req := middlewares.GetValidatedModel(c).(*models.WarrantyClaimStatusRequest)
+
+	claim, err := ctn.WarrantyClaimService.UpdateStatus(claimID, userID, req.Status, req.Note)
+	if err != nil {
+		handleServiceError(c, err, "Warranty claim")
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "Warranty claim updated successfully", claim)
+}
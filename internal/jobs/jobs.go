@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"api_techstore/internal/config"
+	"api_techstore/internal/container"
+	"context"
+)
+
+// Start registers the background jobs of the API and starts them
+func Start(ctx context.Context, ctn *container.Container) {
+	cfg := config.GetJobsConfig()
+
+	scheduler := NewScheduler(ctn.Logger)
+	scheduler.Add(Job{
+		Name:     "refresh-recommendations",
+		Interval: cfg.RecommendationRefreshInterval,
+		Run:      ctn.RecommendationService.RefreshRecommendations,
+	})
+	scheduler.Add(Job{
+		Name:     "apply-scheduled-prices",
+		Interval: cfg.PriceScheduleInterval,
+		Run:      ctn.PriceService.ApplyScheduledPrices,
+	})
+	scheduler.Add(Job{
+		Name:     "purge-trash",
+		Interval: cfg.TrashPurgeInterval,
+		Run:      ctn.TrashService.PurgeExpired,
+	})
+	scheduler.Add(Job{
+		Name:     "fail-stale-imports",
+		Interval: cfg.ImportWatchdogInterval,
+		Run:      ctn.ProductImportService.FailStaleImports,
+	})
+	scheduler.Start(ctx)
+}
@@ -16,49 +16,65 @@ import (
 // check jwt in request header
 func JWTAuthMiddleware(ctn *container.Container) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		authHeader := ctx.GetHeader("Authorization")
-		if authHeader == "" {
+		if ctx.GetHeader("Authorization") == "" {
 			response.NewErrorResponse(ctx, apperrors.NewUnauthorized())
 			ctx.Abort()
 			return
 		}
-
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			appErr := apperrors.New(apperrors.ErrCodeTokenInvalid, "Invalid authorization header format", http.StatusUnauthorized)
-			response.NewErrorResponse(ctx, appErr)
+		if !authenticate(ctx, ctn) {
 			ctx.Abort()
 			return
 		}
+		ctx.Next()
+	}
+}
 
-		claims, err := ctn.JWTConfig.ValidateAccessRedisToken(parts[1])
-		if err != nil {
-			var appErr *apperrors.AppError
-			if err == jwtpkg.ErrExpiredToken {
-				appErr = apperrors.NewTokenExpired()
-			} else {
-				appErr = apperrors.NewTokenInvalid()
-			}
-			response.NewErrorResponse(ctx, appErr)
+// OptionalJWTAuthMiddleware lets guests through; a request carrying a token is authenticated like
+// by JWTAuthMiddleware, and rejected when the token is not valid
+func OptionalJWTAuthMiddleware(ctn *container.Container) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetHeader("Authorization") != "" && !authenticate(ctx, ctn) {
 			ctx.Abort()
 			return
 		}
+		ctx.Next()
+	}
+}
 
-		// Check if token exists in Redis
-		redisClient := cache.NewRedisClient(ctn.Redis)
-		isValid, err := redisClient.IsValidToken(ctx.Request.Context(), claims.AccessUUID)
-		if err != nil || !isValid {
-			response.NewErrorResponse(ctx, apperrors.NewTokenRevoked())
-			ctx.Abort()
-			return
-		}
+// authenticate validates the bearer token of the request and sets the user in the context. It
+// writes the error response and returns false when the token is not valid.
+func authenticate(ctx *gin.Context, ctn *container.Container) bool {
+	parts := strings.Split(ctx.GetHeader("Authorization"), " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		appErr := apperrors.New(apperrors.ErrCodeTokenInvalid, "Invalid authorization header format", http.StatusUnauthorized)
+		response.NewErrorResponse(ctx, appErr)
+		return false
+	}
 
-		ctx.Set("user_id", claims.UserID)
-		ctx.Set("role", claims.Role)
-		ctx.Set("access_uuid", claims.AccessUUID)
+	claims, err := ctn.JWTConfig.ValidateAccessRedisToken(parts[1])
+	if err != nil {
+		var appErr *apperrors.AppError
+		if err == jwtpkg.ErrExpiredToken {
+			appErr = apperrors.NewTokenExpired()
+		} else {
+			appErr = apperrors.NewTokenInvalid()
+		}
+		response.NewErrorResponse(ctx, appErr)
+		return false
+	}
 
-		ctx.Next()
+	// Check if token exists in Redis
+	redisClient := cache.NewRedisClient(ctn.Redis)
+	isValid, err := redisClient.IsValidToken(ctx.Request.Context(), claims.AccessUUID)
+	if err != nil || !isValid {
+		response.NewErrorResponse(ctx, apperrors.NewTokenRevoked())
+		return false
 	}
+
+	ctx.Set("user_id", claims.UserID)
+	ctx.Set("role", claims.Role)
+	ctx.Set("access_uuid", claims.AccessUUID)
+	return true
 }
 
 // authorization middleware to check user roles
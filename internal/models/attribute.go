@@ -0,0 +1,62 @@
+package models
+
+// Spec attribute types
+const (
+	AttributeTypeString  = "string"
+	AttributeTypeNumber  = "number"
+	AttributeTypeBoolean = "boolean"
+	AttributeTypeEnum    = "enum"
+)
+
+// CategoryAttribute defines a technical specification (CPU, RAM, screen size, ...)
+// products of a category can be described with
+type CategoryAttribute struct {
+	Base
+	CategoryID    uint     `gorm:"column:category_id;not null;index;uniqueIndex:idx_category_attributes_code,where:deleted_at IS NULL" json:"category_id"`
+	Code          string   `gorm:"column:code;type:varchar(50);not null;uniqueIndex:idx_category_attributes_code,where:deleted_at IS NULL" json:"code"`
+	Name          string   `gorm:"column:name;type:varchar(100);not null" json:"name"`
+	Type          string   `gorm:"column:type;type:varchar(20);not null" json:"type"`
+	Unit          string   `gorm:"column:unit;type:varchar(20)" json:"unit,omitempty"`
+	AllowedValues []string `gorm:"column:allowed_values;serializer:json" json:"allowed_values,omitempty"`
+	IsRequired    bool     `gorm:"column:is_required" json:"is_required"`
+	IsFilterable  bool     `gorm:"column:is_filterable" json:"is_filterable"`
+	SortOrder     int      `gorm:"column:sort_order;default:0" json:"sort_order"`
+
+	// Relations
+	Category Category `json:"-" gorm:"foreignKey:CategoryID"`
+}
+
+// ProductAttributeValue is the value of one spec attribute for a product; exactly
+// one of the value columns is set, depending on the attribute type
+type ProductAttributeValue struct {
+	ID          uint     `gorm:"primaryKey" json:"-"`
+	ProductID   uint     `gorm:"column:product_id;not null;uniqueIndex:idx_product_attribute_values_attribute" json:"-"`
+	AttributeID uint     `gorm:"column:attribute_id;not null;uniqueIndex:idx_product_attribute_values_attribute;index" json:"attribute_id"`
+	ValueText   string   `gorm:"column:value_text;type:varchar(255)" json:"value_text,omitempty"`
+	ValueNumber *float64 `gorm:"column:value_number;type:double precision" json:"value_number,omitempty"`
+	ValueBool   *bool    `gorm:"column:value_bool" json:"value_bool,omitempty"`
+
+	// Relations
+	Attribute CategoryAttribute `json:"attribute" gorm:"foreignKey:AttributeID"`
+}
+
+type CategoryAttributeCreateRequest struct {
+	Code          string   `json:"code" binding:"required,min=1,max=50"`
+	Name          string   `json:"name" binding:"required,min=1,max=100"`
+	Type          string   `json:"type" binding:"required,oneof=string number boolean enum"`
+	Unit          string   `json:"unit" binding:"omitempty,max=20"`
+	AllowedValues []string `json:"allowed_values" binding:"omitempty,dive,required,max=100"` // required for enum
+	IsRequired    *bool    `json:"is_required" binding:"omitempty"`
+	IsFilterable  *bool    `json:"is_filterable" binding:"omitempty"`
+	SortOrder     int      `json:"sort_order" binding:"omitempty"`
+}
+
+// CategoryAttributeUpdateRequest cannot change code and type: existing product values depend on them
+type CategoryAttributeUpdateRequest struct {
+	Name          string   `json:"name" binding:"omitempty,min=1,max=100"`
+	Unit          *string  `json:"unit" binding:"omitempty,max=20"`
+	AllowedValues []string `json:"allowed_values" binding:"omitempty,dive,required,max=100"`
+	IsRequired    *bool    `json:"is_required" binding:"omitempty"`
+	IsFilterable  *bool    `json:"is_filterable" binding:"omitempty"`
+	SortOrder     *int     `json:"sort_order" binding:"omitempty"`
+}
@@ -5,22 +5,47 @@ type Brand struct {
 	Name        string `gorm:"column:name" json:"name"`
 	Description string `gorm:"column:description" json:"description"`
 	IsActive    bool   `gorm:"column:is_active;default:false" json:"is_active"`
-	Slug        string `gorm:"column:slug" json:"slug"`
+	Slug        string `gorm:"column:slug;uniqueIndex:idx_brands_slug,where:deleted_at IS NULL" json:"slug"`
+	// WarrantyMonths is the manufacturer warranty of the brand's products, unless a product sets its own
+	WarrantyMonths *int `gorm:"column:warranty_months" json:"warranty_months,omitempty"`
+
+	// the logo is uploaded through POST /brands/:id/logo
+	LogoURL        string `gorm:"column:logo_url;type:varchar(500)" json:"logo_url,omitempty"`
+	LogoStorageKey string `gorm:"column:logo_storage_key;type:varchar(500)" json:"-"`
 
 	// Relations
 	Products []Product `json:"products,omitempty" gorm:"foreignKey:BrandID"`
 }
 
 type BrandCreateRequest struct {
-	Name        string `json:"name" binding:"required,min=2,max=100"`
-	Description string `json:"description" binding:"omitempty,max=255"`
-	IsActive    *bool  `json:"is_active" binding:"omitempty"`
-	Slug        string `json:"slug" binding:"required,min=2,max=100"`
+	Name           string `json:"name" binding:"required,min=2,max=100"`
+	Description    string `json:"description" binding:"omitempty,max=255"`
+	IsActive       *bool  `json:"is_active" binding:"omitempty"`
+	Slug           string `json:"slug" binding:"omitempty,min=2,max=100"` // generated from the name when empty
+	WarrantyMonths *int   `json:"warranty_months" binding:"omitempty,gte=0,lte=120"`
 }
 
 type BrandUpdateRequest struct {
-	Name        string `json:"name" binding:"omitempty,min=2,max=100"`
-	Description string `json:"description" binding:"omitempty,max=255"`
-	IsActive    *bool  `json:"is_active" binding:"omitempty"`
-	Slug        string `json:"slug" binding:"omitempty,min=2,max=100"`
+	Name           string `json:"name" binding:"omitempty,min=2,max=100"`
+	Description    string `json:"description" binding:"omitempty,max=255"`
+	IsActive       *bool  `json:"is_active" binding:"omitempty"`
+	Slug           string `json:"slug" binding:"omitempty,min=2,max=100"`
+	WarrantyMonths *int   `json:"warranty_months" binding:"omitempty,gte=0,lte=120"`
+	// ClearWarrantyMonths removes the brand's period, its products without one of their own get no warranty
+	ClearWarrantyMonths bool `json:"clear_warranty_months"`
+}
+
+// BrandPage is the landing data of a brand: its listed products counted per category and its best sellers
+type BrandPage struct {
+	Brand        Brand                `json:"brand"`
+	ProductCount int64                `json:"product_count"`
+	Categories   []BrandCategoryCount `json:"categories"`
+	TopProducts  []Product            `json:"top_products"`
+}
+
+type BrandCategoryCount struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	ProductCount int64  `json:"product_count"`
 }
@@ -0,0 +1,25 @@
+package models
+
+// BundleItem is a component of a bundle product: Quantity units of a product, or of one of its variants
+type BundleItem struct {
+	ID                 uint  `gorm:"primaryKey" json:"id"`
+	BundleID           uint  `gorm:"column:bundle_id;not null;index" json:"-"`
+	ComponentID        uint  `gorm:"column:component_id;not null;index" json:"component_id"`
+	ComponentVariantID *uint `gorm:"column:component_variant_id" json:"component_variant_id,omitempty"`
+	Quantity           int   `gorm:"column:quantity;not null;check:quantity > 0" json:"quantity"`
+	SortOrder          int   `gorm:"column:sort_order;not null;default:0" json:"sort_order"`
+
+	// Relations
+	Component        *Product        `json:"component,omitempty" gorm:"foreignKey:ComponentID"`
+	ComponentVariant *ProductVariant `json:"component_variant,omitempty" gorm:"foreignKey:ComponentVariantID"`
+}
+
+type BundleItemInput struct {
+	ProductID uint  `json:"product_id" binding:"required"`
+	VariantID *uint `json:"variant_id" binding:"omitempty"` // required when the component has variants
+	Quantity  int   `json:"quantity" binding:"required,gte=1"`
+}
+
+type BundleItemsRequest struct {
+	Items []BundleItemInput `json:"items" binding:"required,min=1,dive"`
+}
@@ -6,11 +6,15 @@ type Cart struct {
 	SessionID string     `gorm:"column:session_id;index" json:"session_id"`
 	Status    string     `gorm:"column:status;default:'active'" json:"status"`
 	Items     []CartItem `gorm:"foreignKey:CartID" json:"items,omitempty"`
+
+	// Warnings lists the accessories that do not fit a device in the cart, filled in by the handler
+	Warnings []CompatibilityWarning `gorm:"-" json:"warnings,omitempty"`
 }
 
 type CartAddItemRequest struct {
-	ProductID uint `json:"product_id" binding:"required"`
-	Quantity  int  `json:"quantity" binding:"required,gte=1"`
+	ProductID uint  `json:"product_id" binding:"required"`
+	VariantID *uint `json:"variant_id" binding:"omitempty"` // required when the product has variants
+	Quantity  int   `json:"quantity" binding:"required,gte=1"`
 }
 
 type CartUpdateItemRequest struct {
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"api_techstore/pkg/response"
+	"time"
+)
+
+// Catalog sort orders
+const (
+	CatalogSortNewest    = "newest"
+	CatalogSortPriceAsc  = "price_asc"
+	CatalogSortPriceDesc = "price_desc"
+	CatalogSortRating    = "rating"
+)
+
+const (
+	CatalogDefaultLimit = 20
+	CatalogMaxLimit     = 100
+)
+
+// CatalogQuery lists the products of the public catalog, with the filters of the search
+type CatalogQuery struct {
+	SearchQuery
+	Sort  string `form:"sort" binding:"omitempty,oneof=newest price_asc price_desc rating"`
+	Page  int    `form:"page" binding:"omitempty,gte=1"`
+	Limit int    `form:"limit" binding:"omitempty,gte=1,lte=100"`
+}
+
+// CatalogProduct is what anonymous shoppers see of a product: stock levels, storage details and
+// relations such as order lines are left out
+type CatalogProduct struct {
+	ID            uint             `json:"id"`
+	Name          string           `json:"name"`
+	Slug          string           `json:"slug"`
+	Description   string           `json:"description"`
+	Type          string           `json:"type"`
+	Price         float64          `json:"price"`
+	InStock       bool             `json:"in_stock"`
+	StockPolicy   string           `json:"stock_policy"`
+	AvailableAt   *time.Time       `json:"available_at,omitempty"` // expected shipping date of pre-ordered and backordered units
+	RatingAverage float64          `json:"rating_average"`
+	RatingCount   int              `json:"rating_count"`
+	Ratings       map[string]int64 `json:"ratings,omitempty"` // star ("1".."5") => number of reviews
+
+	Category    CatalogCategoryRef  `json:"category"`
+	Brand       *CatalogBrand       `json:"brand,omitempty"`
+	Breadcrumbs []Breadcrumb        `json:"breadcrumbs,omitempty"`
+	Images      []CatalogImage      `json:"images,omitempty"`
+	Variants    []CatalogVariant    `json:"variants,omitempty"`
+	Specs       []CatalogSpec       `json:"specs,omitempty"`
+	BundleItems []CatalogBundleItem `json:"bundle_items,omitempty"`
+}
+
+type CatalogCategoryRef struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type CatalogCategory struct {
+	CatalogCategoryRef
+	Children []CatalogCategory `json:"children"`
+}
+
+type CatalogBrand struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description,omitempty"`
+	LogoURL     string `json:"logo_url,omitempty"`
+}
+
+type CatalogImage struct {
+	URL       string                `json:"url"`
+	Width     int                   `json:"width"`
+	Height    int                   `json:"height"`
+	IsMain    bool                  `json:"is_main"`
+	VariantID *uint                 `json:"variant_id,omitempty"`
+	Sizes     []CatalogImageVariant `json:"sizes,omitempty"`
+}
+
+type CatalogImageVariant struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type CatalogVariant struct {
+	ID      uint                   `json:"id"`
+	SKU     string                 `json:"sku"`
+	Price   float64                `json:"price"`
+	InStock bool                   `json:"in_stock"`
+	Options []ProductVariantOption `json:"options"`
+}
+
+type CatalogSpec struct {
+	Code  string `json:"code"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Unit  string `json:"unit,omitempty"`
+}
+
+type CatalogBundleItem struct {
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	Quantity int    `json:"quantity"`
+}
+
+// CatalogProductList is one page of catalog products
+type CatalogProductList struct {
+	Products   []CatalogProduct    `json:"products"`
+	Pagination response.Pagination `json:"pagination"`
+}
+
+// CatalogBrandPage is the public landing data of a brand, see BrandPage
+type CatalogBrandPage struct {
+	Brand        CatalogBrand         `json:"brand"`
+	ProductCount int64                `json:"product_count"`
+	Categories   []BrandCategoryCount `json:"categories"`
+	TopProducts  []CatalogProduct     `json:"top_products"`
+}
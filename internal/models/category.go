@@ -2,19 +2,38 @@ package models
 
 type Category struct {
 	Base
-	Name string `gorm:"column:name;type:varchar(100);not null;unique" json:"name"`
-	Slug string `gorm:"column:slug;type:varchar(100);not null;unique" json:"slug"`
+	Name     string `gorm:"column:name;type:varchar(100);not null;uniqueIndex:idx_categories_name,where:deleted_at IS NULL" json:"name"`
+	Slug     string `gorm:"column:slug;type:varchar(100);not null;uniqueIndex:idx_categories_slug,where:deleted_at IS NULL" json:"slug"`
+	ParentID *uint  `gorm:"column:parent_id;index" json:"parent_id,omitempty"`
+	// Path is the materialized path of ids from the root down to the category, e.g. "/1/4/9/"
+	Path  string `gorm:"column:path;type:varchar(255);index" json:"path"`
+	Depth int    `gorm:"column:depth;default:0" json:"depth"`
+	// an inactive category hides the products of its whole subtree from the catalog
+	IsActive bool `gorm:"column:is_active;not null;default:true" json:"is_active"`
 
 	// Relations
-	Products []Product `json:"products,omitempty" gorm:"foreignKey:CategoryID"`
+	Products []Product  `json:"products,omitempty" gorm:"foreignKey:CategoryID"`
+	Children []Category `json:"children,omitempty" gorm:"foreignKey:ParentID"`
+}
+
+// Breadcrumb is one level of a category trail, root first
+type Breadcrumb struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
 }
 
 type CategoryCreateRequest struct {
-	Name string `json:"name" binding:"required,min=2,max=100"`
-	Slug string `json:"slug" binding:"required,min=2,max=100"`
+	Name     string `json:"name" binding:"required,min=2,max=100"`
+	Slug     string `json:"slug" binding:"omitempty,min=2,max=100"` // generated from the name when empty
+	ParentID *uint  `json:"parent_id" binding:"omitempty"`
+	IsActive *bool  `json:"is_active" binding:"omitempty"` // defaults to true
 }
 
 type CategoryUpdateRequest struct {
 	Name string `json:"name" binding:"omitempty,min=2,max=100"`
 	Slug string `json:"slug" binding:"omitempty,min=2,max=100"`
+	// ParentID moves the category (with its subtree); 0 moves it to the top level
+	ParentID *uint `json:"parent_id" binding:"omitempty"`
+	IsActive *bool `json:"is_active" binding:"omitempty"`
 }
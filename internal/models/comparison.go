@@ -0,0 +1,40 @@
+package models
+
+// Comparison row groups
+const (
+	ComparisonGroupGeneral = "general"
+	ComparisonGroupSpecs   = "specs"
+)
+
+// ProductComparison is the side by side view of 2-4 products of one category.
+// Every row has one value per product, in the order of Products.
+type ProductComparison struct {
+	Category ComparedCategory  `json:"category"`
+	Products []ComparedProduct `json:"products"`
+	Rows     []ComparisonRow   `json:"rows"`
+}
+
+type ComparedCategory struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type ComparedProduct struct {
+	ID       uint    `json:"id"`
+	Name     string  `json:"name"`
+	Slug     string  `json:"slug"`
+	ImageURL string  `json:"image_url,omitempty"`
+	Price    float64 `json:"price"`
+}
+
+// ComparisonRow is one aligned line of the matrix; a nil value means the product
+// has no value for it. Different is set when the products don't all share the same value.
+type ComparisonRow struct {
+	Group     string    `json:"group"`
+	Code      string    `json:"code"`
+	Label     string    `json:"label"`
+	Unit      string    `json:"unit,omitempty"`
+	Values    []*string `json:"values"`
+	Different bool      `json:"different"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// ProductCompatibility states that an accessory fits a device, whatever their specs say
+type ProductCompatibility struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	AccessoryID uint      `gorm:"column:accessory_id;not null;uniqueIndex:idx_product_compatibilities_pair" json:"accessory_id"`
+	DeviceID    uint      `gorm:"column:device_id;not null;uniqueIndex:idx_product_compatibilities_pair;index" json:"device_id"`
+}
+
+// CompatibilityRule matches accessories of one category to devices of another by a spec, e.g. the
+// memory type of RAM modules and laptops. An accessory fits a device when the two attribute values
+// are equal for every rule between their categories.
+type CompatibilityRule struct {
+	Base
+	AccessoryAttributeID uint   `gorm:"column:accessory_attribute_id;not null;uniqueIndex:idx_compatibility_rules_attributes,where:deleted_at IS NULL" json:"accessory_attribute_id"`
+	DeviceAttributeID    uint   `gorm:"column:device_attribute_id;not null;uniqueIndex:idx_compatibility_rules_attributes,where:deleted_at IS NULL" json:"device_attribute_id"`
+	Description          string `gorm:"column:description;type:varchar(255)" json:"description,omitempty"`
+
+	// Relations
+	AccessoryAttribute *CategoryAttribute `json:"accessory_attribute,omitempty" gorm:"foreignKey:AccessoryAttributeID"`
+	DeviceAttribute    *CategoryAttribute `json:"device_attribute,omitempty" gorm:"foreignKey:DeviceAttributeID"`
+}
+
+// CompatibilityWarning reports an accessory in the cart that does not fit a device in the same cart
+type CompatibilityWarning struct {
+	AccessoryID   uint   `json:"accessory_id"`
+	AccessoryName string `json:"accessory_name"`
+	DeviceID      uint   `json:"device_id"`
+	DeviceName    string `json:"device_name"`
+	Reason        string `json:"reason"`
+}
+
+type CompatibleDevicesRequest struct {
+	DeviceIDs []uint `json:"device_ids" binding:"omitempty,max=500,dive,gt=0"`
+}
+
+type CompatibilityRuleCreateRequest struct {
+	AccessoryAttributeID uint   `json:"accessory_attribute_id" binding:"required,gt=0"`
+	DeviceAttributeID    uint   `json:"device_attribute_id" binding:"required,gt=0"`
+	Description          string `json:"description" binding:"omitempty,max=255"`
+}
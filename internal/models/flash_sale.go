@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// FlashSale sells a limited quantity of a product, or of one of its variants, at a sale price
+// during a time window. The remaining stock and the quantities bought per user are counted in
+// Redis; at checkout the units sold are taken from the product stock and SoldQuantity is
+// synchronized from the orders.
+type FlashSale struct {
+	Base
+	ProductID     uint      `gorm:"column:product_id;not null;index" json:"product_id"`
+	VariantID     *uint     `gorm:"column:variant_id" json:"variant_id,omitempty"`
+	SalePrice     float64   `gorm:"column:sale_price;type:numeric(12,2);not null" json:"sale_price"`
+	StartsAt      time.Time `gorm:"column:starts_at;not null;index" json:"starts_at"`
+	EndsAt        time.Time `gorm:"column:ends_at;not null;index" json:"ends_at"`
+	TotalQuantity int       `gorm:"column:total_quantity;not null" json:"total_quantity"`
+	SoldQuantity  int       `gorm:"column:sold_quantity;not null;default:0" json:"sold_quantity"`
+	PerUserLimit  int       `gorm:"column:per_user_limit;not null" json:"per_user_limit"`
+	IsActive      bool      `gorm:"column:is_active;not null;default:true" json:"is_active"` // false once cancelled
+	CreatedBy     uint      `gorm:"column:created_by;not null" json:"created_by"`
+
+	// Relations
+	Product *Product        `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	Variant *ProductVariant `json:"variant,omitempty" gorm:"foreignKey:VariantID"`
+
+	// Remaining is the sale stock left, read from Redis by the service
+	Remaining int `json:"remaining" gorm:"-"`
+}
+
+// IsLive reports whether the sale can be bought from at the given time
+func (s FlashSale) IsLive(at time.Time) bool {
+	return s.IsActive && !at.Before(s.StartsAt) && at.Before(s.EndsAt)
+}
+
+type FlashSaleCreateRequest struct {
+	ProductID     uint      `json:"product_id" binding:"required"`
+	VariantID     *uint     `json:"variant_id" binding:"omitempty"` // required when the product has variants
+	SalePrice     float64   `json:"sale_price" binding:"required,gt=0"`
+	StartsAt      time.Time `json:"starts_at" binding:"required"`
+	EndsAt        time.Time `json:"ends_at" binding:"required,gtfield=StartsAt"`
+	TotalQuantity int       `json:"total_quantity" binding:"required,gte=1"`
+	PerUserLimit  int       `json:"per_user_limit" binding:"required,gte=1"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Import job states
+const (
+	ImportStatusPending   = "pending"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed" // every row was processed, some may have failed
+	ImportStatusFailed    = "failed"    // the job stopped before the end
+)
+
+// ImportJob tracks a bulk product import running in the background
+type ImportJob struct {
+	Base
+	FileName      string           `gorm:"column:file_name;type:varchar(255);not null" json:"file_name"`
+	Format        string           `gorm:"column:format;type:varchar(10);not null" json:"format"`
+	Status        string           `gorm:"column:status;type:varchar(20);not null;index" json:"status"`
+	TotalRows     int              `gorm:"column:total_rows;not null;default:0" json:"total_rows"`
+	ProcessedRows int              `gorm:"column:processed_rows;not null;default:0" json:"processed_rows"`
+	CreatedCount  int              `gorm:"column:created_count;not null;default:0" json:"created_count"`
+	UpdatedCount  int              `gorm:"column:updated_count;not null;default:0" json:"updated_count"`
+	FailedCount   int              `gorm:"column:failed_count;not null;default:0" json:"failed_count"`
+	RowErrors     []ImportRowError `gorm:"column:row_errors;serializer:json" json:"row_errors"`
+	Message       string           `gorm:"column:message;type:text" json:"message,omitempty"` // why a failed job stopped
+	CreatedBy     uint             `gorm:"column:created_by;not null" json:"created_by"`
+	StartedAt     *time.Time       `gorm:"column:started_at" json:"started_at,omitempty"`
+	FinishedAt    *time.Time       `gorm:"column:finished_at" json:"finished_at,omitempty"`
+}
+
+// ImportRowError reports why a row of the file was not imported; Row is the spreadsheet row number
+type ImportRowError struct {
+	Row    int      `json:"row"`
+	Slug   string   `json:"slug,omitempty"`
+	Errors []string `json:"errors"`
+}
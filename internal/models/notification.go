@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Notification types
+const (
+	NotificationQuestionAnswered = "question_answered"
+	NotificationClaimStatus      = "warranty_claim_status"
+	NotificationOrderInStock     = "order_in_stock"
+)
+
+// Notification is an in-app message for a user, e.g. that their question was answered
+type Notification struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UserID     uint       `gorm:"column:user_id;not null;index" json:"user_id"`
+	Type       string     `gorm:"column:type;type:varchar(50);not null" json:"type"`
+	Title      string     `gorm:"column:title;type:varchar(200);not null" json:"title"`
+	Message    string     `gorm:"column:message;type:text" json:"message"`
+	EntityType string     `gorm:"column:entity_type;type:varchar(20)" json:"entity_type,omitempty"` // what the notification is about, e.g. "product"
+	EntityID   *uint      `gorm:"column:entity_id" json:"entity_id,omitempty"`
+	ReadAt     *time.Time `gorm:"column:read_at" json:"read_at,omitempty"`
+}
@@ -1,11 +1,17 @@
 package models
 
+// OrderStatusAwaitingStock holds an order with pre-ordered or backordered units until they are
+// allocated from received stock; it then goes back to pending
+const OrderStatusAwaitingStock = "awaiting_stock"
+
 type Order struct {
 	Base
 	UserID            uint    `gorm:"column:user_id" json:"user_id"`
 	TotalAmount       float64 `gorm:"column:total_amount" json:"total_amount"`
-	Status            string  `gorm:"column:status;check:status IN ('pending', 'confirmed', 'processing', 'shipped', 'delivered', 'cancelled')" json:"status"`
+	Status            string  `gorm:"column:status;check:status IN ('pending', 'awaiting_stock', 'confirmed', 'processing', 'shipped', 'delivered', 'cancelled')" json:"status"`
 	ShippingAddressID *uint   `gorm:"column:shipping_address_id" json:"shipping_address_id"`
+	// TradeInCredit is the trade-in value deducted from the total
+	TradeInCredit float64 `gorm:"column:trade_in_credit;type:numeric(12,2);not null;default:0" json:"trade_in_credit"`
 
 	// Relations
 	User            User        `json:"user,omitempty" gorm:"foreignKey:UserID"`
@@ -18,6 +24,8 @@ type OrderCreateRequest struct {
 	// TotalAmount       float64 `json:"total_amount" binding:"required,gt=0"`
 	Status            string `json:"status" binding:"omitempty" default:"pending"`
 	ShippingAddressID *uint  `json:"shipping_address_id" binding:"required"`
+	// TradeInID is an accepted trade-in whose value is credited to the order
+	TradeInID *uint `json:"trade_in_id" binding:"omitempty,gt=0"`
 }
 
 type OrderUpdateRequest struct {
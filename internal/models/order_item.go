@@ -4,10 +4,21 @@ type OrderItem struct {
 	Base
 	OrderID   uint    `gorm:"column:order_id;not null" json:"order_id"`
 	ProductID uint    `gorm:"column:product_id;not null" json:"product_id"`
+	VariantID *uint   `gorm:"column:variant_id" json:"variant_id,omitempty"`
+	SKU       string  `gorm:"column:sku;type:varchar(64)" json:"sku,omitempty"`
 	Quantity  int     `gorm:"column:quantity;not null" json:"quantity"`
 	UnitPrice float64 `gorm:"column:unit_price;type:numeric(10,2);not null" json:"unit_price"`
+	// FlashSaleID is set when the line was bought at a flash sale price
+	FlashSaleID *uint `gorm:"column:flash_sale_id;index" json:"flash_sale_id,omitempty"`
+	// BundleProductID is set on the component lines a bundle was split into
+	BundleProductID *uint `gorm:"column:bundle_product_id;index" json:"bundle_product_id,omitempty"`
+	// BackorderedQuantity is the number of units of the line still waiting for stock
+	BackorderedQuantity int `gorm:"column:backordered_quantity;not null;default:0" json:"backordered_quantity"`
+	// StockTaken is set while the units of the line are taken from stock, until the order is cancelled
+	StockTaken bool `gorm:"column:stock_taken;not null;default:false" json:"-"`
 
 	// Relations
-	Order   Order   `json:"-,omitempty" gorm:"foreignKey:OrderID"`
-	Product Product `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	Order   Order           `json:"-,omitempty" gorm:"foreignKey:OrderID"`
+	Product Product         `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	Variant *ProductVariant `json:"variant,omitempty" gorm:"foreignKey:VariantID"`
 }
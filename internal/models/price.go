@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// What caused a price change
+const (
+	PriceSourceManual   = "manual"   // product created or updated by an admin
+	PriceSourceImport   = "import"   // bulk product import
+	PriceSourceSchedule = "schedule" // scheduled price started or ended
+)
+
+// Scheduled price states
+const (
+	ScheduledPricePending   = "pending"   // waiting for its start
+	ScheduledPriceActive    = "active"    // applied, the previous price comes back at the end
+	ScheduledPriceCompleted = "completed" // applied and, when it had an end, reverted
+	ScheduledPriceCancelled = "cancelled"
+)
+
+// PriceHistory records a change of a product's price; OldPrice is nil for the first price
+type PriceHistory struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	ProductID        uint      `gorm:"column:product_id;not null;index:idx_price_history_product_changed" json:"product_id"`
+	OldPrice         *float64  `gorm:"column:old_price;type:numeric(12,2)" json:"old_price"`
+	NewPrice         float64   `gorm:"column:new_price;type:numeric(12,2);not null" json:"new_price"`
+	Source           string    `gorm:"column:source;type:varchar(20);not null" json:"source"`
+	ScheduledPriceID *uint     `gorm:"column:scheduled_price_id" json:"scheduled_price_id,omitempty"`
+	ChangedAt        time.Time `gorm:"column:changed_at;not null;index:idx_price_history_product_changed" json:"changed_at"`
+}
+
+// TableName overrides the pluralised default "price_histories"
+func (PriceHistory) TableName() string {
+	return "price_history"
+}
+
+// ScheduledPrice sets the price of a product at StartsAt and, when EndsAt is set, restores
+// the previous price at EndsAt. Both are applied by the price schedule job.
+type ScheduledPrice struct {
+	Base
+	ProductID     uint       `gorm:"column:product_id;not null;index" json:"product_id"`
+	Price         float64    `gorm:"column:price;type:numeric(12,2);not null" json:"price"`
+	StartsAt      time.Time  `gorm:"column:starts_at;not null;index" json:"starts_at"`
+	EndsAt        *time.Time `gorm:"column:ends_at" json:"ends_at,omitempty"`
+	Status        string     `gorm:"column:status;type:varchar(20);not null;default:pending;index;check:status IN ('pending', 'active', 'completed', 'cancelled')" json:"status"`
+	PreviousPrice *float64   `gorm:"column:previous_price;type:numeric(12,2)" json:"previous_price,omitempty"` // price replaced at the start
+	Note          string     `gorm:"column:note;type:varchar(255)" json:"note,omitempty"`
+	CreatedBy     uint       `gorm:"column:created_by;not null" json:"created_by"`
+}
+
+type ScheduledPriceCreateRequest struct {
+	Price    float64    `json:"price" binding:"required,gt=0"`
+	StartsAt time.Time  `json:"starts_at" binding:"required"`
+	EndsAt   *time.Time `json:"ends_at" binding:"omitempty,gtfield=StartsAt"`
+	Note     string     `json:"note" binding:"omitempty,max=255"`
+}
+
+// ProductPriceHistory is the price history of a product with the lowest price of the 30 days
+// before the current price took effect, the only price a sale may show as the "was" price
+type ProductPriceHistory struct {
+	ProductID        uint           `json:"product_id"`
+	CurrentPrice     float64        `json:"current_price"`
+	LowestPrice      float64        `json:"lowest_price"`
+	LowestPriceSince time.Time      `json:"lowest_price_since"`
+	History          []PriceHistory `json:"history"`
+}
@@ -1,5 +1,21 @@
 package models
 
+import "time"
+
+// Product types: a bundle is sold as one product and fulfilled from its component products
+const (
+	ProductTypeSimple = "simple"
+	ProductTypeBundle = "bundle"
+)
+
+// Stock policies: pre-order and backorder products can be ordered past zero stock, up to the
+// backorder limit; those units wait for stock and are allocated to orders first come first served
+const (
+	StockPolicyNormal    = "normal"
+	StockPolicyPreorder  = "preorder"
+	StockPolicyBackorder = "backorder"
+)
+
 type Product struct {
 	Base
 	Name        string  `gorm:"column:name" json:"name"`
@@ -8,33 +24,98 @@ type Product struct {
 	Quantity    int     `gorm:"column:quantity" json:"quantity"`
 	CategoryID  uint    `gorm:"column:category_id;not null" json:"category_id"`
 	BrandID     *uint   `gorm:"column:brand_id" json:"brand_id,omitempty"`
-	Slug        string  `gorm:"column:slug;unique" json:"slug"`
+	Slug        string  `gorm:"column:slug;uniqueIndex:idx_products_slug,where:deleted_at IS NULL" json:"slug"`
 	IsActive    bool    `gorm:"column:is_active" json:"is_active"`
+	Type        string  `gorm:"column:type;type:varchar(20);not null;default:simple" json:"type"`
+	// WarrantyMonths overrides the brand's warranty period for the units sold
+	WarrantyMonths *int `gorm:"column:warranty_months" json:"warranty_months,omitempty"`
+
+	// Pre-order and backorder settings, see StockPolicy*
+	StockPolicy string `gorm:"column:stock_policy;type:varchar(20);not null;default:normal;check:stock_policy IN ('normal', 'preorder', 'backorder')" json:"stock_policy"`
+	// ExpectedAvailableAt is when pre-ordered or backordered units are expected to ship
+	ExpectedAvailableAt *time.Time `gorm:"column:expected_available_at" json:"expected_available_at,omitempty"`
+	BackorderLimit      int        `gorm:"column:backorder_limit;not null;default:0" json:"backorder_limit"`           // units that may wait for stock at once
+	BackorderedQuantity int        `gorm:"column:backordered_quantity;not null;default:0" json:"backordered_quantity"` // units ordered and waiting for stock
+
+	// Rating aggregate of the approved reviews, kept up to date by the review service
+	RatingAverage   float64          `gorm:"column:rating_average;type:numeric(3,2);not null;default:0" json:"rating_average"`
+	RatingCount     int              `gorm:"column:rating_count;not null;default:0" json:"rating_count"`
+	RatingHistogram map[string]int64 `gorm:"column:rating_histogram;serializer:json" json:"rating_histogram,omitempty"` // star ("1".."5") => number of reviews
 
 	// Relations
-	Category   Category    `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
-	Brand      *Brand      `json:"brand,omitempty" gorm:"foreignKey:BrandID"`
-	OrderItems []OrderItem `json:"order_items,omitempty" gorm:"foreignKey:ProductID"`
+	Category   Category                `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
+	Brand      *Brand                  `json:"brand,omitempty" gorm:"foreignKey:BrandID"`
+	Images     []ProductImage          `json:"images,omitempty" gorm:"foreignKey:ProductID"`
+	Variants   []ProductVariant        `json:"variants,omitempty" gorm:"foreignKey:ProductID"`
+	Attributes []ProductAttributeValue `json:"attributes,omitempty" gorm:"foreignKey:ProductID"`
+	OrderItems []OrderItem             `json:"order_items,omitempty" gorm:"foreignKey:ProductID"`
+	// BundleItems are the components of a bundle; its Quantity is computed from their stock
+	BundleItems []BundleItem `json:"bundle_items,omitempty" gorm:"foreignKey:BundleID"`
+
+	// Breadcrumbs is the category trail of the product, filled in by the service
+	Breadcrumbs []Breadcrumb `json:"breadcrumbs,omitempty" gorm:"-"`
+}
+
+// BackorderAllowance is the number of units that can still be ordered past the stock
+func (p Product) BackorderAllowance() int {
+	if p.StockPolicy != StockPolicyPreorder && p.StockPolicy != StockPolicyBackorder {
+		return 0
+	}
+	if p.BackorderedQuantity >= p.BackorderLimit {
+		return 0
+	}
+	return p.BackorderLimit - p.BackorderedQuantity
 }
 
 type ProductCreateRequest struct {
-	Name        string  `json:"name" binding:"required,min=2,max=200"`
-	Description string  `json:"description" binding:"omitempty,max=1000"`
-	Price       float64 `json:"price" binding:"required,gt=0"`
-	Quantity    int     `json:"quantity" binding:"required,gte=0"`
-	CategoryID  uint    `json:"category_id" binding:"required"`
-	BrandID     *uint   `json:"brand_id,omitempty" binding:"omitempty"`
-	Slug        string  `json:"slug" binding:"required,min=2,max=100"`
-	IsActive    *bool   `json:"is_active" binding:"omitempty"`
+	Name           string  `json:"name" binding:"required,min=2,max=200"`
+	Description    string  `json:"description" binding:"omitempty,max=1000"`
+	Price          float64 `json:"price" binding:"required,gt=0"`
+	Quantity       int     `json:"quantity" binding:"required,gte=0"`
+	CategoryID     uint    `json:"category_id" binding:"required"`
+	BrandID        *uint   `json:"brand_id,omitempty" binding:"omitempty"`
+	Slug           string  `json:"slug" binding:"omitempty,min=2,max=100"` // generated from the name when empty
+	IsActive       *bool   `json:"is_active" binding:"omitempty"`
+	WarrantyMonths *int    `json:"warranty_months" binding:"omitempty,gte=0,lte=120"` // the brand's period when empty
+	Type           string  `json:"type" binding:"omitempty,oneof=simple bundle"`      // simple when empty
+	// Attributes are spec values keyed by attribute code, e.g. {"ram_gb": 16, "cpu": "Core i7"}
+	Attributes map[string]interface{} `json:"attributes" binding:"omitempty"`
 }
 
 type ProductUpdateRequest struct {
-	Name        string  `json:"name" binding:"omitempty,min=2,max=200"`
-	Description string  `json:"description" binding:"omitempty,max=1000"`
-	Price       float64 `json:"price" binding:"omitempty,gt=0"`
-	Quantity    int     `json:"quantity" binding:"omitempty,gte=0"`
-	CategoryID  uint    `json:"category_id" binding:"omitempty"`
-	BrandID     *uint   `json:"brand_id,omitempty" binding:"omitempty"`
-	Slug        string  `json:"slug" binding:"omitempty,min=2,max=100"`
-	IsActive    *bool   `json:"is_active" binding:"omitempty"`
+	Name           string  `json:"name" binding:"omitempty,min=2,max=200"`
+	Description    string  `json:"description" binding:"omitempty,max=1000"`
+	Price          float64 `json:"price" binding:"omitempty,gt=0"`
+	Quantity       int     `json:"quantity" binding:"omitempty,gte=0"`
+	CategoryID     uint    `json:"category_id" binding:"omitempty"`
+	BrandID        *uint   `json:"brand_id,omitempty" binding:"omitempty"`
+	Slug           string  `json:"slug" binding:"omitempty,min=2,max=100"`
+	IsActive       *bool   `json:"is_active" binding:"omitempty"`
+	WarrantyMonths *int    `json:"warranty_months" binding:"omitempty,gte=0,lte=120"`
+	// ClearWarrantyMonths removes the product's own period, so the brand's applies again
+	ClearWarrantyMonths bool `json:"clear_warranty_months"`
+	// Attributes are merged into the current values; a null value removes the attribute
+	Attributes map[string]interface{} `json:"attributes" binding:"omitempty"`
+}
+
+type ProductStockPolicyRequest struct {
+	StockPolicy         string     `json:"stock_policy" binding:"required,oneof=normal preorder backorder"`
+	ExpectedAvailableAt *time.Time `json:"expected_available_at" binding:"omitempty"`
+	BackorderLimit      int        `json:"backorder_limit" binding:"omitempty,gte=0"`
+}
+
+// StockReceiptRequest records units received for a product, or for one of its variants
+type StockReceiptRequest struct {
+	VariantID *uint `json:"variant_id" binding:"omitempty"` // required when the product has variants
+	Quantity  int   `json:"quantity" binding:"required,gte=1"`
+}
+
+// StockReceipt is the outcome of receiving stock: units allocated to waiting orders and what is left
+type StockReceipt struct {
+	ProductID      uint   `json:"product_id"`
+	VariantID      *uint  `json:"variant_id,omitempty"`
+	Received       int    `json:"received"`
+	Allocated      int    `json:"allocated"`
+	Stock          int    `json:"stock"`
+	ReleasedOrders []uint `json:"released_orders"` // orders whose every unit is now in stock
 }
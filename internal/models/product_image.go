@@ -2,11 +2,36 @@ package models
 
 type ProductImage struct {
 	Base
-	ProductID uint   `gorm:"column:product_id;not null" json:"product_id"`
-	ImageURL  string `gorm:"column:image_url;not null" json:"image_url"`
-	IsMain    bool   `gorm:"column:is_main;default:false" json:"is_main"`
-	SortOrder int    `gorm:"column:sort_order;default:0" json:"sort_order"`
+	ProductID   uint   `gorm:"column:product_id;not null;index;uniqueIndex:idx_product_images_main,where:is_main = true AND deleted_at IS NULL" json:"product_id"`
+	VariantID   *uint  `gorm:"column:variant_id;index" json:"variant_id,omitempty"`
+	ImageURL    string `gorm:"column:image_url;not null" json:"image_url"`
+	StorageKey  string `gorm:"column:storage_key" json:"-"`
+	ContentType string `gorm:"column:content_type" json:"content_type"`
+	FileSize    int64  `gorm:"column:file_size" json:"file_size"`
+	Width       int    `gorm:"column:width" json:"width"`
+	Height      int    `gorm:"column:height" json:"height"`
+	IsMain      bool   `gorm:"column:is_main;default:false" json:"is_main"`
+	SortOrder   int    `gorm:"column:sort_order;default:0" json:"sort_order"`
 
 	// Relations
-	Product Product `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	Product  Product               `json:"-" gorm:"foreignKey:ProductID"`
+	Variants []ProductImageVariant `json:"variants,omitempty" gorm:"foreignKey:ProductImageID;constraint:OnDelete:CASCADE"`
+}
+
+// ProductImageVariant is a resized rendition of a product image (thumbnail, medium, large)
+// in one format; every size is stored in WebP and as JPEG for photos or PNG for images with transparency
+type ProductImageVariant struct {
+	Base
+	ProductImageID uint   `gorm:"column:product_image_id;not null;index" json:"product_image_id"`
+	Name           string `gorm:"column:name;type:varchar(20);not null" json:"name"`     // thumbnail, medium, large
+	Format         string `gorm:"column:format;type:varchar(10);not null" json:"format"` // jpeg, png, webp
+	ImageURL       string `gorm:"column:image_url;not null" json:"image_url"`
+	StorageKey     string `gorm:"column:storage_key" json:"-"`
+	Width          int    `gorm:"column:width" json:"width"`
+	Height         int    `gorm:"column:height" json:"height"`
+	FileSize       int64  `gorm:"column:file_size" json:"file_size"`
+}
+
+type ProductImageReorderRequest struct {
+	ImageIDs []uint `json:"image_ids" binding:"required,min=1"`
 }
@@ -0,0 +1,49 @@
+package models
+
+// ProductVariant is a purchasable configuration of a product (e.g. 16GB / 512GB / Silver)
+// with its own SKU, price and stock
+type ProductVariant struct {
+	Base
+	ProductID uint    `gorm:"column:product_id;not null;index;uniqueIndex:idx_product_variants_options,where:deleted_at IS NULL" json:"product_id"`
+	SKU       string  `gorm:"column:sku;type:varchar(64);not null;uniqueIndex:idx_product_variants_sku,where:deleted_at IS NULL" json:"sku"`
+	OptionKey string  `gorm:"column:option_key;type:varchar(500);not null;uniqueIndex:idx_product_variants_options,where:deleted_at IS NULL" json:"-"`
+	Price     float64 `gorm:"column:price;type:numeric(12,2);not null" json:"price"`
+	Quantity  int     `gorm:"column:quantity;not null;default:0" json:"quantity"`
+	IsActive  bool    `gorm:"column:is_active" json:"is_active"`
+
+	// Relations
+	Product Product                `json:"-" gorm:"foreignKey:ProductID"`
+	Options []ProductVariantOption `json:"options" gorm:"foreignKey:VariantID;constraint:OnDelete:CASCADE"`
+	Images  []ProductImage         `json:"images,omitempty" gorm:"foreignKey:VariantID"`
+}
+
+// ProductVariantOption is one option value of a variant, e.g. color=Silver
+type ProductVariantOption struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	VariantID uint   `gorm:"column:variant_id;not null;index" json:"-"`
+	Name      string `gorm:"column:name;type:varchar(50);not null" json:"name"`
+	Value     string `gorm:"column:value;type:varchar(100);not null" json:"value"`
+}
+
+type ProductVariantOptionInput struct {
+	Name  string `json:"name" binding:"required,max=50"`
+	Value string `json:"value" binding:"required,max=100"`
+}
+
+type ProductVariantCreateRequest struct {
+	SKU      string                      `json:"sku" binding:"required,min=2,max=64"`
+	Price    float64                     `json:"price" binding:"required,gt=0"`
+	Quantity int                         `json:"quantity" binding:"gte=0"`
+	IsActive *bool                       `json:"is_active" binding:"omitempty"`
+	Options  []ProductVariantOptionInput `json:"options" binding:"required,min=1,dive"`
+	ImageIDs []uint                      `json:"image_ids" binding:"omitempty"`
+}
+
+type ProductVariantUpdateRequest struct {
+	SKU      string                      `json:"sku" binding:"omitempty,min=2,max=64"`
+	Price    float64                     `json:"price" binding:"omitempty,gt=0"`
+	Quantity *int                        `json:"quantity" binding:"omitempty,gte=0"`
+	IsActive *bool                       `json:"is_active" binding:"omitempty"`
+	Options  []ProductVariantOptionInput `json:"options" binding:"omitempty,min=1,dive"`
+	ImageIDs *[]uint                     `json:"image_ids" binding:"omitempty"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// TrendingProduct is a product of the trending listing with its number of views in the period
+type TrendingProduct struct {
+	Product
+	Views int64 `json:"views"`
+}
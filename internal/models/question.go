@@ -0,0 +1,62 @@
+package models
+
+// Moderation states of questions and answers; only approved ones are public
+const (
+	QAStatusPending  = "pending"
+	QAStatusApproved = "approved"
+	QAStatusRejected = "rejected"
+)
+
+// Who wrote an answer, shown as a badge next to it
+const (
+	AnswerAuthorStaff         = "staff"
+	AnswerAuthorVerifiedBuyer = "verified_buyer"
+	AnswerAuthorCustomer      = "customer"
+)
+
+// ProductQuestion is a pre-sales question about a product
+type ProductQuestion struct {
+	Base
+	ProductID      uint   `gorm:"column:product_id;not null;index" json:"product_id"`
+	UserID         uint   `gorm:"column:user_id;not null;index" json:"user_id"`
+	Content        string `gorm:"column:content;type:varchar(1000);not null" json:"content"`
+	Status         string `gorm:"column:status;type:varchar(20);not null;default:pending;index;check:status IN ('pending', 'approved', 'rejected')" json:"status"`
+	ModerationNote string `gorm:"column:moderation_note;type:varchar(500)" json:"moderation_note,omitempty"`
+
+	// Relations
+	User    *User           `json:"-" gorm:"foreignKey:UserID"`
+	Answers []ProductAnswer `json:"answers" gorm:"foreignKey:QuestionID"`
+
+	// AskerName is the public name of the author, filled in by the service
+	AskerName string `json:"asker_name" gorm:"-"`
+}
+
+// ProductAnswer is an answer to a product question by staff or another customer
+type ProductAnswer struct {
+	Base
+	QuestionID     uint   `gorm:"column:question_id;not null;index" json:"question_id"`
+	UserID         uint   `gorm:"column:user_id;not null;index" json:"user_id"`
+	Content        string `gorm:"column:content;type:varchar(2000);not null" json:"content"`
+	AuthorType     string `gorm:"column:author_type;type:varchar(20);not null;check:author_type IN ('staff', 'verified_buyer', 'customer')" json:"author_type"`
+	Status         string `gorm:"column:status;type:varchar(20);not null;default:pending;index;check:status IN ('pending', 'approved', 'rejected')" json:"status"`
+	ModerationNote string `gorm:"column:moderation_note;type:varchar(500)" json:"moderation_note,omitempty"`
+
+	// Relations
+	User *User `json:"-" gorm:"foreignKey:UserID"`
+
+	// AuthorName is the public name of the author, filled in by the service
+	AuthorName string `json:"author_name" gorm:"-"`
+}
+
+type ProductQuestionCreateRequest struct {
+	Content string `json:"content" binding:"required,min=5,max=1000"`
+}
+
+type ProductAnswerCreateRequest struct {
+	Content string `json:"content" binding:"required,min=2,max=2000"`
+}
+
+type QAModerateRequest struct {
+	Status string `json:"status" binding:"required,oneof=pending approved rejected"`
+	Note   string `json:"note" binding:"omitempty,max=500"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Recommendation lists kept for every product
+const (
+	RecommendationRelated        = "related"
+	RecommendationBoughtTogether = "bought_together"
+)
+
+// Where a recommendation comes from: orders containing both products, or the catalog fallbacks
+const (
+	RecommendationSourceOrders   = "orders"
+	RecommendationSourceCategory = "category"
+	RecommendationSourceBrand    = "brand"
+)
+
+// ProductRecommendation is one precomputed entry of a product's recommendation list,
+// rebuilt periodically by the recommendation job
+type ProductRecommendation struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	ProductID     uint      `gorm:"column:product_id;not null;uniqueIndex:idx_product_recommendations_entry" json:"product_id"`
+	Kind          string    `gorm:"column:kind;type:varchar(30);not null;uniqueIndex:idx_product_recommendations_entry" json:"kind"`
+	RecommendedID uint      `gorm:"column:recommended_id;not null;uniqueIndex:idx_product_recommendations_entry" json:"recommended_id"`
+	Rank          int       `gorm:"column:rank;not null" json:"rank"`
+	Score         float64   `gorm:"column:score;not null;default:0" json:"score"` // orders containing both products; 0 for fallbacks
+	Source        string    `gorm:"column:source;type:varchar(20);not null" json:"source"`
+	ComputedAt    time.Time `gorm:"column:computed_at;not null" json:"computed_at"`
+}
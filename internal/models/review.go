@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// Review moderation states; only approved reviews are public and count towards the rating
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+)
+
+// MaxReviewPhotos is the number of photos a review may carry
+const MaxReviewPhotos = 5
+
+// Review is a verified-purchase rating of a product; a customer reviews a product once
+type Review struct {
+	Base
+	ProductID      uint       `gorm:"column:product_id;not null;index;uniqueIndex:idx_reviews_product_user,where:deleted_at IS NULL" json:"product_id"`
+	UserID         uint       `gorm:"column:user_id;not null;index;uniqueIndex:idx_reviews_product_user,where:deleted_at IS NULL" json:"user_id"`
+	OrderItemID    uint       `gorm:"column:order_item_id;not null" json:"order_item_id"`
+	Rating         int        `gorm:"column:rating;not null;check:rating BETWEEN 1 AND 5" json:"rating"`
+	Title          string     `gorm:"column:title;type:varchar(150)" json:"title"`
+	Content        string     `gorm:"column:content;type:text" json:"content"`
+	Status         string     `gorm:"column:status;type:varchar(20);not null;default:pending;index;check:status IN ('pending', 'approved', 'rejected')" json:"status"`
+	ModerationNote string     `gorm:"column:moderation_note;type:varchar(500)" json:"moderation_note,omitempty"`
+	ModeratedAt    *time.Time `gorm:"column:moderated_at" json:"moderated_at,omitempty"`
+	HelpfulCount   int        `gorm:"column:helpful_count;not null;default:0" json:"helpful_count"`
+
+	// Relations
+	User   *User         `json:"-" gorm:"foreignKey:UserID"`
+	Photos []ReviewPhoto `json:"photos,omitempty" gorm:"foreignKey:ReviewID;constraint:OnDelete:CASCADE"`
+
+	// ReviewerName is the public name of the author, filled in by the service
+	ReviewerName string `json:"reviewer_name" gorm:"-"`
+}
+
+// ReviewPhoto is a customer photo attached to a review
+type ReviewPhoto struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	ReviewID    uint   `gorm:"column:review_id;not null;index" json:"-"`
+	ImageURL    string `gorm:"column:image_url;not null" json:"image_url"`
+	StorageKey  string `gorm:"column:storage_key" json:"-"`
+	ContentType string `gorm:"column:content_type;type:varchar(50)" json:"content_type"`
+	SortOrder   int    `gorm:"column:sort_order;not null;default:0" json:"sort_order"`
+}
+
+// ReviewVote records that a user found a review helpful, once per user
+type ReviewVote struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ReviewID  uint      `gorm:"column:review_id;not null;uniqueIndex:idx_review_votes_review_user" json:"review_id"`
+	UserID    uint      `gorm:"column:user_id;not null;uniqueIndex:idx_review_votes_review_user" json:"user_id"`
+}
+
+// ReviewCreateRequest is bound from multipart form fields; photos are sent as "photos" files
+type ReviewCreateRequest struct {
+	Rating  int    `form:"rating" binding:"required,min=1,max=5"`
+	Title   string `form:"title" binding:"omitempty,max=150"`
+	Content string `form:"content" binding:"omitempty,max=5000"`
+}
+
+type ReviewModerateRequest struct {
+	Status string `json:"status" binding:"required,oneof=pending approved rejected"`
+	Note   string `json:"note" binding:"omitempty,max=500"`
+}
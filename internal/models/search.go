@@ -0,0 +1,59 @@
+package models
+
+// SearchQuery holds the filters a shopper has already applied on the storefront.
+// Multi-select facets (category_id, brand_id) are passed as repeated query params.
+// Spec attributes are passed as attr[code]=value, several values separated by commas
+// (attr[ram_gb]=16,32); number attributes also accept a range (attr[screen_inch]=13..15).
+type SearchQuery struct {
+	Keyword     string            `form:"q" binding:"omitempty,max=200"`
+	CategoryIDs []uint            `form:"category_id" binding:"omitempty"`
+	BrandIDs    []uint            `form:"brand_id" binding:"omitempty"`
+	MinPrice    *float64          `form:"min_price" binding:"omitempty,gte=0"`
+	MaxPrice    *float64          `form:"max_price" binding:"omitempty,gte=0"`
+	InStock     *bool             `form:"in_stock" binding:"omitempty"`
+	Attributes  map[string]string `form:"-"`
+}
+
+// SearchFacets is the sidebar data returned by /search/filters
+type SearchFacets struct {
+	Total        int64             `json:"total"`
+	Categories   []FacetCount      `json:"categories"`
+	Brands       []FacetCount      `json:"brands"`
+	PriceRanges  []PriceRangeFacet `json:"price_ranges"`
+	Availability AvailabilityFacet `json:"availability"`
+	Attributes   []AttributeFacet  `json:"attributes"`
+}
+
+type FacetCount struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	Count    int64  `json:"count"`
+	Selected bool   `json:"selected"`
+}
+
+type PriceRangeFacet struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int64   `json:"count"`
+}
+
+type AvailabilityFacet struct {
+	InStock    int64 `json:"in_stock"`
+	OutOfStock int64 `json:"out_of_stock"`
+}
+
+// AttributeFacet lists the values of a filterable spec attribute among the matching products
+type AttributeFacet struct {
+	Code   string                `json:"code"`
+	Name   string                `json:"name"`
+	Type   string                `json:"type"`
+	Unit   string                `json:"unit,omitempty"`
+	Values []AttributeFacetValue `json:"values"`
+}
+
+type AttributeFacetValue struct {
+	Value    string `json:"value"`
+	Count    int64  `json:"count"`
+	Selected bool   `json:"selected"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Entity types that have slugs
+const (
+	SlugEntityProduct  = "product"
+	SlugEntityBrand    = "brand"
+	SlugEntityCategory = "category"
+)
+
+// SlugRedirect remembers a slug an entity used to have, so old URLs keep working
+type SlugRedirect struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	EntityType string    `gorm:"column:entity_type;type:varchar(20);not null;uniqueIndex:idx_slug_redirects_slug" json:"entity_type"`
+	Slug       string    `gorm:"column:slug;type:varchar(100);not null;uniqueIndex:idx_slug_redirects_slug" json:"slug"`
+	EntityID   uint      `gorm:"column:entity_id;not null;index" json:"entity_id"`
+}
@@ -33,14 +33,142 @@ type SwaggerProduct struct {
 	BrandID     *uint   `json:"brand_id,omitempty" example:"1"`
 	Slug        string  `json:"slug" example:"iphone-15"`
 	IsActive    bool    `json:"is_active" example:"true"`
+	Type        string  `json:"type" example:"simple"`
+	// WarrantyMonths overrides the brand's warranty period
+	WarrantyMonths *int `json:"warranty_months,omitempty" example:"24"`
+
+	StockPolicy         string     `json:"stock_policy" example:"preorder"`
+	ExpectedAvailableAt *time.Time `json:"expected_available_at,omitempty" example:"2024-02-01T00:00:00Z"`
+	BackorderLimit      int        `json:"backorder_limit" example:"50"`
+	BackorderedQuantity int        `json:"backordered_quantity" example:"12"`
+
+	RatingAverage   float64          `json:"rating_average" example:"4.6"`
+	RatingCount     int              `json:"rating_count" example:"25"`
+	RatingHistogram map[string]int64 `json:"rating_histogram,omitempty"`
+
+	Images     []SwaggerProductImage          `json:"images,omitempty"`
+	Variants   []SwaggerProductVariant        `json:"variants,omitempty"`
+	Attributes []SwaggerProductAttributeValue `json:"attributes,omitempty"`
+	// BundleItems are the components of a bundle product
+	BundleItems []SwaggerBundleItem `json:"bundle_items,omitempty"`
+
+	Breadcrumbs []SwaggerBreadcrumb `json:"breadcrumbs,omitempty"`
+}
+
+// SwaggerBundleItem represents a component of a bundle for Swagger documentation
+// @Description Bundle component model for Swagger documentation
+type SwaggerBundleItem struct {
+	ID                 uint                   `json:"id" example:"1"`
+	ComponentID        uint                   `json:"component_id" example:"12"`
+	ComponentVariantID *uint                  `json:"component_variant_id,omitempty" example:"4"`
+	Quantity           int                    `json:"quantity" example:"1"`
+	SortOrder          int                    `json:"sort_order" example:"0"`
+	Component          *SwaggerProduct        `json:"component,omitempty"`
+	ComponentVariant   *SwaggerProductVariant `json:"component_variant,omitempty"`
+}
+
+// SwaggerProductImage represents product image model for Swagger documentation
+// @Description Product image model for Swagger documentation
+type SwaggerProductImage struct {
+	SwaggerBase
+	ProductID   uint   `json:"product_id" example:"1"`
+	ImageURL    string `json:"image_url" example:"/uploads/products/1/3f1c2a9e.jpg"`
+	ContentType string `json:"content_type" example:"image/jpeg"`
+	FileSize    int64  `json:"file_size" example:"204800"`
+	Width       int    `json:"width" example:"3000"`
+	Height      int    `json:"height" example:"2000"`
+	IsMain      bool   `json:"is_main" example:"true"`
+	SortOrder   int    `json:"sort_order" example:"0"`
+	VariantID   *uint  `json:"variant_id,omitempty" example:"1"`
+
+	Variants []SwaggerProductImageVariant `json:"variants,omitempty"`
+}
+
+// SwaggerProductImageVariant represents a resized product image for Swagger documentation
+// @Description Product image variant model for Swagger documentation
+type SwaggerProductImageVariant struct {
+	SwaggerBase
+	ProductImageID uint   `json:"product_image_id" example:"1"`
+	Name           string `json:"name" example:"thumbnail"` // thumbnail, medium, large
+	Format         string `json:"format" example:"jpeg"`    // jpeg, png, webp
+	ImageURL       string `json:"image_url" example:"/uploads/products/1/3f1c2a9e_thumbnail.jpg"`
+	Width          int    `json:"width" example:"150"`
+	Height         int    `json:"height" example:"100"`
+	FileSize       int64  `json:"file_size" example:"4752"`
+}
+
+// SwaggerProductVariant represents product variant model for Swagger documentation
+// @Description Product variant model for Swagger documentation
+type SwaggerProductVariant struct {
+	SwaggerBase
+	ProductID uint    `json:"product_id" example:"1"`
+	SKU       string  `json:"sku" example:"XPS13-16-512-SLV"`
+	Price     float64 `json:"price" example:"1499.99"`
+	Quantity  int     `json:"quantity" example:"10"`
+	IsActive  bool    `json:"is_active" example:"true"`
+
+	Options []SwaggerProductVariantOption `json:"options"`
+	Images  []SwaggerProductImage         `json:"images,omitempty"`
+}
+
+// SwaggerProductVariantOption represents a variant option value for Swagger documentation
+// @Description Product variant option model for Swagger documentation
+type SwaggerProductVariantOption struct {
+	ID    uint   `json:"id" example:"1"`
+	Name  string `json:"name" example:"color"`
+	Value string `json:"value" example:"Silver"`
+}
+
+// SwaggerCategoryAttribute represents a spec attribute definition for Swagger documentation
+// @Description Category attribute model for Swagger documentation
+type SwaggerCategoryAttribute struct {
+	SwaggerBase
+	CategoryID    uint     `json:"category_id" example:"1"`
+	Code          string   `json:"code" example:"ram_gb"`
+	Name          string   `json:"name" example:"RAM"`
+	Type          string   `json:"type" example:"number"` // string, number, boolean, enum
+	Unit          string   `json:"unit,omitempty" example:"GB"`
+	AllowedValues []string `json:"allowed_values,omitempty"`
+	IsRequired    bool     `json:"is_required" example:"true"`
+	IsFilterable  bool     `json:"is_filterable" example:"true"`
+	SortOrder     int      `json:"sort_order" example:"0"`
+}
+
+// SwaggerProductAttributeValue represents a product spec value for Swagger documentation
+// @Description Product attribute value model for Swagger documentation
+type SwaggerProductAttributeValue struct {
+	AttributeID uint                     `json:"attribute_id" example:"1"`
+	ValueText   string                   `json:"value_text,omitempty" example:"Intel Core i7-1360P"`
+	ValueNumber *float64                 `json:"value_number,omitempty" example:"16"`
+	ValueBool   *bool                    `json:"value_bool,omitempty" example:"true"`
+	Attribute   SwaggerCategoryAttribute `json:"attribute"`
 }
 
 // SwaggerCategory represents category model for Swagger documentation
 // @Description Category model for Swagger documentation
 type SwaggerCategory struct {
 	SwaggerBase
-	Name string `json:"name" example:"Laptop"`
-	Slug string `json:"slug" example:"laptop"`
+	Name     string `json:"name" example:"Gaming Laptops"`
+	Slug     string `json:"slug" example:"gaming-laptops"`
+	ParentID *uint  `json:"parent_id,omitempty" example:"1"`
+	Path     string `json:"path" example:"/1/4/"`
+	Depth    int    `json:"depth" example:"1"`
+	IsActive bool   `json:"is_active" example:"true"`
+}
+
+// SwaggerCategoryNode represents a category with its subcategories for Swagger documentation
+// @Description Category tree node for Swagger documentation
+type SwaggerCategoryNode struct {
+	SwaggerCategory
+	Children []SwaggerCategoryNode `json:"children,omitempty"`
+}
+
+// SwaggerBreadcrumb represents one level of a category trail for Swagger documentation
+// @Description Breadcrumb model for Swagger documentation
+type SwaggerBreadcrumb struct {
+	ID   uint   `json:"id" example:"1"`
+	Name string `json:"name" example:"Laptops"`
+	Slug string `json:"slug" example:"laptops"`
 }
 
 // SwaggerBrand represents brand model for Swagger documentation
@@ -51,6 +179,9 @@ type SwaggerBrand struct {
 	Description string `json:"description" example:"Apple Inc."`
 	IsActive    bool   `json:"is_active" example:"true"`
 	Slug        string `json:"slug" example:"apple"`
+	// WarrantyMonths is the warranty period of the brand's products that set none
+	WarrantyMonths *int   `json:"warranty_months,omitempty" example:"12"`
+	LogoURL        string `json:"logo_url,omitempty" example:"https://cdn.example.com/brands/1/logo.png"`
 }
 
 // SwaggerOrder represents order model for Swagger documentation
@@ -61,6 +192,7 @@ type SwaggerOrder struct {
 	TotalAmount       float64 `json:"total_amount" example:"1999.99"`
 	Status            string  `json:"status" example:"pending"` // pending, confirmed, processing, shipped, delivered, cancelled
 	ShippingAddressID *uint   `json:"shipping_address_id,omitempty" example:"1"`
+	TradeInCredit     float64 `json:"trade_in_credit" example:"250"`
 }
 
 // SwaggerAddress represents address model for Swagger documentation
@@ -81,19 +213,21 @@ type SwaggerAddress struct {
 // @Description Cart model for Swagger documentation
 type SwaggerCart struct {
 	SwaggerBase
-	UserID    *uint             `json:"user_id,omitempty" example:"1"`
-	SessionID string            `json:"session_id" example:"session123"`
-	Status    string            `json:"status" example:"active"`
-	Items     []SwaggerCartItem `json:"items,omitempty"`
+	UserID    *uint                         `json:"user_id,omitempty" example:"1"`
+	SessionID string                        `json:"session_id" example:"session123"`
+	Status    string                        `json:"status" example:"active"`
+	Items     []SwaggerCartItem             `json:"items,omitempty"`
+	Warnings  []SwaggerCompatibilityWarning `json:"warnings,omitempty"`
 }
 
 // SwaggerCartItem represents cart item model for Swagger documentation
 // @Description Cart item model for Swagger documentation
 type SwaggerCartItem struct {
 	SwaggerBase
-	CartID    uint `json:"cart_id" example:"1"`
-	ProductID uint `json:"product_id" example:"1"`
-	Quantity  int  `json:"quantity" example:"2"`
+	CartID    uint  `json:"cart_id" example:"1"`
+	ProductID uint  `json:"product_id" example:"1"`
+	VariantID *uint `json:"variant_id,omitempty" example:"1"`
+	Quantity  int   `json:"quantity" example:"2"`
 }
 
 // SwaggerPayment represents payment model for Swagger documentation
@@ -105,3 +239,307 @@ type SwaggerPayment struct {
 	Method  string  `json:"method" example:"cod"`     // momo, zalopay, vnpay, cod
 	Status  string  `json:"status" example:"pending"` // pending, completed, failed, refunded, cancelled
 }
+
+// SwaggerReview represents a product review for Swagger documentation
+// @Description Product review model for Swagger documentation
+type SwaggerReview struct {
+	SwaggerBase
+	ProductID      uint                 `json:"product_id" example:"1"`
+	UserID         uint                 `json:"user_id" example:"7"`
+	OrderItemID    uint                 `json:"order_item_id" example:"42"`
+	Rating         int                  `json:"rating" example:"5"`
+	Title          string               `json:"title" example:"Great laptop"`
+	Content        string               `json:"content" example:"Fast, quiet and the battery lasts all day"`
+	Status         string               `json:"status" example:"approved"`
+	ModerationNote string               `json:"moderation_note,omitempty" example:""`
+	ModeratedAt    *time.Time           `json:"moderated_at,omitempty" example:"2024-01-02T00:00:00Z"`
+	HelpfulCount   int                  `json:"helpful_count" example:"3"`
+	ReviewerName   string               `json:"reviewer_name" example:"Nguyen Van A"`
+	Photos         []SwaggerReviewPhoto `json:"photos,omitempty"`
+}
+
+// SwaggerReviewPhoto represents a review photo for Swagger documentation
+// @Description Review photo model for Swagger documentation
+type SwaggerReviewPhoto struct {
+	ID          uint   `json:"id" example:"1"`
+	ImageURL    string `json:"image_url" example:"/uploads/reviews/1/3f1c2a9e.jpg"`
+	ContentType string `json:"content_type" example:"image/jpeg"`
+	SortOrder   int    `json:"sort_order" example:"0"`
+}
+
+// SwaggerProductQuestion represents a product question for Swagger documentation
+// @Description Product question model for Swagger documentation
+type SwaggerProductQuestion struct {
+	SwaggerBase
+	ProductID      uint                   `json:"product_id" example:"1"`
+	UserID         uint                   `json:"user_id" example:"7"`
+	Content        string                 `json:"content" example:"Does this laptop support Thunderbolt 4?"`
+	Status         string                 `json:"status" example:"approved"`
+	ModerationNote string                 `json:"moderation_note,omitempty" example:""`
+	AskerName      string                 `json:"asker_name" example:"Nguyen Van A"`
+	Answers        []SwaggerProductAnswer `json:"answers"`
+}
+
+// SwaggerProductAnswer represents an answer to a product question for Swagger documentation
+// @Description Product answer model for Swagger documentation
+type SwaggerProductAnswer struct {
+	SwaggerBase
+	QuestionID     uint   `json:"question_id" example:"1"`
+	UserID         uint   `json:"user_id" example:"2"`
+	Content        string `json:"content" example:"Yes, both USB-C ports support Thunderbolt 4"`
+	AuthorType     string `json:"author_type" example:"staff"` // staff, verified_buyer or customer
+	Status         string `json:"status" example:"approved"`
+	ModerationNote string `json:"moderation_note,omitempty" example:""`
+	AuthorName     string `json:"author_name" example:"TechStore Support"`
+}
+
+// SwaggerNotification represents a user notification for Swagger documentation
+// @Description Notification model for Swagger documentation
+type SwaggerNotification struct {
+	ID         uint       `json:"id" example:"1"`
+	CreatedAt  time.Time  `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UserID     uint       `json:"user_id" example:"7"`
+	Type       string     `json:"type" example:"question_answered"`
+	Title      string     `json:"title" example:"Your question was answered"`
+	Message    string     `json:"message" example:"Your question about MacBook Pro 14 has a new answer"`
+	EntityType string     `json:"entity_type,omitempty" example:"product"`
+	EntityID   *uint      `json:"entity_id,omitempty" example:"1"`
+	ReadAt     *time.Time `json:"read_at,omitempty" example:"2023-01-02T00:00:00Z"`
+}
+
+// SwaggerTrendingProduct represents a trending product for Swagger documentation
+// @Description Trending product model for Swagger documentation
+type SwaggerTrendingProduct struct {
+	SwaggerProduct
+	Views int64 `json:"views" example:"1250"`
+}
+
+// SwaggerImportRowError represents a rejected import row for Swagger documentation
+// @Description Import row error model for Swagger documentation
+type SwaggerImportRowError struct {
+	Row    int      `json:"row" example:"4"`
+	Slug   string   `json:"slug,omitempty" example:"macbook-pro-14"`
+	Errors []string `json:"errors" example:"category 'laptop' not found"`
+}
+
+// SwaggerImportJob represents a product import job for Swagger documentation
+// @Description Import job model for Swagger documentation
+type SwaggerImportJob struct {
+	ID            uint                    `json:"id" example:"1"`
+	CreatedAt     time.Time               `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt     time.Time               `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+	FileName      string                  `json:"file_name" example:"products.csv"`
+	Format        string                  `json:"format" example:"csv"`
+	Status        string                  `json:"status" example:"completed"`
+	TotalRows     int                     `json:"total_rows" example:"120"`
+	ProcessedRows int                     `json:"processed_rows" example:"120"`
+	CreatedCount  int                     `json:"created_count" example:"80"`
+	UpdatedCount  int                     `json:"updated_count" example:"38"`
+	FailedCount   int                     `json:"failed_count" example:"2"`
+	RowErrors     []SwaggerImportRowError `json:"row_errors"`
+	Message       string                  `json:"message,omitempty" example:""`
+	CreatedBy     uint                    `json:"created_by" example:"1"`
+	StartedAt     *time.Time              `json:"started_at,omitempty" example:"2023-01-01T00:00:01Z"`
+	FinishedAt    *time.Time              `json:"finished_at,omitempty" example:"2023-01-01T00:00:09Z"`
+}
+
+// SwaggerPriceChange represents a price history entry for Swagger documentation
+// @Description Price change model for Swagger documentation
+type SwaggerPriceChange struct {
+	ID               uint      `json:"id" example:"1"`
+	ProductID        uint      `json:"product_id" example:"1"`
+	OldPrice         *float64  `json:"old_price" example:"1999.99"`
+	NewPrice         float64   `json:"new_price" example:"1799.99"`
+	Source           string    `json:"source" example:"schedule"`
+	ScheduledPriceID *uint     `json:"scheduled_price_id,omitempty" example:"3"`
+	ChangedAt        time.Time `json:"changed_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// SwaggerProductPriceHistory represents the price history of a product for Swagger documentation
+// @Description Product price history model for Swagger documentation
+type SwaggerProductPriceHistory struct {
+	ProductID        uint                 `json:"product_id" example:"1"`
+	CurrentPrice     float64              `json:"current_price" example:"1799.99"`
+	LowestPrice      float64              `json:"lowest_price" example:"1899.99"`
+	LowestPriceSince time.Time            `json:"lowest_price_since" example:"2022-12-02T00:00:00Z"`
+	History          []SwaggerPriceChange `json:"history"`
+}
+
+// SwaggerScheduledPrice represents a scheduled price change for Swagger documentation
+// @Description Scheduled price model for Swagger documentation
+type SwaggerScheduledPrice struct {
+	SwaggerBase
+	ProductID     uint       `json:"product_id" example:"1"`
+	Price         float64    `json:"price" example:"1799.99"`
+	StartsAt      time.Time  `json:"starts_at" example:"2023-01-01T00:00:00Z"`
+	EndsAt        *time.Time `json:"ends_at,omitempty" example:"2023-01-08T00:00:00Z"`
+	Status        string     `json:"status" example:"pending"`
+	PreviousPrice *float64   `json:"previous_price,omitempty" example:"1999.99"`
+	Note          string     `json:"note,omitempty" example:"New year sale"`
+	CreatedBy     uint       `json:"created_by" example:"1"`
+}
+
+// SwaggerFlashSale represents a flash sale for Swagger documentation
+// @Description Flash sale model for Swagger documentation
+type SwaggerFlashSale struct {
+	SwaggerBase
+	ProductID     uint                   `json:"product_id" example:"1"`
+	VariantID     *uint                  `json:"variant_id,omitempty" example:"2"`
+	SalePrice     float64                `json:"sale_price" example:"799.99"`
+	StartsAt      time.Time              `json:"starts_at" example:"2023-01-01T12:00:00Z"`
+	EndsAt        time.Time              `json:"ends_at" example:"2023-01-01T13:00:00Z"`
+	TotalQuantity int                    `json:"total_quantity" example:"100"`
+	SoldQuantity  int                    `json:"sold_quantity" example:"37"`
+	PerUserLimit  int                    `json:"per_user_limit" example:"1"`
+	IsActive      bool                   `json:"is_active" example:"true"`
+	CreatedBy     uint                   `json:"created_by" example:"1"`
+	Product       *SwaggerProduct        `json:"product,omitempty"`
+	Variant       *SwaggerProductVariant `json:"variant,omitempty"`
+	Remaining     int                    `json:"remaining" example:"63"`
+}
+
+// SwaggerSoldUnit represents a sold unit with its warranty for Swagger documentation
+// @Description Sold unit model for Swagger documentation
+type SwaggerSoldUnit struct {
+	SwaggerBase
+	OrderItemID      uint            `json:"order_item_id" example:"12"`
+	ProductID        uint            `json:"product_id" example:"1"`
+	VariantID        *uint           `json:"variant_id,omitempty" example:"3"`
+	SerialNumber     string          `json:"serial_number" example:"C02XK1ZJJGH5"`
+	WarrantyMonths   int             `json:"warranty_months" example:"24"`
+	WarrantyStartsAt time.Time       `json:"warranty_starts_at" example:"2023-01-01T00:00:00Z"`
+	WarrantyEndsAt   time.Time       `json:"warranty_ends_at" example:"2025-01-01T00:00:00Z"`
+	WarrantyStatus   string          `json:"warranty_status" example:"active"`
+	RecordedBy       uint            `json:"recorded_by" example:"1"`
+	Product          *SwaggerProduct `json:"product,omitempty"`
+}
+
+// SwaggerWarrantyClaim represents a warranty claim for Swagger documentation
+// @Description Warranty claim model for Swagger documentation
+type SwaggerWarrantyClaim struct {
+	SwaggerBase
+	UserID        uint                             `json:"user_id" example:"7"`
+	OrderItemID   uint                             `json:"order_item_id" example:"12"`
+	SoldUnitID    *uint                            `json:"sold_unit_id,omitempty" example:"4"`
+	Description   string                           `json:"description" example:"The screen flickers after a few minutes"`
+	Status        string                           `json:"status" example:"diagnosing"`
+	UnderWarranty bool                             `json:"under_warranty" example:"true"`
+	SoldUnit      *SwaggerSoldUnit                 `json:"sold_unit,omitempty"`
+	Notes         []SwaggerWarrantyClaimNote       `json:"notes,omitempty"`
+	Attachments   []SwaggerWarrantyClaimAttachment `json:"attachments,omitempty"`
+}
+
+// SwaggerWarrantyClaimNote represents a staff note on a warranty claim for Swagger documentation
+// @Description Warranty claim note model for Swagger documentation
+type SwaggerWarrantyClaimNote struct {
+	ID        uint      `json:"id" example:"1"`
+	CreatedAt time.Time `json:"created_at" example:"2024-01-02T00:00:00Z"`
+	ClaimID   uint      `json:"claim_id" example:"3"`
+	AuthorID  uint      `json:"author_id" example:"1"`
+	Content   string    `json:"content" example:"Display cable replaced"`
+	Status    string    `json:"status,omitempty" example:"repaired"`
+	Internal  bool      `json:"internal" example:"false"`
+}
+
+// SwaggerWarrantyClaimAttachment represents a warranty claim attachment for Swagger documentation
+// @Description Warranty claim attachment model for Swagger documentation
+type SwaggerWarrantyClaimAttachment struct {
+	ID          uint      `json:"id" example:"1"`
+	CreatedAt   time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	ClaimID     uint      `json:"claim_id" example:"3"`
+	UploadedBy  uint      `json:"uploaded_by" example:"7"`
+	FileName    string    `json:"file_name" example:"screen.jpg"`
+	FileURL     string    `json:"file_url" example:"/uploads/warranty-claims/3/3f1c2a9e.jpg"`
+	ContentType string    `json:"content_type" example:"image/jpeg"`
+}
+
+// SwaggerCompatibilityWarning represents a cart compatibility warning for Swagger documentation
+// @Description Compatibility warning model for Swagger documentation
+type SwaggerCompatibilityWarning struct {
+	AccessoryID   uint   `json:"accessory_id" example:"31"`
+	AccessoryName string `json:"accessory_name" example:"Kingston 16GB DDR4 SO-DIMM"`
+	DeviceID      uint   `json:"device_id" example:"1"`
+	DeviceName    string `json:"device_name" example:"MacBook Pro 14"`
+	Reason        string `json:"reason" example:"Memory type DDR4 of Kingston 16GB DDR4 SO-DIMM does not match Memory type DDR5 of MacBook Pro 14"`
+}
+
+// SwaggerCompatibilityRule represents a compatibility rule for Swagger documentation
+// @Description Compatibility rule model for Swagger documentation
+type SwaggerCompatibilityRule struct {
+	SwaggerBase
+	AccessoryAttributeID uint                      `json:"accessory_attribute_id" example:"12"`
+	DeviceAttributeID    uint                      `json:"device_attribute_id" example:"4"`
+	Description          string                    `json:"description,omitempty" example:"RAM modules fit laptops with the same memory type"`
+	AccessoryAttribute   *SwaggerCategoryAttribute `json:"accessory_attribute,omitempty"`
+	DeviceAttribute      *SwaggerCategoryAttribute `json:"device_attribute,omitempty"`
+}
+
+// SwaggerTradeInPriceRule represents a trade-in pricing rule for Swagger documentation
+// @Description Trade-in price rule model for Swagger documentation
+type SwaggerTradeInPriceRule struct {
+	SwaggerBase
+	BrandID               uint          `json:"brand_id" example:"1"`
+	Model                 string        `json:"model" example:"iPhone 13"`
+	BaseValue             float64       `json:"base_value" example:"400"`
+	GoodPercent           int           `json:"good_percent" example:"85"`
+	FairPercent           int           `json:"fair_percent" example:"65"`
+	PoorPercent           int           `json:"poor_percent" example:"40"`
+	ScreenDamageDeduction float64       `json:"screen_damage_deduction" example:"80"`
+	BodyDamageDeduction   float64       `json:"body_damage_deduction" example:"30"`
+	NoPowerValue          float64       `json:"no_power_value" example:"40"`
+	IsActive              bool          `json:"is_active" example:"true"`
+	Brand                 *SwaggerBrand `json:"brand,omitempty"`
+}
+
+// SwaggerTradeIn represents a trade-in for Swagger documentation
+// @Description Trade-in model for Swagger documentation
+type SwaggerTradeIn struct {
+	SwaggerBase
+	UserID         uint          `json:"user_id" example:"7"`
+	BrandID        uint          `json:"brand_id" example:"1"`
+	Model          string        `json:"model" example:"iPhone 13"`
+	Condition      string        `json:"condition" example:"good"`
+	PowersOn       bool          `json:"powers_on" example:"true"`
+	ScreenDamaged  bool          `json:"screen_damaged" example:"false"`
+	BodyDamaged    bool          `json:"body_damaged" example:"true"`
+	Description    string        `json:"description,omitempty" example:"Small dent on the corner"`
+	PriceRuleID    uint          `json:"price_rule_id" example:"3"`
+	QuotedValue    float64       `json:"quoted_value" example:"310"`
+	QuoteExpiresAt time.Time     `json:"quote_expires_at" example:"2024-01-15T00:00:00Z"`
+	Status         string        `json:"status" example:"accepted"`
+	FinalValue     *float64      `json:"final_value,omitempty" example:"290"`
+	InspectionNote string        `json:"inspection_note,omitempty" example:"Battery health 78%"`
+	InspectedBy    *uint         `json:"inspected_by,omitempty" example:"1"`
+	InspectedAt    *time.Time    `json:"inspected_at,omitempty" example:"2024-01-10T00:00:00Z"`
+	OrderID        *uint         `json:"order_id,omitempty" example:"42"`
+	Brand          *SwaggerBrand `json:"brand,omitempty"`
+}
+
+// SwaggerStockReceipt represents the outcome of receiving stock for Swagger documentation
+// @Description Stock receipt model for Swagger documentation
+type SwaggerStockReceipt struct {
+	ProductID      uint   `json:"product_id" example:"1"`
+	VariantID      *uint  `json:"variant_id,omitempty" example:"3"`
+	Received       int    `json:"received" example:"20"`
+	Allocated      int    `json:"allocated" example:"12"`
+	Stock          int    `json:"stock" example:"8"`
+	ReleasedOrders []uint `json:"released_orders" example:"41,42"`
+}
+
+// SwaggerBrandPage represents the landing data of a brand for Swagger documentation
+// @Description Brand page model for Swagger documentation
+type SwaggerBrandPage struct {
+	Brand        SwaggerBrand                `json:"brand"`
+	ProductCount int64                       `json:"product_count" example:"42"`
+	Categories   []SwaggerBrandCategoryCount `json:"categories"`
+	TopProducts  []SwaggerProduct            `json:"top_products"`
+}
+
+// SwaggerBrandCategoryCount represents the number of products of a brand in a category for Swagger documentation
+// @Description Brand category count model for Swagger documentation
+type SwaggerBrandCategoryCount struct {
+	ID           uint   `json:"id" example:"4"`
+	Name         string `json:"name" example:"Laptops"`
+	Slug         string `json:"slug" example:"laptops"`
+	ProductCount int64  `json:"product_count" example:"17"`
+}
@@ -0,0 +1,113 @@
+package models
+
+import "time"
+
+// Device conditions a customer can declare for a trade-in
+const (
+	TradeInConditionExcellent = "excellent"
+	TradeInConditionGood      = "good"
+	TradeInConditionFair      = "fair"
+	TradeInConditionPoor      = "poor"
+)
+
+// Trade-in states: quoted, then accepted or declined by the customer
+const (
+	TradeInStatusQuoted   = "quoted"
+	TradeInStatusAccepted = "accepted"
+	TradeInStatusDeclined = "declined"
+)
+
+// TradeInPriceRule prices used devices of a brand and model. The base value is paid for a device in
+// excellent condition; the other conditions get a percentage of it, damage is deducted, and a
+// device that does not power on is worth a fixed value.
+type TradeInPriceRule struct {
+	Base
+	BrandID               uint    `gorm:"column:brand_id;not null;uniqueIndex:idx_trade_in_price_rules_model,where:deleted_at IS NULL" json:"brand_id"`
+	Model                 string  `gorm:"column:model;type:varchar(150);not null;uniqueIndex:idx_trade_in_price_rules_model,where:deleted_at IS NULL" json:"model"`
+	BaseValue             float64 `gorm:"column:base_value;type:numeric(12,2);not null" json:"base_value"`
+	GoodPercent           int     `gorm:"column:good_percent;not null;default:85" json:"good_percent"`
+	FairPercent           int     `gorm:"column:fair_percent;not null;default:65" json:"fair_percent"`
+	PoorPercent           int     `gorm:"column:poor_percent;not null;default:40" json:"poor_percent"`
+	ScreenDamageDeduction float64 `gorm:"column:screen_damage_deduction;type:numeric(12,2);not null;default:0" json:"screen_damage_deduction"`
+	BodyDamageDeduction   float64 `gorm:"column:body_damage_deduction;type:numeric(12,2);not null;default:0" json:"body_damage_deduction"`
+	NoPowerValue          float64 `gorm:"column:no_power_value;type:numeric(12,2);not null;default:0" json:"no_power_value"`
+	IsActive              bool    `gorm:"column:is_active;not null;default:true" json:"is_active"`
+
+	// Relations
+	Brand *Brand `json:"brand,omitempty" gorm:"foreignKey:BrandID"`
+}
+
+// TradeIn is a customer's used device with its quote. Once accepted, its value is credited to a new
+// order; the final value set on inspection replaces the quote, and adjusts that order's credit while
+// it is pending and unpaid.
+type TradeIn struct {
+	Base
+	UserID         uint       `gorm:"column:user_id;not null;index" json:"user_id"`
+	BrandID        uint       `gorm:"column:brand_id;not null" json:"brand_id"`
+	Model          string     `gorm:"column:model;type:varchar(150);not null" json:"model"`
+	Condition      string     `gorm:"column:condition;type:varchar(20);not null;check:condition IN ('excellent', 'good', 'fair', 'poor')" json:"condition"`
+	PowersOn       bool       `gorm:"column:powers_on;not null" json:"powers_on"`
+	ScreenDamaged  bool       `gorm:"column:screen_damaged;not null" json:"screen_damaged"`
+	BodyDamaged    bool       `gorm:"column:body_damaged;not null" json:"body_damaged"`
+	Description    string     `gorm:"column:description;type:text" json:"description,omitempty"`
+	PriceRuleID    uint       `gorm:"column:price_rule_id;not null" json:"price_rule_id"`
+	QuotedValue    float64    `gorm:"column:quoted_value;type:numeric(12,2);not null" json:"quoted_value"`
+	QuoteExpiresAt time.Time  `gorm:"column:quote_expires_at;not null" json:"quote_expires_at"`
+	Status         string     `gorm:"column:status;type:varchar(20);not null;default:quoted;index;check:status IN ('quoted', 'accepted', 'declined')" json:"status"`
+	FinalValue     *float64   `gorm:"column:final_value;type:numeric(12,2)" json:"final_value,omitempty"`
+	InspectionNote string     `gorm:"column:inspection_note;type:varchar(1000)" json:"inspection_note,omitempty"`
+	InspectedBy    *uint      `gorm:"column:inspected_by" json:"inspected_by,omitempty"`
+	InspectedAt    *time.Time `gorm:"column:inspected_at" json:"inspected_at,omitempty"`
+	OrderID        *uint      `gorm:"column:order_id;index" json:"order_id,omitempty"` // the order the value is credited to
+
+	// Relations
+	Brand *Brand `json:"brand,omitempty" gorm:"foreignKey:BrandID"`
+}
+
+// Value is what the trade-in is worth: the final value once inspected, else the quote
+func (t TradeIn) Value() float64 {
+	if t.FinalValue != nil {
+		return *t.FinalValue
+	}
+	return t.QuotedValue
+}
+
+type TradeInPriceRuleCreateRequest struct {
+	BrandID               uint    `json:"brand_id" binding:"required,gt=0"`
+	Model                 string  `json:"model" binding:"required,min=1,max=150"`
+	BaseValue             float64 `json:"base_value" binding:"required,gt=0"`
+	GoodPercent           *int    `json:"good_percent" binding:"omitempty,gte=0,lte=100"`
+	FairPercent           *int    `json:"fair_percent" binding:"omitempty,gte=0,lte=100"`
+	PoorPercent           *int    `json:"poor_percent" binding:"omitempty,gte=0,lte=100"`
+	ScreenDamageDeduction float64 `json:"screen_damage_deduction" binding:"omitempty,gte=0"`
+	BodyDamageDeduction   float64 `json:"body_damage_deduction" binding:"omitempty,gte=0"`
+	NoPowerValue          float64 `json:"no_power_value" binding:"omitempty,gte=0"`
+	IsActive              *bool   `json:"is_active" binding:"omitempty"`
+}
+
+type TradeInPriceRuleUpdateRequest struct {
+	BaseValue             *float64 `json:"base_value" binding:"omitempty,gt=0"`
+	GoodPercent           *int     `json:"good_percent" binding:"omitempty,gte=0,lte=100"`
+	FairPercent           *int     `json:"fair_percent" binding:"omitempty,gte=0,lte=100"`
+	PoorPercent           *int     `json:"poor_percent" binding:"omitempty,gte=0,lte=100"`
+	ScreenDamageDeduction *float64 `json:"screen_damage_deduction" binding:"omitempty,gte=0"`
+	BodyDamageDeduction   *float64 `json:"body_damage_deduction" binding:"omitempty,gte=0"`
+	NoPowerValue          *float64 `json:"no_power_value" binding:"omitempty,gte=0"`
+	IsActive              *bool    `json:"is_active" binding:"omitempty"`
+}
+
+// TradeInQuoteRequest is the condition questionnaire of a device
+type TradeInQuoteRequest struct {
+	BrandID       uint   `json:"brand_id" binding:"required,gt=0"`
+	Model         string `json:"model" binding:"required,min=1,max=150"`
+	Condition     string `json:"condition" binding:"required,oneof=excellent good fair poor"`
+	PowersOn      *bool  `json:"powers_on" binding:"required"`
+	ScreenDamaged *bool  `json:"screen_damaged" binding:"required"`
+	BodyDamaged   *bool  `json:"body_damaged" binding:"required"`
+	Description   string `json:"description" binding:"omitempty,max=2000"`
+}
+
+type TradeInInspectionRequest struct {
+	FinalValue *float64 `json:"final_value" binding:"required,gte=0"`
+	Note       string   `json:"note" binding:"omitempty,max=1000"`
+}
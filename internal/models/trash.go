@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// TrashEntry is a soft-deleted product, brand or category, kept until PurgeAt
+type TrashEntry struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	DeletedAt time.Time `json:"deleted_at"`
+	// PurgeAt is when the entry is deleted for good, unless it is restored or still referenced
+	PurgeAt time.Time `json:"purge_at"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Warranty states of a sold unit, computed when it is read
+const (
+	WarrantyStatusActive  = "active"
+	WarrantyStatusExpired = "expired"
+	WarrantyStatusNone    = "none" // sold without warranty
+)
+
+// SoldUnit is one unit of an order line, recorded by its serial number when the order is fulfilled.
+// Its warranty starts at fulfillment, for the period of the product or else of its brand.
+type SoldUnit struct {
+	Base
+	OrderItemID      uint      `gorm:"column:order_item_id;not null;index" json:"order_item_id"`
+	ProductID        uint      `gorm:"column:product_id;not null;index" json:"product_id"`
+	VariantID        *uint     `gorm:"column:variant_id" json:"variant_id,omitempty"`
+	SerialNumber     string    `gorm:"column:serial_number;type:varchar(100);not null;uniqueIndex:idx_sold_units_serial_number,where:deleted_at IS NULL" json:"serial_number"`
+	WarrantyMonths   int       `gorm:"column:warranty_months;not null;default:0" json:"warranty_months"`
+	WarrantyStartsAt time.Time `gorm:"column:warranty_starts_at;not null" json:"warranty_starts_at"`
+	WarrantyEndsAt   time.Time `gorm:"column:warranty_ends_at;not null;index" json:"warranty_ends_at"`
+	RecordedBy       uint      `gorm:"column:recorded_by;not null" json:"recorded_by"`
+
+	// Relations
+	OrderItem *OrderItem `json:"order_item,omitempty" gorm:"foreignKey:OrderItemID"`
+	Product   *Product   `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+
+	// WarrantyStatus is filled in by the service
+	WarrantyStatus string `json:"warranty_status" gorm:"-"`
+}
+
+type SerialNumbersRequest struct {
+	SerialNumbers []string `json:"serial_numbers" binding:"required,min=1,max=100,dive,required,max=100"`
+}
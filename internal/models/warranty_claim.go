@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// States of a warranty claim. A claim is received at the service center, diagnosed, and ends
+// repaired, replaced or rejected.
+const (
+	ClaimStatusSubmitted  = "submitted"
+	ClaimStatusReceived   = "received"
+	ClaimStatusDiagnosing = "diagnosing"
+	ClaimStatusRepaired   = "repaired"
+	ClaimStatusReplaced   = "replaced"
+	ClaimStatusRejected   = "rejected"
+)
+
+// ClaimTransitions lists the states a claim may move to from each state; final states have none
+var ClaimTransitions = map[string][]string{
+	ClaimStatusSubmitted:  {ClaimStatusReceived, ClaimStatusRejected},
+	ClaimStatusReceived:   {ClaimStatusDiagnosing, ClaimStatusRejected},
+	ClaimStatusDiagnosing: {ClaimStatusRepaired, ClaimStatusReplaced, ClaimStatusRejected},
+}
+
+// MaxClaimAttachments is the number of files a claim may carry
+const MaxClaimAttachments = 10
+
+// WarrantyClaim is a customer's warranty or repair request for a purchased order line, or for
+// one unit of it when the serial number is known
+type WarrantyClaim struct {
+	Base
+	UserID        uint   `gorm:"column:user_id;not null;index" json:"user_id"`
+	OrderItemID   uint   `gorm:"column:order_item_id;not null;index" json:"order_item_id"`
+	SoldUnitID    *uint  `gorm:"column:sold_unit_id;index" json:"sold_unit_id,omitempty"`
+	Description   string `gorm:"column:description;type:text;not null" json:"description"`
+	Status        string `gorm:"column:status;type:varchar(20);not null;default:submitted;index;check:status IN ('submitted', 'received', 'diagnosing', 'repaired', 'replaced', 'rejected')" json:"status"`
+	UnderWarranty bool   `gorm:"column:under_warranty;not null;default:false" json:"under_warranty"` // whether the unit's warranty was active when the claim was submitted
+
+	// Relations
+	OrderItem   *OrderItem                `json:"order_item,omitempty" gorm:"foreignKey:OrderItemID"`
+	SoldUnit    *SoldUnit                 `json:"sold_unit,omitempty" gorm:"foreignKey:SoldUnitID"`
+	Notes       []WarrantyClaimNote       `json:"notes,omitempty" gorm:"foreignKey:ClaimID;constraint:OnDelete:CASCADE"`
+	Attachments []WarrantyClaimAttachment `json:"attachments,omitempty" gorm:"foreignKey:ClaimID;constraint:OnDelete:CASCADE"`
+}
+
+// WarrantyClaimNote is a staff note on a claim. Notes written with a status change record it, so
+// the notes are also the history of the claim. Internal notes are only shown to staff.
+type WarrantyClaimNote struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ClaimID   uint      `gorm:"column:claim_id;not null;index" json:"claim_id"`
+	AuthorID  uint      `gorm:"column:author_id;not null" json:"author_id"`
+	Content   string    `gorm:"column:content;type:text" json:"content"`
+	Status    string    `gorm:"column:status;type:varchar(20)" json:"status,omitempty"` // the state the claim moved to with this note
+	Internal  bool      `gorm:"column:internal;not null;default:false" json:"internal"`
+}
+
+// WarrantyClaimAttachment is a photo or document attached to a claim by the customer or staff
+type WarrantyClaimAttachment struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	ClaimID     uint      `gorm:"column:claim_id;not null;index" json:"claim_id"`
+	UploadedBy  uint      `gorm:"column:uploaded_by;not null" json:"uploaded_by"`
+	FileName    string    `gorm:"column:file_name;type:varchar(255)" json:"file_name"`
+	FileURL     string    `gorm:"column:file_url;not null" json:"file_url"`
+	StorageKey  string    `gorm:"column:storage_key" json:"-"`
+	ContentType string    `gorm:"column:content_type;type:varchar(50)" json:"content_type"`
+}
+
+// WarrantyClaimCreateRequest is bound from multipart form fields; files are sent as "attachments"
+type WarrantyClaimCreateRequest struct {
+	OrderItemID  uint   `form:"order_item_id" binding:"required,gt=0"`
+	SerialNumber string `form:"serial_number" binding:"omitempty,max=100"`
+	Description  string `form:"description" binding:"required,min=10,max=5000"`
+}
+
+type WarrantyClaimStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=received diagnosing repaired replaced rejected"`
+	Note   string `json:"note" binding:"omitempty,max=5000"`
+}
+
+type WarrantyClaimNoteRequest struct {
+	Content  string `json:"content" binding:"required,max=5000"`
+	Internal bool   `json:"internal"`
+}
@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"api_techstore/internal/config"
 	"api_techstore/internal/container"
 	"api_techstore/internal/middlewares"
 	v1 "api_techstore/internal/routes/v1"
@@ -16,12 +17,25 @@ func SetupRouter(r *gin.Engine, ctn *container.Container) {
 
 	r.Use(middlewares.RequestLogger())
 
+	// Serve uploaded files when they are stored on the local filesystem
+	if storageCfg := config.GetStorageConfig(); storageCfg.Driver == "local" {
+		r.Static(storageCfg.LocalURLPrefix, storageCfg.LocalDir)
+	}
+
 	routeV1 := r.Group("/api/v1")
 	{
 		// Auth routes (không cần JWT)
 		v1.SetupAuthRoute(routeV1, ctn)
 
-		// v1.SetupSearchRoute(routeV1, ctn)
+		v1.SetupSearchRoute(routeV1, ctn)
+
+		// Guest routes, which know the user when a JWT is sent
+		optional := routeV1.Group("")
+		optional.Use(middlewares.OptionalJWTAuthMiddleware(ctn))
+		{
+			v1.SetupCatalogRoutes(optional, ctn)
+			v1.SetupRecentlyViewedRoutes(optional, ctn)
+		}
 
 		// Protected routes (cần JWT)
 		protected := routeV1.Group("")
@@ -34,6 +48,15 @@ func SetupRouter(r *gin.Engine, ctn *container.Container) {
 			v1.SetupOrderRoute(protected, ctn)
 			v1.SetupAddressRoutes(protected, ctn)
 			v1.SetupCartRoutes(protected, ctn)
+			v1.SetupReviewRoute(protected, ctn)
+			v1.SetupQuestionRoute(protected, ctn)
+			v1.SetupNotificationRoutes(protected, ctn)
+			v1.SetupMeRoutes(protected, ctn)
+			v1.SetupFlashSaleRoutes(protected, ctn)
+			v1.SetupWarrantyRoutes(protected, ctn)
+			v1.SetupWarrantyClaimRoutes(protected, ctn)
+			v1.SetupCompatibilityRuleRoutes(protected, ctn)
+			v1.SetupTradeInRoutes(protected, ctn)
 		}
 
 		// Routes for both protected and public access
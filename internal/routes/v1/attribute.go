@@ -0,0 +1,37 @@
+package v1
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupCategoryAttributeRoutes configures routes for spec attribute definitions, nested under /categories/:id
+func SetupCategoryAttributeRoutes(r *gin.RouterGroup, ctn *container.Container) {
+	attributes := r.Group("/:id/attributes")
+	{
+		attributes.GET("", func(c *gin.Context) {
+			handlers.GetCategoryAttributes(c, ctn)
+		})
+		attributes.POST("",
+			middlewares.RequireRole("admin"),
+			middlewares.ValidateRequest(&models.CategoryAttributeCreateRequest{}),
+			func(c *gin.Context) {
+				handlers.CreateCategoryAttribute(c, ctn)
+			})
+		attributes.PUT("/:attributeId",
+			middlewares.RequireRole("admin"),
+			middlewares.ValidateRequest(&models.CategoryAttributeUpdateRequest{}),
+			func(c *gin.Context) {
+				handlers.UpdateCategoryAttribute(c, ctn)
+			})
+		attributes.DELETE("/:attributeId",
+			middlewares.RequireRole("admin"),
+			func(c *gin.Context) {
+				handlers.DeleteCategoryAttribute(c, ctn)
+			})
+	}
+}
@@ -0,0 +1,26 @@
+package v1
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupProductBackorderRoutes configures the pre-order and stock receipt routes, nested under /products/:id
+func SetupProductBackorderRoutes(r *gin.RouterGroup, ctn *container.Container) {
+	r.PUT("/:id/stock-policy",
+		middlewares.RequireRole("admin"),
+		middlewares.ValidateRequest(&models.ProductStockPolicyRequest{}),
+		func(ctx *gin.Context) {
+			handlers.SetProductStockPolicy(ctx, ctn)
+		})
+	r.POST("/:id/stock-receipts",
+		middlewares.RequireRole("admin"),
+		middlewares.ValidateRequest(&models.StockReceiptRequest{}),
+		func(ctx *gin.Context) {
+			handlers.ReceiveProductStock(ctx, ctn)
+		})
+}
@@ -5,6 +5,7 @@ import (
 	"api_techstore/internal/handlers"
 	"api_techstore/internal/middlewares"
 	"api_techstore/internal/models"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,8 +16,22 @@ func SetupBrandRoute(r *gin.RouterGroup, ctn *container.Container) {
 		brands.GET("", func(c *gin.Context) {
 			handlers.GetAllBrands(c, ctn)
 		})
+		brands.GET("/slug/:slug", func(c *gin.Context) {
+			handlers.GetBrandBySlug(c, ctn)
+		})
+		brands.GET("/trash",
+			middlewares.RequireRole("admin"),
+			func(c *gin.Context) {
+				handlers.GetDeletedBrands(c, ctn)
+			})
+		// /brands/:slug shares its wildcard with /brands/:id, numeric values are ids
 		brands.GET("/:id", func(c *gin.Context) {
-			handlers.GetBrandById(c, ctn)
+			if _, err := strconv.ParseUint(c.Param("id"), 10, 32); err == nil {
+				handlers.GetBrandById(c, ctn)
+				return
+			}
+			c.Params = append(c.Params, gin.Param{Key: "slug", Value: c.Param("id")})
+			handlers.GetBrandPage(c, ctn)
 		})
 		brands.POST("",
 			middlewares.RequireRole("admin"),
@@ -35,8 +50,17 @@ func SetupBrandRoute(r *gin.RouterGroup, ctn *container.Container) {
 			func(c *gin.Context) {
 				handlers.DeleteBrand(c, ctn)
 			})
+		brands.POST("/:id/restore",
+			middlewares.RequireRole("admin"),
+			func(c *gin.Context) {
+				handlers.RestoreBrand(c, ctn)
+			})
+		brands.POST("/:id/logo",
+			middlewares.RequireRole("admin"),
+			func(c *gin.Context) {
+				handlers.UploadBrandLogo(c, ctn)
+			})
 
-		// brands.GET("/:slug", handlers.GetBrandBySlug)
 		// brands.GET("/:id/products", handlers.GetProductsByBrand)
 	}
 }
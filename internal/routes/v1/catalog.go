@@ -0,0 +1,31 @@
+package v1
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupCatalogRoutes configures the public, read-only catalog; writes stay on the protected
+// /products, /categories and /brands routes
+func SetupCatalogRoutes(r *gin.RouterGroup, ctn *container.Container) {
+	catalog := r.Group("/catalog")
+	{
+		catalog.GET("/products", func(c *gin.Context) {
+			handlers.GetCatalogProducts(c, ctn)
+		})
+		catalog.GET("/products/:slug", func(c *gin.Context) {
+			handlers.GetCatalogProduct(c, ctn)
+		})
+		catalog.GET("/categories", func(c *gin.Context) {
+			handlers.GetCatalogCategories(c, ctn)
+		})
+		catalog.GET("/brands", func(c *gin.Context) {
+			handlers.GetCatalogBrands(c, ctn)
+		})
+		catalog.GET("/brands/:slug", func(c *gin.Context) {
+			handlers.GetCatalogBrand(c, ctn)
+		})
+	}
+}
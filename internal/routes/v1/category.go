@@ -15,9 +15,23 @@ func SetupCategoryRoute(r *gin.RouterGroup, ctn *container.Container) {
 		category.GET("", func(c *gin.Context) {
 			handlers.GetAllCategories(c, ctn)
 		})
+		category.GET("/tree", func(c *gin.Context) {
+			handlers.GetCategoryTree(c, ctn)
+		})
+		category.GET("/slug/:slug", func(c *gin.Context) {
+			handlers.GetCategoryBySlug(c, ctn)
+		})
+		category.GET("/trash",
+			middlewares.RequireRole("admin"),
+			func(c *gin.Context) {
+				handlers.GetDeletedCategories(c, ctn)
+			})
 		category.GET("/:id", func(c *gin.Context) {
 			handlers.GetCategoryById(c, ctn)
 		})
+		category.GET("/:id/products", func(c *gin.Context) {
+			handlers.GetCategoryProducts(c, ctn)
+		})
 		category.POST("",
 			middlewares.RequireRole("admin"),
 			middlewares.ValidateRequest(&models.CategoryCreateRequest{}),
@@ -35,7 +49,12 @@ func SetupCategoryRoute(r *gin.RouterGroup, ctn *container.Container) {
 			func(c *gin.Context) {
 				handlers.DeleteCategory(c, ctn)
 			})
+		category.POST("/:id/restore",
+			middlewares.RequireRole("admin"),
+			func(c *gin.Context) {
+				handlers.RestoreCategory(c, ctn)
+			})
 
-		// category.GET("/:slug", handlers.GetCategoryBySlug)
+		SetupCategoryAttributeRoutes(category, ctn)
 	}
 }
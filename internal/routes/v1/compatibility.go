@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupProductCompatibilityRoutes configures the accessory compatibility routes, nested under /products/:id
+func SetupProductCompatibilityRoutes(r *gin.RouterGroup, ctn *container.Container) {
+	r.GET("/:id/compatible-accessories", func(ctx *gin.Context) {
+		handlers.GetCompatibleAccessories(ctx, ctn)
+	})
+	r.GET("/:id/compatible-devices", func(ctx *gin.Context) {
+		handlers.GetCompatibleDevices(ctx, ctn)
+	})
+	r.PUT("/:id/compatible-devices",
+		middlewares.RequireRole("admin"),
+		middlewares.ValidateRequest(&models.CompatibleDevicesRequest{}),
+		func(ctx *gin.Context) {
+			handlers.SetCompatibleDevices(ctx, ctn)
+		})
+}
+
+// SetupCompatibilityRuleRoutes configures the compatibility rule routes for admins
+func SetupCompatibilityRuleRoutes(r *gin.RouterGroup, ctn *container.Container) {
+	rules := r.Group("/compatibility-rules", middlewares.RequireRole("admin"))
+	{
+		rules.GET("", func(ctx *gin.Context) {
+			handlers.GetCompatibilityRules(ctx, ctn)
+		})
+		rules.POST("",
+			middlewares.ValidateRequest(&models.CompatibilityRuleCreateRequest{}),
+			func(ctx *gin.Context) {
+				handlers.CreateCompatibilityRule(ctx, ctn)
+			})
+		rules.DELETE("/:ruleId", func(ctx *gin.Context) {
+			handlers.DeleteCompatibilityRule(ctx, ctn)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package v1
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupFlashSaleRoutes(r *gin.RouterGroup, ctn *container.Container) {
+	sales := r.Group("/flash-sales")
+	{
+		sales.GET("", func(ctx *gin.Context) {
+			handlers.GetFlashSales(ctx, ctn)
+		})
+		sales.GET("/:id", func(ctx *gin.Context) {
+			handlers.GetFlashSaleByID(ctx, ctn)
+		})
+		sales.POST("",
+			middlewares.RequireRole("admin"),
+			middlewares.ValidateRequest(&models.FlashSaleCreateRequest{}),
+			func(ctx *gin.Context) {
+				handlers.CreateFlashSale(ctx, ctn)
+			})
+		sales.DELETE("/:id",
+			middlewares.RequireRole("admin"),
+			func(ctx *gin.Context) {
+				handlers.CancelFlashSale(ctx, ctn)
+			})
+	}
+}
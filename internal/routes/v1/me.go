@@ -0,0 +1,38 @@
+package v1
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupRecentlyViewedRoutes configures the browsing history of the current viewer, a user or a
+// guest session; r authenticates the user when a token is sent
+func SetupRecentlyViewedRoutes(r *gin.RouterGroup, ctn *container.Container) {
+	me := r.Group("/me")
+	{
+		me.GET("/recently-viewed", func(ctx *gin.Context) {
+			handlers.GetRecentlyViewed(ctx, ctn)
+		})
+		me.DELETE("/recently-viewed", func(ctx *gin.Context) {
+			handlers.ClearRecentlyViewed(ctx, ctn)
+		})
+	}
+}
+
+// SetupMeRoutes configures routes about the current user
+func SetupMeRoutes(r *gin.RouterGroup, ctn *container.Container) {
+	me := r.Group("/me")
+	{
+		me.GET("/warranties", func(ctx *gin.Context) {
+			handlers.GetMyWarranties(ctx, ctn)
+		})
+		me.GET("/warranty-claims", func(ctx *gin.Context) {
+			handlers.GetMyWarrantyClaims(ctx, ctn)
+		})
+		me.GET("/trade-ins", func(ctx *gin.Context) {
+			handlers.GetMyTradeIns(ctx, ctn)
+		})
+	}
+}
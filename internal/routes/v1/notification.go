@@ -0,0 +1,27 @@
+package v1
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupNotificationRoutes configures the routes of the current user's notifications
+func SetupNotificationRoutes(r *gin.RouterGroup, ctn *container.Container) {
+	notifications := r.Group("/notifications")
+	{
+		notifications.GET("", func(ctx *gin.Context) {
+			handlers.GetNotifications(ctx, ctn)
+		})
+		notifications.GET("/unread-count", func(ctx *gin.Context) {
+			handlers.GetUnreadNotificationCount(ctx, ctn)
+		})
+		notifications.PUT("/read-all", func(ctx *gin.Context) {
+			handlers.MarkAllNotificationsAsRead(ctx, ctn)
+		})
+		notifications.PUT("/:notificationId/read", func(ctx *gin.Context) {
+			handlers.MarkNotificationAsRead(ctx, ctn)
+		})
+	}
+}
@@ -36,6 +36,12 @@ func SetupOrderRoute(route *gin.RouterGroup, ctn *container.Container) {
 			func(c *gin.Context) {
 				handlers.DeleteOrder(c, ctn)
 			})
+		order.POST("/:id/items/:itemId/serial-numbers",
+			middlewares.RequireRole("admin"),
+			middlewares.ValidateRequest(&models.SerialNumbersRequest{}),
+			func(c *gin.Context) {
+				handlers.RecordSerialNumbers(c, ctn)
+			})
 		/// chỗ này cần kiểm tra lại
 		order.GET("/user/:userId", func(c *gin.Context) {
 			handlers.GetOrdersByUserID(c, ctn)
@@ -0,0 +1,34 @@
+package v1
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupProductPriceRoutes configures the price history and scheduled price routes, nested under /products/:id
+func SetupProductPriceRoutes(r *gin.RouterGroup, ctn *container.Container) {
+	r.GET("/:id/price-history",
+		middlewares.RequireRole("admin"),
+		func(ctx *gin.Context) {
+			handlers.GetProductPriceHistory(ctx, ctn)
+		})
+
+	schedules := r.Group("/:id/scheduled-prices", middlewares.RequireRole("admin"))
+	{
+		schedules.GET("", func(ctx *gin.Context) {
+			handlers.GetScheduledPrices(ctx, ctn)
+		})
+		schedules.POST("",
+			middlewares.ValidateRequest(&models.ScheduledPriceCreateRequest{}),
+			func(ctx *gin.Context) {
+				handlers.CreateScheduledPrice(ctx, ctn)
+			})
+		schedules.DELETE("/:scheduleId", func(ctx *gin.Context) {
+			handlers.CancelScheduledPrice(ctx, ctn)
+		})
+	}
+}
@@ -15,9 +15,29 @@ func SetupProductRoute(r *gin.RouterGroup, ctn *container.Container) {
 		products.GET("", func(c *gin.Context) {
 			handlers.GetAllProducts(c, ctn)
 		})
+		products.GET("/compare", func(c *gin.Context) {
+			handlers.CompareProducts(c, ctn)
+		})
+		products.GET("/trending", func(c *gin.Context) {
+			handlers.GetTrendingProducts(c, ctn)
+		})
+		products.GET("/slug/:slug", func(c *gin.Context) {
+			handlers.GetProductBySlug(c, ctn)
+		})
+		products.GET("/trash",
+			middlewares.RequireRole("admin"),
+			func(c *gin.Context) {
+				handlers.GetDeletedProducts(c, ctn)
+			})
 		products.GET("/:id", func(c *gin.Context) {
 			handlers.GetProductById(c, ctn)
 		})
+		products.GET("/:id/related", func(c *gin.Context) {
+			handlers.GetRelatedProducts(c, ctn)
+		})
+		products.GET("/:id/frequently-bought-together", func(c *gin.Context) {
+			handlers.GetFrequentlyBoughtTogether(c, ctn)
+		})
 		products.POST("",
 			middlewares.RequireRole("admin"),
 			middlewares.ValidateRequest(&models.ProductCreateRequest{}),
@@ -35,11 +55,28 @@ func SetupProductRoute(r *gin.RouterGroup, ctn *container.Container) {
 			func(c *gin.Context) {
 				handlers.DeleteProduct(c, ctn)
 			})
+		products.POST("/:id/restore",
+			middlewares.RequireRole("admin"),
+			func(c *gin.Context) {
+				handlers.RestoreProduct(c, ctn)
+			})
+		products.PUT("/:id/bundle-items",
+			middlewares.RequireRole("admin"),
+			middlewares.ValidateRequest(&models.BundleItemsRequest{}),
+			func(c *gin.Context) {
+				handlers.SetBundleItems(c, ctn)
+			})
 
 		// Nested routes for product images
 		SetupProductImageRoutes(products, ctn)
+		SetupProductVariantRoutes(products, ctn)
+		SetupProductReviewRoutes(products, ctn)
+		SetupProductQuestionRoutes(products, ctn)
+		SetupProductImportRoutes(products, ctn)
+		SetupProductPriceRoutes(products, ctn)
+		SetupProductCompatibilityRoutes(products, ctn)
+		SetupProductBackorderRoutes(products, ctn)
 
-		// products.GET("/:slug", handlers.GetProductBySlug) //get product with slug
 		// products.GET("/search", handlers.SearchProducts) //search products
 	}
 }
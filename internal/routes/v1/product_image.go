@@ -2,22 +2,40 @@ package v1
 
 import (
 	"api_techstore/internal/container"
-	// "api_techstore/internal/handlers"
-	// "api_techstore/internal/middlewares"
+	"api_techstore/internal/handlers"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
 
-// SetupProductImageRoutes configures routes for product images, likely nested under products
+// SetupProductImageRoutes configures routes for product images, nested under /products/:id
 func SetupProductImageRoutes(r *gin.RouterGroup, ctn *container.Container) {
-	// These routes are nested under /products/:productId and should be protected
-	// images := r.Group("/:productId/images", middlewares.RequireRole("admin"))
-	// {
-	// 	images.POST("", func(ctx *gin.Context) {
-	// 		handlers.AddProductImage(ctx, ctn)
-	// 	})
-	// 	images.DELETE("/:imageId", func(ctx *gin.Context) {
-	// 		handlers.DeleteProductImage(ctx, ctn)
-	// 	})
-	// }
+	images := r.Group("/:id/images")
+	{
+		images.GET("", func(ctx *gin.Context) {
+			handlers.GetProductImages(ctx, ctn)
+		})
+		images.POST("",
+			middlewares.RequireRole("admin"),
+			func(ctx *gin.Context) {
+				handlers.AddProductImage(ctx, ctn)
+			})
+		images.PUT("/order",
+			middlewares.RequireRole("admin"),
+			middlewares.ValidateRequest(&models.ProductImageReorderRequest{}),
+			func(ctx *gin.Context) {
+				handlers.ReorderProductImages(ctx, ctn)
+			})
+		images.PUT("/:imageId/main",
+			middlewares.RequireRole("admin"),
+			func(ctx *gin.Context) {
+				handlers.SetMainProductImage(ctx, ctn)
+			})
+		images.DELETE("/:imageId",
+			middlewares.RequireRole("admin"),
+			func(ctx *gin.Context) {
+				handlers.DeleteProductImage(ctx, ctn)
+			})
+	}
 }
@@ -0,0 +1,31 @@
+package v1
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"api_techstore/internal/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupProductImportRoutes registers the bulk import and export routes under /products
+func SetupProductImportRoutes(products *gin.RouterGroup, ctn *container.Container) {
+	products.GET("/export",
+		middlewares.RequireRole("admin"),
+		func(c *gin.Context) {
+			handlers.ExportProducts(c, ctn)
+		})
+
+	imports := products.Group("/imports", middlewares.RequireRole("admin"))
+	{
+		imports.GET("", func(c *gin.Context) {
+			handlers.GetImportJobs(c, ctn)
+		})
+		imports.GET("/:jobId", func(c *gin.Context) {
+			handlers.GetImportJob(c, ctn)
+		})
+		imports.POST("", func(c *gin.Context) {
+			handlers.ImportProducts(c, ctn)
+		})
+	}
+}
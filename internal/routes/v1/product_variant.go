@@ -0,0 +1,40 @@
+package v1
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupProductVariantRoutes configures routes for product variants, nested under /products/:id
+func SetupProductVariantRoutes(r *gin.RouterGroup, ctn *container.Container) {
+	variants := r.Group("/:id/variants")
+	{
+		variants.GET("", func(ctx *gin.Context) {
+			handlers.GetProductVariants(ctx, ctn)
+		})
+		variants.GET("/:variantId", func(ctx *gin.Context) {
+			handlers.GetProductVariant(ctx, ctn)
+		})
+		variants.POST("",
+			middlewares.RequireRole("admin"),
+			middlewares.ValidateRequest(&models.ProductVariantCreateRequest{}),
+			func(ctx *gin.Context) {
+				handlers.CreateProductVariant(ctx, ctn)
+			})
+		variants.PUT("/:variantId",
+			middlewares.RequireRole("admin"),
+			middlewares.ValidateRequest(&models.ProductVariantUpdateRequest{}),
+			func(ctx *gin.Context) {
+				handlers.UpdateProductVariant(ctx, ctn)
+			})
+		variants.DELETE("/:variantId",
+			middlewares.RequireRole("admin"),
+			func(ctx *gin.Context) {
+				handlers.DeleteProductVariant(ctx, ctn)
+			})
+	}
+}
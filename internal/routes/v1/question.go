@@ -0,0 +1,60 @@
+package v1
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupProductQuestionRoutes configures the product Q&A routes, nested under /products/:id
+func SetupProductQuestionRoutes(r *gin.RouterGroup, ctn *container.Container) {
+	questions := r.Group("/:id/questions")
+	{
+		questions.GET("", func(ctx *gin.Context) {
+			handlers.GetProductQuestions(ctx, ctn)
+		})
+		questions.POST("",
+			middlewares.ValidateRequest(&models.ProductQuestionCreateRequest{}),
+			func(ctx *gin.Context) {
+				handlers.CreateProductQuestion(ctx, ctn)
+			})
+		questions.POST("/:questionId/answers",
+			middlewares.ValidateRequest(&models.ProductAnswerCreateRequest{}),
+			func(ctx *gin.Context) {
+				handlers.CreateProductAnswer(ctx, ctn)
+			})
+	}
+}
+
+// SetupQuestionRoute configures the Q&A moderation routes for admins
+func SetupQuestionRoute(r *gin.RouterGroup, ctn *container.Container) {
+	questions := r.Group("/questions", middlewares.RequireRole("admin"))
+	{
+		questions.GET("", func(ctx *gin.Context) {
+			handlers.GetQuestions(ctx, ctn)
+		})
+		questions.PUT("/:questionId/moderation",
+			middlewares.ValidateRequest(&models.QAModerateRequest{}),
+			func(ctx *gin.Context) {
+				handlers.ModerateQuestion(ctx, ctn)
+			})
+		questions.DELETE("/:questionId", func(ctx *gin.Context) {
+			handlers.DeleteQuestion(ctx, ctn)
+		})
+
+		questions.GET("/answers", func(ctx *gin.Context) {
+			handlers.GetAnswers(ctx, ctn)
+		})
+		questions.PUT("/answers/:answerId/moderation",
+			middlewares.ValidateRequest(&models.QAModerateRequest{}),
+			func(ctx *gin.Context) {
+				handlers.ModerateAnswer(ctx, ctn)
+			})
+		questions.DELETE("/answers/:answerId", func(ctx *gin.Context) {
+			handlers.DeleteAnswer(ctx, ctn)
+		})
+	}
+}
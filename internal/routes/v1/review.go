@@ -0,0 +1,47 @@
+package v1
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupProductReviewRoutes configures the customer review routes, nested under /products/:id
+func SetupProductReviewRoutes(r *gin.RouterGroup, ctn *container.Container) {
+	reviews := r.Group("/:id/reviews")
+	{
+		reviews.GET("", func(ctx *gin.Context) {
+			handlers.GetProductReviews(ctx, ctn)
+		})
+		reviews.POST("", func(ctx *gin.Context) {
+			handlers.CreateProductReview(ctx, ctn)
+		})
+		reviews.POST("/:reviewId/helpful", func(ctx *gin.Context) {
+			handlers.VoteReviewHelpful(ctx, ctn)
+		})
+		reviews.DELETE("/:reviewId/helpful", func(ctx *gin.Context) {
+			handlers.RemoveReviewHelpfulVote(ctx, ctn)
+		})
+	}
+}
+
+// SetupReviewRoute configures the review moderation routes for admins
+func SetupReviewRoute(r *gin.RouterGroup, ctn *container.Container) {
+	reviews := r.Group("/reviews", middlewares.RequireRole("admin"))
+	{
+		reviews.GET("", func(ctx *gin.Context) {
+			handlers.GetReviews(ctx, ctn)
+		})
+		reviews.PUT("/:reviewId/moderation",
+			middlewares.ValidateRequest(&models.ReviewModerateRequest{}),
+			func(ctx *gin.Context) {
+				handlers.ModerateReview(ctx, ctn)
+			})
+		reviews.DELETE("/:reviewId", func(ctx *gin.Context) {
+			handlers.DeleteReview(ctx, ctn)
+		})
+	}
+}
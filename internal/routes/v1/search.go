@@ -2,6 +2,7 @@ package v1
 
 import (
 	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,8 +15,9 @@ func SetupSearchRoute(r *gin.RouterGroup, ctn *container.Container) {
 		search.GET("/suggestions", func(c *gin.Context) {
 		})
 		search.GET("/filters", func(c *gin.Context) {
+			handlers.GetSearchFilters(c, ctn)
 		})
 		search.GET("/popular", func(c *gin.Context) {
 		})
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupTradeInRoutes configures the trade-in routes for customers and the pricing rules for admins
+func SetupTradeInRoutes(r *gin.RouterGroup, ctn *container.Container) {
+	tradeIns := r.Group("/trade-ins")
+	{
+		tradeIns.POST("",
+			middlewares.RequireRole("user", "admin"),
+			middlewares.ValidateRequest(&models.TradeInQuoteRequest{}),
+			func(ctx *gin.Context) {
+				handlers.RequestTradeInQuote(ctx, ctn)
+			})
+		tradeIns.GET("",
+			middlewares.RequireRole("admin"),
+			func(ctx *gin.Context) {
+				handlers.GetTradeIns(ctx, ctn)
+			})
+		tradeIns.GET("/:id",
+			middlewares.RequireRole("user", "admin"),
+			func(ctx *gin.Context) {
+				handlers.GetTradeIn(ctx, ctn)
+			})
+		tradeIns.POST("/:id/accept",
+			middlewares.RequireRole("user", "admin"),
+			func(ctx *gin.Context) {
+				handlers.AcceptTradeInQuote(ctx, ctn)
+			})
+		tradeIns.POST("/:id/decline",
+			middlewares.RequireRole("user", "admin"),
+			func(ctx *gin.Context) {
+				handlers.DeclineTradeInQuote(ctx, ctn)
+			})
+		tradeIns.PUT("/:id/inspection",
+			middlewares.RequireRole("admin"),
+			middlewares.ValidateRequest(&models.TradeInInspectionRequest{}),
+			func(ctx *gin.Context) {
+				handlers.InspectTradeIn(ctx, ctn)
+			})
+	}
+
+	rules := r.Group("/trade-in-price-rules", middlewares.RequireRole("admin"))
+	{
+		rules.GET("", func(ctx *gin.Context) {
+			handlers.GetTradeInPriceRules(ctx, ctn)
+		})
+		rules.POST("",
+			middlewares.ValidateRequest(&models.TradeInPriceRuleCreateRequest{}),
+			func(ctx *gin.Context) {
+				handlers.CreateTradeInPriceRule(ctx, ctn)
+			})
+		rules.PUT("/:ruleId",
+			middlewares.ValidateRequest(&models.TradeInPriceRuleUpdateRequest{}),
+			func(ctx *gin.Context) {
+				handlers.UpdateTradeInPriceRule(ctx, ctn)
+			})
+		rules.DELETE("/:ruleId", func(ctx *gin.Context) {
+			handlers.DeleteTradeInPriceRule(ctx, ctn)
+		})
+	}
+}
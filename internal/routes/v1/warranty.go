@@ -0,0 +1,19 @@
+package v1
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"api_techstore/internal/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupWarrantyRoutes configures the staff warranty lookup
+func SetupWarrantyRoutes(r *gin.RouterGroup, ctn *container.Container) {
+	warranties := r.Group("/warranties", middlewares.RequireRole("admin"))
+	{
+		warranties.GET("/:serial", func(ctx *gin.Context) {
+			handlers.GetWarrantyBySerialNumber(ctx, ctn)
+		})
+	}
+}
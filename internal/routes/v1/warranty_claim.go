@@ -0,0 +1,49 @@
+package v1
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"api_techstore/internal/middlewares"
+	"api_techstore/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupWarrantyClaimRoutes configures the warranty claim routes for customers and the service center
+func SetupWarrantyClaimRoutes(r *gin.RouterGroup, ctn *container.Container) {
+	claims := r.Group("/warranty-claims")
+	{
+		claims.POST("",
+			middlewares.RequireRole("user", "admin"),
+			func(ctx *gin.Context) {
+				handlers.CreateWarrantyClaim(ctx, ctn)
+			})
+		claims.GET("",
+			middlewares.RequireRole("admin"),
+			func(ctx *gin.Context) {
+				handlers.GetWarrantyClaims(ctx, ctn)
+			})
+		claims.GET("/:id",
+			middlewares.RequireRole("user", "admin"),
+			func(ctx *gin.Context) {
+				handlers.GetWarrantyClaim(ctx, ctn)
+			})
+		claims.POST("/:id/attachments",
+			middlewares.RequireRole("user", "admin"),
+			func(ctx *gin.Context) {
+				handlers.AddWarrantyClaimAttachments(ctx, ctn)
+			})
+		claims.POST("/:id/notes",
+			middlewares.RequireRole("admin"),
+			middlewares.ValidateRequest(&models.WarrantyClaimNoteRequest{}),
+			func(ctx *gin.Context) {
+				handlers.AddWarrantyClaimNote(ctx, ctn)
+			})
+		claims.PUT("/:id/status",
+			middlewares.RequireRole("admin"),
+			middlewares.ValidateRequest(&models.WarrantyClaimStatusRequest{}),
+			func(ctx *gin.Context) {
+				handlers.UpdateWarrantyClaimStatus(ctx, ctn)
+			})
+	}
+}
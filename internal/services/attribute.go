@@ -0,0 +1,295 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	apperrors "api_techstore/pkg/errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var attributeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+type AttributeService interface {
+	GetCategoryAttributes(categoryID uint) ([]models.CategoryAttribute, error)
+	GetCategoryAttribute(categoryID, attributeID uint) (models.CategoryAttribute, error)
+	CreateCategoryAttribute(attribute models.CategoryAttribute) (models.CategoryAttribute, error)
+	UpdateCategoryAttribute(categoryID, attributeID uint, attribute models.CategoryAttribute) (models.CategoryAttribute, error)
+	DeleteCategoryAttribute(categoryID, attributeID uint) error
+	// BuildProductAttributes validates spec values (keyed by attribute code) against the
+	// category's attribute definitions and merges them into the current values
+	BuildProductAttributes(categoryID uint, current []models.ProductAttributeValue, input map[string]interface{}) ([]models.ProductAttributeValue, error)
+}
+
+type attributeService struct {
+	db *gorm.DB
+}
+
+func NewAttributeService(db *gorm.DB) AttributeService {
+	return &attributeService{db: db}
+}
+
+func orderedAttributes(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order, id")
+}
+
+// orderedAttributeValues is used when preloading Product.Attributes: in the attribute's sort order
+func orderedAttributeValues(db *gorm.DB) *gorm.DB {
+	return db.Select("product_attribute_values.*").
+		Joins("JOIN category_attributes ON category_attributes.id = product_attribute_values.attribute_id").
+		Order("category_attributes.sort_order, category_attributes.id")
+}
+
+func (s *attributeService) GetCategoryAttributes(categoryID uint) ([]models.CategoryAttribute, error) {
+	if err := s.db.Select("id").First(&models.Category{}, categoryID).Error; err != nil {
+		return nil, err
+	}
+	var attributes []models.CategoryAttribute
+	err := orderedAttributes(s.db.Where("category_id = ?", categoryID)).Find(&attributes).Error
+	return attributes, err
+}
+
+func (s *attributeService) GetCategoryAttribute(categoryID, attributeID uint) (models.CategoryAttribute, error) {
+	var attribute models.CategoryAttribute
+	err := s.db.Where("category_id = ?", categoryID).First(&attribute, attributeID).Error
+	return attribute, err
+}
+
+func (s *attributeService) CreateCategoryAttribute(attribute models.CategoryAttribute) (models.CategoryAttribute, error) {
+	if err := s.db.Select("id").First(&models.Category{}, attribute.CategoryID).Error; err != nil {
+		return models.CategoryAttribute{}, err
+	}
+	if !attributeCodePattern.MatchString(attribute.Code) {
+		return models.CategoryAttribute{}, apperrors.NewValidationFailed("code must start with a letter and contain only lowercase letters, digits and underscores")
+	}
+	if err := normalizeAllowedValues(&attribute); err != nil {
+		return models.CategoryAttribute{}, err
+	}
+
+	var count int64
+	if err := s.db.Model(&models.CategoryAttribute{}).
+		Where("category_id = ? AND code = ?", attribute.CategoryID, attribute.Code).
+		Count(&count).Error; err != nil {
+		return models.CategoryAttribute{}, err
+	}
+	if count > 0 {
+		return models.CategoryAttribute{}, apperrors.NewAlreadyExists("Attribute with this code")
+	}
+
+	err := s.db.Create(&attribute).Error
+	return attribute, err
+}
+
+func (s *attributeService) UpdateCategoryAttribute(categoryID, attributeID uint, attribute models.CategoryAttribute) (models.CategoryAttribute, error) {
+	existing, err := s.GetCategoryAttribute(categoryID, attributeID)
+	if err != nil {
+		return models.CategoryAttribute{}, err
+	}
+	attribute.Type = existing.Type
+	if err := normalizeAllowedValues(&attribute); err != nil {
+		return models.CategoryAttribute{}, err
+	}
+
+	// Select so false / zero values are written as well
+	if err := s.db.Model(&existing).Select("name", "unit", "allowed_values", "is_required", "is_filterable", "sort_order").
+		Updates(models.CategoryAttribute{
+			Name:          attribute.Name,
+			Unit:          attribute.Unit,
+			AllowedValues: attribute.AllowedValues,
+			IsRequired:    attribute.IsRequired,
+			IsFilterable:  attribute.IsFilterable,
+			SortOrder:     attribute.SortOrder,
+		}).Error; err != nil {
+		return models.CategoryAttribute{}, err
+	}
+
+	err = s.db.First(&existing, attributeID).Error
+	return existing, err
+}
+
+func (s *attributeService) DeleteCategoryAttribute(categoryID, attributeID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var attribute models.CategoryAttribute
+		if err := tx.Where("category_id = ?", categoryID).First(&attribute, attributeID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("attribute_id = ?", attributeID).Delete(&models.ProductAttributeValue{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&attribute).Error
+	})
+}
+
+func (s *attributeService) BuildProductAttributes(categoryID uint, current []models.ProductAttributeValue, input map[string]interface{}) ([]models.ProductAttributeValue, error) {
+	var attributes []models.CategoryAttribute
+	if err := orderedAttributes(s.db.Where("category_id = ?", categoryID)).Find(&attributes).Error; err != nil {
+		return nil, err
+	}
+	return MergeProductAttributes(attributes, current, input)
+}
+
+// MergeProductAttributes validates spec values keyed by attribute code against the attribute
+// definitions of a category, in their order, and merges them into the current values. A nil
+// value removes the attribute; every problem is reported at once.
+func MergeProductAttributes(attributes []models.CategoryAttribute, current []models.ProductAttributeValue, input map[string]interface{}) ([]models.ProductAttributeValue, error) {
+	byCode := make(map[string]models.CategoryAttribute, len(attributes))
+	for _, attribute := range attributes {
+		byCode[attribute.Code] = attribute
+	}
+
+	// values that belong to another category are dropped (the product changed category)
+	values := make(map[uint]models.ProductAttributeValue, len(current))
+	for _, value := range current {
+		values[value.AttributeID] = value
+	}
+
+	var problems []string
+	for code, raw := range input {
+		attribute, ok := byCode[code]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown attribute '%s'", code))
+			continue
+		}
+		if raw == nil {
+			delete(values, attribute.ID)
+			continue
+		}
+		value, err := attributeValue(attribute, raw)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", code, err.Error()))
+			continue
+		}
+		values[attribute.ID] = value
+	}
+
+	result := make([]models.ProductAttributeValue, 0, len(values))
+	for _, attribute := range attributes {
+		value, ok := values[attribute.ID]
+		if !ok {
+			if attribute.IsRequired {
+				problems = append(problems, fmt.Sprintf("%s: is required", attribute.Code))
+			}
+			continue
+		}
+		value.ID = 0
+		value.Attribute = attribute
+		result = append(result, value)
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, apperrors.NewValidationFailed("invalid attributes: " + strings.Join(problems, "; "))
+	}
+	return result, nil
+}
+
+// attributeValue converts a JSON value to the typed column of the attribute.
+// Numbers and booleans are also accepted as strings (e.g. from CSV imports).
+func attributeValue(attribute models.CategoryAttribute, raw interface{}) (models.ProductAttributeValue, error) {
+	value := models.ProductAttributeValue{AttributeID: attribute.ID}
+
+	switch attribute.Type {
+	case models.AttributeTypeNumber:
+		var number float64
+		switch v := raw.(type) {
+		case float64:
+			number = v
+		case int:
+			number = float64(v)
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return value, fmt.Errorf("must be a number")
+			}
+			number = parsed
+		default:
+			return value, fmt.Errorf("must be a number")
+		}
+		value.ValueNumber = &number
+
+	case models.AttributeTypeBoolean:
+		var flag bool
+		switch v := raw.(type) {
+		case bool:
+			flag = v
+		case string:
+			parsed, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return value, fmt.Errorf("must be true or false")
+			}
+			flag = parsed
+		default:
+			return value, fmt.Errorf("must be true or false")
+		}
+		value.ValueBool = &flag
+
+	case models.AttributeTypeEnum:
+		text := strings.TrimSpace(fmt.Sprint(raw))
+		for _, allowed := range attribute.AllowedValues {
+			if strings.EqualFold(allowed, text) {
+				value.ValueText = allowed
+				return value, nil
+			}
+		}
+		return value, fmt.Errorf("must be one of %s", strings.Join(attribute.AllowedValues, ", "))
+
+	default:
+		switch v := raw.(type) {
+		case string:
+			value.ValueText = strings.TrimSpace(v)
+		case float64, bool:
+			value.ValueText = fmt.Sprint(v)
+		default:
+			return value, fmt.Errorf("must be a text value")
+		}
+		if value.ValueText == "" {
+			return value, fmt.Errorf("must not be empty")
+		}
+		if len(value.ValueText) > 255 {
+			return value, fmt.Errorf("must not exceed 255 characters")
+		}
+	}
+	return value, nil
+}
+
+// AttributeValueString renders a typed attribute value as text (without the unit)
+func AttributeValueString(value models.ProductAttributeValue) string {
+	switch {
+	case value.ValueNumber != nil:
+		return strconv.FormatFloat(*value.ValueNumber, 'f', -1, 64)
+	case value.ValueBool != nil:
+		return strconv.FormatBool(*value.ValueBool)
+	default:
+		return value.ValueText
+	}
+}
+
+// normalizeAllowedValues trims and de-duplicates the allowed values; they are required
+// for enum attributes and ignored for the other types
+func normalizeAllowedValues(attribute *models.CategoryAttribute) error {
+	if attribute.Type != models.AttributeTypeEnum {
+		attribute.AllowedValues = nil
+		return nil
+	}
+
+	seen := make(map[string]bool, len(attribute.AllowedValues))
+	values := make([]string, 0, len(attribute.AllowedValues))
+	for _, value := range attribute.AllowedValues {
+		value = strings.TrimSpace(value)
+		key := strings.ToLower(value)
+		if value == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		values = append(values, value)
+	}
+	if len(values) == 0 {
+		return apperrors.NewValidationFailed("allowed_values is required for enum attributes")
+	}
+	attribute.AllowedValues = values
+	return nil
+}
@@ -0,0 +1,317 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	apperrors "api_techstore/pkg/errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BackorderService interface {
+	SetStockPolicy(productID uint, policy string, expectedAt *time.Time, limit int) (models.Product, error)
+	ReceiveStock(productID uint, variantID *uint, quantity int) (models.StockReceipt, error)
+}
+
+type backorderService struct {
+	db *gorm.DB
+}
+
+func NewBackorderService(db *gorm.DB) BackorderService {
+	return &backorderService{db: db}
+}
+
+// SetStockPolicy lets a product be pre-ordered or backordered up to a limit, or turns it back to
+// normal. Units already waiting for stock keep waiting whatever the new settings.
+func (s *backorderService) SetStockPolicy(productID uint, policy string, expectedAt *time.Time, limit int) (models.Product, error) {
+	if policy == models.StockPolicyPreorder && expectedAt == nil {
+		return models.Product{}, apperrors.NewValidationFailed("expected_available_at is required for pre-orders")
+	}
+	var product models.Product
+	if err := s.db.Select("id", "type").First(&product, productID).Error; err != nil {
+		return models.Product{}, err
+	}
+	if product.Type == models.ProductTypeBundle && policy != models.StockPolicyNormal {
+		return models.Product{}, apperrors.NewValidationFailed("Bundles take the stock of their components and cannot be pre-ordered")
+	}
+	if policy == models.StockPolicyNormal {
+		expectedAt, limit = nil, 0
+	}
+	err := s.db.Model(&product).Updates(map[string]interface{}{
+		"stock_policy":          policy,
+		"expected_available_at": expectedAt,
+		"backorder_limit":       limit,
+	}).Error
+	if err != nil {
+		return models.Product{}, err
+	}
+	return (&productService{db: s.db}).GetProductById(strconv.FormatUint(uint64(productID), 10))
+}
+
+// ReceiveStock adds received units to the stock of a product, or of one of its variants, and
+// allocates them to the units waiting for stock, oldest order first. Orders with nothing left
+// waiting go back to pending and their customer is notified.
+func (s *backorderService) ReceiveStock(productID uint, variantID *uint, quantity int) (models.StockReceipt, error) {
+	var receipt models.StockReceipt
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var variants int64
+		if err := tx.Model(&models.ProductVariant{}).Where("product_id = ?", productID).Count(&variants).Error; err != nil {
+			return err
+		}
+		if variants > 0 && variantID == nil {
+			return apperrors.NewValidationFailed("variant_id is required for this product")
+		}
+		var err error
+		receipt, err = receiveStock(tx, productID, variantID, quantity)
+		return err
+	})
+	if err != nil {
+		return models.StockReceipt{}, err
+	}
+	return receipt, nil
+}
+
+// receiveStock is ReceiveStock inside the caller's transaction; every increase of the stock of a
+// product that may have units waiting goes through it
+func receiveStock(tx *gorm.DB, productID uint, variantID *uint, quantity int) (models.StockReceipt, error) {
+	receipt := models.StockReceipt{ProductID: productID, VariantID: variantID, Received: quantity, ReleasedOrders: []uint{}}
+	var product models.Product
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "name", "quantity", "backordered_quantity").
+		First(&product, productID).Error
+	if err != nil {
+		return models.StockReceipt{}, err
+	}
+
+	stock := product.Quantity
+	stockRow := tx.Model(&product)
+	lines := tx.Model(&models.OrderItem{}).Where("order_items.product_id = ?", productID)
+	if variantID != nil {
+		var variant models.ProductVariant
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id = ?", productID).
+			First(&variant, *variantID).Error
+		if err == gorm.ErrRecordNotFound {
+			return models.StockReceipt{}, apperrors.NewNotFound("Product variant")
+		}
+		if err != nil {
+			return models.StockReceipt{}, err
+		}
+		stock = variant.Quantity
+		stockRow = tx.Model(&variant)
+		lines = lines.Where("order_items.variant_id = ?", *variantID)
+	} else {
+		lines = lines.Where("order_items.variant_id IS NULL")
+	}
+	stock += quantity
+
+	var waiting []models.OrderItem
+	err = lines.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "order_items"}}).
+		Select("order_items.*").
+		Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL").
+		Where("orders.status = ? AND order_items.backordered_quantity > 0", models.OrderStatusAwaitingStock).
+		Order("orders.created_at, orders.id, order_items.id").
+		Find(&waiting).Error
+	if err != nil {
+		return models.StockReceipt{}, err
+	}
+
+	touched := map[uint]bool{}
+	var orderIDs []uint
+	for _, line := range waiting {
+		if stock == 0 {
+			break
+		}
+		allocated := line.BackorderedQuantity
+		if allocated > stock {
+			allocated = stock
+		}
+		err := tx.Model(&line).UpdateColumn("backordered_quantity", gorm.Expr("backordered_quantity - ?", allocated)).Error
+		if err != nil {
+			return models.StockReceipt{}, err
+		}
+		stock -= allocated
+		receipt.Allocated += allocated
+		if !touched[line.OrderID] {
+			touched[line.OrderID] = true
+			orderIDs = append(orderIDs, line.OrderID)
+		}
+	}
+
+	if err := stockRow.UpdateColumn("quantity", stock).Error; err != nil {
+		return models.StockReceipt{}, err
+	}
+	if receipt.Allocated > 0 {
+		err := tx.Model(&product).UpdateColumn("backordered_quantity", gorm.Expr("GREATEST(backordered_quantity - ?, 0)", receipt.Allocated)).Error
+		if err != nil {
+			return models.StockReceipt{}, err
+		}
+	}
+	receipt.Stock = stock
+
+	for _, orderID := range orderIDs {
+		released, err := releaseOrderIfStocked(tx, orderID)
+		if err != nil {
+			return models.StockReceipt{}, err
+		}
+		if released {
+			receipt.ReleasedOrders = append(receipt.ReleasedOrders, orderID)
+		}
+	}
+	return receipt, nil
+}
+
+// setStock sets the stock of a product, or of one of its variants, from a hand edit or an import.
+// When units are waiting for it, an increase is received like a delivery so they get it first.
+func setStock(tx *gorm.DB, productID uint, variantID *uint, current, quantity int) error {
+	if quantity > current {
+		waiting := tx.Model(&models.OrderItem{}).
+			Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL").
+			Where("orders.status = ? AND order_items.product_id = ? AND order_items.backordered_quantity > 0",
+				models.OrderStatusAwaitingStock, productID)
+		if variantID != nil {
+			waiting = waiting.Where("order_items.variant_id = ?", *variantID)
+		} else {
+			waiting = waiting.Where("order_items.variant_id IS NULL")
+		}
+		var count int64
+		if err := waiting.Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			_, err := receiveStock(tx, productID, variantID, quantity-current)
+			return err
+		}
+	}
+	stockRow := tx.Model(&models.Product{}).Where("id = ?", productID)
+	if variantID != nil {
+		stockRow = tx.Model(&models.ProductVariant{}).Where("id = ?", *variantID)
+	}
+	return stockRow.UpdateColumn("quantity", quantity).Error
+}
+
+// releaseOrderIfStocked moves an order awaiting stock back to pending once none of its units is
+// waiting anymore, and tells the customer
+func releaseOrderIfStocked(tx *gorm.DB, orderID uint) (bool, error) {
+	var waiting int64
+	err := tx.Model(&models.OrderItem{}).Where("order_id = ? AND backordered_quantity > 0", orderID).Count(&waiting).Error
+	if err != nil || waiting > 0 {
+		return false, err
+	}
+	var order models.Order
+	if err := tx.Select("id", "user_id", "status").First(&order, orderID).Error; err != nil {
+		return false, err
+	}
+	if order.Status != models.OrderStatusAwaitingStock {
+		return false, nil
+	}
+	if err := tx.Model(&order).Update("status", "pending").Error; err != nil {
+		return false, err
+	}
+	return true, notify(tx, models.Notification{
+		UserID:     order.UserID,
+		Type:       models.NotificationOrderInStock,
+		Title:      "Your order is in stock",
+		Message:    fmt.Sprintf("Every item of your order #%d is now in stock and it will be shipped soon.", order.ID),
+		EntityType: "order",
+		EntityID:   &orderID,
+	})
+}
+
+// takeStock takes the units of a line from stock inside the checkout transaction. Pre-order and
+// backorder products can go past their stock: the missing units wait for it, within the product's
+// backorder limit, and the line records how many. Other lines, bundle components and flash sale
+// lines included, must be in stock.
+func takeStock(tx *gorm.DB, item *models.OrderItem) error {
+	var product models.Product
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "name", "quantity", "stock_policy", "backorder_limit", "backordered_quantity").
+		First(&product, item.ProductID).Error
+	if err != nil {
+		return err
+	}
+	item.StockTaken = true
+	if item.BundleProductID != nil || item.FlashSaleID != nil ||
+		(product.StockPolicy != models.StockPolicyPreorder && product.StockPolicy != models.StockPolicyBackorder) {
+		return takeUnits(tx, *item, product.Name)
+	}
+
+	stock := product.Quantity
+	stockRow := tx.Model(&product)
+	if item.VariantID != nil {
+		var variant models.ProductVariant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&variant, *item.VariantID).Error; err != nil {
+			return err
+		}
+		stock = variant.Quantity
+		stockRow = tx.Model(&variant)
+	}
+
+	taken := item.Quantity
+	if taken > stock {
+		taken = stock
+	}
+	if taken < 0 {
+		taken = 0
+	}
+	waiting := item.Quantity - taken
+	if waiting > product.BackorderAllowance() {
+		return apperrors.New(apperrors.ErrCodeInsufficientStock,
+			fmt.Sprintf("Only %d more units of %s can be pre-ordered", product.BackorderAllowance(), product.Name), http.StatusConflict)
+	}
+	if taken > 0 {
+		if err := stockRow.UpdateColumn("quantity", gorm.Expr("quantity - ?", taken)).Error; err != nil {
+			return err
+		}
+	}
+	if waiting > 0 {
+		err := tx.Model(&product).UpdateColumn("backordered_quantity", gorm.Expr("backordered_quantity + ?", waiting)).Error
+		if err != nil {
+			return err
+		}
+	}
+	item.BackorderedQuantity = waiting
+	return nil
+}
+
+// returnStock gives back what the lines of a cancelled or deleted order hold: the backorder
+// allowance of their units still waiting for stock, and the units they took from stock, which go
+// to the orders waiting for them first. The lines are cleared, so this is done once.
+func returnStock(tx *gorm.DB, orderID uint) error {
+	var lines []models.OrderItem
+	err := tx.Where("order_id = ? AND (stock_taken = ? OR backordered_quantity > 0)", orderID, true).
+		Order("id").
+		Find(&lines).Error
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if line.BackorderedQuantity > 0 {
+			err := tx.Model(&models.Product{}).Where("id = ?", line.ProductID).
+				UpdateColumn("backordered_quantity", gorm.Expr("GREATEST(backordered_quantity - ?, 0)", line.BackorderedQuantity)).Error
+			if err != nil {
+				return err
+			}
+		}
+		// read before the line is cleared, the update writes the cleared values back into it
+		taken := 0
+		if line.StockTaken {
+			taken = line.Quantity - line.BackorderedQuantity
+		}
+		// cleared first, so the units given back are not allocated to the line itself
+		err := tx.Model(&line).UpdateColumns(map[string]interface{}{"backordered_quantity": 0, "stock_taken": false}).Error
+		if err != nil {
+			return err
+		}
+		if taken > 0 {
+			if _, err := receiveStock(tx, line.ProductID, line.VariantID, taken); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -2,24 +2,39 @@ package services
 
 import (
 	"api_techstore/internal/models"
+	apperrors "api_techstore/pkg/errors"
+	"api_techstore/pkg/storage"
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+const brandTopProducts = 8
+
 type BrandService interface {
 	GetAllBrands() ([]models.Brand, error)
 	GetBrandById(id string) (models.Brand, error)
+	GetBrandBySlug(slug string) (models.Brand, error)
 	CreateBrand(brand models.Brand) (models.Brand, error)
 	UpdateBrand(id string, brand models.Brand) (models.Brand, error)
 	DeleteBrand(id string) error
+	ReassignAndDeleteBrand(id string, targetID uint) error
+	GetBrandPage(slug string) (models.BrandPage, error)
+	SetBrandLogo(ctx context.Context, id uint, file *multipart.FileHeader) (models.Brand, error)
 }
 
 type brandService struct {
-	db *gorm.DB
+	db      *gorm.DB
+	storage storage.Storage
 }
 
-func NewBrandService(db *gorm.DB) BrandService {
-	return &brandService{db: db}
+func NewBrandService(db *gorm.DB, store storage.Storage) BrandService {
+	return &brandService{db: db, storage: store}
 }
 
 func (s *brandService) GetAllBrands() ([]models.Brand, error) {
@@ -34,13 +49,50 @@ func (s *brandService) GetBrandById(id string) (models.Brand, error) {
 	return brand, err
 }
 
+// GetBrandBySlug also resolves old slugs; the returned brand then carries its current slug
+func (s *brandService) GetBrandBySlug(slug string) (models.Brand, error) {
+	var brand models.Brand
+	err := findBySlug(s.db, &brand, models.SlugEntityBrand, slug)
+	return brand, err
+}
+
 func (s *brandService) CreateBrand(brand models.Brand) (models.Brand, error) {
-	err := s.db.Create(&brand).Error
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		slug, err := assignSlug(tx, &models.Brand{}, models.SlugEntityBrand, brand.Slug, brand.Name, 0)
+		if err != nil {
+			return err
+		}
+		brand.Slug = slug
+		if err := tx.Create(&brand).Error; err != nil {
+			return err
+		}
+		return claimSlug(tx, models.SlugEntityBrand, brand.ID, "", brand.Slug)
+	})
 	return brand, err
 }
 
 func (s *brandService) UpdateBrand(id string, brand models.Brand) (models.Brand, error) {
-	if err := s.db.Model(&models.Brand{}).Where("id = ?", id).Updates(brand).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.Brand
+		if err := tx.Select("id, slug").First(&existing, "id = ?", id).Error; err != nil {
+			return err
+		}
+		slug, err := changeSlug(tx, &models.Brand{}, models.SlugEntityBrand, existing.ID, existing.Slug, brand.Slug)
+		if err != nil {
+			return err
+		}
+		brand.Slug = slug
+		if err := tx.Model(&existing).Updates(brand).Error; err != nil {
+			return err
+		}
+		// Updates skips false and nil, so the flag and the warranty are written on their own; the caller
+		// passes the current values when unchanged
+		return tx.Model(&existing).Updates(map[string]interface{}{
+			"is_active":       brand.IsActive,
+			"warranty_months": brand.WarrantyMonths,
+		}).Error
+	})
+	if err != nil {
 		return models.Brand{}, err
 	}
 	var updatedBrand models.Brand
@@ -50,7 +102,161 @@ func (s *brandService) UpdateBrand(id string, brand models.Brand) (models.Brand,
 	return updatedBrand, nil
 }
 
+// DeleteBrand refuses to delete a brand that products, trashed ones included, or trade-in price
+// rules still refer to
 func (s *brandService) DeleteBrand(id string) error {
-	err := s.db.Delete(&models.Brand{}, "id = ?", id).Error
-	return err
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		brand, err := lockBrand(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkBrandUnused(tx, brand.ID, true); err != nil {
+			return err
+		}
+		return tx.Delete(&brand).Error
+	})
+}
+
+// ReassignAndDeleteBrand moves the products of a brand, trashed ones included, to another active
+// brand and deletes it in one transaction. Trade-in price rules are model specific, so a brand that
+// still has some is refused.
+func (s *brandService) ReassignAndDeleteBrand(id string, targetID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		brand, err := lockBrand(tx, id)
+		if err != nil {
+			return err
+		}
+		if targetID == brand.ID {
+			return apperrors.NewValidationFailed("cannot reassign products to the brand being deleted")
+		}
+		var target models.Brand
+		if err := tx.Select("id", "is_active").First(&target, targetID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return apperrors.NewValidationFailed("target brand not found")
+			}
+			return err
+		}
+		if !target.IsActive {
+			return apperrors.NewValidationFailed("target brand is inactive, the products would be hidden")
+		}
+		if err := checkBrandUnused(tx, brand.ID, false); err != nil {
+			return err
+		}
+		err = tx.Unscoped().Model(&models.Product{}).
+			Where("brand_id = ?", brand.ID).
+			Update("brand_id", targetID).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&brand).Error
+	})
+}
+
+func lockBrand(tx *gorm.DB, id string) (models.Brand, error) {
+	var brand models.Brand
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&brand, "id = ?", id).Error
+	return brand, err
+}
+
+// checkBrandUnused returns a conflict listing what still refers to the brand. Trashed products count,
+// they can be restored; products are left out when they are about to be reassigned.
+// when they are about to be reassigned
+func checkBrandUnused(tx *gorm.DB, brandID uint, countProducts bool) error {
+	var products, priceRules int64
+	if countProducts {
+		if err := tx.Unscoped().Model(&models.Product{}).Where("brand_id = ?", brandID).Count(&products).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Model(&models.TradeInPriceRule{}).Where("brand_id = ?", brandID).Count(&priceRules).Error; err != nil {
+		return err
+	}
+	if products > 0 || priceRules > 0 {
+		appErr := apperrors.NewConflict("Brand is in use")
+		appErr.Details = fmt.Sprintf("brand has %d products and %d trade-in price rules", products, priceRules)
+		return appErr
+	}
+	return nil
+}
+
+// GetBrandPage gathers the landing data of an active brand: how many of its products the catalog
+// lists, per category too, and its best sellers. Old slugs resolve like GetBrandBySlug.
+func (s *brandService) GetBrandPage(slug string) (models.BrandPage, error) {
+	var brand models.Brand
+	if err := findBySlug(s.db, &brand, models.SlugEntityBrand, slug); err != nil {
+		return models.BrandPage{}, err
+	}
+	if !brand.IsActive {
+		return models.BrandPage{}, gorm.ErrRecordNotFound
+	}
+	page := models.BrandPage{Brand: brand, Categories: []models.BrandCategoryCount{}, TopProducts: []models.Product{}}
+
+	err := s.db.Model(&models.Product{}).
+		Scopes(listedProducts).
+		Select("categories.id, categories.name, categories.slug, COUNT(*) AS product_count").
+		Joins("JOIN categories ON categories.id = products.category_id").
+		Where("products.brand_id = ?", brand.ID).
+		Group("categories.id, categories.name, categories.slug").
+		Order("product_count DESC, categories.name").
+		Scan(&page.Categories).Error
+	if err != nil {
+		return models.BrandPage{}, err
+	}
+	for _, category := range page.Categories {
+		page.ProductCount += category.ProductCount
+	}
+
+	// best sellers by units in orders that were not cancelled, then by rating
+	err = preloadProduct(s.db).
+		Scopes(listedProducts).
+		Where("products.brand_id = ?", brand.ID).
+		Clauses(clause.OrderBy{Expression: clause.Expr{SQL: `(SELECT COALESCE(SUM(order_items.quantity), 0) FROM order_items
+			JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL AND orders.status <> ?
+			WHERE order_items.product_id = products.id AND order_items.deleted_at IS NULL) DESC, products.rating_average DESC, products.id DESC`,
+			Vars: []interface{}{"cancelled"}}}).
+		Limit(brandTopProducts).
+		Find(&page.TopProducts).Error
+	if err != nil {
+		return models.BrandPage{}, err
+	}
+	setBundleAvailability(page.TopProducts)
+	if err := setBreadcrumbs(s.db, page.TopProducts); err != nil {
+		return models.BrandPage{}, err
+	}
+	return page, nil
+}
+
+// SetBrandLogo stores an uploaded logo for the brand and removes the one it replaces
+func (s *brandService) SetBrandLogo(ctx context.Context, id uint, file *multipart.FileHeader) (models.Brand, error) {
+	var brand models.Brand
+	if err := s.db.First(&brand, id).Error; err != nil {
+		return models.Brand{}, err
+	}
+
+	data, contentType, err := readImageUpload(file)
+	if err != nil {
+		return models.Brand{}, err
+	}
+	data, contentType, err = keptImage(data, contentType)
+	if err != nil {
+		return models.Brand{}, err
+	}
+	key := fmt.Sprintf("brands/%d/logo_%s%s", id, uuid.NewString(), allowedImageTypes[contentType])
+	url, err := s.storage.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType)
+	if err != nil {
+		return models.Brand{}, apperrors.NewStorageError(err)
+	}
+
+	previousKey := brand.LogoStorageKey
+	err = s.db.Model(&brand).Updates(map[string]interface{}{"logo_url": url, "logo_storage_key": key}).Error
+	if err != nil {
+		s.storage.Delete(ctx, key)
+		return models.Brand{}, err
+	}
+	brand.LogoURL, brand.LogoStorageKey = url, key
+	if previousKey != "" {
+		// a leftover file only wastes space, the brand already points to the new logo
+		s.storage.Delete(ctx, previousKey)
+	}
+	return brand, nil
 }
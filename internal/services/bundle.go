@@ -0,0 +1,211 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	"fmt"
+	"math"
+	"strconv"
+
+	apperrors "api_techstore/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+type BundleService interface {
+	SetBundleItems(bundleID uint, items []models.BundleItem) (models.Product, error)
+	ExpandOrderItems(items []models.OrderItem) ([]models.OrderItem, error)
+}
+
+type bundleService struct {
+	db *gorm.DB
+}
+
+func NewBundleService(db *gorm.DB) BundleService {
+	return &bundleService{db: db}
+}
+
+func orderedBundleItems(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order, id")
+}
+
+// preloadBundleItems loads the components of bundles with what their stock and price come from
+func preloadBundleItems(db *gorm.DB) *gorm.DB {
+	return db.Preload("BundleItems", orderedBundleItems).
+		Preload("BundleItems.Component").
+		Preload("BundleItems.ComponentVariant")
+}
+
+// SetBundleItems replaces the components of a bundle. A component is a simple product, or one of
+// its variants when it has some, and appears once.
+func (s *bundleService) SetBundleItems(bundleID uint, items []models.BundleItem) (models.Product, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var bundle models.Product
+		if err := tx.Select("id", "type").First(&bundle, bundleID).Error; err != nil {
+			return err
+		}
+		if bundle.Type != models.ProductTypeBundle {
+			return apperrors.NewValidationFailed("Product is not a bundle")
+		}
+
+		seen := make(map[string]bool, len(items))
+		for i, item := range items {
+			if item.ComponentID == bundleID {
+				return apperrors.NewValidationFailed("A bundle cannot contain itself")
+			}
+			var component models.Product
+			if err := tx.Preload("Variants").Select("id", "type").First(&component, item.ComponentID).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return apperrors.NewNotFound(fmt.Sprintf("Component product %d", item.ComponentID))
+				}
+				return err
+			}
+			if component.Type == models.ProductTypeBundle {
+				return apperrors.NewValidationFailed("A bundle cannot contain another bundle")
+			}
+			if err := checkComponentVariant(component, item.ComponentVariantID); err != nil {
+				return err
+			}
+
+			key := strconv.FormatUint(uint64(item.ComponentID), 10)
+			if item.ComponentVariantID != nil {
+				key += ":" + strconv.FormatUint(uint64(*item.ComponentVariantID), 10)
+			}
+			if seen[key] {
+				return apperrors.NewValidationFailed("Each component may appear only once")
+			}
+			seen[key] = true
+
+			items[i].ID = 0
+			items[i].BundleID = bundleID
+			items[i].SortOrder = i
+		}
+
+		if err := tx.Where("bundle_id = ?", bundleID).Delete(&models.BundleItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Omit("Component", "ComponentVariant").Create(&items).Error
+	})
+	if err != nil {
+		return models.Product{}, err
+	}
+	return (&productService{db: s.db}).GetProductById(strconv.FormatUint(uint64(bundleID), 10))
+}
+
+// checkComponentVariant requires a variant of the component when it has variants, and none otherwise
+func checkComponentVariant(component models.Product, variantID *uint) error {
+	if len(component.Variants) == 0 {
+		if variantID != nil {
+			return apperrors.NewValidationFailed(fmt.Sprintf("Component product %d has no variants", component.ID))
+		}
+		return nil
+	}
+	if variantID == nil {
+		return apperrors.NewValidationFailed(fmt.Sprintf("variant_id is required for component product %d", component.ID))
+	}
+	for _, variant := range component.Variants {
+		if variant.ID == *variantID {
+			return nil
+		}
+	}
+	return apperrors.NewNotFound("Product variant")
+}
+
+// setBundleAvailability sets the Quantity of bundles whose components are preloaded to the
+// number of bundles their stock allows; an inactive component makes the bundle unavailable
+func setBundleAvailability(products []models.Product) {
+	for i := range products {
+		if products[i].Type != models.ProductTypeBundle {
+			continue
+		}
+		available := 0
+		for j, item := range products[i].BundleItems {
+			units := 0
+			switch {
+			case item.ComponentVariant != nil:
+				if item.ComponentVariant.IsActive {
+					units = item.ComponentVariant.Quantity / item.Quantity
+				}
+			case item.Component != nil:
+				if item.Component.IsActive {
+					units = item.Component.Quantity / item.Quantity
+				}
+			}
+			if j == 0 || units < available {
+				available = units
+			}
+		}
+		products[i].Quantity = available
+	}
+}
+
+// ExpandOrderItems replaces the order lines of bundles by one line per component, for
+// fulfillment. The bundle price is shared between the components in proportion to their
+// regular price; the unit prices are rounded to the cent.
+func (s *bundleService) ExpandOrderItems(items []models.OrderItem) ([]models.OrderItem, error) {
+	productIDs := make([]uint, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	var bundles []models.Product
+	err := preloadBundleItems(s.db.Select("id", "name", "type")).
+		Where("id IN ? AND type = ?", productIDs, models.ProductTypeBundle).
+		Find(&bundles).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(bundles) == 0 {
+		return items, nil
+	}
+	byID := make(map[uint]models.Product, len(bundles))
+	for _, bundle := range bundles {
+		byID[bundle.ID] = bundle
+	}
+
+	expanded := make([]models.OrderItem, 0, len(items))
+	for _, item := range items {
+		bundle, ok := byID[item.ProductID]
+		if !ok {
+			expanded = append(expanded, item)
+			continue
+		}
+		if len(bundle.BundleItems) == 0 {
+			return nil, apperrors.NewValidationFailed(fmt.Sprintf("Bundle '%s' has no components", bundle.Name))
+		}
+
+		regularTotal, units := 0.0, 0
+		for _, component := range bundle.BundleItems {
+			regularTotal += componentPrice(component) * float64(component.Quantity)
+			units += component.Quantity
+		}
+		bundleID := bundle.ID
+		for _, component := range bundle.BundleItems {
+			unitPrice := item.UnitPrice / float64(units)
+			if regularTotal > 0 {
+				unitPrice = item.UnitPrice * componentPrice(component) / regularTotal
+			}
+			line := models.OrderItem{
+				OrderID:         item.OrderID,
+				ProductID:       component.ComponentID,
+				VariantID:       component.ComponentVariantID,
+				Quantity:        item.Quantity * component.Quantity,
+				UnitPrice:       math.Round(unitPrice*100) / 100,
+				BundleProductID: &bundleID,
+			}
+			if component.ComponentVariant != nil {
+				line.SKU = component.ComponentVariant.SKU
+			}
+			expanded = append(expanded, line)
+		}
+	}
+	return expanded, nil
+}
+
+func componentPrice(item models.BundleItem) float64 {
+	if item.ComponentVariant != nil {
+		return item.ComponentVariant.Price
+	}
+	if item.Component != nil {
+		return item.Component.Price
+	}
+	return 0
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"api_techstore/internal/models"
+	apperrors "api_techstore/pkg/errors"
 
 	"gorm.io/gorm"
 )
@@ -22,10 +23,26 @@ func NewCartItemService(db *gorm.DB) CartItemService {
 	return &cartItemService{db: db}
 }
 
+// AddItemToCart adds a product the catalog lists to the cart, or adds to the quantity already
+// there. Inactive products and those of inactive brands or categories cannot be added.
 func (s *cartItemService) AddItemToCart(item models.CartItem) (models.CartItem, error) {
+	var listed int64
+	if err := s.db.Model(&models.Product{}).Scopes(listedProducts).Where("products.id = ?", item.ProductID).Count(&listed).Error; err != nil {
+		return models.CartItem{}, err
+	}
+	if listed == 0 {
+		return models.CartItem{}, apperrors.NewValidationFailed("Product is not available")
+	}
+
 	// Kiểm tra xem item đã tồn tại trong cart chưa
 	var existingItem models.CartItem
-	err := s.db.Where("cart_id = ? AND product_id = ?", item.CartID, item.ProductID).First(&existingItem).Error
+	query := s.db.Where("cart_id = ? AND product_id = ?", item.CartID, item.ProductID)
+	if item.VariantID != nil {
+		query = query.Where("variant_id = ?", *item.VariantID)
+	} else {
+		query = query.Where("variant_id IS NULL")
+	}
+	err := query.First(&existingItem).Error
 
 	if err == gorm.ErrRecordNotFound {
 		// Item chưa tồn tại, tạo mới
@@ -63,7 +80,7 @@ func (s *cartItemService) RemoveItemFromCart(id uint) error {
 
 func (s *cartItemService) GetItemsByCartID(cartID uint) ([]models.CartItem, error) {
 	var items []models.CartItem
-	err := s.db.Preload("Product").Where("cart_id = ?", cartID).Find(&items).Error
+	err := s.db.Preload("Product").Preload("Variant.Options").Where("cart_id = ?", cartID).Find(&items).Error
 	return items, err
 }
 
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	"api_techstore/pkg/response"
+
+	"gorm.io/gorm"
+)
+
+// catalogOrders maps the catalog sort orders to their ORDER BY
+var catalogOrders = map[string]string{
+	models.CatalogSortNewest:    "products.created_at DESC, products.id DESC",
+	models.CatalogSortPriceAsc:  "products.price, products.id",
+	models.CatalogSortPriceDesc: "products.price DESC, products.id DESC",
+	models.CatalogSortRating:    "products.rating_average DESC, products.rating_count DESC, products.id DESC",
+}
+
+// CatalogService serves the public, read-only catalog: only listed products (see listedProducts),
+// active categories and brands, in the shapes of models.Catalog*
+type CatalogService interface {
+	ListProducts(query models.CatalogQuery) (models.CatalogProductList, error)
+	GetProduct(slug string) (models.CatalogProduct, error)
+	GetCategoryTree() ([]models.CatalogCategory, error)
+	GetBrands() ([]models.CatalogBrand, error)
+	GetBrandPage(slug string) (models.CatalogBrandPage, error)
+}
+
+type catalogService struct {
+	db *gorm.DB
+}
+
+func NewCatalogService(db *gorm.DB) CatalogService {
+	return &catalogService{db: db}
+}
+
+// ListProducts pages through the listed products matching the search filters
+func (s *catalogService) ListProducts(query models.CatalogQuery) (models.CatalogProductList, error) {
+	pagination := response.Pagination{Page: query.Page, Limit: query.Limit}
+	if pagination.Page == 0 {
+		pagination.Page = 1
+	}
+	if pagination.Limit == 0 {
+		pagination.Limit = models.CatalogDefaultLimit
+	}
+	order, ok := catalogOrders[query.Sort]
+	if !ok {
+		order = catalogOrders[models.CatalogSortNewest]
+	}
+
+	// a session lets the filtered scope run both the count and the page
+	filtered := (&searchService{db: s.db}).filtered(query.SearchQuery, "").Session(&gorm.Session{})
+	var total int64
+	if err := filtered.Count(&total).Error; err != nil {
+		return models.CatalogProductList{}, err
+	}
+	pagination.TotalItems = int(total)
+	pagination.CalculateTotalPages(pagination.TotalItems)
+
+	var products []models.Product
+	err := preloadProduct(filtered).
+		Order(order).
+		Offset(pagination.CalculateOffset()).
+		Limit(pagination.Limit).
+		Find(&products).Error
+	if err != nil {
+		return models.CatalogProductList{}, err
+	}
+	setBundleAvailability(products)
+	if err := setBreadcrumbs(s.db, products); err != nil {
+		return models.CatalogProductList{}, err
+	}
+	return models.CatalogProductList{Products: catalogProducts(products), Pagination: pagination}, nil
+}
+
+// GetProduct loads a listed product by slug. Old slugs resolve like GetProductBySlug; a hidden
+// product is not found.
+func (s *catalogService) GetProduct(slug string) (models.CatalogProduct, error) {
+	var product models.Product
+	if err := findBySlug(preloadProduct(s.db).Scopes(listedProducts), &product, models.SlugEntityProduct, slug); err != nil {
+		return models.CatalogProduct{}, err
+	}
+	products := []models.Product{product}
+	setBundleAvailability(products)
+	if err := setBreadcrumbs(s.db, products); err != nil {
+		return models.CatalogProduct{}, err
+	}
+	return CatalogProduct(products[0]), nil
+}
+
+// GetCategoryTree returns the active categories as a tree; an inactive category is left out with
+// its whole subtree
+func (s *catalogService) GetCategoryTree() ([]models.CatalogCategory, error) {
+	var categories []models.Category
+	if err := s.db.Order("depth, name").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	exists := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		exists[category.ID] = true
+	}
+	// as in GetCategoryTree, a category whose parent is gone is shown at the top level
+	children := make(map[uint][]models.Category)
+	for _, category := range categories {
+		parent := uint(0)
+		if category.ParentID != nil && exists[*category.ParentID] {
+			parent = *category.ParentID
+		}
+		children[parent] = append(children[parent], category)
+	}
+
+	var build func(parent uint) []models.CatalogCategory
+	build = func(parent uint) []models.CatalogCategory {
+		nodes := []models.CatalogCategory{}
+		for _, category := range children[parent] {
+			if !category.IsActive {
+				continue
+			}
+			nodes = append(nodes, models.CatalogCategory{
+				CatalogCategoryRef: catalogCategoryRef(category),
+				Children:           build(category.ID),
+			})
+		}
+		return nodes
+	}
+	return build(0), nil
+}
+
+func (s *catalogService) GetBrands() ([]models.CatalogBrand, error) {
+	var brands []models.Brand
+	if err := s.db.Where("is_active = ?", true).Order("name").Find(&brands).Error; err != nil {
+		return nil, err
+	}
+	result := make([]models.CatalogBrand, 0, len(brands))
+	for _, brand := range brands {
+		result = append(result, catalogBrand(brand))
+	}
+	return result, nil
+}
+
+func (s *catalogService) GetBrandPage(slug string) (models.CatalogBrandPage, error) {
+	page, err := (&brandService{db: s.db}).GetBrandPage(slug)
+	if err != nil {
+		return models.CatalogBrandPage{}, err
+	}
+	return models.CatalogBrandPage{
+		Brand:        catalogBrand(page.Brand),
+		ProductCount: page.ProductCount,
+		Categories:   page.Categories,
+		TopProducts:  catalogProducts(page.TopProducts),
+	}, nil
+}
+
+func catalogProducts(products []models.Product) []models.CatalogProduct {
+	result := make([]models.CatalogProduct, 0, len(products))
+	for _, product := range products {
+		result = append(result, CatalogProduct(product))
+	}
+	return result
+}
+
+// CatalogProduct keeps the shopper-facing part of a product loaded by preloadProduct: stock
+// counts, settings and inactive variants, with their images, are left out
+func CatalogProduct(product models.Product) models.CatalogProduct {
+	result := models.CatalogProduct{
+		ID:            product.ID,
+		Name:          product.Name,
+		Slug:          product.Slug,
+		Description:   product.Description,
+		Type:          product.Type,
+		Price:         product.Price,
+		InStock:       product.Quantity > 0,
+		StockPolicy:   product.StockPolicy,
+		RatingAverage: product.RatingAverage,
+		RatingCount:   product.RatingCount,
+		Ratings:       product.RatingHistogram,
+		Category:      catalogCategoryRef(product.Category),
+		Breadcrumbs:   product.Breadcrumbs,
+	}
+	if product.StockPolicy != models.StockPolicyNormal {
+		result.AvailableAt = product.ExpectedAvailableAt
+	}
+	if product.Brand != nil {
+		brand := catalogBrand(*product.Brand)
+		result.Brand = &brand
+	}
+
+	hiddenVariants := make(map[uint]bool)
+	for _, variant := range product.Variants {
+		if !variant.IsActive {
+			hiddenVariants[variant.ID] = true
+		}
+	}
+	for _, image := range product.Images {
+		if image.VariantID != nil && hiddenVariants[*image.VariantID] {
+			continue
+		}
+		catalogImage := models.CatalogImage{
+			URL:       image.ImageURL,
+			Width:     image.Width,
+			Height:    image.Height,
+			IsMain:    image.IsMain,
+			VariantID: image.VariantID,
+		}
+		for _, variant := range image.Variants {
+			catalogImage.Sizes = append(catalogImage.Sizes, models.CatalogImageVariant{
+				Name:   variant.Name,
+				Format: variant.Format,
+				URL:    variant.ImageURL,
+				Width:  variant.Width,
+				Height: variant.Height,
+			})
+		}
+		result.Images = append(result.Images, catalogImage)
+	}
+	for _, variant := range product.Variants {
+		if hiddenVariants[variant.ID] {
+			continue
+		}
+		result.Variants = append(result.Variants, models.CatalogVariant{
+			ID:      variant.ID,
+			SKU:     variant.SKU,
+			Price:   variant.Price,
+			InStock: variant.Quantity > 0,
+			Options: variant.Options,
+		})
+	}
+	for _, value := range product.Attributes {
+		result.Specs = append(result.Specs, models.CatalogSpec{
+			Code:  value.Attribute.Code,
+			Name:  value.Attribute.Name,
+			Value: AttributeValueString(value),
+			Unit:  value.Attribute.Unit,
+		})
+	}
+	for _, item := range product.BundleItems {
+		if item.Component == nil {
+			continue
+		}
+		result.BundleItems = append(result.BundleItems, models.CatalogBundleItem{
+			Name:     item.Component.Name,
+			Slug:     item.Component.Slug,
+			Quantity: item.Quantity,
+		})
+	}
+	return result
+}
+
+func catalogCategoryRef(category models.Category) models.CatalogCategoryRef {
+	return models.CatalogCategoryRef{ID: category.ID, Name: category.Name, Slug: category.Slug}
+}
+
+func catalogBrand(brand models.Brand) models.CatalogBrand {
+	return models.CatalogBrand{
+		ID:          brand.ID,
+		Name:        brand.Name,
+		Slug:        brand.Slug,
+		Description: brand.Description,
+		LogoURL:     brand.LogoURL,
+	}
+}
@@ -2,16 +2,26 @@ package services
 
 import (
 	"api_techstore/internal/models"
+	apperrors "api_techstore/pkg/errors"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CategoryService interface {
 	GetAllCategories() ([]models.Category, error)
 	GetCategoryById(id string) (models.Category, error)
+	GetCategoryBySlug(slug string) (models.Category, error)
 	CreateCategory(category models.Category) (models.Category, error)
 	UpdateCategory(id string, category models.Category) (models.Category, error)
+	MoveCategory(id string, parentID *uint) (models.Category, error)
 	DeleteCategory(id string) error
+	ReassignAndDeleteCategory(id string, targetID uint, dropAttributes bool) error
+	GetCategoryTree() ([]models.Category, error)
+	GetCategoryProducts(id string) ([]models.Product, error)
 }
 
 type categoryService struct {
@@ -34,13 +44,63 @@ func (s *categoryService) GetCategoryById(id string) (models.Category, error) {
 	return category, err
 }
 
+// GetCategoryBySlug also resolves old slugs; the returned category then carries its current slug
+func (s *categoryService) GetCategoryBySlug(slug string) (models.Category, error) {
+	var category models.Category
+	err := findBySlug(s.db, &category, models.SlugEntityCategory, slug)
+	return category, err
+}
+
 func (s *categoryService) CreateCategory(category models.Category) (models.Category, error) {
-	err := s.db.Create(&category).Error
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		slug, err := assignSlug(tx, &models.Category{}, models.SlugEntityCategory, category.Slug, category.Name, 0)
+		if err != nil {
+			return err
+		}
+		category.Slug = slug
+
+		parentPath, depth := "/", 0
+		if category.ParentID != nil {
+			parent, err := findParentCategory(tx, *category.ParentID)
+			if err != nil {
+				return err
+			}
+			parentPath, depth = categoryPath(parent), parent.Depth+1
+		}
+
+		if err := tx.Create(&category).Error; err != nil {
+			return err
+		}
+		if err := claimSlug(tx, models.SlugEntityCategory, category.ID, "", category.Slug); err != nil {
+			return err
+		}
+		// the path contains the category's own id, so it is set once the id is known
+		category.Path = parentPath + strconv.FormatUint(uint64(category.ID), 10) + "/"
+		category.Depth = depth
+		return tx.Model(&category).Updates(map[string]interface{}{"path": category.Path, "depth": category.Depth}).Error
+	})
 	return category, err
 }
 
 func (s *categoryService) UpdateCategory(id string, category models.Category) (models.Category, error) {
-	if err := s.db.Model(&models.Category{}).Where("id = ?", id).Updates(category).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.Category
+		if err := tx.Select("id, slug").First(&existing, "id = ?", id).Error; err != nil {
+			return err
+		}
+		slug, err := changeSlug(tx, &models.Category{}, models.SlugEntityCategory, existing.ID, existing.Slug, category.Slug)
+		if err != nil {
+			return err
+		}
+		category.Slug = slug
+		// the position in the tree is only changed through MoveCategory
+		if err := tx.Model(&existing).Omit("parent_id", "path", "depth").Updates(category).Error; err != nil {
+			return err
+		}
+		// Updates skips false, so the flag is written on its own; the caller passes the current value when unchanged
+		return tx.Model(&existing).Update("is_active", category.IsActive).Error
+	})
+	if err != nil {
 		return models.Category{}, err
 	}
 	// Return the updated category
@@ -51,7 +111,347 @@ func (s *categoryService) UpdateCategory(id string, category models.Category) (m
 	return updatedCategory, nil
 }
 
+// MoveCategory re-parents a category (nil parent = top level) and rewrites the
+// path and depth of the whole subtree
+func (s *categoryService) MoveCategory(id string, parentID *uint) (models.Category, error) {
+	var category models.Category
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		categoryID, err := strconv.ParseUint(id, 10, 32)
+		if err != nil {
+			return gorm.ErrRecordNotFound
+		}
+		// lock the category and its new parent in id order, so two moves of the same pair cannot
+		// deadlock and no concurrent move changes either path between the cycle check and the update
+		var parent models.Category
+		locking := tx.Clauses(clause.Locking{Strength: "UPDATE"})
+		if parentID != nil && *parentID < uint(categoryID) {
+			if parent, err = findParentCategory(locking, *parentID); err != nil {
+				return err
+			}
+		}
+		if category, err = lockCategory(tx, id); err != nil {
+			return err
+		}
+		if parentID != nil && *parentID > category.ID {
+			if parent, err = findParentCategory(locking, *parentID); err != nil {
+				return err
+			}
+		} else if parentID != nil && *parentID == category.ID {
+			parent = category
+		}
+		oldPath, oldDepth := categoryPath(category), category.Depth
+		self := strconv.FormatUint(uint64(category.ID), 10)
+
+		newPath, depth := "/"+self+"/", 0
+		if parentID != nil {
+			parentPath := categoryPath(parent)
+			// moving a category below itself would create a cycle
+			if strings.Contains(parentPath, "/"+self+"/") {
+				return apperrors.NewValidationFailed("a category cannot be moved under itself or one of its descendants")
+			}
+			newPath, depth = parentPath+self+"/", parent.Depth+1
+		}
+
+		if err := tx.Model(&category).Updates(map[string]interface{}{
+			"parent_id": parentID,
+			"path":      newPath,
+			"depth":     depth,
+		}).Error; err != nil {
+			return err
+		}
+		category.ParentID, category.Path, category.Depth = parentID, newPath, depth
+
+		return tx.Model(&models.Category{}).
+			Where("path LIKE ? AND id <> ?", oldPath+"%", category.ID).
+			Updates(map[string]interface{}{
+				"path":  gorm.Expr("? || SUBSTRING(path FROM ?)", newPath, len(oldPath)+1),
+				"depth": gorm.Expr("depth + ?", depth-oldDepth),
+			}).Error
+	})
+	return category, err
+}
+
+// DeleteCategory refuses to delete a category that still has subcategories or products, trashed
+// ones included
 func (s *categoryService) DeleteCategory(id string) error {
-	err := s.db.Delete(&models.Category{}, "id = ?", id).Error
-	return err
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		category, err := lockCategory(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkCategoryEmpty(tx, category.ID, true); err != nil {
+			return err
+		}
+		return tx.Delete(&category).Error
+	})
+}
+
+// ReassignAndDeleteCategory moves the products of a category, trashed ones included, to another
+// category and deletes it in one transaction. Subcategories still have to be moved first, and the
+// target and the categories above it must be active or the products would leave the catalog. Spec
+// values move to the target's attribute of the same code; the others are deleted only when
+// dropAttributes is set, otherwise the move is refused with a list of them. The target's required
+// attributes are asked for on the next update of each product.
+func (s *categoryService) ReassignAndDeleteCategory(id string, targetID uint, dropAttributes bool) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		category, err := lockCategory(tx, id)
+		if err != nil {
+			return err
+		}
+		if targetID == category.ID {
+			return apperrors.NewValidationFailed("cannot reassign products to the category being deleted")
+		}
+		var target models.Category
+		if err := tx.Select("id", "path").First(&target, targetID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return apperrors.NewValidationFailed("target category not found")
+			}
+			return err
+		}
+		var hidden int64
+		err = tx.Model(&models.Category{}).Where("id IN ? AND is_active = ?", pathIDs(categoryPath(target)), false).Count(&hidden).Error
+		if err != nil {
+			return err
+		}
+		if hidden > 0 {
+			return apperrors.NewValidationFailed("target category or one of its parents is inactive, the products would be hidden")
+		}
+		if err := checkCategoryEmpty(tx, category.ID, false); err != nil {
+			return err
+		}
+
+		if err := moveAttributeValues(tx, category.ID, targetID, dropAttributes); err != nil {
+			return err
+		}
+		err = tx.Unscoped().Model(&models.Product{}).
+			Where("category_id = ?", category.ID).
+			Update("category_id", targetID).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&category).Error
+	})
+}
+
+// moveAttributeValues hands the spec values of a category's products over to the target category's
+// attributes of the same code and type; an enum value must also be allowed by the target. The
+// values left behind are deleted when drop is set and reported in a conflict otherwise.
+func moveAttributeValues(tx *gorm.DB, categoryID, targetID uint, drop bool) error {
+	var attributes, targetAttributes []models.CategoryAttribute
+	if err := orderedAttributes(tx.Where("category_id = ?", categoryID)).Find(&attributes).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("category_id = ?", targetID).Find(&targetAttributes).Error; err != nil {
+		return err
+	}
+	matches := make(map[string]models.CategoryAttribute, len(targetAttributes))
+	for _, attribute := range targetAttributes {
+		matches[attribute.Code] = attribute
+	}
+
+	var lost []string
+	for _, attribute := range attributes {
+		match, found := matches[attribute.Code]
+		switch {
+		case !found || match.Type != attribute.Type:
+		case attribute.Type == models.AttributeTypeEnum:
+			// enum values take the spelling of the target's allowed value
+			for _, allowed := range match.AllowedValues {
+				err := tx.Model(&models.ProductAttributeValue{}).
+					Where("attribute_id = ? AND LOWER(value_text) = ?", attribute.ID, strings.ToLower(allowed)).
+					Updates(map[string]interface{}{"attribute_id": match.ID, "value_text": allowed}).Error
+				if err != nil {
+					return err
+				}
+			}
+		default:
+			err := tx.Model(&models.ProductAttributeValue{}).Where("attribute_id = ?", attribute.ID).
+				Update("attribute_id", match.ID).Error
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		var left int64
+		if err := tx.Model(&models.ProductAttributeValue{}).Where("attribute_id = ?", attribute.ID).Count(&left).Error; err != nil {
+			return err
+		}
+		if left > 0 {
+			lost = append(lost, fmt.Sprintf("%s (%d)", attribute.Code, left))
+		}
+	}
+	if len(lost) > 0 && !drop {
+		appErr := apperrors.NewConflict("Spec values would be lost")
+		appErr.Details = fmt.Sprintf("spec values that do not fit the target category: %s; set drop_attributes to delete them", strings.Join(lost, ", "))
+		return appErr
+	}
+
+	// values of attributes deleted since are dropped as well
+	return tx.Where("attribute_id IN (?)", tx.Unscoped().Model(&models.CategoryAttribute{}).Select("id").Where("category_id = ?", categoryID)).
+		Delete(&models.ProductAttributeValue{}).Error
+}
+
+func lockCategory(tx *gorm.DB, id string) (models.Category, error) {
+	var category models.Category
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&category, "id = ?", id).Error
+	return category, err
+}
+
+// checkCategoryEmpty returns a conflict listing the subcategories and products of a category. Trashed
+// ones count, they can be restored; products are left out when they are about to be reassigned.
+func checkCategoryEmpty(tx *gorm.DB, categoryID uint, countProducts bool) error {
+	var children, products int64
+	if err := tx.Unscoped().Model(&models.Category{}).Where("parent_id = ?", categoryID).Count(&children).Error; err != nil {
+		return err
+	}
+	if countProducts {
+		if err := tx.Unscoped().Model(&models.Product{}).Where("category_id = ?", categoryID).Count(&products).Error; err != nil {
+			return err
+		}
+	}
+	if children > 0 || products > 0 {
+		appErr := apperrors.NewConflict("Category is not empty")
+		appErr.Details = fmt.Sprintf("category has %d subcategories and %d products", children, products)
+		return appErr
+	}
+	return nil
+}
+
+// GetCategoryTree returns the top level categories with their subcategories nested in Children
+func (s *categoryService) GetCategoryTree() ([]models.Category, error) {
+	var categories []models.Category
+	if err := s.db.Order("depth, name").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	exists := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		exists[category.ID] = true
+	}
+	// key 0 holds the roots; a category whose parent is gone is shown at the top level
+	children := make(map[uint][]models.Category)
+	for _, category := range categories {
+		parent := uint(0)
+		if category.ParentID != nil && exists[*category.ParentID] {
+			parent = *category.ParentID
+		}
+		children[parent] = append(children[parent], category)
+	}
+
+	var build func(parent uint) []models.Category
+	build = func(parent uint) []models.Category {
+		nodes := children[parent]
+		for i := range nodes {
+			nodes[i].Children = build(nodes[i].ID)
+		}
+		return nodes
+	}
+	tree := build(0)
+	if tree == nil {
+		tree = []models.Category{}
+	}
+	return tree, nil
+}
+
+// GetCategoryProducts lists the catalog products of a category and all its descendants
+func (s *categoryService) GetCategoryProducts(id string) ([]models.Product, error) {
+	category, err := s.GetCategoryById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var products []models.Product
+	if err := preloadProduct(s.db).
+		Scopes(listedProducts).
+		Where("category_id IN (?)", subtreeIDs(s.db, category)).
+		Order("id DESC").
+		Find(&products).Error; err != nil {
+		return nil, err
+	}
+	setBundleAvailability(products)
+	if err := setBreadcrumbs(s.db, products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// subtreeIDs selects the ids of a category and its descendants
+func subtreeIDs(db *gorm.DB, category models.Category) *gorm.DB {
+	return db.Model(&models.Category{}).
+		Select("id").
+		Where("id = ? OR path LIKE ?", category.ID, categoryPath(category)+"%")
+}
+
+// subtreesIDs selects the ids of the given categories and their descendants
+func subtreesIDs(db *gorm.DB, ids []uint) *gorm.DB {
+	return db.Model(&models.Category{}).
+		Select("categories.id").
+		Joins("JOIN categories AS selected ON selected.id IN ? AND selected.deleted_at IS NULL", ids).
+		Where("categories.id = selected.id OR (selected.path <> '' AND categories.path LIKE selected.path || '%')")
+}
+
+func findParentCategory(tx *gorm.DB, parentID uint) (models.Category, error) {
+	var parent models.Category
+	err := tx.First(&parent, parentID).Error
+	if err == gorm.ErrRecordNotFound {
+		return parent, apperrors.NewValidationFailed("parent category not found")
+	}
+	return parent, err
+}
+
+// categoryPath falls back to a top level path for categories created before
+// the hierarchy existed
+func categoryPath(category models.Category) string {
+	if category.Path == "" {
+		return "/" + strconv.FormatUint(uint64(category.ID), 10) + "/"
+	}
+	return category.Path
+}
+
+// pathIDs parses a materialized path into ids, root first
+func pathIDs(path string) []uint {
+	var ids []uint
+	for _, part := range strings.Split(strings.Trim(path, "/"), "/") {
+		id, err := strconv.ParseUint(part, 10, 32)
+		if err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
+
+// setBreadcrumbs fills the category trail of products whose Category is preloaded,
+// loading all ancestor categories in one query
+func setBreadcrumbs(db *gorm.DB, products []models.Product) error {
+	var ids []uint
+	for _, product := range products {
+		ids = append(ids, pathIDs(categoryPath(product.Category))...)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var ancestors []models.Category
+	if err := db.Select("id, name, slug").Where("id IN ?", uniqueIDs(ids)).Find(&ancestors).Error; err != nil {
+		return err
+	}
+	byID := make(map[uint]models.Category, len(ancestors))
+	for _, ancestor := range ancestors {
+		byID[ancestor.ID] = ancestor
+	}
+
+	for i := range products {
+		if products[i].Category.ID == 0 {
+			continue
+		}
+		breadcrumbs := []models.Breadcrumb{}
+		for _, id := range pathIDs(categoryPath(products[i].Category)) {
+			if ancestor, ok := byID[id]; ok {
+				breadcrumbs = append(breadcrumbs, models.Breadcrumb{ID: ancestor.ID, Name: ancestor.Name, Slug: ancestor.Slug})
+			}
+		}
+		products[i].Breadcrumbs = breadcrumbs
+	}
+	return nil
 }
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	apperrors "api_techstore/pkg/errors"
+	"net/http"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+const (
+	MinComparedProducts = 2
+	MaxComparedProducts = 4
+)
+
+type ComparisonService interface {
+	CompareProducts(ids []uint) (models.ProductComparison, error)
+}
+
+type comparisonService struct {
+	db *gorm.DB
+}
+
+func NewComparisonService(db *gorm.DB) ComparisonService {
+	return &comparisonService{db: db}
+}
+
+func (s *comparisonService) CompareProducts(ids []uint) (models.ProductComparison, error) {
+	ids = uniqueIDs(ids)
+	if len(ids) < MinComparedProducts || len(ids) > MaxComparedProducts {
+		return models.ProductComparison{}, apperrors.NewValidationFailed("between 2 and 4 different products can be compared")
+	}
+
+	var products []models.Product
+	if err := s.db.Preload("Category").
+		Preload("Brand").
+		Preload("Images", orderedImages).
+		Preload("Attributes", orderedAttributeValues).
+		Where("id IN ? AND is_active = ?", ids, true).
+		Find(&products).Error; err != nil {
+		return models.ProductComparison{}, err
+	}
+	if len(products) != len(ids) {
+		return models.ProductComparison{}, apperrors.NewNotFound("Product")
+	}
+
+	// keep the order the products were requested in
+	byID := make(map[uint]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+	for i, id := range ids {
+		products[i] = byID[id]
+	}
+
+	categoryID := products[0].CategoryID
+	for _, product := range products[1:] {
+		if product.CategoryID != categoryID {
+			return models.ProductComparison{}, apperrors.NewWithDetails(apperrors.ErrCodeValidationFailed,
+				"Products cannot be compared", "all products must belong to the same category", http.StatusBadRequest)
+		}
+	}
+
+	var attributes []models.CategoryAttribute
+	if err := orderedAttributes(s.db.Where("category_id = ?", categoryID)).Find(&attributes).Error; err != nil {
+		return models.ProductComparison{}, err
+	}
+
+	return BuildComparison(products, attributes), nil
+}
+
+// BuildComparison aligns products of one category into the comparison matrix: the general rows,
+// then a row per spec attribute in the given order, each flagged when the products differ
+func BuildComparison(products []models.Product, attributes []models.CategoryAttribute) models.ProductComparison {
+	category := products[0].Category
+	comparison := models.ProductComparison{
+		Category: models.ComparedCategory{ID: category.ID, Name: category.Name, Slug: category.Slug},
+		Products: make([]models.ComparedProduct, 0, len(products)),
+	}
+	price := comparisonRow(models.ComparisonGroupGeneral, "price", "Price", "")
+	brand := comparisonRow(models.ComparisonGroupGeneral, "brand", "Brand", "")
+	availability := comparisonRow(models.ComparisonGroupGeneral, "availability", "Availability", "")
+
+	for _, product := range products {
+		compared := models.ComparedProduct{
+			ID:    product.ID,
+			Name:  product.Name,
+			Slug:  product.Slug,
+			Price: product.Price,
+		}
+		if len(product.Images) > 0 {
+			compared.ImageURL = product.Images[0].ImageURL
+		}
+		comparison.Products = append(comparison.Products, compared)
+
+		price.Values = append(price.Values, stringPtr(strconv.FormatFloat(product.Price, 'f', 2, 64)))
+		if product.Brand != nil {
+			brand.Values = append(brand.Values, stringPtr(product.Brand.Name))
+		} else {
+			brand.Values = append(brand.Values, nil)
+		}
+		if product.Quantity > 0 {
+			availability.Values = append(availability.Values, stringPtr("in_stock"))
+		} else {
+			availability.Values = append(availability.Values, stringPtr("out_of_stock"))
+		}
+	}
+	comparison.Rows = append(comparison.Rows, price, brand, availability)
+
+	for _, attribute := range attributes {
+		row := comparisonRow(models.ComparisonGroupSpecs, attribute.Code, attribute.Name, attribute.Unit)
+		for _, product := range products {
+			row.Values = append(row.Values, attributeValueOf(product, attribute.ID))
+		}
+		comparison.Rows = append(comparison.Rows, row)
+	}
+
+	for i := range comparison.Rows {
+		comparison.Rows[i].Different = valuesDiffer(comparison.Rows[i].Values)
+	}
+	return comparison
+}
+
+func comparisonRow(group, code, label, unit string) models.ComparisonRow {
+	return models.ComparisonRow{Group: group, Code: code, Label: label, Unit: unit, Values: []*string{}}
+}
+
+func attributeValueOf(product models.Product, attributeID uint) *string {
+	for _, value := range product.Attributes {
+		if value.AttributeID == attributeID {
+			return stringPtr(AttributeValueString(value))
+		}
+	}
+	return nil
+}
+
+// valuesDiffer reports whether the products don't all share one value; a missing
+// value counts as different from a present one
+func valuesDiffer(values []*string) bool {
+	for _, value := range values[1:] {
+		if (value == nil) != (values[0] == nil) {
+			return true
+		}
+		if value != nil && *value != *values[0] {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
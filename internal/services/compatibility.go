@@ -0,0 +1,361 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	apperrors "api_techstore/pkg/errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type CompatibilityService interface {
+	GetCompatibleAccessories(deviceID uint) ([]models.Product, error)
+	GetCompatibleDevices(accessoryID uint) ([]models.Product, error)
+	SetCompatibleDevices(accessoryID uint, deviceIDs []uint) ([]models.Product, error)
+	GetRules() ([]models.CompatibilityRule, error)
+	CreateRule(rule models.CompatibilityRule) (models.CompatibilityRule, error)
+	DeleteRule(ruleID uint) error
+	CheckCart(items []models.CartItem) ([]models.CompatibilityWarning, error)
+}
+
+type compatibilityService struct {
+	db *gorm.DB
+}
+
+func NewCompatibilityService(db *gorm.DB) CompatibilityService {
+	return &compatibilityService{db: db}
+}
+
+// Outcome of checking whether an accessory fits a device
+const (
+	FitUnknown = iota // nothing relates the two products, or a spec is missing
+	FitYes
+	FitNo
+)
+
+// CompatibilityGraph holds what decides whether accessories fit devices: the explicit links and
+// the spec rules between their categories, with the spec values of the products involved
+type CompatibilityGraph struct {
+	Linked          map[[2]uint]bool                       // accessory, device
+	LinksToCategory map[[2]uint]bool                       // accessory, category of a linked device
+	Rules           map[[2]uint][]models.CompatibilityRule // accessory category, device category
+	Values          map[uint]map[uint]string               // product, attribute
+}
+
+// Fit tells whether an accessory fits a device. An explicit link always does. Otherwise the rules
+// between their categories decide; without rules, an accessory linked to other devices of the
+// category does not fit this one.
+func (g CompatibilityGraph) Fit(accessory, device models.Product) (int, string) {
+	if g.Linked[[2]uint{accessory.ID, device.ID}] {
+		return FitYes, ""
+	}
+	rules := g.Rules[[2]uint{accessory.CategoryID, device.CategoryID}]
+	if len(rules) == 0 {
+		if g.LinksToCategory[[2]uint{accessory.ID, device.CategoryID}] {
+			return FitNo, fmt.Sprintf("%s is not listed as compatible with %s", accessory.Name, device.Name)
+		}
+		return FitUnknown, ""
+	}
+
+	outcome := FitYes
+	for _, rule := range rules {
+		accessoryValue, ok := g.Values[accessory.ID][rule.AccessoryAttributeID]
+		if !ok {
+			outcome = FitUnknown
+			continue
+		}
+		deviceValue, ok := g.Values[device.ID][rule.DeviceAttributeID]
+		if !ok {
+			outcome = FitUnknown
+			continue
+		}
+		if !strings.EqualFold(strings.TrimSpace(accessoryValue), strings.TrimSpace(deviceValue)) {
+			return FitNo, fmt.Sprintf("%s %s of %s does not match %s %s of %s",
+				rule.AccessoryAttribute.Name, accessoryValue, accessory.Name,
+				rule.DeviceAttribute.Name, deviceValue, device.Name)
+		}
+	}
+	return outcome, ""
+}
+
+// loadRules loads the rules whose attributes still exist, by accessory and device category
+func (s *compatibilityService) loadRules() (map[[2]uint][]models.CompatibilityRule, []uint, error) {
+	var rules []models.CompatibilityRule
+	if err := s.db.Preload("AccessoryAttribute").Preload("DeviceAttribute").Order("id").Find(&rules).Error; err != nil {
+		return nil, nil, err
+	}
+	byCategories := make(map[[2]uint][]models.CompatibilityRule)
+	var attributeIDs []uint
+	for _, rule := range rules {
+		if rule.AccessoryAttribute == nil || rule.DeviceAttribute == nil {
+			continue
+		}
+		key := [2]uint{rule.AccessoryAttribute.CategoryID, rule.DeviceAttribute.CategoryID}
+		byCategories[key] = append(byCategories[key], rule)
+		attributeIDs = append(attributeIDs, rule.AccessoryAttributeID, rule.DeviceAttributeID)
+	}
+	return byCategories, attributeIDs, nil
+}
+
+// specValues loads the given attributes of products as text
+func (s *compatibilityService) specValues(productIDs, attributeIDs []uint) (map[uint]map[uint]string, error) {
+	values := make(map[uint]map[uint]string)
+	if len(productIDs) == 0 || len(attributeIDs) == 0 {
+		return values, nil
+	}
+	var rows []models.ProductAttributeValue
+	err := s.db.Where("product_id IN ? AND attribute_id IN ?", productIDs, attributeIDs).Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if values[row.ProductID] == nil {
+			values[row.ProductID] = make(map[uint]string)
+		}
+		values[row.ProductID][row.AttributeID] = AttributeValueString(row)
+	}
+	return values, nil
+}
+
+// GetCompatibleAccessories lists the active accessories that fit a device: those linked to it and
+// those whose specs match it by the rules of its category
+func (s *compatibilityService) GetCompatibleAccessories(deviceID uint) ([]models.Product, error) {
+	var device models.Product
+	if err := s.db.Select("id", "name", "category_id").First(&device, deviceID).Error; err != nil {
+		return nil, err
+	}
+
+	var ids []uint
+	if err := s.db.Model(&models.ProductCompatibility{}).Where("device_id = ?", deviceID).Pluck("accessory_id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	rules, attributeIDs, err := s.loadRules()
+	if err != nil {
+		return nil, err
+	}
+	var accessoryCategories []uint
+	for key := range rules {
+		if key[1] == device.CategoryID {
+			accessoryCategories = append(accessoryCategories, key[0])
+		}
+	}
+	if len(accessoryCategories) > 0 {
+		var candidates []models.Product
+		err := s.db.Select("id", "name", "category_id").
+			Scopes(listedProducts).
+			Where("category_id IN ? AND id <> ?", accessoryCategories, deviceID).
+			Find(&candidates).Error
+		if err != nil {
+			return nil, err
+		}
+		productIDs := []uint{deviceID}
+		for _, candidate := range candidates {
+			productIDs = append(productIDs, candidate.ID)
+		}
+		values, err := s.specValues(productIDs, attributeIDs)
+		if err != nil {
+			return nil, err
+		}
+		graph := CompatibilityGraph{Rules: rules, Values: values}
+		for _, candidate := range candidates {
+			if outcome, _ := graph.Fit(candidate, device); outcome == FitYes {
+				ids = append(ids, candidate.ID)
+			}
+		}
+	}
+
+	products := []models.Product{}
+	if len(ids) == 0 {
+		return products, nil
+	}
+	err = s.db.Preload("Brand").Preload("Images", orderedImages).
+		Scopes(listedProducts).
+		Where("id IN ?", ids).
+		Order("name, id").
+		Find(&products).Error
+	return products, err
+}
+
+// GetCompatibleDevices lists the devices an accessory is explicitly linked to
+func (s *compatibilityService) GetCompatibleDevices(accessoryID uint) ([]models.Product, error) {
+	if err := s.db.Select("id").First(&models.Product{}, accessoryID).Error; err != nil {
+		return nil, err
+	}
+	products := []models.Product{}
+	err := s.db.Preload("Brand").Preload("Images", orderedImages).
+		Joins("JOIN product_compatibilities ON product_compatibilities.device_id = products.id").
+		Where("product_compatibilities.accessory_id = ?", accessoryID).
+		Order("products.name, products.id").
+		Find(&products).Error
+	return products, err
+}
+
+// SetCompatibleDevices replaces the devices an accessory is explicitly linked to
+func (s *compatibilityService) SetCompatibleDevices(accessoryID uint, deviceIDs []uint) ([]models.Product, error) {
+	seen := make(map[uint]bool, len(deviceIDs))
+	unique := make([]uint, 0, len(deviceIDs))
+	for _, id := range deviceIDs {
+		if id == accessoryID {
+			return nil, apperrors.NewValidationFailed("A product cannot be compatible with itself")
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&models.Product{}, accessoryID).Error; err != nil {
+			return err
+		}
+		if len(unique) > 0 {
+			var found int64
+			if err := tx.Model(&models.Product{}).Where("id IN ?", unique).Count(&found).Error; err != nil {
+				return err
+			}
+			if int(found) != len(unique) {
+				return apperrors.NewNotFound("Device product")
+			}
+		}
+		if err := tx.Where("accessory_id = ?", accessoryID).Delete(&models.ProductCompatibility{}).Error; err != nil {
+			return err
+		}
+		if len(unique) == 0 {
+			return nil
+		}
+		links := make([]models.ProductCompatibility, 0, len(unique))
+		for _, id := range unique {
+			links = append(links, models.ProductCompatibility{AccessoryID: accessoryID, DeviceID: id})
+		}
+		return tx.Create(&links).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetCompatibleDevices(accessoryID)
+}
+
+func (s *compatibilityService) GetRules() ([]models.CompatibilityRule, error) {
+	var rules []models.CompatibilityRule
+	err := s.db.Preload("AccessoryAttribute").Preload("DeviceAttribute").Order("id").Find(&rules).Error
+	return rules, err
+}
+
+// CreateRule adds a spec rule; each pair of attributes is matched once
+func (s *compatibilityService) CreateRule(rule models.CompatibilityRule) (models.CompatibilityRule, error) {
+	if rule.AccessoryAttributeID == rule.DeviceAttributeID {
+		return models.CompatibilityRule{}, apperrors.NewValidationFailed("The accessory and device attributes must differ")
+	}
+	for _, id := range []uint{rule.AccessoryAttributeID, rule.DeviceAttributeID} {
+		if err := s.db.Select("id").First(&models.CategoryAttribute{}, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return models.CompatibilityRule{}, apperrors.NewNotFound(fmt.Sprintf("Attribute %d", id))
+			}
+			return models.CompatibilityRule{}, err
+		}
+	}
+	var existing int64
+	err := s.db.Model(&models.CompatibilityRule{}).
+		Where("accessory_attribute_id = ? AND device_attribute_id = ?", rule.AccessoryAttributeID, rule.DeviceAttributeID).
+		Count(&existing).Error
+	if err != nil {
+		return models.CompatibilityRule{}, err
+	}
+	if existing > 0 {
+		return models.CompatibilityRule{}, apperrors.NewAlreadyExists("Compatibility rule")
+	}
+
+	if err := s.db.Omit("AccessoryAttribute", "DeviceAttribute").Create(&rule).Error; err != nil {
+		return models.CompatibilityRule{}, err
+	}
+	err = s.db.Preload("AccessoryAttribute").Preload("DeviceAttribute").First(&rule, rule.ID).Error
+	return rule, err
+}
+
+func (s *compatibilityService) DeleteRule(ruleID uint) error {
+	result := s.db.Delete(&models.CompatibilityRule{}, ruleID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CheckCart warns about every accessory in the cart that does not fit a device in the same cart
+func (s *compatibilityService) CheckCart(items []models.CartItem) ([]models.CompatibilityWarning, error) {
+	warnings := []models.CompatibilityWarning{}
+	seen := make(map[uint]bool, len(items))
+	var productIDs []uint
+	for _, item := range items {
+		if !seen[item.ProductID] {
+			seen[item.ProductID] = true
+			productIDs = append(productIDs, item.ProductID)
+		}
+	}
+	if len(productIDs) < 2 {
+		return warnings, nil
+	}
+
+	var products []models.Product
+	if err := s.db.Select("id", "name", "category_id").Where("id IN ?", productIDs).Order("id").Find(&products).Error; err != nil {
+		return nil, err
+	}
+
+	var links []struct {
+		AccessoryID uint
+		DeviceID    uint
+		CategoryID  uint
+	}
+	err := s.db.Model(&models.ProductCompatibility{}).
+		Select("product_compatibilities.accessory_id, product_compatibilities.device_id, products.category_id").
+		Joins("JOIN products ON products.id = product_compatibilities.device_id AND products.deleted_at IS NULL").
+		Where("product_compatibilities.accessory_id IN ?", productIDs).
+		Scan(&links).Error
+	if err != nil {
+		return nil, err
+	}
+	graph := CompatibilityGraph{
+		Linked:          make(map[[2]uint]bool, len(links)),
+		LinksToCategory: make(map[[2]uint]bool, len(links)),
+	}
+	for _, link := range links {
+		graph.Linked[[2]uint{link.AccessoryID, link.DeviceID}] = true
+		graph.LinksToCategory[[2]uint{link.AccessoryID, link.CategoryID}] = true
+	}
+
+	var attributeIDs []uint
+	if graph.Rules, attributeIDs, err = s.loadRules(); err != nil {
+		return nil, err
+	}
+	if graph.Values, err = s.specValues(productIDs, attributeIDs); err != nil {
+		return nil, err
+	}
+	return graph.Warnings(products), nil
+}
+
+// Warnings checks every product against every other one, as accessory and as device, and reports
+// the pairs that do not fit
+func (g CompatibilityGraph) Warnings(products []models.Product) []models.CompatibilityWarning {
+	warnings := []models.CompatibilityWarning{}
+	for _, accessory := range products {
+		for _, device := range products {
+			if accessory.ID == device.ID {
+				continue
+			}
+			if outcome, reason := g.Fit(accessory, device); outcome == FitNo {
+				warnings = append(warnings, models.CompatibilityWarning{
+					AccessoryID:   accessory.ID,
+					AccessoryName: accessory.Name,
+					DeviceID:      device.ID,
+					DeviceName:    device.Name,
+					Reason:        reason,
+				})
+			}
+		}
+	}
+	return warnings
+}
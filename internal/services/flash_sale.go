@@ -0,0 +1,402 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	apperrors "api_techstore/pkg/errors"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// flashSaleKeyTTL keeps the counters of a sale a while after its end, for late releases
+const flashSaleKeyTTL = time.Hour
+
+type FlashSaleService interface {
+	CreateFlashSale(ctx context.Context, sale models.FlashSale) (models.FlashSale, error)
+	GetFlashSales(ctx context.Context) ([]models.FlashSale, error)
+	GetFlashSaleByID(ctx context.Context, id uint) (models.FlashSale, error)
+	CancelFlashSale(ctx context.Context, id uint) error
+	Reserve(ctx context.Context, userID uint, items []models.CartItem) ([]*FlashSaleReservation, error)
+	Release(ctx context.Context, userID uint, reservations []*FlashSaleReservation) error
+	SyncSold(ctx context.Context, reservations []*FlashSaleReservation) error
+}
+
+type flashSaleService struct {
+	db    *gorm.DB
+	redis *redis.Client
+}
+
+func NewFlashSaleService(db *gorm.DB, redisClient *redis.Client) FlashSaleService {
+	return &flashSaleService{db: db, redis: redisClient}
+}
+
+// FlashSaleReservation is sale stock held in Redis for a cart item until its order is created
+type FlashSaleReservation struct {
+	FlashSaleID uint
+	Quantity    int
+	SalePrice   float64
+}
+
+// The sale stock left is a counter and the quantities bought by each user a hash, so a
+// purchase is checked and counted by a single script without any lock.
+func flashSaleStockKey(id uint) string {
+	return fmt.Sprintf("flashsale:%d:stock", id)
+}
+
+func flashSaleBuyersKey(id uint) string {
+	return fmt.Sprintf("flashsale:%d:buyers", id)
+}
+
+// Results of reserveScript besides the stock left
+const (
+	reserveNotLoaded = -1
+	reserveSoldOut   = -2
+	reserveOverLimit = -3
+)
+
+// reserveScript takes ARGV[2] units for user ARGV[1] if the stock allows it and the user stays
+// within the limit ARGV[3]
+var reserveScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+local quantity = tonumber(ARGV[2])
+local stock = tonumber(redis.call('GET', KEYS[1]))
+if stock < quantity then
+	return -2
+end
+local bought = tonumber(redis.call('HGET', KEYS[2], ARGV[1]) or '0')
+if bought + quantity > tonumber(ARGV[3]) then
+	return -3
+end
+redis.call('DECRBY', KEYS[1], quantity)
+redis.call('HINCRBY', KEYS[2], ARGV[1], quantity)
+return stock - quantity
+`)
+
+// releaseScript gives back the units of a reservation whose order was not created
+var releaseScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('INCRBY', KEYS[1], ARGV[2])
+if tonumber(redis.call('HINCRBY', KEYS[2], ARGV[1], -tonumber(ARGV[2]))) <= 0 then
+	redis.call('HDEL', KEYS[2], ARGV[1])
+end
+return 1
+`)
+
+// loadScript sets the counters of a sale unless another request did it first. ARGV[1] is the
+// stock left, ARGV[2] the expiry in seconds, then user / quantity pairs.
+var loadScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'EX', ARGV[2])
+redis.call('DEL', KEYS[2])
+for i = 3, #ARGV, 2 do
+	redis.call('HSET', KEYS[2], ARGV[i], ARGV[i + 1])
+end
+redis.call('EXPIRE', KEYS[2], ARGV[2])
+return 1
+`)
+
+// CreateFlashSale checks the sale against the product and the other sales of the same item,
+// then loads its counters into Redis
+func (s *flashSaleService) CreateFlashSale(ctx context.Context, sale models.FlashSale) (models.FlashSale, error) {
+	if !sale.EndsAt.After(sale.StartsAt) {
+		return models.FlashSale{}, apperrors.NewValidationFailed("ends_at must be after starts_at")
+	}
+	if !sale.EndsAt.After(time.Now()) {
+		return models.FlashSale{}, apperrors.NewValidationFailed("ends_at must be in the future")
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "price", "quantity", "type", "is_active").First(&product, sale.ProductID).Error; err != nil {
+			return err
+		}
+		if !product.IsActive {
+			return apperrors.NewValidationFailed("Flash sales are not available for inactive products")
+		}
+		if product.Type == models.ProductTypeBundle {
+			return apperrors.NewValidationFailed("Flash sales are not available for bundles")
+		}
+		var variantCount int64
+		if err := tx.Model(&models.ProductVariant{}).Where("product_id = ?", product.ID).Count(&variantCount).Error; err != nil {
+			return err
+		}
+		price, stock := product.Price, product.Quantity
+		switch {
+		case sale.VariantID != nil:
+			var variant models.ProductVariant
+			if err := tx.Where("product_id = ?", product.ID).First(&variant, *sale.VariantID).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return apperrors.NewNotFound("Product variant")
+				}
+				return err
+			}
+			if !variant.IsActive {
+				return apperrors.NewValidationFailed("Flash sales are not available for inactive variants")
+			}
+			price, stock = variant.Price, variant.Quantity
+		case variantCount > 0:
+			return apperrors.NewValidationFailed("variant_id is required for a product with variants")
+		}
+		if sale.SalePrice >= price {
+			return apperrors.NewValidationFailed("sale_price must be lower than the regular price")
+		}
+		if sale.TotalQuantity > stock {
+			return apperrors.NewValidationFailed(fmt.Sprintf("total_quantity must not exceed the %d units in stock", stock))
+		}
+
+		var overlapping int64
+		query := tx.Model(&models.FlashSale{}).
+			Where("product_id = ? AND is_active = ? AND starts_at < ? AND ends_at > ?", sale.ProductID, true, sale.EndsAt, sale.StartsAt)
+		if sale.VariantID != nil {
+			query = query.Where("variant_id = ?", *sale.VariantID)
+		} else {
+			query = query.Where("variant_id IS NULL")
+		}
+		if err := query.Count(&overlapping).Error; err != nil {
+			return err
+		}
+		if overlapping > 0 {
+			return apperrors.NewConflict("Another flash sale of the product overlaps this period")
+		}
+
+		sale.SoldQuantity = 0
+		sale.IsActive = true
+		return tx.Create(&sale).Error
+	})
+	if err != nil {
+		return models.FlashSale{}, err
+	}
+
+	if err := s.load(ctx, sale); err != nil {
+		return models.FlashSale{}, err
+	}
+	sale.Remaining = sale.TotalQuantity
+	return sale, nil
+}
+
+// GetFlashSales lists the live and upcoming sales, soonest first
+func (s *flashSaleService) GetFlashSales(ctx context.Context) ([]models.FlashSale, error) {
+	var sales []models.FlashSale
+	err := s.db.Preload("Product").Preload("Product.Images", orderedImages).Preload("Variant").
+		Where("is_active = ? AND ends_at > ?", true, time.Now()).
+		Order("starts_at, id").
+		Find(&sales).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range sales {
+		if sales[i].Remaining, err = s.remaining(ctx, sales[i]); err != nil {
+			return nil, err
+		}
+	}
+	return sales, nil
+}
+
+func (s *flashSaleService) GetFlashSaleByID(ctx context.Context, id uint) (models.FlashSale, error) {
+	var sale models.FlashSale
+	err := s.db.Preload("Product").Preload("Product.Images", orderedImages).Preload("Variant").First(&sale, id).Error
+	if err != nil {
+		return models.FlashSale{}, err
+	}
+	sale.Remaining, err = s.remaining(ctx, sale)
+	return sale, err
+}
+
+// CancelFlashSale stops a sale; orders already placed keep their sale price
+func (s *flashSaleService) CancelFlashSale(ctx context.Context, id uint) error {
+	result := s.db.Model(&models.FlashSale{}).Where("id = ? AND is_active = ?", id, true).Update("is_active", false)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		var sale models.FlashSale
+		if err := s.db.Select("id").First(&sale, id).Error; err != nil {
+			return err
+		}
+		return apperrors.NewConflict("Flash sale is already cancelled")
+	}
+	// a missing stock key would be loaded again, an empty one turns buyers away
+	if err := s.redis.Set(ctx, flashSaleStockKey(id), 0, flashSaleKeyTTL).Err(); err != nil {
+		return apperrors.NewRedisError(err)
+	}
+	return nil
+}
+
+// remaining reads the stock left of a sale, from the orders when Redis does not hold it
+func (s *flashSaleService) remaining(ctx context.Context, sale models.FlashSale) (int, error) {
+	stock, err := s.redis.Get(ctx, flashSaleStockKey(sale.ID)).Int()
+	if err == redis.Nil {
+		return sale.TotalQuantity - sale.SoldQuantity, nil
+	}
+	if err != nil {
+		return 0, apperrors.NewRedisError(err)
+	}
+	return stock, nil
+}
+
+// Reserve holds the sale stock of the cart items on a live flash sale. The result is aligned
+// with items, nil for the items bought at their regular price. Nothing stays reserved when
+// an item cannot be bought: the sale is sold out or the user would exceed its limit.
+func (s *flashSaleService) Reserve(ctx context.Context, userID uint, items []models.CartItem) ([]*FlashSaleReservation, error) {
+	reservations := make([]*FlashSaleReservation, len(items))
+	if len(items) == 0 {
+		return reservations, nil
+	}
+
+	productIDs := make([]uint, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	now := time.Now()
+	var sales []models.FlashSale
+	err := s.db.Where("product_id IN ? AND is_active = ? AND starts_at <= ? AND ends_at > ?", productIDs, true, now, now).
+		Find(&sales).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(sales) == 0 {
+		return reservations, nil
+	}
+
+	for i, item := range items {
+		sale, ok := flashSaleForItem(sales, item)
+		if !ok {
+			continue
+		}
+		if err := s.reserve(ctx, sale, userID, item.Quantity); err != nil {
+			if releaseErr := s.Release(ctx, userID, reservations); releaseErr != nil {
+				return nil, releaseErr
+			}
+			return nil, err
+		}
+		reservations[i] = &FlashSaleReservation{FlashSaleID: sale.ID, Quantity: item.Quantity, SalePrice: sale.SalePrice}
+	}
+	return reservations, nil
+}
+
+func flashSaleForItem(sales []models.FlashSale, item models.CartItem) (models.FlashSale, bool) {
+	for _, sale := range sales {
+		if sale.ProductID != item.ProductID {
+			continue
+		}
+		if (sale.VariantID == nil && item.VariantID == nil) ||
+			(sale.VariantID != nil && item.VariantID != nil && *sale.VariantID == *item.VariantID) {
+			return sale, true
+		}
+	}
+	return models.FlashSale{}, false
+}
+
+func (s *flashSaleService) reserve(ctx context.Context, sale models.FlashSale, userID uint, quantity int) error {
+	keys := []string{flashSaleStockKey(sale.ID), flashSaleBuyersKey(sale.ID)}
+	user := strconv.FormatUint(uint64(userID), 10)
+	for attempt := 0; attempt < 2; attempt++ {
+		result, err := reserveScript.Run(ctx, s.redis, keys, user, quantity, sale.PerUserLimit).Int64()
+		if err != nil {
+			return apperrors.NewRedisError(err)
+		}
+		switch result {
+		case reserveNotLoaded:
+			if err := s.load(ctx, sale); err != nil {
+				return err
+			}
+			continue
+		case reserveSoldOut:
+			return apperrors.NewConflict("Flash sale is sold out")
+		case reserveOverLimit:
+			return apperrors.NewConflict(fmt.Sprintf("Flash sale is limited to %d units per customer", sale.PerUserLimit))
+		}
+		return nil
+	}
+	return apperrors.NewRedisError(fmt.Errorf("flash sale %d counters could not be loaded", sale.ID))
+}
+
+// Release gives back the stock held by reservations whose order was not created
+func (s *flashSaleService) Release(ctx context.Context, userID uint, reservations []*FlashSaleReservation) error {
+	user := strconv.FormatUint(uint64(userID), 10)
+	for _, reservation := range reservations {
+		if reservation == nil {
+			continue
+		}
+		keys := []string{flashSaleStockKey(reservation.FlashSaleID), flashSaleBuyersKey(reservation.FlashSaleID)}
+		if err := releaseScript.Run(ctx, s.redis, keys, user, reservation.Quantity).Err(); err != nil {
+			return apperrors.NewRedisError(err)
+		}
+	}
+	return nil
+}
+
+// SyncSold recounts the units sold by the sales of the reservations from their order lines
+func (s *flashSaleService) SyncSold(ctx context.Context, reservations []*FlashSaleReservation) error {
+	seen := make(map[uint]bool)
+	for _, reservation := range reservations {
+		if reservation == nil || seen[reservation.FlashSaleID] {
+			continue
+		}
+		seen[reservation.FlashSaleID] = true
+		sold := s.db.WithContext(ctx).Model(&models.OrderItem{}).
+			Select("COALESCE(SUM(quantity), 0)").
+			Where("flash_sale_id = ?", reservation.FlashSaleID)
+		err := s.db.WithContext(ctx).Model(&models.FlashSale{}).
+			Where("id = ?", reservation.FlashSaleID).
+			Update("sold_quantity", sold).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load sets the Redis counters of a sale from its order lines, e.g. after Redis lost them.
+// Every order counts, cancelled ones included, like the counters which never give stock back.
+func (s *flashSaleService) load(ctx context.Context, sale models.FlashSale) error {
+	var bought []struct {
+		UserID   uint
+		Quantity int
+	}
+	err := s.db.WithContext(ctx).Table("order_items").
+		Select("orders.user_id, SUM(order_items.quantity) AS quantity").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("order_items.flash_sale_id = ? AND order_items.deleted_at IS NULL", sale.ID).
+		Group("orders.user_id").
+		Scan(&bought).Error
+	if err != nil {
+		return err
+	}
+
+	stock := sale.TotalQuantity
+	if !sale.IsActive {
+		stock = 0
+	}
+	args := []interface{}{0, 0}
+	for _, entry := range bought {
+		stock -= entry.Quantity
+		args = append(args, entry.UserID, entry.Quantity)
+	}
+	if stock < 0 {
+		stock = 0
+	}
+	ttl := time.Until(sale.EndsAt) + flashSaleKeyTTL
+	if ttl < flashSaleKeyTTL {
+		ttl = flashSaleKeyTTL
+	}
+	args[0], args[1] = stock, int64(ttl.Seconds())
+
+	keys := []string{flashSaleStockKey(sale.ID), flashSaleBuyersKey(sale.ID)}
+	if err := loadScript.Run(ctx, s.redis, keys, args...).Err(); err != nil {
+		return apperrors.NewRedisError(err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationService interface {
+	GetNotifications(userID uint, unreadOnly bool) ([]models.Notification, error)
+	CountUnread(userID uint) (int64, error)
+	MarkAsRead(userID, notificationID uint) (models.Notification, error)
+	MarkAllAsRead(userID uint) error
+}
+
+type notificationService struct {
+	db *gorm.DB
+}
+
+func NewNotificationService(db *gorm.DB) NotificationService {
+	return &notificationService{db: db}
+}
+
+// GetNotifications lists the user's notifications, newest first
+func (s *notificationService) GetNotifications(userID uint, unreadOnly bool) ([]models.Notification, error) {
+	query := s.db.Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	var notifications []models.Notification
+	err := query.Order("created_at DESC, id DESC").Find(&notifications).Error
+	return notifications, err
+}
+
+func (s *notificationService) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := s.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// MarkAsRead marks one of the user's notifications as read; notifications of other users are not found
+func (s *notificationService) MarkAsRead(userID, notificationID uint) (models.Notification, error) {
+	var notification models.Notification
+	if err := s.db.Where("id = ? AND user_id = ?", notificationID, userID).First(&notification).Error; err != nil {
+		return models.Notification{}, err
+	}
+	if notification.ReadAt == nil {
+		now := time.Now()
+		if err := s.db.Model(&notification).Update("read_at", now).Error; err != nil {
+			return models.Notification{}, err
+		}
+		notification.ReadAt = &now
+	}
+	return notification, nil
+}
+
+func (s *notificationService) MarkAllAsRead(userID uint) error {
+	return s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error
+}
+
+// notify stores a notification, inside the caller's transaction
+func notify(tx *gorm.DB, notification models.Notification) error {
+	return tx.Create(&notification).Error
+}
@@ -2,14 +2,19 @@ package services
 
 import (
 	"api_techstore/internal/models"
+	"fmt"
+	"net/http"
+
+	apperrors "api_techstore/pkg/errors"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderService interface {
 	GetAllOrders() ([]models.Order, error)
 	GetOrderByID(id string) (models.Order, error)
-	CreateOrder(order models.Order) (models.Order, error)
+	CreateOrder(order models.Order, items []models.OrderItem, tradeInID *uint) (models.Order, error)
 	UpdateOrder(id string, order models.Order) (models.Order, error)
 	DeleteOrder(id string) error
 	GetOrdersByUserID(userID string) ([]models.Order, error)
@@ -39,8 +44,24 @@ func (s *orderService) GetOrderByID(id string) (models.Order, error) {
 	return order, nil
 }
 
-func (s *orderService) CreateOrder(order models.Order) (models.Order, error) {
-	if err := s.db.Create(&order).Error; err != nil {
+// CreateOrder saves an order with its lines in one transaction: the trade-in given by tradeInID is
+// credited to it, then the lines take their stock. Nothing is saved when a step fails.
+func (s *orderService) CreateOrder(order models.Order, items []models.OrderItem, tradeInID *uint) (models.Order, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+		if tradeInID != nil {
+			if err := applyTradeIn(tx, *tradeInID, order.UserID, order.ID); err != nil {
+				return err
+			}
+		}
+		for i := range items {
+			items[i].OrderID = order.ID
+		}
+		return addOrderItems(tx, items)
+	})
+	if err != nil {
 		return models.Order{}, err
 	}
 
@@ -52,14 +73,45 @@ func (s *orderService) CreateOrder(order models.Order) (models.Order, error) {
 	return order, nil
 }
 
+// UpdateOrder changes the status or shipping address of an order. A cancelled order gave its stock
+// back, so it cannot be reopened, and an order that has shipped cannot be cancelled since its units
+// have left the warehouse.
 func (s *orderService) UpdateOrder(id string, order models.Order) (models.Order, error) {
 	var existingOrder models.Order
-	if err := s.db.First(&existingOrder, "id = ?", id).Error; err != nil {
-		return models.Order{}, err
-	}
-
-	// Update only the fields that are provided (non-zero values)
-	if err := s.db.Model(&existingOrder).Updates(order).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&existingOrder, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if order.Status != "" && order.Status != existingOrder.Status {
+			if existingOrder.Status == "cancelled" {
+				return apperrors.NewConflict("Order is cancelled, place a new order instead")
+			}
+			if order.Status == "cancelled" && orderHasShipped(existingOrder.Status) {
+				return apperrors.NewConflict("Order has already shipped and cannot be cancelled")
+			}
+		}
+
+		// units waiting for stock hold the order until they are allocated, or the order is cancelled
+		if existingOrder.Status == models.OrderStatusAwaitingStock && order.Status != "" &&
+			order.Status != models.OrderStatusAwaitingStock && order.Status != "cancelled" {
+			var waiting int64
+			if err := tx.Model(&models.OrderItem{}).Where("order_id = ? AND backordered_quantity > 0", existingOrder.ID).Count(&waiting).Error; err != nil {
+				return err
+			}
+			if waiting > 0 {
+				return apperrors.NewConflict("Order has units waiting for stock")
+			}
+		}
+		if order.Status == "cancelled" && existingOrder.Status != "cancelled" {
+			if err := returnStock(tx, existingOrder.ID); err != nil {
+				return err
+			}
+		}
+
+		// Update only the fields that are provided (non-zero values)
+		return tx.Model(&existingOrder).Updates(order).Error
+	})
+	if err != nil {
 		return models.Order{}, err
 	}
 
@@ -71,15 +123,26 @@ func (s *orderService) UpdateOrder(id string, order models.Order) (models.Order,
 	return existingOrder, nil
 }
 
+// DeleteOrder gives back the stock the order still holds, like a cancellation, then deletes it. The
+// units of an order that has shipped are gone and stay out of stock.
 func (s *orderService) DeleteOrder(id string) error {
 	var order models.Order
-	if err := s.db.First(&order, "id = ?", id).Error; err != nil {
-		return err
-	}
-	if err := s.db.Delete(&order).Error; err != nil {
-		return err
-	}
-	return nil
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&order, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if !orderHasShipped(order.Status) {
+			if err := returnStock(tx, order.ID); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&order).Error
+	})
+}
+
+// orderHasShipped tells whether the units of an order have left the warehouse
+func orderHasShipped(status string) bool {
+	return status == "shipped" || status == "delivered"
 }
 
 func (s *orderService) GetOrdersByUserID(userID string) ([]models.Order, error) {
@@ -89,3 +152,47 @@ func (s *orderService) GetOrdersByUserID(userID string) ([]models.Order, error)
 	}
 	return orders, nil
 }
+
+// addOrderItems saves the lines of an order, taking their stock at the same time (see takeStock);
+// the caller's transaction is rolled back when one ran out. An order with units waiting for stock
+// is held as awaiting_stock.
+func addOrderItems(tx *gorm.DB, items []models.OrderItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	awaitingStock := false
+	for i := range items {
+		if err := takeStock(tx, &items[i]); err != nil {
+			return err
+		}
+		awaitingStock = awaitingStock || items[i].BackorderedQuantity > 0
+	}
+	if err := tx.Create(&items).Error; err != nil {
+		return err
+	}
+	if !awaitingStock {
+		return nil
+	}
+	return tx.Model(&models.Order{}).Where("id = ?", items[0].OrderID).Update("status", models.OrderStatusAwaitingStock).Error
+}
+
+// takeUnits takes the units of a line that must be in stock
+func takeUnits(tx *gorm.DB, item models.OrderItem, name string) error {
+	query := tx.Model(&models.Product{}).Where("id = ?", item.ProductID)
+	if item.VariantID != nil {
+		query = tx.Model(&models.ProductVariant{}).Where("id = ?", *item.VariantID)
+	}
+	result := query.Where("quantity >= ?", item.Quantity).
+		UpdateColumn("quantity", gorm.Expr("quantity - ?", item.Quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+	if item.BundleProductID != nil {
+		return apperrors.New(apperrors.ErrCodeInsufficientStock,
+			fmt.Sprintf("Not enough stock of bundle component %s", name), http.StatusConflict)
+	}
+	return apperrors.New(apperrors.ErrCodeInsufficientStock, fmt.Sprintf("Not enough stock of %s", name), http.StatusConflict)
+}
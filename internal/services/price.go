@@ -0,0 +1,251 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	"context"
+	"time"
+
+	apperrors "api_techstore/pkg/errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LowestPricePeriod is how far back the reference price of a price change is looked up
+const LowestPricePeriod = 30 * 24 * time.Hour
+
+type PriceService interface {
+	GetPriceHistory(productID uint) (models.ProductPriceHistory, error)
+	GetScheduledPrices(productID uint) ([]models.ScheduledPrice, error)
+	SchedulePrice(productID uint, schedule models.ScheduledPrice) (models.ScheduledPrice, error)
+	CancelScheduledPrice(productID, scheduleID uint) (models.ScheduledPrice, error)
+	ApplyScheduledPrices(ctx context.Context) error
+}
+
+type priceService struct {
+	db *gorm.DB
+}
+
+func NewPriceService(db *gorm.DB) PriceService {
+	return &priceService{db: db}
+}
+
+// recordPriceChange appends a change to the price history of a product; oldPrice is nil for its first price
+func recordPriceChange(tx *gorm.DB, productID uint, oldPrice *float64, newPrice float64, source string, scheduleID *uint) error {
+	return tx.Create(&models.PriceHistory{
+		ProductID:        productID,
+		OldPrice:         oldPrice,
+		NewPrice:         newPrice,
+		Source:           source,
+		ScheduledPriceID: scheduleID,
+		ChangedAt:        time.Now(),
+	}).Error
+}
+
+// GetPriceHistory returns the changes of a product, newest first, with the lowest price of the
+// LowestPricePeriod before the current price took effect
+func (s *priceService) GetPriceHistory(productID uint) (models.ProductPriceHistory, error) {
+	var product models.Product
+	if err := s.db.Select("id", "price").First(&product, productID).Error; err != nil {
+		return models.ProductPriceHistory{}, err
+	}
+	var history []models.PriceHistory
+	if err := s.db.Where("product_id = ?", productID).Order("changed_at DESC, id DESC").Find(&history).Error; err != nil {
+		return models.ProductPriceHistory{}, err
+	}
+
+	result := models.ProductPriceHistory{
+		ProductID:    productID,
+		CurrentPrice: product.Price,
+		LowestPrice:  product.Price, // when there is no earlier price to compare with
+		History:      history,
+	}
+	if len(history) == 0 {
+		result.LowestPriceSince = time.Now().Add(-LowestPricePeriod)
+		return result, nil
+	}
+
+	// the period ends when the current price took effect; the price in force at its start counts too
+	result.LowestPriceSince = history[0].ChangedAt.Add(-LowestPricePeriod)
+	for i, change := range history[1:] {
+		if i == 0 || change.NewPrice < result.LowestPrice {
+			result.LowestPrice = change.NewPrice
+		}
+		if !change.ChangedAt.After(result.LowestPriceSince) {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (s *priceService) GetScheduledPrices(productID uint) ([]models.ScheduledPrice, error) {
+	if err := s.db.Select("id").First(&models.Product{}, productID).Error; err != nil {
+		return nil, err
+	}
+	var schedules []models.ScheduledPrice
+	err := s.db.Where("product_id = ?", productID).Order("starts_at DESC, id DESC").Find(&schedules).Error
+	return schedules, err
+}
+
+// SchedulePrice plans a price change; its period must not overlap another pending or active one
+// of the product. A schedule without an end is a one-off change at its start.
+func (s *priceService) SchedulePrice(productID uint, schedule models.ScheduledPrice) (models.ScheduledPrice, error) {
+	if !schedule.StartsAt.After(time.Now()) {
+		return models.ScheduledPrice{}, apperrors.NewValidationFailed("starts_at must be in the future")
+	}
+	if schedule.EndsAt != nil && !schedule.EndsAt.After(schedule.StartsAt) {
+		return models.ScheduledPrice{}, apperrors.NewValidationFailed("ends_at must be after starts_at")
+	}
+	end := schedule.StartsAt
+	if schedule.EndsAt != nil {
+		end = *schedule.EndsAt
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&product, productID).Error; err != nil {
+			return err
+		}
+		var overlapping int64
+		err := tx.Model(&models.ScheduledPrice{}).
+			Where("product_id = ? AND status IN ?", productID, []string{models.ScheduledPricePending, models.ScheduledPriceActive}).
+			Where("starts_at < ? AND COALESCE(ends_at, starts_at) > ?", end, schedule.StartsAt).
+			Count(&overlapping).Error
+		if err != nil {
+			return err
+		}
+		if overlapping > 0 {
+			return apperrors.NewConflict("Another scheduled price of the product overlaps this period")
+		}
+
+		schedule.ProductID = productID
+		schedule.Status = models.ScheduledPricePending
+		schedule.PreviousPrice = nil
+		return tx.Create(&schedule).Error
+	})
+	return schedule, err
+}
+
+// CancelScheduledPrice drops a pending schedule, or ends an active one right away
+func (s *priceService) CancelScheduledPrice(productID, scheduleID uint) (models.ScheduledPrice, error) {
+	var schedule models.ScheduledPrice
+	if err := s.db.Where("product_id = ?", productID).First(&schedule, scheduleID).Error; err != nil {
+		return models.ScheduledPrice{}, err
+	}
+
+	var err error
+	switch schedule.Status {
+	case models.ScheduledPricePending:
+		err = s.db.Model(&models.ScheduledPrice{}).
+			Where("id = ? AND status = ?", schedule.ID, models.ScheduledPricePending).
+			Update("status", models.ScheduledPriceCancelled).Error
+	case models.ScheduledPriceActive:
+		err = s.endSchedule(s.db, schedule, models.ScheduledPriceCancelled)
+	default:
+		return models.ScheduledPrice{}, apperrors.NewConflict("Scheduled price is already " + schedule.Status)
+	}
+	if err != nil {
+		return models.ScheduledPrice{}, err
+	}
+	err = s.db.First(&schedule, schedule.ID).Error
+	return schedule, err
+}
+
+// ApplyScheduledPrices ends the schedules whose end has passed, then starts the due ones, so a
+// sale can follow another one right away
+func (s *priceService) ApplyScheduledPrices(ctx context.Context) error {
+	db := s.db.WithContext(ctx)
+	now := time.Now()
+
+	var ending []models.ScheduledPrice
+	err := db.Where("status = ? AND ends_at <= ?", models.ScheduledPriceActive, now).
+		Order("ends_at, id").Find(&ending).Error
+	if err != nil {
+		return err
+	}
+	for _, schedule := range ending {
+		if err := s.endSchedule(db, schedule, models.ScheduledPriceCompleted); err != nil {
+			return err
+		}
+	}
+
+	var starting []models.ScheduledPrice
+	err = db.Where("status = ? AND starts_at <= ?", models.ScheduledPricePending, now).
+		Order("starts_at, id").Find(&starting).Error
+	if err != nil {
+		return err
+	}
+	for _, schedule := range starting {
+		if err := s.startSchedule(db, schedule, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startSchedule applies the price of a due schedule. A schedule whose end passed before the job
+// got to it is completed without touching the price.
+func (s *priceService) startSchedule(db *gorm.DB, schedule models.ScheduledPrice, now time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "price").First(&product, schedule.ProductID).Error
+		if err == gorm.ErrRecordNotFound {
+			// the product was deleted before the schedule started
+			return tx.Model(&models.ScheduledPrice{}).Where("id = ?", schedule.ID).Update("status", models.ScheduledPriceCancelled).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		missed := schedule.EndsAt != nil && !schedule.EndsAt.After(now)
+		status := models.ScheduledPriceCompleted
+		if schedule.EndsAt != nil && !missed {
+			status = models.ScheduledPriceActive
+		}
+		updates := map[string]interface{}{"status": status}
+		if !missed {
+			updates["previous_price"] = product.Price
+		}
+		// another instance of the job may have claimed the schedule meanwhile
+		result := tx.Model(&models.ScheduledPrice{}).
+			Where("id = ? AND status = ?", schedule.ID, models.ScheduledPricePending).
+			Updates(updates)
+		if result.Error != nil || result.RowsAffected == 0 || missed {
+			return result.Error
+		}
+		return setProductPrice(tx, product, schedule.Price, schedule.ID)
+	})
+}
+
+// endSchedule restores the price replaced by an active schedule. A price changed by hand while
+// the schedule was active is kept.
+func (s *priceService) endSchedule(db *gorm.DB, schedule models.ScheduledPrice, status string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "price").First(&product, schedule.ProductID).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+		result := tx.Model(&models.ScheduledPrice{}).
+			Where("id = ? AND status = ?", schedule.ID, models.ScheduledPriceActive).
+			Update("status", status)
+		if result.Error != nil || result.RowsAffected == 0 || err != nil {
+			return result.Error
+		}
+		if schedule.PreviousPrice == nil || product.Price != schedule.Price {
+			return nil
+		}
+		return setProductPrice(tx, product, *schedule.PreviousPrice, schedule.ID)
+	})
+}
+
+func setProductPrice(tx *gorm.DB, product models.Product, price float64, scheduleID uint) error {
+	if product.Price == price {
+		return nil
+	}
+	if err := tx.Model(&models.Product{}).Where("id = ?", product.ID).Update("price", price).Error; err != nil {
+		return err
+	}
+	oldPrice := product.Price
+	return recordPriceChange(tx, product.ID, &oldPrice, price, models.PriceSourceSchedule, &scheduleID)
+}
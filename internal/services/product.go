@@ -4,11 +4,14 @@ import (
 	"api_techstore/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductService interface {
 	GetAllProducts() ([]models.Product, error)
+	GetAllProductsIncludingHidden() ([]models.Product, error)
 	GetProductById(id string) (models.Product, error)
+	GetProductBySlug(slug string) (models.Product, error)
 	CreateProduct(product models.Product) (models.Product, error)
 	UpdateProduct(id string, product models.Product) (models.Product, error)
 	DeleteProduct(id string) error
@@ -22,34 +25,162 @@ func NewProductService(db *gorm.DB) ProductService {
 	return &productService{db: db}
 }
 
+// preloadProduct loads everything a product response shows
+func preloadProduct(db *gorm.DB) *gorm.DB {
+	return db.Preload("Category").
+		Preload("Brand").
+		Preload("Images", orderedImages).
+		Preload("Images.Variants", orderedVariants).
+		Preload("Variants", orderedProductVariants).
+		Preload("Variants.Options", orderedVariantOptions).
+		Preload("Attributes", orderedAttributeValues).
+		Preload("Attributes.Attribute").
+		Scopes(preloadBundleItems)
+}
+
+// listedProducts keeps the products the catalog shows: active products whose brand is active and
+// whose category, and every category above it, is active
+func listedProducts(db *gorm.DB) *gorm.DB {
+	return db.Where("products.is_active = ?", true).
+		Where("NOT EXISTS (SELECT 1 FROM brands WHERE brands.id = products.brand_id AND brands.is_active = ?)", false).
+		Where(`NOT EXISTS (SELECT 1 FROM categories c JOIN categories hidden
+			ON hidden.is_active = ? AND hidden.deleted_at IS NULL AND (hidden.id = c.id OR (hidden.path <> '' AND c.path LIKE hidden.path || '%'))
+			WHERE c.id = products.category_id)`, false)
+}
+
+// GetAllProducts lists the products of the catalog, leaving out inactive products and those of
+// inactive brands and categories
 func (s *productService) GetAllProducts() ([]models.Product, error) {
+	return s.findProducts(preloadProduct(s.db).Scopes(listedProducts))
+}
+
+// GetAllProductsIncludingHidden lists every product, for admins
+func (s *productService) GetAllProductsIncludingHidden() ([]models.Product, error) {
+	return s.findProducts(preloadProduct(s.db))
+}
+
+func (s *productService) findProducts(query *gorm.DB) ([]models.Product, error) {
 	var products []models.Product
-	err := s.db.Preload("Category").Preload("Brand").Find(&products).Error
+	if err := query.Find(&products).Error; err != nil {
+		return nil, err
+	}
+	setBundleAvailability(products)
+	err := setBreadcrumbs(s.db, products)
 	return products, err
 }
 
 func (s *productService) GetProductById(id string) (models.Product, error) {
 	var product models.Product
-	err := s.db.Preload("Category").Preload("Brand").First(&product, "id = ?", id).Error
-	return product, err
+	if err := preloadProduct(s.db).First(&product, "id = ?", id).Error; err != nil {
+		return models.Product{}, err
+	}
+	products := []models.Product{product}
+	setBundleAvailability(products)
+	err := setBreadcrumbs(s.db, products)
+	return products[0], err
+}
+
+// GetProductBySlug also resolves old slugs; the returned product then carries its current slug
+func (s *productService) GetProductBySlug(slug string) (models.Product, error) {
+	var product models.Product
+	if err := findBySlug(preloadProduct(s.db), &product, models.SlugEntityProduct, slug); err != nil {
+		return models.Product{}, err
+	}
+	products := []models.Product{product}
+	setBundleAvailability(products)
+	err := setBreadcrumbs(s.db, products)
+	return products[0], err
 }
 
 func (s *productService) CreateProduct(product models.Product) (models.Product, error) {
-	err := s.db.Create(&product).Error
+	return s.createProduct(product, models.PriceSourceManual)
+}
+
+// createProduct creates a product and starts its price history with the given source
+func (s *productService) createProduct(product models.Product, priceSource string) (models.Product, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		slug, err := assignSlug(tx, &models.Product{}, models.SlugEntityProduct, product.Slug, product.Name, 0)
+		if err != nil {
+			return err
+		}
+		product.Slug = slug
+		if err := tx.Omit(clause.Associations).Create(&product).Error; err != nil {
+			return err
+		}
+		if err := claimSlug(tx, models.SlugEntityProduct, product.ID, "", product.Slug); err != nil {
+			return err
+		}
+		if err := recordPriceChange(tx, product.ID, nil, product.Price, priceSource, nil); err != nil {
+			return err
+		}
+		return saveProductAttributes(tx, product.ID, product.Attributes)
+	})
 	return product, err
 }
 
 func (s *productService) UpdateProduct(id string, product models.Product) (models.Product, error) {
-	if err := s.db.Model(&models.Product{}).Where("id = ?", id).Updates(product).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id, slug, price, quantity").First(&existing, "id = ?", id).Error; err != nil {
+			return err
+		}
+		// a zero quantity is left out of the update, like the other fields
+		if product.Quantity != 0 && product.Quantity != existing.Quantity {
+			if err := setStock(tx, existing.ID, nil, existing.Quantity, product.Quantity); err != nil {
+				return err
+			}
+		}
+		product.Quantity = 0
+		slug, err := changeSlug(tx, &models.Product{}, models.SlugEntityProduct, existing.ID, existing.Slug, product.Slug)
+		if err != nil {
+			return err
+		}
+		product.Slug = slug
+		if err := tx.Model(&existing).Omit(clause.Associations).Updates(product).Error; err != nil {
+			return err
+		}
+		// Updates skips nil, so the warranty is written on its own; the caller passes the current value when unchanged
+		if err := tx.Model(&existing).Update("warranty_months", product.WarrantyMonths).Error; err != nil {
+			return err
+		}
+		// a zero price is left out of the update
+		if product.Price != 0 && product.Price != existing.Price {
+			if err := recordPriceChange(tx, existing.ID, &existing.Price, product.Price, models.PriceSourceManual, nil); err != nil {
+				return err
+			}
+		}
+		// nil Attributes leaves the spec values untouched, otherwise they are replaced
+		if product.Attributes == nil {
+			return nil
+		}
+		if err := tx.Where("product_id = ?", existing.ID).Delete(&models.ProductAttributeValue{}).Error; err != nil {
+			return err
+		}
+		return saveProductAttributes(tx, existing.ID, product.Attributes)
+	})
+	if err != nil {
 		return models.Product{}, err
 	}
-	var updatedProduct models.Product
-	if err := s.db.Preload("Category").Preload("Brand").First(&updatedProduct, "id = ?", id).Error; err != nil {
-		return models.Product{}, err
-	}
-	return updatedProduct, nil
+	return s.GetProductById(id)
 }
 
 func (s *productService) DeleteProduct(id string) error {
 	return s.db.Delete(&models.Product{}, "id = ?", id).Error
 }
+
+func saveProductAttributes(tx *gorm.DB, productID uint, values []models.ProductAttributeValue) error {
+	if len(values) == 0 {
+		return nil
+	}
+	rows := make([]models.ProductAttributeValue, 0, len(values))
+	for _, value := range values {
+		rows = append(rows, models.ProductAttributeValue{
+			ProductID:   productID,
+			AttributeID: value.AttributeID,
+			ValueText:   value.ValueText,
+			ValueNumber: value.ValueNumber,
+			ValueBool:   value.ValueBool,
+		})
+	}
+	return tx.Create(&rows).Error
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	apperrors "api_techstore/pkg/errors"
+	"api_techstore/pkg/imaging"
+	"api_techstore/pkg/storage"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxProductImageSize bounds an upload read in memory; heavy or huge photos below it are not refused
+// but scaled down (see keptImage)
+const MaxProductImageSize = 25 << 20 // 25MB
+
+// allowedImageTypes maps accepted (sniffed) MIME types to the stored file extension
+var allowedImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+type ProductImageService interface {
+	GetImagesByProductID(productID uint) ([]models.ProductImage, error)
+	AddProductImage(ctx context.Context, productID uint, file *multipart.FileHeader, isMain bool) (models.ProductImage, error)
+	SetMainImage(productID, imageID uint) (models.ProductImage, error)
+	ReorderImages(productID uint, imageIDs []uint) ([]models.ProductImage, error)
+	DeleteProductImage(ctx context.Context, productID, imageID uint) error
+}
+
+type productImageService struct {
+	db      *gorm.DB
+	storage storage.Storage
+}
+
+func NewProductImageService(db *gorm.DB, store storage.Storage) ProductImageService {
+	return &productImageService{db: db, storage: store}
+}
+
+// orderedImages is used when preloading Product.Images: main image first, then by sort order
+func orderedImages(db *gorm.DB) *gorm.DB {
+	return db.Order("is_main DESC, sort_order, id")
+}
+
+func orderedVariants(db *gorm.DB) *gorm.DB {
+	return db.Order("width, format")
+}
+
+func (s *productImageService) GetImagesByProductID(productID uint) ([]models.ProductImage, error) {
+	if err := s.db.Select("id").First(&models.Product{}, productID).Error; err != nil {
+		return nil, err
+	}
+	var images []models.ProductImage
+	err := orderedImages(s.db.Preload("Variants", orderedVariants).Where("product_id = ?", productID)).Find(&images).Error
+	return images, err
+}
+
+// AddProductImage checks the upload before the product, then stores it with its variants
+func (s *productImageService) AddProductImage(ctx context.Context, productID uint, file *multipart.FileHeader, isMain bool) (models.ProductImage, error) {
+	data, contentType, err := readImageUpload(file)
+	if err != nil {
+		return models.ProductImage{}, err
+	}
+	if err := s.db.Select("id").First(&models.Product{}, productID).Error; err != nil {
+		return models.ProductImage{}, err
+	}
+
+	data, contentType, err = keptImage(data, contentType)
+	if err != nil {
+		return models.ProductImage{}, err
+	}
+	width, height, err := imaging.Dimensions(data)
+	if err != nil {
+		return models.ProductImage{}, apperrors.NewValidationFailed("Unable to decode uploaded image")
+	}
+	renditions, err := imaging.GenerateVariants(data, imaging.DefaultSizes)
+	if err != nil {
+		return models.ProductImage{}, apperrors.NewValidationFailed(err.Error())
+	}
+
+	// original and variants share one base name: products/12/<uuid>.jpg, products/12/<uuid>_thumbnail.jpg, ...
+	baseKey := fmt.Sprintf("products/%d/%s", productID, uuid.NewString())
+	var storedKeys []string
+	cleanup := func() {
+		for _, key := range storedKeys {
+			s.storage.Delete(ctx, key)
+		}
+	}
+
+	key := baseKey + allowedImageTypes[contentType]
+	url, err := s.storage.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType)
+	if err != nil {
+		return models.ProductImage{}, apperrors.NewStorageError(err)
+	}
+	storedKeys = append(storedKeys, key)
+
+	image := models.ProductImage{
+		ProductID:   productID,
+		ImageURL:    url,
+		StorageKey:  key,
+		ContentType: contentType,
+		FileSize:    int64(len(data)),
+		Width:       width,
+		Height:      height,
+	}
+
+	for _, rendition := range renditions {
+		variantKey := baseKey + "_" + rendition.Name + rendition.Extension
+		variantURL, err := s.storage.Put(ctx, variantKey, bytes.NewReader(rendition.Data), int64(len(rendition.Data)), rendition.ContentType)
+		if err != nil {
+			cleanup()
+			return models.ProductImage{}, apperrors.NewStorageError(err)
+		}
+		storedKeys = append(storedKeys, variantKey)
+
+		image.Variants = append(image.Variants, models.ProductImageVariant{
+			Name:       rendition.Name,
+			Format:     rendition.Format,
+			ImageURL:   variantURL,
+			StorageKey: variantKey,
+			Width:      rendition.Width,
+			Height:     rendition.Height,
+			FileSize:   int64(len(rendition.Data)),
+		})
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var stats struct {
+			Total   int64
+			MaxSort *int
+		}
+		if err := tx.Model(&models.ProductImage{}).
+			Select("COUNT(*) AS total, MAX(sort_order) AS max_sort").
+			Where("product_id = ?", productID).
+			Scan(&stats).Error; err != nil {
+			return err
+		}
+
+		// the first image of a product always becomes the main one
+		image.IsMain = isMain || stats.Total == 0
+		if stats.MaxSort != nil {
+			image.SortOrder = *stats.MaxSort + 1
+		}
+
+		if image.IsMain {
+			if err := unsetMainImage(tx, productID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&image).Error
+	})
+	if err != nil {
+		// don't leave orphaned files behind
+		cleanup()
+		return models.ProductImage{}, err
+	}
+	return image, nil
+}
+
+func (s *productImageService) SetMainImage(productID, imageID uint) (models.ProductImage, error) {
+	var image models.ProductImage
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("Variants", orderedVariants).Where("product_id = ?", productID).First(&image, imageID).Error; err != nil {
+			return err
+		}
+		if image.IsMain {
+			return nil
+		}
+		if err := unsetMainImage(tx, productID); err != nil {
+			return err
+		}
+		image.IsMain = true
+		return tx.Model(&image).Update("is_main", true).Error
+	})
+	return image, err
+}
+
+func (s *productImageService) ReorderImages(productID uint, imageIDs []uint) ([]models.ProductImage, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var existing []uint
+		if err := tx.Model(&models.ProductImage{}).Where("product_id = ?", productID).Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+		if !sameIDSet(existing, imageIDs) {
+			return apperrors.NewValidationFailed("image_ids must list every image of the product exactly once")
+		}
+
+		for position, id := range imageIDs {
+			if err := tx.Model(&models.ProductImage{}).Where("id = ?", id).Update("sort_order", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetImagesByProductID(productID)
+}
+
+func (s *productImageService) DeleteProductImage(ctx context.Context, productID, imageID uint) error {
+	var image models.ProductImage
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("Variants").Where("product_id = ?", productID).First(&image, imageID).Error; err != nil {
+			return err
+		}
+		// images are hard deleted: the files go away with the rows
+		if err := tx.Unscoped().Where("product_image_id = ?", image.ID).Delete(&models.ProductImageVariant{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&image).Error; err != nil {
+			return err
+		}
+		if !image.IsMain {
+			return nil
+		}
+
+		// promote the next image so the product keeps a main one
+		var next models.ProductImage
+		err := tx.Where("product_id = ?", productID).Order("sort_order, id").First(&next).Error
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&next).Update("is_main", true).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	keys := []string{image.StorageKey}
+	for _, variant := range image.Variants {
+		keys = append(keys, variant.StorageKey)
+	}
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := s.storage.Delete(ctx, key); err != nil {
+			return apperrors.NewStorageError(err)
+		}
+	}
+	return nil
+}
+
+func unsetMainImage(tx *gorm.DB, productID uint) error {
+	return tx.Model(&models.ProductImage{}).
+		Where("product_id = ? AND is_main = ?", productID, true).
+		Update("is_main", false).Error
+}
+
+// readImageUpload reads the uploaded file and checks its size and sniffed content type
+// (the client supplied Content-Type header is not trusted)
+func readImageUpload(file *multipart.FileHeader) ([]byte, string, error) {
+	if file.Size > MaxProductImageSize {
+		return nil, "", apperrors.New(apperrors.ErrCodeFileTooLarge,
+			fmt.Sprintf("Image must not exceed %dMB", MaxProductImageSize>>20), http.StatusRequestEntityTooLarge)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, "", apperrors.NewValidationFailed("Unable to read uploaded image")
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(src, MaxProductImageSize+1))
+	if err != nil {
+		return nil, "", apperrors.NewValidationFailed("Unable to read uploaded image")
+	}
+	if len(data) > MaxProductImageSize {
+		return nil, "", apperrors.New(apperrors.ErrCodeFileTooLarge,
+			fmt.Sprintf("Image must not exceed %dMB", MaxProductImageSize>>20), http.StatusRequestEntityTooLarge)
+	}
+
+	contentType := http.DetectContentType(data)
+	if _, ok := allowedImageTypes[contentType]; !ok {
+		return nil, "", apperrors.New(apperrors.ErrCodeUnsupportedFileType,
+			"Only JPEG, PNG and WebP images are allowed", http.StatusUnsupportedMediaType)
+	}
+	return data, contentType, nil
+}
+
+// keptImage is the upload as it is stored, scaled down by imaging.Downscale when it is too large
+func keptImage(data []byte, contentType string) ([]byte, string, error) {
+	original, ok, err := imaging.Downscale(data)
+	if err != nil {
+		return nil, "", apperrors.NewValidationFailed(err.Error())
+	}
+	if !ok {
+		return data, contentType, nil
+	}
+	return original.Data, original.ContentType, nil
+}
+
+func sameIDSet(existing, requested []uint) bool {
+	if len(existing) != len(requested) {
+		return false
+	}
+	seen := make(map[uint]bool, len(existing))
+	for _, id := range existing {
+		seen[id] = true
+	}
+	for _, id := range requested {
+		if !seen[id] {
+			return false
+		}
+		delete(seen, id)
+	}
+	return true
+}
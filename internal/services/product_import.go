@@ -0,0 +1,564 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	apperrors "api_techstore/pkg/errors"
+	"api_techstore/pkg/slug"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	MaxImportFileSize   = 10 << 20 // 10MB
+	MaxImportRows       = 10000
+	importProgressEvery = 50 // rows between two progress updates of the job
+	// a running job saves its progress at least this often...
+	importHeartbeat = 30 * time.Second
+	// ...and is failed by FailStaleImports when it has not for this long: the API stopped during it
+	importStaleAfter = 10 * time.Minute
+)
+
+// Spreadsheet formats of product imports and exports
+const (
+	ProductFileCSV  = "csv"
+	ProductFileXLSX = "xlsx"
+)
+
+// productFileColumns are the columns of product spreadsheets; spec attributes follow as "attr:<code>"
+var productFileColumns = []string{"slug", "name", "description", "price", "quantity", "category_slug", "brand_slug", "is_active"}
+
+var requiredImportColumns = []string{"name", "price", "quantity", "category_slug"}
+
+const attributeColumnPrefix = "attr:"
+
+// ProductImportService imports and exports the catalog as CSV or XLSX spreadsheets
+type ProductImportService interface {
+	StartImport(fileName, format string, data []byte, userID uint) (models.ImportJob, error)
+	GetImportJob(id uint) (models.ImportJob, error)
+	GetImportJobs() ([]models.ImportJob, error)
+	ExportProducts(format string) ([]byte, error)
+	FailStaleImports(ctx context.Context) error
+}
+
+type productImportService struct {
+	db         *gorm.DB
+	products   *productService
+	attributes *attributeService
+}
+
+func NewProductImportService(db *gorm.DB) ProductImportService {
+	return &productImportService{
+		db:         db,
+		products:   &productService{db: db},
+		attributes: &attributeService{db: db},
+	}
+}
+
+// importRow is a non-empty data row of an import file
+type importRow struct {
+	Number     int               // row number in the spreadsheet, the header being row 1
+	Values     map[string]string // by column name
+	Attributes map[string]string // by attribute code, nil when the file has no attribute columns
+}
+
+// StartImport checks the file layout, records the job and processes the rows in the background
+func (s *productImportService) StartImport(fileName, format string, data []byte, userID uint) (models.ImportJob, error) {
+	rows, err := parseProductFile(format, data)
+	if err != nil {
+		return models.ImportJob{}, err
+	}
+
+	job := models.ImportJob{
+		FileName:  fileName,
+		Format:    format,
+		Status:    models.ImportStatusPending,
+		TotalRows: len(rows),
+		RowErrors: []models.ImportRowError{},
+		CreatedBy: userID,
+	}
+	if err := s.db.Create(&job).Error; err != nil {
+		return models.ImportJob{}, err
+	}
+
+	go s.process(job, rows)
+	return job, nil
+}
+
+func (s *productImportService) GetImportJob(id uint) (models.ImportJob, error) {
+	var job models.ImportJob
+	err := s.db.First(&job, id).Error
+	return job, err
+}
+
+// GetImportJobs lists the latest jobs without their row reports
+func (s *productImportService) GetImportJobs() ([]models.ImportJob, error) {
+	var jobs []models.ImportJob
+	err := s.db.Omit("row_errors").Order("created_at DESC, id DESC").Limit(50).Find(&jobs).Error
+	return jobs, err
+}
+
+// FailStaleImports fails the pending and running jobs that stopped saving their progress. Their
+// rows were only held in memory by an API process that is gone, so the file has to be uploaded again.
+func (s *productImportService) FailStaleImports(ctx context.Context) error {
+	now := time.Now()
+	return s.db.WithContext(ctx).Model(&models.ImportJob{}).
+		Where("status IN ? AND updated_at < ?", []string{models.ImportStatusPending, models.ImportStatusRunning}, now.Add(-importStaleAfter)).
+		Updates(map[string]interface{}{
+			"status":      models.ImportStatusFailed,
+			"message":     "import interrupted by a restart of the API, upload the file again",
+			"finished_at": now,
+		}).Error
+}
+
+// process imports the rows one by one; a failing row is reported and does not stop the job. It
+// stops when the job was failed meanwhile by FailStaleImports.
+func (s *productImportService) process(job models.ImportJob, rows []importRow) {
+	lastSave := time.Now()
+	save := func() bool {
+		lastSave = time.Now()
+		job.UpdatedAt = lastSave
+		result := s.db.Model(&job).
+			Where("status IN ?", []string{models.ImportStatusPending, models.ImportStatusRunning}).
+			Select("status", "processed_rows", "created_count", "updated_count", "failed_count", "row_errors", "message", "started_at", "finished_at", "updated_at").
+			Updates(&job)
+		return result.Error != nil || result.RowsAffected > 0
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			now := time.Now()
+			job.Status = models.ImportStatusFailed
+			job.Message = fmt.Sprintf("import stopped unexpectedly: %v", r)
+			job.FinishedAt = &now
+			save()
+		}
+	}()
+
+	started := time.Now()
+	job.Status = models.ImportStatusRunning
+	job.StartedAt = &started
+	if !save() {
+		return
+	}
+
+	for i, row := range rows {
+		created, problems := s.importRow(row)
+		job.ProcessedRows++
+		switch {
+		case len(problems) > 0:
+			job.FailedCount++
+			job.RowErrors = append(job.RowErrors, models.ImportRowError{Row: row.Number, Slug: row.Values["slug"], Errors: problems})
+		case created:
+			job.CreatedCount++
+		default:
+			job.UpdatedCount++
+		}
+		if (i+1)%importProgressEvery == 0 || time.Since(lastSave) > importHeartbeat {
+			if !save() {
+				return
+			}
+		}
+	}
+
+	finished := time.Now()
+	job.Status = models.ImportStatusCompleted
+	job.FinishedAt = &finished
+	save()
+}
+
+// importRow creates the product of a row, or updates the product with the row's slug. It reports
+// whether a product was created, or why the row was rejected.
+func (s *productImportService) importRow(row importRow) (bool, []string) {
+	req, problems := productRequestFromRow(row)
+
+	if categorySlug := row.Values["category_slug"]; categorySlug != "" {
+		var category models.Category
+		if err := s.db.Select("id").Where("slug = ?", slug.Make(categorySlug)).First(&category).Error; err != nil {
+			problems = append(problems, fmt.Sprintf("category '%s' not found", categorySlug))
+		}
+		req.CategoryID = category.ID
+	}
+	if brandSlug := row.Values["brand_slug"]; brandSlug != "" {
+		var brand models.Brand
+		if err := s.db.Select("id").Where("slug = ?", slug.Make(brandSlug)).First(&brand).Error; err != nil {
+			problems = append(problems, fmt.Sprintf("brand '%s' not found", brandSlug))
+		}
+		req.BrandID = &brand.ID
+	}
+
+	// same rules as the create endpoint
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		problems = append(problems, validationProblems(err)...)
+	}
+	if len(problems) > 0 {
+		return false, problems
+	}
+
+	product := models.Product{
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		Quantity:    req.Quantity,
+		CategoryID:  req.CategoryID,
+		BrandID:     req.BrandID,
+		Slug:        req.Slug,
+	}
+
+	var existing models.Product
+	found := false
+	if req.Slug != "" {
+		err := findBySlug(s.db.Preload("Attributes"), &existing, models.SlugEntityProduct, slug.Make(req.Slug))
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return false, []string{err.Error()}
+		}
+		found = err == nil
+	}
+
+	if !found {
+		if req.IsActive != nil {
+			product.IsActive = *req.IsActive
+		}
+		attributes, err := s.attributes.BuildProductAttributes(product.CategoryID, nil, req.Attributes)
+		if err != nil {
+			return false, errorProblems(err)
+		}
+		product.Attributes = attributes
+		if _, err := s.products.createProduct(product, models.PriceSourceImport); err != nil {
+			return false, errorProblems(err)
+		}
+		return true, nil
+	}
+
+	product.IsActive = existing.IsActive
+	if req.IsActive != nil {
+		product.IsActive = *req.IsActive
+	}
+	// spec values are replaced when the file has attribute columns, and revalidated when the category changes
+	var attributes []models.ProductAttributeValue
+	if req.Attributes != nil || product.CategoryID != existing.CategoryID {
+		current := existing.Attributes
+		if req.Attributes != nil {
+			current = nil
+		}
+		var err error
+		attributes, err = s.attributes.BuildProductAttributes(product.CategoryID, current, req.Attributes)
+		if err != nil {
+			return false, errorProblems(err)
+		}
+		if attributes == nil {
+			attributes = []models.ProductAttributeValue{}
+		}
+	}
+	if err := s.replaceProduct(existing, product, attributes); err != nil {
+		return false, errorProblems(err)
+	}
+	return false, nil
+}
+
+// replaceProduct overwrites the product with a spreadsheet row, zero values included; nil
+// attributes leave the spec values untouched. The slug of the product is kept, and the stock is
+// set by setStock.
+func (s *productImportService) replaceProduct(existing models.Product, product models.Product, attributes []models.ProductAttributeValue) error {
+	id := existing.ID
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var current models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "quantity").First(&current, id).Error; err != nil {
+			return err
+		}
+		if err := setStock(tx, id, nil, current.Quantity, product.Quantity); err != nil {
+			return err
+		}
+		err := tx.Model(&models.Product{}).Where("id = ?", id).Omit(clause.Associations).
+			Select("name", "description", "price", "category_id", "brand_id", "is_active").
+			Updates(&product).Error
+		if err != nil {
+			return err
+		}
+		if product.Price != existing.Price {
+			if err := recordPriceChange(tx, id, &existing.Price, product.Price, models.PriceSourceImport, nil); err != nil {
+				return err
+			}
+		}
+		if attributes == nil {
+			return nil
+		}
+		if err := tx.Where("product_id = ?", id).Delete(&models.ProductAttributeValue{}).Error; err != nil {
+			return err
+		}
+		return saveProductAttributes(tx, id, attributes)
+	})
+}
+
+// productRequestFromRow converts the cells of a row into a create request
+func productRequestFromRow(row importRow) (models.ProductCreateRequest, []string) {
+	var problems []string
+	req := models.ProductCreateRequest{
+		Name:        row.Values["name"],
+		Description: row.Values["description"],
+		Slug:        row.Values["slug"],
+	}
+	if value := row.Values["price"]; value != "" {
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			problems = append(problems, "price must be a number")
+		}
+		req.Price = price
+	}
+	if value := row.Values["quantity"]; value != "" {
+		quantity, err := strconv.Atoi(value)
+		if err != nil {
+			problems = append(problems, "quantity must be a whole number")
+		}
+		req.Quantity = quantity
+	}
+	if value := row.Values["is_active"]; value != "" {
+		active, err := strconv.ParseBool(value)
+		if err != nil {
+			problems = append(problems, "is_active must be true or false")
+		}
+		req.IsActive = &active
+	}
+	if row.Attributes != nil {
+		// attribute columns hold the complete spec of the product; empty cells are not set, so a
+		// file with the columns of several categories can be imported back
+		req.Attributes = make(map[string]interface{}, len(row.Attributes))
+		for code, value := range row.Attributes {
+			if value != "" {
+				req.Attributes[code] = value
+			}
+		}
+	}
+	return req, problems
+}
+
+func validationProblems(err error) []string {
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []string{err.Error()}
+	}
+	problems := make([]string, 0, len(validationErrors))
+	for _, e := range validationErrors {
+		rule := e.Tag()
+		if e.Param() != "" {
+			rule += "=" + e.Param()
+		}
+		problems = append(problems, fmt.Sprintf("%s failed the '%s' rule", e.Field(), rule))
+	}
+	return problems
+}
+
+func errorProblems(err error) []string {
+	if appErr := apperrors.GetAppError(err); appErr != nil {
+		if appErr.Details != "" {
+			return []string{appErr.Details}
+		}
+		return []string{appErr.Message}
+	}
+	return []string{err.Error()}
+}
+
+// parseProductFile reads the header and data rows of a CSV or XLSX file (first sheet)
+func parseProductFile(format string, data []byte) ([]importRow, error) {
+	var records [][]string
+	switch format {
+	case ProductFileCSV:
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		var err error
+		if records, err = reader.ReadAll(); err != nil {
+			return nil, apperrors.NewValidationFailed("Unable to read CSV file: " + err.Error())
+		}
+	case ProductFileXLSX:
+		file, err := excelize.OpenReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, apperrors.NewValidationFailed("Unable to read XLSX file")
+		}
+		defer file.Close()
+		sheets := file.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, apperrors.NewValidationFailed("XLSX file has no sheet")
+		}
+		if records, err = file.GetRows(sheets[0]); err != nil {
+			return nil, apperrors.NewValidationFailed("Unable to read XLSX file")
+		}
+	default:
+		return nil, apperrors.NewValidationFailed("format must be csv or xlsx")
+	}
+	if len(records) == 0 {
+		return nil, apperrors.NewValidationFailed("file is empty")
+	}
+
+	header := make([]string, len(records[0]))
+	seen := make(map[string]bool, len(header))
+	known := make(map[string]bool, len(productFileColumns))
+	for _, column := range productFileColumns {
+		known[column] = true
+	}
+	hasAttributes := false
+	for i, cell := range records[0] {
+		column := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(cell, "\ufeff")))
+		switch {
+		case column == "":
+			continue
+		case strings.HasPrefix(column, attributeColumnPrefix):
+			hasAttributes = true
+		case !known[column]:
+			return nil, apperrors.NewValidationFailed(fmt.Sprintf("unknown column '%s'", cell))
+		}
+		if seen[column] {
+			return nil, apperrors.NewValidationFailed(fmt.Sprintf("duplicate column '%s'", cell))
+		}
+		seen[column] = true
+		header[i] = column
+	}
+	var missing []string
+	for _, column := range requiredImportColumns {
+		if !seen[column] {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, apperrors.NewValidationFailed("missing columns: " + strings.Join(missing, ", "))
+	}
+
+	var rows []importRow
+	for i, record := range records[1:] {
+		row := importRow{Number: i + 2, Values: make(map[string]string)}
+		if hasAttributes {
+			row.Attributes = make(map[string]string)
+		}
+		empty := true
+		for j, cell := range record {
+			if j >= len(header) || header[j] == "" {
+				continue
+			}
+			cell = strings.TrimSpace(cell)
+			if cell != "" {
+				empty = false
+			}
+			if code := strings.TrimPrefix(header[j], attributeColumnPrefix); code != header[j] {
+				row.Attributes[code] = cell
+			} else {
+				row.Values[header[j]] = cell
+			}
+		}
+		if empty {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, apperrors.NewValidationFailed("file has no product rows")
+	}
+	if len(rows) > MaxImportRows {
+		return nil, apperrors.NewValidationFailed(fmt.Sprintf("file must not have more than %d rows", MaxImportRows))
+	}
+	return rows, nil
+}
+
+// ExportProducts writes every product in the import layout, so the file can be edited and imported back
+func (s *productImportService) ExportProducts(format string) ([]byte, error) {
+	if format != ProductFileCSV && format != ProductFileXLSX {
+		return nil, apperrors.NewValidationFailed("format must be csv or xlsx")
+	}
+
+	var products []models.Product
+	err := s.db.Preload("Category").Preload("Brand").
+		Preload("Attributes", orderedAttributeValues).Preload("Attributes.Attribute").
+		Order("id").
+		Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+
+	codeSet := make(map[string]bool)
+	for _, product := range products {
+		for _, value := range product.Attributes {
+			codeSet[value.Attribute.Code] = true
+		}
+	}
+	codes := make([]string, 0, len(codeSet))
+	for code := range codeSet {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	header := append([]string{}, productFileColumns...)
+	for _, code := range codes {
+		header = append(header, attributeColumnPrefix+code)
+	}
+	records := [][]interface{}{toCells(header)}
+	for _, product := range products {
+		brandSlug := ""
+		if product.Brand != nil {
+			brandSlug = product.Brand.Slug
+		}
+		record := []interface{}{
+			product.Slug, product.Name, product.Description, product.Price, product.Quantity,
+			product.Category.Slug, brandSlug, product.IsActive,
+		}
+		values := make(map[string]string, len(product.Attributes))
+		for _, value := range product.Attributes {
+			values[value.Attribute.Code] = AttributeValueString(value)
+		}
+		for _, code := range codes {
+			record = append(record, values[code])
+		}
+		records = append(records, record)
+	}
+
+	var buf bytes.Buffer
+	if format == ProductFileCSV {
+		writer := csv.NewWriter(&buf)
+		for _, record := range records {
+			cells := make([]string, len(record))
+			for i, cell := range record {
+				cells[i] = fmt.Sprint(cell)
+			}
+			if err := writer.Write(cells); err != nil {
+				return nil, err
+			}
+		}
+		writer.Flush()
+		return buf.Bytes(), writer.Error()
+	}
+
+	file := excelize.NewFile()
+	defer file.Close()
+	sheet := "Products"
+	if err := file.SetSheetName(file.GetSheetName(0), sheet); err != nil {
+		return nil, err
+	}
+	for i, record := range records {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return nil, err
+		}
+		if err := file.SetSheetRow(sheet, cell, &record); err != nil {
+			return nil, err
+		}
+	}
+	if err := file.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func toCells(values []string) []interface{} {
+	cells := make([]interface{}, len(values))
+	for i, value := range values {
+		cells[i] = value
+	}
+	return cells
+}
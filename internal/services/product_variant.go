@@ -0,0 +1,240 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	apperrors "api_techstore/pkg/errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProductVariantService interface {
+	GetVariantsByProductID(productID uint) ([]models.ProductVariant, error)
+	GetVariantByID(productID, variantID uint) (models.ProductVariant, error)
+	CreateVariant(productID uint, variant models.ProductVariant, imageIDs []uint) (models.ProductVariant, error)
+	UpdateVariant(productID, variantID uint, variant models.ProductVariant, imageIDs *[]uint) (models.ProductVariant, error)
+	DeleteVariant(productID, variantID uint) error
+}
+
+type productVariantService struct {
+	db *gorm.DB
+}
+
+func NewProductVariantService(db *gorm.DB) ProductVariantService {
+	return &productVariantService{db: db}
+}
+
+// orderedProductVariants is used when preloading Product.Variants
+func orderedProductVariants(db *gorm.DB) *gorm.DB {
+	return db.Order("price, id")
+}
+
+func orderedVariantOptions(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
+
+// VariantOptionKey builds the canonical form of an option combination
+// ("color=silver;storage=512gb"), used to keep combinations unique per product.
+// Names and values are trimmed and compared case-insensitively, order does not matter.
+func VariantOptionKey(options []models.ProductVariantOption) string {
+	pairs := make([]string, 0, len(options))
+	for _, option := range options {
+		pairs = append(pairs, normalizeOption(option.Name)+"="+normalizeOption(option.Value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ";")
+}
+
+func normalizeOption(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+func (s *productVariantService) preloaded(db *gorm.DB) *gorm.DB {
+	return db.Preload("Options", orderedVariantOptions).
+		Preload("Images", orderedImages).
+		Preload("Images.Variants", orderedVariants)
+}
+
+func (s *productVariantService) GetVariantsByProductID(productID uint) ([]models.ProductVariant, error) {
+	if err := s.db.Select("id").First(&models.Product{}, productID).Error; err != nil {
+		return nil, err
+	}
+	var variants []models.ProductVariant
+	err := orderedProductVariants(s.preloaded(s.db).Where("product_id = ?", productID)).Find(&variants).Error
+	return variants, err
+}
+
+func (s *productVariantService) GetVariantByID(productID, variantID uint) (models.ProductVariant, error) {
+	var variant models.ProductVariant
+	err := s.preloaded(s.db).Where("product_id = ?", productID).First(&variant, variantID).Error
+	return variant, err
+}
+
+func (s *productVariantService) CreateVariant(productID uint, variant models.ProductVariant, imageIDs []uint) (models.ProductVariant, error) {
+	variant.ProductID = productID
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&models.Product{}, productID).Error; err != nil {
+			return err
+		}
+		if err := validateVariant(tx, &variant, 0); err != nil {
+			return err
+		}
+		if err := tx.Create(&variant).Error; err != nil {
+			return err
+		}
+		return assignVariantImages(tx, productID, variant.ID, imageIDs)
+	})
+	if err != nil {
+		return models.ProductVariant{}, err
+	}
+	return s.GetVariantByID(productID, variant.ID)
+}
+
+func (s *productVariantService) UpdateVariant(productID, variantID uint, variant models.ProductVariant, imageIDs *[]uint) (models.ProductVariant, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.ProductVariant
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Options").
+			Where("product_id = ?", productID).
+			First(&existing, variantID).Error
+		if err != nil {
+			return err
+		}
+
+		variant.ProductID = productID
+		if err := validateVariant(tx, &variant, variantID); err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"sku":        variant.SKU,
+			"option_key": variant.OptionKey,
+			"price":      variant.Price,
+			"is_active":  variant.IsActive,
+		}
+		if err := tx.Model(&existing).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := setStock(tx, productID, &variantID, existing.Quantity, variant.Quantity); err != nil {
+			return err
+		}
+
+		if variant.OptionKey != existing.OptionKey {
+			if err := tx.Where("variant_id = ?", variantID).Delete(&models.ProductVariantOption{}).Error; err != nil {
+				return err
+			}
+			options := make([]models.ProductVariantOption, 0, len(variant.Options))
+			for _, option := range variant.Options {
+				options = append(options, models.ProductVariantOption{VariantID: variantID, Name: option.Name, Value: option.Value})
+			}
+			if err := tx.Create(&options).Error; err != nil {
+				return err
+			}
+		}
+
+		if imageIDs == nil {
+			return nil
+		}
+		if err := tx.Model(&models.ProductImage{}).Where("variant_id = ?", variantID).Update("variant_id", nil).Error; err != nil {
+			return err
+		}
+		return assignVariantImages(tx, productID, variantID, *imageIDs)
+	})
+	if err != nil {
+		return models.ProductVariant{}, err
+	}
+	return s.GetVariantByID(productID, variantID)
+}
+
+func (s *productVariantService) DeleteVariant(productID, variantID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var variant models.ProductVariant
+		if err := tx.Where("product_id = ?", productID).First(&variant, variantID).Error; err != nil {
+			return err
+		}
+		// the images stay with the product
+		if err := tx.Model(&models.ProductImage{}).Where("variant_id = ?", variantID).Update("variant_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("variant_id = ?", variantID).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&variant).Error
+	})
+}
+
+// validateVariant checks the option set of a variant against the product's other
+// variants: option names must not repeat, every variant of a product uses the same
+// option names, the combination must be unique and so must the SKU
+func validateVariant(tx *gorm.DB, variant *models.ProductVariant, excludeID uint) error {
+	names := make(map[string]bool, len(variant.Options))
+	for _, option := range variant.Options {
+		name := normalizeOption(option.Name)
+		if name == "" || normalizeOption(option.Value) == "" {
+			return apperrors.NewValidationFailed("option name and value must not be empty")
+		}
+		if names[name] {
+			return apperrors.NewValidationFailed("option '" + option.Name + "' is specified more than once")
+		}
+		names[name] = true
+	}
+	variant.OptionKey = VariantOptionKey(variant.Options)
+
+	var siblings []models.ProductVariant
+	if err := tx.Preload("Options").
+		Where("product_id = ? AND id <> ?", variant.ProductID, excludeID).
+		Find(&siblings).Error; err != nil {
+		return err
+	}
+	for _, sibling := range siblings {
+		if sibling.OptionKey == variant.OptionKey {
+			return apperrors.New(apperrors.ErrCodeAlreadyExists,
+				"A variant with the same options already exists for this product", http.StatusConflict)
+		}
+		if !sameOptionNames(sibling.Options, names) {
+			return apperrors.NewValidationFailed("all variants of a product must define the same options")
+		}
+	}
+
+	var count int64
+	if err := tx.Model(&models.ProductVariant{}).
+		Where("sku = ? AND id <> ?", variant.SKU, excludeID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return apperrors.NewAlreadyExists("Variant with this SKU")
+	}
+	return nil
+}
+
+func sameOptionNames(options []models.ProductVariantOption, names map[string]bool) bool {
+	if len(options) != len(names) {
+		return false
+	}
+	for _, option := range options {
+		if !names[normalizeOption(option.Name)] {
+			return false
+		}
+	}
+	return true
+}
+
+// assignVariantImages links existing product images to a variant
+func assignVariantImages(tx *gorm.DB, productID, variantID uint, imageIDs []uint) error {
+	if len(imageIDs) == 0 {
+		return nil
+	}
+	result := tx.Model(&models.ProductImage{}).
+		Where("id IN ? AND product_id = ?", imageIDs, productID).
+		Update("variant_id", variantID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected != int64(len(imageIDs)) {
+		return apperrors.NewValidationFailed("image_ids must reference images of the same product")
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	apperrors "api_techstore/pkg/errors"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+const (
+	MaxRecentlyViewed   = 50                  // products kept in a viewer's history
+	RecentlyViewedTTL   = 30 * 24 * time.Hour // history of inactive viewers expires
+	MaxTrendingDays     = 30                  // daily view counts are kept this long
+	DefaultTrendingDays = 7
+	trendingCacheTTL    = 5 * time.Minute
+)
+
+type ProductViewService interface {
+	RecordView(ctx context.Context, viewer string, productID uint) error
+	GetRecentlyViewed(ctx context.Context, viewer string, limit int) ([]models.Product, error)
+	ClearRecentlyViewed(ctx context.Context, viewer string) error
+	GetTrending(ctx context.Context, days, limit int) ([]models.TrendingProduct, error)
+}
+
+type productViewService struct {
+	db    *gorm.DB
+	redis *redis.Client
+}
+
+func NewProductViewService(db *gorm.DB, redisClient *redis.Client) ProductViewService {
+	return &productViewService{db: db, redis: redisClient}
+}
+
+// A viewer's history is a sorted set of product ids scored by view time, so viewing a product
+// again moves it to the front. Views are also counted per day for the trending listing.
+func recentlyViewedKey(viewer string) string {
+	return "views:recent:" + viewer
+}
+
+func dailyViewsKey(day time.Time) string {
+	return "views:daily:" + day.UTC().Format("20060102")
+}
+
+// RecordView adds the product to the viewer's history and counts the view. viewer identifies a
+// user ("user:12") or a guest session ("session:<id>"); views without a viewer are only counted.
+func (s *productViewService) RecordView(ctx context.Context, viewer string, productID uint) error {
+	now := time.Now()
+	member := strconv.FormatUint(uint64(productID), 10)
+	_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if viewer != "" {
+			key := recentlyViewedKey(viewer)
+			pipe.ZAdd(ctx, key, &redis.Z{Score: float64(now.UnixNano()), Member: member})
+			pipe.ZRemRangeByRank(ctx, key, 0, -MaxRecentlyViewed-1)
+			pipe.Expire(ctx, key, RecentlyViewedTTL)
+		}
+		daily := dailyViewsKey(now)
+		pipe.ZIncrBy(ctx, daily, 1, member)
+		pipe.Expire(ctx, daily, (MaxTrendingDays+1)*24*time.Hour)
+		return nil
+	})
+	if err != nil {
+		return apperrors.NewRedisError(err)
+	}
+	return nil
+}
+
+// GetRecentlyViewed returns the viewer's most recently viewed products that are still active, latest first
+func (s *productViewService) GetRecentlyViewed(ctx context.Context, viewer string, limit int) ([]models.Product, error) {
+	if limit <= 0 || limit > MaxRecentlyViewed {
+		limit = MaxRecentlyViewed
+	}
+	members, err := s.redis.ZRevRange(ctx, recentlyViewedKey(viewer), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, apperrors.NewRedisError(err)
+	}
+	ids := parseProductIDs(members)
+
+	products, err := s.activeProductsByID(ids)
+	if err != nil {
+		return nil, err
+	}
+	recent := make([]models.Product, 0, len(ids))
+	for _, id := range ids {
+		if product, ok := products[id]; ok {
+			recent = append(recent, product)
+		}
+	}
+	return recent, nil
+}
+
+func (s *productViewService) ClearRecentlyViewed(ctx context.Context, viewer string) error {
+	if err := s.redis.Del(ctx, recentlyViewedKey(viewer)).Err(); err != nil {
+		return apperrors.NewRedisError(err)
+	}
+	return nil
+}
+
+// GetTrending returns the most viewed active products of the last days. The summed counts are
+// cached for a few minutes.
+func (s *productViewService) GetTrending(ctx context.Context, days, limit int) ([]models.TrendingProduct, error) {
+	if days <= 0 {
+		days = DefaultTrendingDays
+	}
+	if days > MaxTrendingDays {
+		return nil, apperrors.NewValidationFailed(fmt.Sprintf("days must not exceed %d", MaxTrendingDays))
+	}
+	limit = recommendationLimit(limit)
+
+	key := fmt.Sprintf("views:trending:%d", days)
+	exists, err := s.redis.Exists(ctx, key).Result()
+	if err != nil {
+		return nil, apperrors.NewRedisError(err)
+	}
+	if exists == 0 {
+		now := time.Now()
+		dailyKeys := make([]string, days)
+		for i := range dailyKeys {
+			dailyKeys[i] = dailyViewsKey(now.AddDate(0, 0, -i))
+		}
+		_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZUnionStore(ctx, key, &redis.ZStore{Keys: dailyKeys})
+			pipe.Expire(ctx, key, trendingCacheTTL)
+			return nil
+		})
+		if err != nil {
+			return nil, apperrors.NewRedisError(err)
+		}
+	}
+
+	// read a few extra entries since inactive or deleted products are skipped
+	scores, err := s.redis.ZRevRangeWithScores(ctx, key, 0, int64(2*limit-1)).Result()
+	if err != nil {
+		return nil, apperrors.NewRedisError(err)
+	}
+	members := make([]string, len(scores))
+	views := make(map[string]int64, len(scores))
+	for i, score := range scores {
+		members[i] = fmt.Sprint(score.Member)
+		views[members[i]] = int64(score.Score)
+	}
+	ids := parseProductIDs(members)
+
+	products, err := s.activeProductsByID(ids)
+	if err != nil {
+		return nil, err
+	}
+	trending := make([]models.TrendingProduct, 0, limit)
+	for _, id := range ids {
+		product, ok := products[id]
+		if !ok {
+			continue
+		}
+		count := views[strconv.FormatUint(uint64(id), 10)]
+		trending = append(trending, models.TrendingProduct{Product: product, Views: count})
+		if len(trending) == limit {
+			break
+		}
+	}
+	return trending, nil
+}
+
+func (s *productViewService) activeProductsByID(ids []uint) (map[uint]models.Product, error) {
+	byID := make(map[uint]models.Product, len(ids))
+	if len(ids) == 0 {
+		return byID, nil
+	}
+	var products []models.Product
+	err := s.db.Preload("Brand").Preload("Images", orderedImages).
+		Scopes(listedProducts).
+		Where("id IN ?", ids).
+		Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+	return byID, nil
+}
+
+// parseProductIDs converts sorted set members back to ids; members are always written by RecordView
+func parseProductIDs(members []string) []uint {
+	ids := make([]uint, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseUint(member, 10, 32)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids
+}
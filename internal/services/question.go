@@ -0,0 +1,290 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	apperrors "api_techstore/pkg/errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type QuestionService interface {
+	GetProductQuestions(productID uint) ([]models.ProductQuestion, error)
+	GetQuestions(status string) ([]models.ProductQuestion, error)
+	CreateQuestion(productID, userID uint, content string) (models.ProductQuestion, error)
+	ModerateQuestion(questionID uint, status, note string) (models.ProductQuestion, error)
+	DeleteQuestion(questionID uint) error
+	GetAnswers(status string) ([]models.ProductAnswer, error)
+	CreateAnswer(productID, questionID, userID uint, content string, isStaff bool) (models.ProductAnswer, error)
+	ModerateAnswer(answerID uint, status, note string) (models.ProductAnswer, error)
+	DeleteAnswer(answerID uint) error
+}
+
+type questionService struct {
+	db *gorm.DB
+}
+
+func NewQuestionService(db *gorm.DB) QuestionService {
+	return &questionService{db: db}
+}
+
+// orderedAnswers puts staff answers first, then verified buyers, then the rest oldest first
+func orderedAnswers(db *gorm.DB) *gorm.DB {
+	return db.Order("CASE author_type WHEN 'staff' THEN 0 WHEN 'verified_buyer' THEN 1 ELSE 2 END, created_at, id")
+}
+
+func publishedAnswers(db *gorm.DB) *gorm.DB {
+	return orderedAnswers(db).Where("status = ?", models.QAStatusApproved)
+}
+
+// preloadQuestion loads a question with every answer, for admins and the author
+func (s *questionService) preloadQuestion(db *gorm.DB) *gorm.DB {
+	return db.Preload("User", reviewers).Preload("Answers", orderedAnswers).Preload("Answers.User", reviewers)
+}
+
+// GetProductQuestions lists the approved questions of a product with their approved answers, newest first
+func (s *questionService) GetProductQuestions(productID uint) ([]models.ProductQuestion, error) {
+	if err := s.db.Select("id").First(&models.Product{}, productID).Error; err != nil {
+		return nil, err
+	}
+	var questions []models.ProductQuestion
+	err := s.db.Preload("User", reviewers).Preload("Answers", publishedAnswers).Preload("Answers.User", reviewers).
+		Where("product_id = ? AND status = ?", productID, models.QAStatusApproved).
+		Order("created_at DESC, id DESC").
+		Find(&questions).Error
+	if err != nil {
+		return nil, err
+	}
+	setQuestionNames(questions)
+	return questions, nil
+}
+
+// GetQuestions is the moderation queue: questions in the given status (or all) with every answer, oldest first
+func (s *questionService) GetQuestions(status string) ([]models.ProductQuestion, error) {
+	query := s.preloadQuestion(s.db)
+	if status != "" {
+		if !isQAStatus(status) {
+			return nil, apperrors.NewValidationFailed("status must be one of pending, approved, rejected")
+		}
+		query = query.Where("status = ?", status)
+	}
+	var questions []models.ProductQuestion
+	if err := query.Order("created_at, id").Find(&questions).Error; err != nil {
+		return nil, err
+	}
+	setQuestionNames(questions)
+	return questions, nil
+}
+
+// CreateQuestion stores a question, published once approved by an admin
+func (s *questionService) CreateQuestion(productID, userID uint, content string) (models.ProductQuestion, error) {
+	if err := s.db.Select("id").First(&models.Product{}, productID).Error; err != nil {
+		return models.ProductQuestion{}, err
+	}
+	question := models.ProductQuestion{
+		ProductID: productID,
+		UserID:    userID,
+		Content:   content,
+		Status:    models.QAStatusPending,
+	}
+	if err := s.db.Create(&question).Error; err != nil {
+		return models.ProductQuestion{}, err
+	}
+	return s.getQuestion(question.ID)
+}
+
+func (s *questionService) ModerateQuestion(questionID uint, status, note string) (models.ProductQuestion, error) {
+	var question models.ProductQuestion
+	if err := s.db.First(&question, questionID).Error; err != nil {
+		return models.ProductQuestion{}, err
+	}
+	err := s.db.Model(&question).Updates(map[string]interface{}{
+		"status":          status,
+		"moderation_note": note,
+	}).Error
+	if err != nil {
+		return models.ProductQuestion{}, err
+	}
+	return s.getQuestion(questionID)
+}
+
+// DeleteQuestion removes a question together with its answers
+func (s *questionService) DeleteQuestion(questionID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&models.ProductQuestion{}, questionID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("question_id = ?", questionID).Delete(&models.ProductAnswer{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.ProductQuestion{}, questionID).Error
+	})
+}
+
+// GetAnswers is the answer moderation queue, oldest first
+func (s *questionService) GetAnswers(status string) ([]models.ProductAnswer, error) {
+	query := s.db.Preload("User", reviewers)
+	if status != "" {
+		if !isQAStatus(status) {
+			return nil, apperrors.NewValidationFailed("status must be one of pending, approved, rejected")
+		}
+		query = query.Where("status = ?", status)
+	}
+	var answers []models.ProductAnswer
+	if err := query.Order("created_at, id").Find(&answers).Error; err != nil {
+		return nil, err
+	}
+	for i := range answers {
+		setAnswerName(&answers[i])
+	}
+	return answers, nil
+}
+
+// CreateAnswer answers an approved question. Staff answers are published right away and notify
+// the asker; customer answers are marked as from a verified buyer when the product was delivered
+// to them, and wait for moderation.
+func (s *questionService) CreateAnswer(productID, questionID, userID uint, content string, isStaff bool) (models.ProductAnswer, error) {
+	var answer models.ProductAnswer
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var question models.ProductQuestion
+		err := tx.Where("id = ? AND product_id = ? AND status = ?", questionID, productID, models.QAStatusApproved).
+			First(&question).Error
+		if err != nil {
+			return err
+		}
+
+		answer = models.ProductAnswer{
+			QuestionID: questionID,
+			UserID:     userID,
+			Content:    content,
+			AuthorType: models.AnswerAuthorCustomer,
+			Status:     models.QAStatusPending,
+		}
+		if isStaff {
+			answer.AuthorType = models.AnswerAuthorStaff
+			answer.Status = models.QAStatusApproved
+		} else {
+			_, err := findDeliveredOrderItem(tx, productID, userID)
+			if err == nil {
+				answer.AuthorType = models.AnswerAuthorVerifiedBuyer
+			} else if err != gorm.ErrRecordNotFound {
+				return err
+			}
+		}
+
+		if err := tx.Create(&answer).Error; err != nil {
+			return err
+		}
+		if answer.Status == models.QAStatusApproved {
+			return notifyQuestionAnswered(tx, question, answer)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.ProductAnswer{}, err
+	}
+	return s.getAnswer(answer.ID)
+}
+
+// ModerateAnswer changes the moderation state of an answer; the asker is notified when it gets published
+func (s *questionService) ModerateAnswer(answerID uint, status, note string) (models.ProductAnswer, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var answer models.ProductAnswer
+		if err := tx.First(&answer, answerID).Error; err != nil {
+			return err
+		}
+		published := answer.Status != models.QAStatusApproved && status == models.QAStatusApproved
+
+		err := tx.Model(&answer).Updates(map[string]interface{}{
+			"status":          status,
+			"moderation_note": note,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if !published {
+			return nil
+		}
+
+		var question models.ProductQuestion
+		if err := tx.First(&question, answer.QuestionID).Error; err != nil {
+			return err
+		}
+		return notifyQuestionAnswered(tx, question, answer)
+	})
+	if err != nil {
+		return models.ProductAnswer{}, err
+	}
+	return s.getAnswer(answerID)
+}
+
+func (s *questionService) DeleteAnswer(answerID uint) error {
+	result := s.db.Delete(&models.ProductAnswer{}, answerID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *questionService) getQuestion(questionID uint) (models.ProductQuestion, error) {
+	var question models.ProductQuestion
+	if err := s.preloadQuestion(s.db).First(&question, questionID).Error; err != nil {
+		return models.ProductQuestion{}, err
+	}
+	questions := []models.ProductQuestion{question}
+	setQuestionNames(questions)
+	return questions[0], nil
+}
+
+func (s *questionService) getAnswer(answerID uint) (models.ProductAnswer, error) {
+	var answer models.ProductAnswer
+	if err := s.db.Preload("User", reviewers).First(&answer, answerID).Error; err != nil {
+		return models.ProductAnswer{}, err
+	}
+	setAnswerName(&answer)
+	return answer, nil
+}
+
+// notifyQuestionAnswered tells the asker that their question got a published answer
+func notifyQuestionAnswered(tx *gorm.DB, question models.ProductQuestion, answer models.ProductAnswer) error {
+	if answer.UserID == question.UserID {
+		return nil
+	}
+	var product models.Product
+	if err := tx.Select("id", "name").First(&product, question.ProductID).Error; err != nil {
+		return err
+	}
+	productID := product.ID
+	return notify(tx, models.Notification{
+		UserID:     question.UserID,
+		Type:       models.NotificationQuestionAnswered,
+		Title:      "Your question was answered",
+		Message:    fmt.Sprintf("Your question about %s has a new answer: %s", product.Name, answer.Content),
+		EntityType: models.SlugEntityProduct,
+		EntityID:   &productID,
+	})
+}
+
+func isQAStatus(status string) bool {
+	return status == models.QAStatusPending || status == models.QAStatusApproved || status == models.QAStatusRejected
+}
+
+func setQuestionNames(questions []models.ProductQuestion) {
+	for i := range questions {
+		if questions[i].User != nil {
+			questions[i].AskerName = questions[i].User.FullName
+		}
+		for j := range questions[i].Answers {
+			setAnswerName(&questions[i].Answers[j])
+		}
+	}
+}
+
+func setAnswerName(answer *models.ProductAnswer) {
+	if answer.User != nil {
+		answer.AuthorName = answer.User.FullName
+	}
+}
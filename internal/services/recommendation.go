@@ -0,0 +1,251 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	MaxRecommendations         = 20 // entries kept per product and list
+	DefaultRecommendationLimit = 8
+)
+
+type RecommendationService interface {
+	GetRelatedProducts(productID uint, limit int) ([]models.Product, error)
+	GetFrequentlyBoughtTogether(productID uint, limit int) ([]models.Product, error)
+	RefreshRecommendations(ctx context.Context) error
+}
+
+type recommendationService struct {
+	db *gorm.DB
+}
+
+func NewRecommendationService(db *gorm.DB) RecommendationService {
+	return &recommendationService{db: db}
+}
+
+// CatalogEntry is the part of an active product the recommendation job works with
+type CatalogEntry struct {
+	ID         uint
+	CategoryID uint
+	BrandID    *uint
+	Price      float64
+}
+
+// CoPurchase counts the orders in which two products were bought together
+type CoPurchase struct {
+	ProductID     uint
+	RecommendedID uint
+	Together      int64
+}
+
+// GetRelatedProducts returns the precomputed related products; a product the job has not seen yet
+// gets the products of its category closest in price
+func (s *recommendationService) GetRelatedProducts(productID uint, limit int) ([]models.Product, error) {
+	products, err := s.recommendedProducts(productID, models.RecommendationRelated, limit)
+	if err != nil || len(products) > 0 {
+		return products, err
+	}
+
+	var product models.Product
+	if err := s.db.Select("id", "category_id", "price").First(&product, productID).Error; err != nil {
+		return nil, err
+	}
+	err = s.db.Preload("Brand").Preload("Images", orderedImages).
+		Scopes(listedProducts).
+		Where("category_id = ? AND id <> ?", product.CategoryID, productID).
+		Clauses(clause.OrderBy{Expression: clause.Expr{SQL: "ABS(price - ?), id", Vars: []interface{}{product.Price}}}).
+		Limit(recommendationLimit(limit)).
+		Find(&products).Error
+	return products, err
+}
+
+// GetFrequentlyBoughtTogether returns the products most often ordered together with the product
+func (s *recommendationService) GetFrequentlyBoughtTogether(productID uint, limit int) ([]models.Product, error) {
+	return s.recommendedProducts(productID, models.RecommendationBoughtTogether, limit)
+}
+
+func (s *recommendationService) recommendedProducts(productID uint, kind string, limit int) ([]models.Product, error) {
+	if err := s.db.Select("id").First(&models.Product{}, productID).Error; err != nil {
+		return nil, err
+	}
+	var products []models.Product
+	err := s.db.Preload("Brand").Preload("Images", orderedImages).
+		Joins("JOIN product_recommendations ON product_recommendations.recommended_id = products.id").
+		Scopes(listedProducts).
+		Where("product_recommendations.product_id = ? AND product_recommendations.kind = ?", productID, kind).
+		Order("product_recommendations.rank").
+		Limit(recommendationLimit(limit)).
+		Find(&products).Error
+	return products, err
+}
+
+// RefreshRecommendations rebuilds the recommendation table from the order history and the catalog.
+// Frequently bought together lists the products sharing the most (not cancelled) orders; related
+// starts with the same list and is topped up with products of the same category and brand, then the
+// same category, then the same brand, closest in price first.
+func (s *recommendationService) RefreshRecommendations(ctx context.Context) error {
+	db := s.db.WithContext(ctx)
+
+	var catalog []CatalogEntry
+	err := db.Model(&models.Product{}).
+		Select("id, category_id, brand_id, price").
+		Where("is_active = ?", true).
+		Order("id").
+		Scan(&catalog).Error
+	if err != nil {
+		return err
+	}
+
+	var pairs []CoPurchase
+	err = db.Raw(`SELECT a.product_id, b.product_id AS recommended_id, COUNT(DISTINCT a.order_id) AS together
+		FROM order_items a
+		JOIN order_items b ON b.order_id = a.order_id AND b.product_id <> a.product_id AND b.deleted_at IS NULL
+		JOIN orders o ON o.id = a.order_id AND o.deleted_at IS NULL AND o.status <> 'cancelled'
+		WHERE a.deleted_at IS NULL
+		GROUP BY a.product_id, b.product_id
+		ORDER BY a.product_id, together DESC, b.product_id`).
+		Scan(&pairs).Error
+	if err != nil {
+		return err
+	}
+
+	rows := BuildRecommendations(catalog, pairs, time.Now())
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.ProductRecommendation{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(rows, 500).Error
+	})
+}
+
+// BuildRecommendations computes both recommendation lists of every active product from the
+// co-purchases, ordered by product then most orders first
+func BuildRecommendations(catalog []CatalogEntry, pairs []CoPurchase, now time.Time) []models.ProductRecommendation {
+	active := make(map[uint]CatalogEntry, len(catalog))
+	byCategory := make(map[uint][]CatalogEntry)
+	byBrand := make(map[uint][]CatalogEntry)
+	for _, product := range catalog {
+		active[product.ID] = product
+		byCategory[product.CategoryID] = append(byCategory[product.CategoryID], product)
+		if product.BrandID != nil {
+			byBrand[*product.BrandID] = append(byBrand[*product.BrandID], product)
+		}
+	}
+	for _, group := range byCategory {
+		sortByPrice(group)
+	}
+	for _, group := range byBrand {
+		sortByPrice(group)
+	}
+
+	// pairs come ordered by product, most orders first
+	coBought := make(map[uint][]CoPurchase)
+	for _, pair := range pairs {
+		if _, ok := active[pair.ProductID]; !ok {
+			continue
+		}
+		if _, ok := active[pair.RecommendedID]; !ok {
+			continue
+		}
+		coBought[pair.ProductID] = append(coBought[pair.ProductID], pair)
+	}
+
+	var rows []models.ProductRecommendation
+	for _, product := range catalog {
+		entry := func(kind string, rank int, recommendedID uint, score float64, source string) models.ProductRecommendation {
+			return models.ProductRecommendation{
+				ProductID:     product.ID,
+				Kind:          kind,
+				RecommendedID: recommendedID,
+				Rank:          rank,
+				Score:         score,
+				Source:        source,
+				ComputedAt:    now,
+			}
+		}
+
+		chosen := map[uint]bool{product.ID: true}
+		var related []models.ProductRecommendation
+		for i, pair := range coBought[product.ID] {
+			if i == MaxRecommendations {
+				break
+			}
+			rows = append(rows, entry(models.RecommendationBoughtTogether, i+1, pair.RecommendedID, float64(pair.Together), models.RecommendationSourceOrders))
+			related = append(related, entry(models.RecommendationRelated, i+1, pair.RecommendedID, float64(pair.Together), models.RecommendationSourceOrders))
+			chosen[pair.RecommendedID] = true
+		}
+
+		addFallbacks := func(group []CatalogEntry, source string, match func(CatalogEntry) bool) {
+			missing := MaxRecommendations - len(related)
+			for _, candidate := range nearestByPrice(group, product.Price, missing, func(c CatalogEntry) bool {
+				return !chosen[c.ID] && match(c)
+			}) {
+				chosen[candidate.ID] = true
+				related = append(related, entry(models.RecommendationRelated, len(related)+1, candidate.ID, 0, source))
+			}
+		}
+		sameBrand := func(c CatalogEntry) bool {
+			return product.BrandID != nil && c.BrandID != nil && *c.BrandID == *product.BrandID
+		}
+		anyProduct := func(CatalogEntry) bool { return true }
+
+		addFallbacks(byCategory[product.CategoryID], models.RecommendationSourceCategory, sameBrand)
+		addFallbacks(byCategory[product.CategoryID], models.RecommendationSourceCategory, anyProduct)
+		if product.BrandID != nil {
+			addFallbacks(byBrand[*product.BrandID], models.RecommendationSourceBrand, anyProduct)
+		}
+		rows = append(rows, related...)
+	}
+	return rows
+}
+
+func sortByPrice(products []CatalogEntry) {
+	sort.Slice(products, func(i, j int) bool {
+		if products[i].Price != products[j].Price {
+			return products[i].Price < products[j].Price
+		}
+		return products[i].ID < products[j].ID
+	})
+}
+
+// nearestByPrice picks up to n accepted products of a price-sorted group, closest to price first
+func nearestByPrice(sorted []CatalogEntry, price float64, n int, accept func(CatalogEntry) bool) []CatalogEntry {
+	var picked []CatalogEntry
+	hi := sort.Search(len(sorted), func(i int) bool { return sorted[i].Price >= price })
+	lo := hi - 1
+	for len(picked) < n && (lo >= 0 || hi < len(sorted)) {
+		var candidate CatalogEntry
+		if hi >= len(sorted) || (lo >= 0 && math.Abs(price-sorted[lo].Price) <= math.Abs(sorted[hi].Price-price)) {
+			candidate = sorted[lo]
+			lo--
+		} else {
+			candidate = sorted[hi]
+			hi++
+		}
+		if accept(candidate) {
+			picked = append(picked, candidate)
+		}
+	}
+	return picked
+}
+
+func recommendationLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultRecommendationLimit
+	}
+	if limit > MaxRecommendations {
+		return MaxRecommendations
+	}
+	return limit
+}
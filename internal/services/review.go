@@ -0,0 +1,355 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	apperrors "api_techstore/pkg/errors"
+	"api_techstore/pkg/storage"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// reviewSorts maps the accepted sort values of the review list to their ORDER BY
+var reviewSorts = map[string]string{
+	"":            "created_at DESC, id DESC",
+	"newest":      "created_at DESC, id DESC",
+	"helpful":     "helpful_count DESC, created_at DESC, id DESC",
+	"rating_high": "rating DESC, created_at DESC, id DESC",
+	"rating_low":  "rating, created_at DESC, id DESC",
+}
+
+type ReviewService interface {
+	GetProductReviews(productID uint, rating int, sort string) ([]models.Review, error)
+	GetReviews(status string) ([]models.Review, error)
+	CreateReview(ctx context.Context, productID, userID uint, review models.Review, photos []*multipart.FileHeader) (models.Review, error)
+	ModerateReview(reviewID uint, status, note string) (models.Review, error)
+	DeleteReview(ctx context.Context, reviewID uint) error
+	VoteHelpful(productID, reviewID, userID uint) (models.Review, error)
+	RemoveHelpfulVote(productID, reviewID, userID uint) (models.Review, error)
+}
+
+type reviewService struct {
+	db      *gorm.DB
+	storage storage.Storage
+}
+
+func NewReviewService(db *gorm.DB, store storage.Storage) ReviewService {
+	return &reviewService{db: db, storage: store}
+}
+
+func orderedReviewPhotos(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order, id")
+}
+
+// reviewers only loads the public part of the author
+func reviewers(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Select("id", "full_name")
+}
+
+func (s *reviewService) preloadReview(db *gorm.DB) *gorm.DB {
+	return db.Preload("Photos", orderedReviewPhotos).Preload("User", reviewers)
+}
+
+// GetProductReviews lists the approved reviews of a product, optionally only those with the given rating
+func (s *reviewService) GetProductReviews(productID uint, rating int, sort string) ([]models.Review, error) {
+	order, ok := reviewSorts[sort]
+	if !ok {
+		return nil, apperrors.NewValidationFailed("sort must be one of newest, helpful, rating_high, rating_low")
+	}
+	if rating < 0 || rating > 5 {
+		return nil, apperrors.NewValidationFailed("rating must be between 1 and 5")
+	}
+	if err := s.db.Select("id").First(&models.Product{}, productID).Error; err != nil {
+		return nil, err
+	}
+
+	query := s.preloadReview(s.db).Where("product_id = ? AND status = ?", productID, models.ReviewStatusApproved)
+	if rating > 0 {
+		query = query.Where("rating = ?", rating)
+	}
+	var reviews []models.Review
+	if err := query.Order(order).Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+	setReviewerNames(reviews)
+	return reviews, nil
+}
+
+// GetReviews is the moderation queue: all reviews, or only those in the given status, oldest first
+func (s *reviewService) GetReviews(status string) ([]models.Review, error) {
+	query := s.preloadReview(s.db)
+	if status != "" {
+		if status != models.ReviewStatusPending && status != models.ReviewStatusApproved && status != models.ReviewStatusRejected {
+			return nil, apperrors.NewValidationFailed("status must be one of pending, approved, rejected")
+		}
+		query = query.Where("status = ?", status)
+	}
+	var reviews []models.Review
+	if err := query.Order("created_at, id").Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+	setReviewerNames(reviews)
+	return reviews, nil
+}
+
+// CreateReview stores a pending review. The user must have received the product in a delivered order
+// and can review each product once.
+func (s *reviewService) CreateReview(ctx context.Context, productID, userID uint, review models.Review, photos []*multipart.FileHeader) (models.Review, error) {
+	if len(photos) > models.MaxReviewPhotos {
+		return models.Review{}, apperrors.NewValidationFailed(fmt.Sprintf("A review can have at most %d photos", models.MaxReviewPhotos))
+	}
+	if err := s.db.Select("id").First(&models.Product{}, productID).Error; err != nil {
+		return models.Review{}, err
+	}
+
+	orderItemID, err := findDeliveredOrderItem(s.db, productID, userID)
+	if err == gorm.ErrRecordNotFound {
+		return models.Review{}, apperrors.New(apperrors.ErrCodeForbidden, "Only customers who received this product can review it", http.StatusForbidden)
+	}
+	if err != nil {
+		return models.Review{}, err
+	}
+
+	var existing int64
+	if err := s.db.Model(&models.Review{}).Where("product_id = ? AND user_id = ?", productID, userID).Count(&existing).Error; err != nil {
+		return models.Review{}, err
+	}
+	if existing > 0 {
+		return models.Review{}, apperrors.NewAlreadyExists("Review")
+	}
+
+	// read every photo before storing anything, so an invalid file does not leave uploads behind
+	uploads := make([][]byte, len(photos))
+	contentTypes := make([]string, len(photos))
+	for i, file := range photos {
+		uploads[i], contentTypes[i], err = readImageUpload(file)
+		if err != nil {
+			return models.Review{}, err
+		}
+	}
+
+	var storedKeys []string
+	cleanup := func() {
+		for _, key := range storedKeys {
+			s.storage.Delete(ctx, key)
+		}
+	}
+	for i, data := range uploads {
+		key := fmt.Sprintf("reviews/%d/%s%s", productID, uuid.NewString(), allowedImageTypes[contentTypes[i]])
+		url, err := s.storage.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentTypes[i])
+		if err != nil {
+			cleanup()
+			return models.Review{}, apperrors.NewStorageError(err)
+		}
+		storedKeys = append(storedKeys, key)
+		review.Photos = append(review.Photos, models.ReviewPhoto{
+			ImageURL:    url,
+			StorageKey:  key,
+			ContentType: contentTypes[i],
+			SortOrder:   i,
+		})
+	}
+
+	review.ProductID = productID
+	review.UserID = userID
+	review.OrderItemID = orderItemID
+	review.Status = models.ReviewStatusPending
+	review.HelpfulCount = 0
+	if err := s.db.Create(&review).Error; err != nil {
+		cleanup()
+		return models.Review{}, err
+	}
+	return s.getReview(s.db, review.ID)
+}
+
+// ModerateReview changes the moderation state of a review and refreshes the product rating
+func (s *reviewService) ModerateReview(reviewID uint, status, note string) (models.Review, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var review models.Review
+		if err := tx.First(&review, reviewID).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		err := tx.Model(&review).Updates(map[string]interface{}{
+			"status":          status,
+			"moderation_note": note,
+			"moderated_at":    now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return refreshProductRating(tx, review.ProductID)
+	})
+	if err != nil {
+		return models.Review{}, err
+	}
+	return s.getReview(s.db, reviewID)
+}
+
+// DeleteReview removes a review together with its votes and photos
+func (s *reviewService) DeleteReview(ctx context.Context, reviewID uint) error {
+	var review models.Review
+	if err := s.db.Preload("Photos").First(&review, reviewID).Error; err != nil {
+		return err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("review_id = ?", reviewID).Delete(&models.ReviewVote{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("review_id = ?", reviewID).Delete(&models.ReviewPhoto{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&review).Error; err != nil {
+			return err
+		}
+		return refreshProductRating(tx, review.ProductID)
+	})
+	if err != nil {
+		return err
+	}
+	for _, photo := range review.Photos {
+		if photo.StorageKey != "" {
+			s.storage.Delete(ctx, photo.StorageKey)
+		}
+	}
+	return nil
+}
+
+// VoteHelpful marks an approved review as helpful for the user; voting twice has no effect
+func (s *reviewService) VoteHelpful(productID, reviewID, userID uint) (models.Review, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		review, err := findPublishedReview(tx, productID, reviewID)
+		if err != nil {
+			return err
+		}
+		if review.UserID == userID {
+			return apperrors.NewValidationFailed("You cannot vote on your own review")
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.ReviewVote{ReviewID: reviewID, UserID: userID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return tx.Model(&models.Review{}).Where("id = ?", reviewID).
+			UpdateColumn("helpful_count", gorm.Expr("helpful_count + 1")).Error
+	})
+	if err != nil {
+		return models.Review{}, err
+	}
+	return s.getReview(s.db, reviewID)
+}
+
+// RemoveHelpfulVote withdraws the user's helpful vote, if any
+func (s *reviewService) RemoveHelpfulVote(productID, reviewID, userID uint) (models.Review, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := findPublishedReview(tx, productID, reviewID); err != nil {
+			return err
+		}
+		result := tx.Where("review_id = ? AND user_id = ?", reviewID, userID).Delete(&models.ReviewVote{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return tx.Model(&models.Review{}).Where("id = ? AND helpful_count > 0", reviewID).
+			UpdateColumn("helpful_count", gorm.Expr("helpful_count - 1")).Error
+	})
+	if err != nil {
+		return models.Review{}, err
+	}
+	return s.getReview(s.db, reviewID)
+}
+
+func (s *reviewService) getReview(db *gorm.DB, reviewID uint) (models.Review, error) {
+	var review models.Review
+	if err := s.preloadReview(db).First(&review, reviewID).Error; err != nil {
+		return models.Review{}, err
+	}
+	if review.User != nil {
+		review.ReviewerName = review.User.FullName
+	}
+	return review, nil
+}
+
+// findDeliveredOrderItem finds the latest order item of the product that was delivered to the user
+func findDeliveredOrderItem(db *gorm.DB, productID, userID uint) (uint, error) {
+	var item models.OrderItem
+	err := db.Select("order_items.id").
+		Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL").
+		Where("orders.user_id = ? AND orders.status = ? AND order_items.product_id = ?", userID, "delivered", productID).
+		Order("orders.id DESC").
+		Take(&item).Error
+	return item.ID, err
+}
+
+// findPublishedReview loads an approved review of the product; other reviews are not visible
+func findPublishedReview(tx *gorm.DB, productID, reviewID uint) (models.Review, error) {
+	var review models.Review
+	err := tx.Where("id = ? AND product_id = ? AND status = ?", reviewID, productID, models.ReviewStatusApproved).
+		First(&review).Error
+	return review, err
+}
+
+// refreshProductRating recomputes the cached rating average, count and histogram of a product
+// from its approved reviews
+func refreshProductRating(tx *gorm.DB, productID uint) error {
+	var rows []struct {
+		Rating int
+		Total  int64
+	}
+	err := tx.Model(&models.Review{}).
+		Select("rating, COUNT(*) AS total").
+		Where("product_id = ? AND status = ?", productID, models.ReviewStatusApproved).
+		Group("rating").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	histogram := make(map[string]int64, 5)
+	for star := 1; star <= 5; star++ {
+		histogram[strconv.Itoa(star)] = 0
+	}
+	var count, sum int64
+	for _, row := range rows {
+		histogram[strconv.Itoa(row.Rating)] = row.Total
+		count += row.Total
+		sum += int64(row.Rating) * row.Total
+	}
+	average := 0.0
+	if count > 0 {
+		average = math.Round(float64(sum)/float64(count)*100) / 100
+	}
+	encoded, err := json.Marshal(histogram)
+	if err != nil {
+		return err
+	}
+
+	// UpdateColumns keeps updated_at: a new review is not a change of the product itself
+	return tx.Model(&models.Product{}).Where("id = ?", productID).UpdateColumns(map[string]interface{}{
+		"rating_average":   average,
+		"rating_count":     count,
+		"rating_histogram": string(encoded),
+	}).Error
+}
+
+func setReviewerNames(reviews []models.Review) {
+	for i := range reviews {
+		if reviews[i].User != nil {
+			reviews[i].ReviewerName = reviews[i].User.FullName
+		}
+	}
+}
@@ -0,0 +1,429 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+)
+
+// facet names, used to leave a facet's own filter out when counting it so
+// the sidebar still shows the other options of a multi-select group
+const (
+	facetCategory     = "category"
+	facetBrand        = "brand"
+	facetPrice        = "price"
+	facetAvailability = "availability"
+	// attribute facets are named "attr:<code>"
+	facetAttributePrefix = "attr:"
+)
+
+const priceBucketCount = 5
+
+// facetQueryLimit caps the attribute value counts run at the same time, so one sidebar does not
+// take the whole connection pool: with the other facets at most 4 + facetQueryLimit queries are open
+const facetQueryLimit = 4
+
+// likeEscaper escapes the LIKE wildcards, so a keyword such as "100%" is matched as typed
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+type SearchService interface {
+	GetFilters(query models.SearchQuery) (models.SearchFacets, error)
+}
+
+type searchService struct {
+	db *gorm.DB
+}
+
+func NewSearchService(db *gorm.DB) SearchService {
+	return &searchService{db: db}
+}
+
+// filtered builds the product scope for the applied filters, skipping the given facet
+func (s *searchService) filtered(query models.SearchQuery, skip string) *gorm.DB {
+	db := s.db.Model(&models.Product{}).Scopes(listedProducts)
+
+	if keyword := strings.TrimSpace(query.Keyword); keyword != "" {
+		like := "%" + likeEscaper.Replace(keyword) + "%"
+		db = db.Where(`(products.name ILIKE ? ESCAPE '\' OR products.description ILIKE ? ESCAPE '\')`, like, like)
+	}
+	if skip != facetCategory && len(query.CategoryIDs) > 0 {
+		// a category stands for its subcategories as well, like on the category page
+		db = db.Where("products.category_id IN (?)", subtreesIDs(s.db, query.CategoryIDs))
+	}
+	if skip != facetBrand && len(query.BrandIDs) > 0 {
+		db = db.Where("products.brand_id IN ?", query.BrandIDs)
+	}
+	if skip != facetPrice {
+		if query.MinPrice != nil {
+			db = db.Where("products.price >= ?", *query.MinPrice)
+		}
+		if query.MaxPrice != nil {
+			db = db.Where("products.price <= ?", *query.MaxPrice)
+		}
+	}
+	if skip != facetAvailability && query.InStock != nil {
+		if *query.InStock {
+			db = db.Where("products.quantity > 0")
+		} else {
+			db = db.Where("products.quantity <= 0")
+		}
+	}
+	for _, code := range sortedKeys(query.Attributes) {
+		if skip == facetAttributePrefix+code {
+			continue
+		}
+		values := splitFilterValues(query.Attributes[code])
+		if len(values) == 0 {
+			continue
+		}
+		db = db.Where("products.id IN (?)", s.attributeMatches(code, values))
+	}
+	return db
+}
+
+// attributeMatches selects the ids of products whose attribute matches any of the values.
+// The attribute type is not known here, so every value is tried against the columns it can apply to.
+func (s *searchService) attributeMatches(code string, values []string) *gorm.DB {
+	var conditions []string
+	var args []interface{}
+	for _, value := range values {
+		if low, high, ok := parseRange(value); ok {
+			switch {
+			case low != nil && high != nil:
+				conditions = append(conditions, "pav.value_number BETWEEN ? AND ?")
+				args = append(args, *low, *high)
+			case low != nil:
+				conditions = append(conditions, "pav.value_number >= ?")
+				args = append(args, *low)
+			case high != nil:
+				conditions = append(conditions, "pav.value_number <= ?")
+				args = append(args, *high)
+			}
+			continue
+		}
+		if flag, err := strconv.ParseBool(value); err == nil && !isNumeric(value) {
+			conditions = append(conditions, "pav.value_bool = ?")
+			args = append(args, flag)
+			continue
+		}
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			conditions = append(conditions, "pav.value_number = ?")
+			args = append(args, number)
+		}
+		conditions = append(conditions, "LOWER(pav.value_text) = LOWER(?)")
+		args = append(args, value)
+	}
+
+	return s.db.Table("product_attribute_values AS pav").
+		Select("pav.product_id").
+		Joins("JOIN category_attributes AS ca ON ca.id = pav.attribute_id AND ca.deleted_at IS NULL").
+		Where("ca.code = ?", code).
+		Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
+
+// GetFilters counts every facet with its own query; they are independent, so they run concurrently.
+// A sidebar costs 6 + N queries for N filterable attributes: availability, categories, brands, the
+// price bounds and buckets, the attribute list, then one value count per attribute. The value
+// counts cannot share a query because each one leaves out the filter of its own attribute (so the
+// other values stay selectable); facetQueryLimit of them run at a time.
+func (s *searchService) GetFilters(query models.SearchQuery) (models.SearchFacets, error) {
+	facets := models.SearchFacets{
+		Categories:  []models.FacetCount{},
+		Brands:      []models.FacetCount{},
+		PriceRanges: []models.PriceRangeFacet{},
+		Attributes:  []models.AttributeFacet{},
+	}
+
+	// every query fills its own fields of facets
+	var g errgroup.Group
+	g.Go(func() error {
+		return s.availabilityFacet(query, &facets)
+	})
+	g.Go(func() error {
+		categories, err := s.countBy(query, facetCategory, "categories", "products.category_id", query.CategoryIDs)
+		if err != nil {
+			return err
+		}
+		facets.Categories = categories
+		return nil
+	})
+	g.Go(func() error {
+		brands, err := s.countBy(query, facetBrand, "brands", "products.brand_id", query.BrandIDs)
+		if err != nil {
+			return err
+		}
+		facets.Brands = brands
+		return nil
+	})
+	g.Go(func() error {
+		priceRanges, err := s.priceFacet(query)
+		if err != nil {
+			return err
+		}
+		facets.PriceRanges = priceRanges
+		return nil
+	})
+	g.Go(func() error {
+		attributes, err := s.attributeFacets(query)
+		if err != nil {
+			return err
+		}
+		facets.Attributes = attributes
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return models.SearchFacets{}, err
+	}
+	return facets, nil
+}
+
+// availabilityFacet counts in/out of stock products in one pass; the overall total
+// is derived from the same row so it honours the in_stock filter as well
+func (s *searchService) availabilityFacet(query models.SearchQuery, facets *models.SearchFacets) error {
+	var row struct {
+		Total   int64
+		InStock int64
+	}
+	err := s.filtered(query, facetAvailability).
+		Select("COUNT(*) AS total, COUNT(*) FILTER (WHERE products.quantity > 0) AS in_stock").
+		Scan(&row).Error
+	if err != nil {
+		return err
+	}
+
+	facets.Availability = models.AvailabilityFacet{
+		InStock:    row.InStock,
+		OutOfStock: row.Total - row.InStock,
+	}
+	facets.Total = row.Total
+	if query.InStock != nil {
+		if *query.InStock {
+			facets.Total = facets.Availability.InStock
+		} else {
+			facets.Total = facets.Availability.OutOfStock
+		}
+	}
+	return nil
+}
+
+// countBy groups the filtered products by a related table (categories, brands)
+func (s *searchService) countBy(query models.SearchQuery, facet, table, foreignKey string, selected []uint) ([]models.FacetCount, error) {
+	counts := []models.FacetCount{}
+	err := s.filtered(query, facet).
+		Select(table + ".id, " + table + ".name, " + table + ".slug, COUNT(products.id) AS count").
+		Joins("JOIN " + table + " ON " + table + ".id = " + foreignKey + " AND " + table + ".deleted_at IS NULL").
+		Group(table + ".id, " + table + ".name, " + table + ".slug").
+		Order("count DESC, " + table + ".name").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range counts {
+		counts[i].Selected = containsUint(selected, counts[i].ID)
+	}
+	return counts, nil
+}
+
+// priceFacet splits the price span of the filtered products into evenly sized buckets
+// rounded to "nice" boundaries (1, 2, 5 x 10^n) so the storefront can render them as is
+func (s *searchService) priceFacet(query models.SearchQuery) ([]models.PriceRangeFacet, error) {
+	var bounds struct {
+		Low  *float64
+		High *float64
+	}
+	if err := s.filtered(query, facetPrice).
+		Select("MIN(products.price) AS low, MAX(products.price) AS high").
+		Scan(&bounds).Error; err != nil {
+		return nil, err
+	}
+	if bounds.Low == nil || bounds.High == nil {
+		return []models.PriceRangeFacet{}, nil
+	}
+
+	step := niceStep((*bounds.High - *bounds.Low) / priceBucketCount)
+	low := math.Floor(*bounds.Low/step) * step
+	buckets := int(math.Floor((*bounds.High-low)/step)) + 1
+	high := low + float64(buckets)*step
+
+	var rows []struct {
+		Bucket int
+		Count  int64
+	}
+	if err := s.filtered(query, facetPrice).
+		Select("width_bucket(products.price, ?, ?, ?) AS bucket, COUNT(*) AS count", low, high, buckets).
+		Group("bucket").
+		Order("bucket").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	ranges := make([]models.PriceRangeFacet, 0, len(rows))
+	for _, row := range rows {
+		ranges = append(ranges, models.PriceRangeFacet{
+			Min:   low + float64(row.Bucket-1)*step,
+			Max:   low + float64(row.Bucket)*step,
+			Count: row.Count,
+		})
+	}
+	return ranges, nil
+}
+
+// attributeFacets lists the filterable spec attributes of the matching products with
+// their value counts. Attributes are grouped by code so the same spec defined in
+// several categories (e.g. ram_gb for laptops and phones) is one facet.
+func (s *searchService) attributeFacets(query models.SearchQuery) ([]models.AttributeFacet, error) {
+	var attributes []struct {
+		Code string
+		Name string
+		Type string
+		Unit string
+	}
+	if err := s.filtered(query, "").
+		Select("ca.code, MIN(ca.name) AS name, MIN(ca.type) AS type, MIN(ca.unit) AS unit").
+		Joins("JOIN product_attribute_values AS pav ON pav.product_id = products.id").
+		Joins("JOIN category_attributes AS ca ON ca.id = pav.attribute_id AND ca.deleted_at IS NULL AND ca.is_filterable").
+		Group("ca.code").
+		Order("MIN(ca.sort_order), ca.code").
+		Scan(&attributes).Error; err != nil {
+		return nil, err
+	}
+
+	facets := make([]models.AttributeFacet, 0, len(attributes))
+	for _, attribute := range attributes {
+		facets = append(facets, models.AttributeFacet{
+			Code: attribute.Code,
+			Name: attribute.Name,
+			Type: attribute.Type,
+			Unit: attribute.Unit,
+		})
+	}
+
+	var g errgroup.Group
+	g.SetLimit(facetQueryLimit)
+	for i := range facets {
+		facet := &facets[i]
+		g.Go(func() error {
+			order := "count DESC, value"
+			if facet.Type == models.AttributeTypeNumber {
+				order = "MIN(pav.value_number)"
+			}
+
+			values := []models.AttributeFacetValue{}
+			if err := s.filtered(query, facetAttributePrefix+facet.Code).
+				Select("CASE WHEN pav.value_number IS NOT NULL THEN pav.value_number::text "+
+					"WHEN pav.value_bool IS NOT NULL THEN pav.value_bool::text "+
+					"ELSE pav.value_text END AS value, COUNT(DISTINCT products.id) AS count").
+				Joins("JOIN product_attribute_values AS pav ON pav.product_id = products.id").
+				Joins("JOIN category_attributes AS ca ON ca.id = pav.attribute_id AND ca.deleted_at IS NULL").
+				Where("ca.code = ?", facet.Code).
+				Group("value").
+				Order(order).
+				Scan(&values).Error; err != nil {
+				return err
+			}
+
+			selected := splitFilterValues(query.Attributes[facet.Code])
+			for j := range values {
+				values[j].Selected = containsFilterValue(selected, values[j].Value)
+			}
+			facet.Values = values
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return facets, nil
+}
+
+// niceStep rounds a raw bucket width up to 1, 2 or 5 times a power of ten
+func niceStep(raw float64) float64 {
+	if raw <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, factor := range []float64{1, 2, 5, 10} {
+		if raw <= factor*magnitude {
+			return factor * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+func containsUint(values []uint, value uint) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// splitFilterValues splits a comma separated filter value, dropping empty entries
+func splitFilterValues(raw string) []string {
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// parseRange parses "min..max", where either side may be left out
+func parseRange(value string) (*float64, *float64, bool) {
+	lowText, highText, found := strings.Cut(value, "..")
+	if !found {
+		return nil, nil, false
+	}
+	var low, high *float64
+	if lowText = strings.TrimSpace(lowText); lowText != "" {
+		number, err := strconv.ParseFloat(lowText, 64)
+		if err != nil {
+			return nil, nil, false
+		}
+		low = &number
+	}
+	if highText = strings.TrimSpace(highText); highText != "" {
+		number, err := strconv.ParseFloat(highText, 64)
+		if err != nil {
+			return nil, nil, false
+		}
+		high = &number
+	}
+	return low, high, low != nil || high != nil
+}
+
+func isNumeric(value string) bool {
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
+
+// containsFilterValue compares case-insensitively, and numerically when both sides are numbers
+func containsFilterValue(selected []string, value string) bool {
+	number, numberErr := strconv.ParseFloat(value, 64)
+	for _, candidate := range selected {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+		if other, err := strconv.ParseFloat(candidate, 64); err == nil && numberErr == nil && other == number {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	apperrors "api_techstore/pkg/errors"
+	"api_techstore/pkg/slug"
+
+	"gorm.io/gorm"
+)
+
+// assignSlug decides the slug of a new or renamed entity. An explicit slug is
+// normalized and must not be used by another live entity; without one a slug is
+// generated from the name, skipping slugs that are taken or still redirect somewhere.
+func assignSlug(tx *gorm.DB, model interface{}, entityType, requested, name string, excludeID uint) (string, error) {
+	if requested != "" {
+		candidate := slug.Make(requested)
+		if candidate == "" {
+			return "", apperrors.NewValidationFailed("slug must contain letters or digits")
+		}
+		taken, err := slugInUse(tx, model, candidate, excludeID)
+		if err != nil {
+			return "", err
+		}
+		if taken {
+			return "", apperrors.NewAlreadyExists("Slug")
+		}
+		return candidate, nil
+	}
+
+	base := slug.Make(name)
+	if base == "" {
+		return "", apperrors.NewValidationFailed("cannot generate a slug from the name, please provide one")
+	}
+	return slug.Unique(base, func(candidate string) (bool, error) {
+		taken, err := slugInUse(tx, model, candidate, excludeID)
+		if err != nil || taken {
+			return taken, err
+		}
+		var redirects int64
+		err = tx.Model(&models.SlugRedirect{}).
+			Where("entity_type = ? AND slug = ? AND entity_id <> ?", entityType, candidate, excludeID).
+			Count(&redirects).Error
+		return redirects > 0, err
+	})
+}
+
+// changeSlug applies a requested slug change on update, keeping the old slug as a redirect.
+// An empty or unchanged request keeps the current slug.
+func changeSlug(tx *gorm.DB, model interface{}, entityType string, entityID uint, current, requested string) (string, error) {
+	if requested == "" || slug.Make(requested) == current {
+		return current, nil
+	}
+	newSlug, err := assignSlug(tx, model, entityType, requested, "", entityID)
+	if err != nil {
+		return "", err
+	}
+	return newSlug, claimSlug(tx, entityType, entityID, current, newSlug)
+}
+
+// slugInUse checks the slug column of live rows; the unique indexes leave deleted rows out, and a
+// deleted row whose slug was taken gets a new one when it is restored
+func slugInUse(tx *gorm.DB, model interface{}, candidate string, excludeID uint) (bool, error) {
+	var count int64
+	err := tx.Model(model).Where("slug = ? AND id <> ?", candidate, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+// claimSlug records the entity's previous slug for redirects and drops any redirect
+// that pointed the new slug somewhere else: a live slug always wins over an old one
+func claimSlug(tx *gorm.DB, entityType string, entityID uint, oldSlug, newSlug string) error {
+	if err := tx.Where("entity_type = ? AND slug = ?", entityType, newSlug).Delete(&models.SlugRedirect{}).Error; err != nil {
+		return err
+	}
+	if oldSlug == "" || oldSlug == newSlug {
+		return nil
+	}
+	if err := tx.Where("entity_type = ? AND slug = ?", entityType, oldSlug).Delete(&models.SlugRedirect{}).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.SlugRedirect{EntityType: entityType, Slug: oldSlug, EntityID: entityID}).Error
+}
+
+// findBySlug loads the entity with the slug, falling back to the slug history; the
+// caller can tell a redirect by the loaded slug differing from the requested one
+func findBySlug(db *gorm.DB, dest interface{}, entityType, value string) error {
+	// db may carry preloads; a session makes it safe to run two queries from it
+	db = db.Session(&gorm.Session{})
+	err := db.First(dest, "slug = ?", value).Error
+	if err != gorm.ErrRecordNotFound {
+		return err
+	}
+
+	var redirect models.SlugRedirect
+	if err := db.Session(&gorm.Session{NewDB: true}).
+		Where("entity_type = ? AND slug = ?", entityType, value).
+		First(&redirect).Error; err != nil {
+		return err
+	}
+	return db.First(dest, "id = ?", redirect.EntityID).Error
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	apperrors "api_techstore/pkg/errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TradeInQuoteValidity is how long a quote can be accepted
+const TradeInQuoteValidity = 14 * 24 * time.Hour
+
+type TradeInService interface {
+	GetPriceRules(brandID uint) ([]models.TradeInPriceRule, error)
+	CreatePriceRule(rule models.TradeInPriceRule) (models.TradeInPriceRule, error)
+	UpdatePriceRule(ruleID uint, updates map[string]interface{}) (models.TradeInPriceRule, error)
+	DeletePriceRule(ruleID uint) error
+	RequestQuote(tradeIn models.TradeIn) (models.TradeIn, error)
+	GetUserTradeIns(userID uint) ([]models.TradeIn, error)
+	GetTradeIns(status string) ([]models.TradeIn, error)
+	GetTradeIn(tradeInID, userID uint, isStaff bool) (models.TradeIn, error)
+	AcceptQuote(tradeInID, userID uint) (models.TradeIn, error)
+	DeclineQuote(tradeInID, userID uint) (models.TradeIn, error)
+	Inspect(tradeInID, staffID uint, finalValue float64, note string) (models.TradeIn, error)
+}
+
+type tradeInService struct {
+	db *gorm.DB
+}
+
+func NewTradeInService(db *gorm.DB) TradeInService {
+	return &tradeInService{db: db}
+}
+
+// GetPriceRules lists the pricing rules, of one brand when brandID is set
+func (s *tradeInService) GetPriceRules(brandID uint) ([]models.TradeInPriceRule, error) {
+	query := s.db.Preload("Brand")
+	if brandID != 0 {
+		query = query.Where("brand_id = ?", brandID)
+	}
+	var rules []models.TradeInPriceRule
+	err := query.Order("brand_id, model").Find(&rules).Error
+	return rules, err
+}
+
+// CreatePriceRule adds the pricing of a model; a brand and model are priced once
+func (s *tradeInService) CreatePriceRule(rule models.TradeInPriceRule) (models.TradeInPriceRule, error) {
+	rule.Model = strings.TrimSpace(rule.Model)
+	if err := s.db.Select("id").First(&models.Brand{}, rule.BrandID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return models.TradeInPriceRule{}, apperrors.NewNotFound("Brand")
+		}
+		return models.TradeInPriceRule{}, err
+	}
+	var existing int64
+	err := s.db.Model(&models.TradeInPriceRule{}).
+		Where("brand_id = ? AND LOWER(model) = LOWER(?)", rule.BrandID, rule.Model).
+		Count(&existing).Error
+	if err != nil {
+		return models.TradeInPriceRule{}, err
+	}
+	if existing > 0 {
+		return models.TradeInPriceRule{}, apperrors.NewAlreadyExists("Trade-in price rule for this model")
+	}
+	if err := s.db.Omit("Brand").Create(&rule).Error; err != nil {
+		return models.TradeInPriceRule{}, err
+	}
+	return s.getPriceRule(rule.ID)
+}
+
+// UpdatePriceRule changes the pricing of a model; quotes already given keep their value
+func (s *tradeInService) UpdatePriceRule(ruleID uint, updates map[string]interface{}) (models.TradeInPriceRule, error) {
+	var rule models.TradeInPriceRule
+	if err := s.db.First(&rule, ruleID).Error; err != nil {
+		return models.TradeInPriceRule{}, err
+	}
+	if len(updates) > 0 {
+		if err := s.db.Model(&rule).Updates(updates).Error; err != nil {
+			return models.TradeInPriceRule{}, err
+		}
+	}
+	return s.getPriceRule(ruleID)
+}
+
+func (s *tradeInService) DeletePriceRule(ruleID uint) error {
+	result := s.db.Delete(&models.TradeInPriceRule{}, ruleID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *tradeInService) getPriceRule(ruleID uint) (models.TradeInPriceRule, error) {
+	var rule models.TradeInPriceRule
+	err := s.db.Preload("Brand").First(&rule, ruleID).Error
+	return rule, err
+}
+
+// RequestQuote prices a device from the active rule of its brand and model, matched ignoring case
+func (s *tradeInService) RequestQuote(tradeIn models.TradeIn) (models.TradeIn, error) {
+	tradeIn.Model = strings.TrimSpace(tradeIn.Model)
+	var rule models.TradeInPriceRule
+	err := s.db.Where("brand_id = ? AND LOWER(model) = LOWER(?) AND is_active = ?", tradeIn.BrandID, tradeIn.Model, true).
+		First(&rule).Error
+	if err == gorm.ErrRecordNotFound {
+		return models.TradeIn{}, apperrors.NewNotFound("Trade-in price for this model")
+	}
+	if err != nil {
+		return models.TradeIn{}, err
+	}
+
+	tradeIn.PriceRuleID = rule.ID
+	tradeIn.QuotedValue = QuoteTradeIn(rule, tradeIn)
+	tradeIn.QuoteExpiresAt = time.Now().Add(TradeInQuoteValidity)
+	tradeIn.Status = models.TradeInStatusQuoted
+	tradeIn.FinalValue = nil
+	tradeIn.OrderID = nil
+	if err := s.db.Omit("Brand").Create(&tradeIn).Error; err != nil {
+		return models.TradeIn{}, err
+	}
+	return s.GetTradeIn(tradeIn.ID, tradeIn.UserID, false)
+}
+
+// QuoteTradeIn computes the value of a device by a pricing rule, rounded to the cent
+func QuoteTradeIn(rule models.TradeInPriceRule, tradeIn models.TradeIn) float64 {
+	if !tradeIn.PowersOn {
+		return math.Round(rule.NoPowerValue*100) / 100
+	}
+	percent := 100
+	switch tradeIn.Condition {
+	case models.TradeInConditionGood:
+		percent = rule.GoodPercent
+	case models.TradeInConditionFair:
+		percent = rule.FairPercent
+	case models.TradeInConditionPoor:
+		percent = rule.PoorPercent
+	}
+	value := rule.BaseValue * float64(percent) / 100
+	if tradeIn.ScreenDamaged {
+		value -= rule.ScreenDamageDeduction
+	}
+	if tradeIn.BodyDamaged {
+		value -= rule.BodyDamageDeduction
+	}
+	return math.Max(0, math.Round(value*100)/100)
+}
+
+// GetUserTradeIns lists the trade-ins of a user, newest first
+func (s *tradeInService) GetUserTradeIns(userID uint) ([]models.TradeIn, error) {
+	var tradeIns []models.TradeIn
+	err := s.db.Preload("Brand").Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&tradeIns).Error
+	return tradeIns, err
+}
+
+// GetTradeIns lists the trade-ins of every customer in the given status (or all), oldest first
+func (s *tradeInService) GetTradeIns(status string) ([]models.TradeIn, error) {
+	query := s.db.Preload("Brand")
+	if status != "" {
+		if status != models.TradeInStatusQuoted && status != models.TradeInStatusAccepted && status != models.TradeInStatusDeclined {
+			return nil, apperrors.NewValidationFailed("status must be one of quoted, accepted, declined")
+		}
+		query = query.Where("status = ?", status)
+	}
+	var tradeIns []models.TradeIn
+	err := query.Order("created_at, id").Find(&tradeIns).Error
+	return tradeIns, err
+}
+
+// GetTradeIn returns a trade-in to staff, or to the customer who requested it
+func (s *tradeInService) GetTradeIn(tradeInID, userID uint, isStaff bool) (models.TradeIn, error) {
+	query := s.db.Preload("Brand")
+	if !isStaff {
+		query = query.Where("user_id = ?", userID)
+	}
+	var tradeIn models.TradeIn
+	err := query.First(&tradeIn, tradeInID).Error
+	return tradeIn, err
+}
+
+// AcceptQuote accepts a quote that has not expired, so its value can be credited to an order
+func (s *tradeInService) AcceptQuote(tradeInID, userID uint) (models.TradeIn, error) {
+	return s.answerQuote(tradeInID, userID, models.TradeInStatusAccepted)
+}
+
+func (s *tradeInService) DeclineQuote(tradeInID, userID uint) (models.TradeIn, error) {
+	return s.answerQuote(tradeInID, userID, models.TradeInStatusDeclined)
+}
+
+func (s *tradeInService) answerQuote(tradeInID, userID uint, status string) (models.TradeIn, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var tradeIn models.TradeIn
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).
+			First(&tradeIn, tradeInID).Error
+		if err != nil {
+			return err
+		}
+		if tradeIn.Status != models.TradeInStatusQuoted {
+			return apperrors.NewConflict(fmt.Sprintf("Trade-in is already %s", tradeIn.Status))
+		}
+		if status == models.TradeInStatusAccepted && time.Now().After(tradeIn.QuoteExpiresAt) {
+			return apperrors.NewConflict("Quote has expired, request a new one")
+		}
+		return tx.Model(&tradeIn).Update("status", status).Error
+	})
+	if err != nil {
+		return models.TradeIn{}, err
+	}
+	return s.GetTradeIn(tradeInID, userID, false)
+}
+
+// Inspect sets the final value of an accepted trade-in once the device is received. When the
+// trade-in is credited to an order, the order's credit and total follow the new value; a value
+// changing the credit is refused once the order is paid or past pending, the amount charged
+// would no longer match.
+func (s *tradeInService) Inspect(tradeInID, staffID uint, finalValue float64, note string) (models.TradeIn, error) {
+	finalValue = math.Round(finalValue*100) / 100
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var tradeIn models.TradeIn
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tradeIn, tradeInID).Error; err != nil {
+			return err
+		}
+		if tradeIn.Status != models.TradeInStatusAccepted {
+			return apperrors.NewConflict("Only accepted trade-ins can be inspected")
+		}
+		now := time.Now()
+		err := tx.Model(&tradeIn).Updates(map[string]interface{}{
+			"final_value":     finalValue,
+			"inspection_note": note,
+			"inspected_by":    staffID,
+			"inspected_at":    now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if tradeIn.OrderID == nil {
+			return nil
+		}
+		order, credited, err := creditedOrder(tx, *tradeIn.OrderID)
+		if err != nil || !credited {
+			return err
+		}
+		if credit, _ := TradeInCredit(order, finalValue); credit == order.TradeInCredit {
+			return nil
+		}
+		if order.Status != "pending" {
+			return apperrors.NewConflict(fmt.Sprintf("Order #%d is already %s, its trade-in credit can no longer change", order.ID, order.Status))
+		}
+		var paid int64
+		if err := tx.Model(&models.Payment{}).Where("order_id = ? AND status = ?", order.ID, "completed").Count(&paid).Error; err != nil {
+			return err
+		}
+		if paid > 0 {
+			return apperrors.NewConflict(fmt.Sprintf("Order #%d is already paid, its trade-in credit can no longer change", order.ID))
+		}
+		return setTradeInCredit(tx, order, finalValue)
+	})
+	if err != nil {
+		return models.TradeIn{}, err
+	}
+	return s.GetTradeIn(tradeInID, staffID, true)
+}
+
+// applyTradeIn credits an accepted trade-in of the user to a new order, inside the transaction
+// creating it. A trade-in is credited to one order, unless that order was cancelled. The credit
+// never exceeds the order total.
+func applyTradeIn(tx *gorm.DB, tradeInID, userID, orderID uint) error {
+	var tradeIn models.TradeIn
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userID).
+		First(&tradeIn, tradeInID).Error
+	if err == gorm.ErrRecordNotFound {
+		return apperrors.NewNotFound("Trade-in")
+	}
+	if err != nil {
+		return err
+	}
+	if tradeIn.Status != models.TradeInStatusAccepted {
+		return apperrors.NewConflict("Only accepted trade-ins can be credited to an order")
+	}
+	if tradeIn.OrderID != nil && *tradeIn.OrderID != orderID {
+		_, credited, err := creditedOrder(tx, *tradeIn.OrderID)
+		if err != nil {
+			return err
+		}
+		if credited {
+			return apperrors.NewConflict("Trade-in is already credited to another order")
+		}
+	}
+
+	var order models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&tradeIn).Update("order_id", orderID).Error; err != nil {
+		return err
+	}
+	return setTradeInCredit(tx, order, tradeIn.Value())
+}
+
+// creditedOrder locks the order a trade-in was credited to; cancelled and deleted orders no longer hold the credit
+func creditedOrder(tx *gorm.DB, orderID uint) (models.Order, bool, error) {
+	var order models.Order
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderID).Error
+	if err == gorm.ErrRecordNotFound {
+		return models.Order{}, false, nil
+	}
+	if err != nil {
+		return models.Order{}, false, err
+	}
+	return order, order.Status != "cancelled", nil
+}
+
+// TradeInCredit is the credit a trade-in value gives an order, capped at the amount before
+// credit, and the order total after it
+func TradeInCredit(order models.Order, value float64) (float64, float64) {
+	gross := order.TotalAmount + order.TradeInCredit
+	credit := math.Max(0, math.Min(value, gross))
+	return credit, math.Round((gross-credit)*100) / 100
+}
+
+// setTradeInCredit replaces the trade-in credit of an order
+func setTradeInCredit(tx *gorm.DB, order models.Order, value float64) error {
+	credit, total := TradeInCredit(order, value)
+	return tx.Model(&order).Updates(map[string]interface{}{
+		"trade_in_credit": credit,
+		"total_amount":    total,
+	}).Error
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	apperrors "api_techstore/pkg/errors"
+	"api_techstore/pkg/storage"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Deleted products, brands and categories stay in the trash while something still refers to them:
+// order history and customer content for products, products (deleted or not) for brands and
+// categories. They are purged by a later run once that is gone.
+var (
+	productPurgeBlockers = []string{
+		"NOT EXISTS (SELECT 1 FROM order_items WHERE order_items.product_id = products.id OR order_items.bundle_product_id = products.id)",
+		"NOT EXISTS (SELECT 1 FROM sold_units WHERE sold_units.product_id = products.id)",
+		"NOT EXISTS (SELECT 1 FROM bundle_items WHERE bundle_items.component_id = products.id)",
+		"NOT EXISTS (SELECT 1 FROM flash_sales WHERE flash_sales.product_id = products.id)",
+		"NOT EXISTS (SELECT 1 FROM reviews WHERE reviews.product_id = products.id)",
+		"NOT EXISTS (SELECT 1 FROM product_questions WHERE product_questions.product_id = products.id)",
+	}
+	brandPurgeBlockers = []string{
+		"NOT EXISTS (SELECT 1 FROM products WHERE products.brand_id = brands.id)",
+		"NOT EXISTS (SELECT 1 FROM trade_in_price_rules WHERE trade_in_price_rules.brand_id = brands.id)",
+		"NOT EXISTS (SELECT 1 FROM trade_ins WHERE trade_ins.brand_id = brands.id)",
+	}
+	categoryPurgeBlockers = []string{
+		"NOT EXISTS (SELECT 1 FROM products WHERE products.category_id = categories.id)",
+		"NOT EXISTS (SELECT 1 FROM categories AS children WHERE children.parent_id = categories.id)",
+		`NOT EXISTS (SELECT 1 FROM compatibility_rules JOIN category_attributes
+			ON category_attributes.id IN (compatibility_rules.accessory_attribute_id, compatibility_rules.device_attribute_id)
+			WHERE category_attributes.category_id = categories.id)`,
+	}
+)
+
+type TrashService interface {
+	GetDeletedProducts() ([]models.TrashEntry, error)
+	GetDeletedBrands() ([]models.TrashEntry, error)
+	GetDeletedCategories() ([]models.TrashEntry, error)
+	RestoreProduct(id uint) (models.Product, error)
+	RestoreBrand(id uint) (models.Brand, error)
+	RestoreCategory(id uint) (models.Category, error)
+	PurgeExpired(ctx context.Context) error
+}
+
+type trashService struct {
+	db        *gorm.DB
+	storage   storage.Storage
+	retention time.Duration
+}
+
+func NewTrashService(db *gorm.DB, store storage.Storage, retention time.Duration) TrashService {
+	return &trashService{db: db, storage: store, retention: retention}
+}
+
+func (s *trashService) GetDeletedProducts() ([]models.TrashEntry, error) {
+	return s.deletedEntries(&models.Product{})
+}
+
+func (s *trashService) GetDeletedBrands() ([]models.TrashEntry, error) {
+	return s.deletedEntries(&models.Brand{})
+}
+
+func (s *trashService) GetDeletedCategories() ([]models.TrashEntry, error) {
+	return s.deletedEntries(&models.Category{})
+}
+
+// deletedEntries lists the soft-deleted rows of a table, latest first
+func (s *trashService) deletedEntries(model interface{}) ([]models.TrashEntry, error) {
+	entries := []models.TrashEntry{}
+	err := s.db.Unscoped().Model(model).
+		Select("id, name, slug, deleted_at").
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC, id DESC").
+		Scan(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].PurgeAt = entries[i].DeletedAt.Add(s.retention)
+	}
+	return entries, nil
+}
+
+// RestoreProduct brings a deleted product back; its category and brand must be restored first
+func (s *trashService) RestoreProduct(id uint) (models.Product, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := findDeleted(tx, &product, id); err != nil {
+			return err
+		}
+		if err := requireLive(tx, &models.Category{}, product.CategoryID, "Restore the category of the product first"); err != nil {
+			return err
+		}
+		if product.BrandID != nil {
+			if err := requireLive(tx, &models.Brand{}, *product.BrandID, "Restore the brand of the product first"); err != nil {
+				return err
+			}
+		}
+		return restoreRow(tx, &models.Product{}, models.SlugEntityProduct, product.ID, product.Name, product.Slug)
+	})
+	if err != nil {
+		return models.Product{}, err
+	}
+	return (&productService{db: s.db}).GetProductById(strconv.FormatUint(uint64(id), 10))
+}
+
+func (s *trashService) RestoreBrand(id uint) (models.Brand, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var brand models.Brand
+		if err := findDeleted(tx, &brand, id); err != nil {
+			return err
+		}
+		return restoreRow(tx, &models.Brand{}, models.SlugEntityBrand, brand.ID, brand.Name, brand.Slug)
+	})
+	if err != nil {
+		return models.Brand{}, err
+	}
+	var brand models.Brand
+	err = s.db.First(&brand, id).Error
+	return brand, err
+}
+
+// RestoreCategory brings a deleted category back under its parent, which must be restored first.
+// Category names are unique, so a name taken since then blocks the restore.
+func (s *trashService) RestoreCategory(id uint) (models.Category, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var category models.Category
+		if err := findDeleted(tx, &category, id); err != nil {
+			return err
+		}
+		if category.ParentID != nil {
+			if err := requireLive(tx, &models.Category{}, *category.ParentID, "Restore the parent category first"); err != nil {
+				return err
+			}
+		}
+		var sameName int64
+		if err := tx.Model(&models.Category{}).Where("name = ?", category.Name).Count(&sameName).Error; err != nil {
+			return err
+		}
+		if sameName > 0 {
+			return apperrors.NewConflict(fmt.Sprintf("Another category is named %q, rename it first", category.Name))
+		}
+		return restoreRow(tx, &models.Category{}, models.SlugEntityCategory, category.ID, category.Name, category.Slug)
+	})
+	if err != nil {
+		return models.Category{}, err
+	}
+	var category models.Category
+	err = s.db.First(&category, id).Error
+	return category, err
+}
+
+// findDeleted locks a soft-deleted row; a live or missing row is not found
+func findDeleted(tx *gorm.DB, dest interface{}, id uint) error {
+	return tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("deleted_at IS NOT NULL").
+		First(dest, id).Error
+}
+
+func requireLive(tx *gorm.DB, model interface{}, id uint, message string) error {
+	var count int64
+	if err := tx.Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return apperrors.NewConflict(message)
+	}
+	return nil
+}
+
+// restoreRow clears the deletion of a row. A live entity may have taken its slug in the meantime;
+// the row then gets a new slug generated from its name.
+func restoreRow(tx *gorm.DB, model interface{}, entityType string, id uint, name, slug string) error {
+	taken, err := slugInUse(tx, model, slug, id)
+	if err != nil {
+		return err
+	}
+	if taken {
+		if slug, err = assignSlug(tx, model, entityType, "", name, id); err != nil {
+			return err
+		}
+	}
+	if err := claimSlug(tx, entityType, id, "", slug); err != nil {
+		return err
+	}
+	return tx.Unscoped().Model(model).Where("id = ?", id).Updates(map[string]interface{}{
+		"deleted_at": nil,
+		"slug":       slug,
+	}).Error
+}
+
+// PurgeExpired deletes for good the products, brands and categories deleted longer than the
+// retention period ago, with the data that only belongs to them. Products go first so that their
+// brands and categories can follow in the same run.
+func (s *trashService) PurgeExpired(ctx context.Context) error {
+	db := s.db.WithContext(ctx)
+	cutoff := time.Now().Add(-s.retention)
+	if err := s.purgeProducts(ctx, db, cutoff); err != nil {
+		return err
+	}
+	if err := s.purgeBrands(ctx, db, cutoff); err != nil {
+		return err
+	}
+	return s.purgeCategories(db, cutoff)
+}
+
+func (s *trashService) purgeProducts(ctx context.Context, db *gorm.DB, cutoff time.Time) error {
+	ids, err := expiredIDs(db, &models.Product{}, "products", cutoff, productPurgeBlockers)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	var keys []string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var images []models.ProductImage
+		err := tx.Unscoped().
+			Preload("Variants", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+			Where("product_id IN ?", ids).
+			Find(&images).Error
+		if err != nil {
+			return err
+		}
+		imageIDs := []uint{}
+		for _, image := range images {
+			imageIDs = append(imageIDs, image.ID)
+			keys = append(keys, image.StorageKey)
+			for _, variant := range image.Variants {
+				keys = append(keys, variant.StorageKey)
+			}
+		}
+
+		variantIDs := tx.Unscoped().Model(&models.ProductVariant{}).Select("id").Where("product_id IN ?", ids)
+		owned := []struct {
+			model     interface{}
+			condition string
+			value     interface{}
+		}{
+			{&models.ProductImageVariant{}, "product_image_id IN ?", imageIDs},
+			{&models.ProductImage{}, "product_id IN ?", ids},
+			{&models.ProductVariantOption{}, "variant_id IN (?)", variantIDs},
+			{&models.ProductVariant{}, "product_id IN ?", ids},
+			{&models.ProductAttributeValue{}, "product_id IN ?", ids},
+			{&models.BundleItem{}, "bundle_id IN ?", ids},
+			{&models.CartItem{}, "product_id IN ?", ids},
+			{&models.ProductRecommendation{}, "product_id IN ?", ids},
+			{&models.ProductRecommendation{}, "recommended_id IN ?", ids},
+			{&models.ProductCompatibility{}, "accessory_id IN ?", ids},
+			{&models.ProductCompatibility{}, "device_id IN ?", ids},
+			{&models.PriceHistory{}, "product_id IN ?", ids},
+			{&models.ScheduledPrice{}, "product_id IN ?", ids},
+		}
+		for _, rows := range owned {
+			if err := tx.Unscoped().Where(rows.condition, rows.value).Delete(rows.model).Error; err != nil {
+				return err
+			}
+		}
+		return purgeRows(tx, &models.Product{}, models.SlugEntityProduct, ids)
+	})
+	if err != nil {
+		return err
+	}
+	s.deleteFiles(ctx, keys)
+	return nil
+}
+
+func (s *trashService) purgeBrands(ctx context.Context, db *gorm.DB, cutoff time.Time) error {
+	ids, err := expiredIDs(db, &models.Brand{}, "brands", cutoff, brandPurgeBlockers)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	var keys []string
+	if err := db.Unscoped().Model(&models.Brand{}).Where("id IN ? AND logo_storage_key <> ''", ids).Pluck("logo_storage_key", &keys).Error; err != nil {
+		return err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return purgeRows(tx, &models.Brand{}, models.SlugEntityBrand, ids)
+	})
+	if err != nil {
+		return err
+	}
+	s.deleteFiles(ctx, keys)
+	return nil
+}
+
+func (s *trashService) purgeCategories(db *gorm.DB, cutoff time.Time) error {
+	ids, err := expiredIDs(db, &models.Category{}, "categories", cutoff, categoryPurgeBlockers)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("category_id IN ?", ids).Delete(&models.CategoryAttribute{}).Error; err != nil {
+			return err
+		}
+		return purgeRows(tx, &models.Category{}, models.SlugEntityCategory, ids)
+	})
+}
+
+// expiredIDs selects the rows deleted before the cutoff that nothing refers to anymore
+func expiredIDs(db *gorm.DB, model interface{}, table string, cutoff time.Time, blockers []string) ([]uint, error) {
+	query := db.Unscoped().Model(model).Where(table+".deleted_at < ?", cutoff)
+	for _, blocker := range blockers {
+		query = query.Where(blocker)
+	}
+	var ids []uint
+	err := query.Pluck(table+".id", &ids).Error
+	return ids, err
+}
+
+// purgeRows deletes rows for good along with their slug redirects
+func purgeRows(tx *gorm.DB, model interface{}, entityType string, ids []uint) error {
+	if err := tx.Where("entity_type = ? AND entity_id IN ?", entityType, ids).Delete(&models.SlugRedirect{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("id IN ?", ids).Delete(model).Error
+}
+
+// deleteFiles removes stored files of purged rows; a leftover file only wastes space
+func (s *trashService) deleteFiles(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if key != "" {
+			s.storage.Delete(ctx, key)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "api_techstore/pkg/errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WarrantyService interface {
+	RecordSerialNumbers(orderID, itemID uint, serialNumbers []string, recordedBy uint) ([]models.SoldUnit, error)
+	GetUserWarranties(userID uint) ([]models.SoldUnit, error)
+	GetBySerialNumber(serialNumber string) (models.SoldUnit, error)
+}
+
+type warrantyService struct {
+	db *gorm.DB
+}
+
+func NewWarrantyService(db *gorm.DB) WarrantyService {
+	return &warrantyService{db: db}
+}
+
+// NormalizeSerialNumber is the stored form of a serial number: trimmed and upper case
+func NormalizeSerialNumber(serialNumber string) string {
+	return strings.ToUpper(strings.TrimSpace(serialNumber))
+}
+
+// RecordSerialNumbers records the units shipped for an order line, at most its quantity, once the
+// order is shipped or delivered. Their warranty starts now.
+func (s *warrantyService) RecordSerialNumbers(orderID, itemID uint, serialNumbers []string, recordedBy uint) ([]models.SoldUnit, error) {
+	normalized := make([]string, 0, len(serialNumbers))
+	seen := make(map[string]bool, len(serialNumbers))
+	for _, serialNumber := range serialNumbers {
+		serialNumber = NormalizeSerialNumber(serialNumber)
+		if serialNumber == "" {
+			return nil, apperrors.NewValidationFailed("serial numbers must not be blank")
+		}
+		if seen[serialNumber] {
+			return nil, apperrors.NewValidationFailed(fmt.Sprintf("serial number '%s' is listed twice", serialNumber))
+		}
+		seen[serialNumber] = true
+		normalized = append(normalized, serialNumber)
+	}
+
+	var units []models.SoldUnit
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var item models.OrderItem
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("order_id = ?", orderID).
+			First(&item, itemID).Error
+		if err != nil {
+			return err
+		}
+		var order models.Order
+		if err := tx.Select("id", "status").First(&order, orderID).Error; err != nil {
+			return err
+		}
+		if order.Status != "shipped" && order.Status != "delivered" {
+			return apperrors.NewConflict("Serial numbers are recorded once the order is shipped")
+		}
+
+		var recorded int64
+		if err := tx.Model(&models.SoldUnit{}).Where("order_item_id = ?", item.ID).Count(&recorded).Error; err != nil {
+			return err
+		}
+		if int(recorded)+len(normalized) > item.Quantity {
+			return apperrors.NewValidationFailed(fmt.Sprintf("the order line has %d units, %d already recorded", item.Quantity, recorded))
+		}
+		var taken []string
+		if err := tx.Model(&models.SoldUnit{}).Where("serial_number IN ?", normalized).Pluck("serial_number", &taken).Error; err != nil {
+			return err
+		}
+		if len(taken) > 0 {
+			return apperrors.NewConflict(fmt.Sprintf("Serial number '%s' is already recorded", taken[0]))
+		}
+
+		months, err := warrantyMonths(tx, item.ProductID)
+		if err != nil {
+			return err
+		}
+		start := time.Now()
+		for _, serialNumber := range normalized {
+			units = append(units, models.SoldUnit{
+				OrderItemID:      item.ID,
+				ProductID:        item.ProductID,
+				VariantID:        item.VariantID,
+				SerialNumber:     serialNumber,
+				WarrantyMonths:   months,
+				WarrantyStartsAt: start,
+				WarrantyEndsAt:   start.AddDate(0, months, 0),
+				RecordedBy:       recordedBy,
+			})
+		}
+		return tx.Create(&units).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	setWarrantyStatus(units)
+	return units, nil
+}
+
+// warrantyMonths is the warranty period of a product, else the one of its brand, else none
+func warrantyMonths(db *gorm.DB, productID uint) (int, error) {
+	var product models.Product
+	if err := db.Preload("Brand").Select("id", "brand_id", "warranty_months").First(&product, productID).Error; err != nil {
+		return 0, err
+	}
+	if product.WarrantyMonths != nil {
+		return *product.WarrantyMonths, nil
+	}
+	if product.Brand != nil && product.Brand.WarrantyMonths != nil {
+		return *product.Brand.WarrantyMonths, nil
+	}
+	return 0, nil
+}
+
+// GetUserWarranties lists the units bought by a user, latest warranty end first
+func (s *warrantyService) GetUserWarranties(userID uint) ([]models.SoldUnit, error) {
+	var units []models.SoldUnit
+	err := s.db.Preload("Product").Preload("Product.Images", orderedImages).Preload("OrderItem").
+		Joins("JOIN order_items ON order_items.id = sold_units.order_item_id").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.user_id = ?", userID).
+		Order("sold_units.warranty_ends_at DESC, sold_units.id DESC").
+		Find(&units).Error
+	if err != nil {
+		return nil, err
+	}
+	setWarrantyStatus(units)
+	return units, nil
+}
+
+// GetBySerialNumber finds a sold unit with its order line, for staff
+func (s *warrantyService) GetBySerialNumber(serialNumber string) (models.SoldUnit, error) {
+	var unit models.SoldUnit
+	err := s.db.Preload("Product").Preload("OrderItem").
+		Where("serial_number = ?", NormalizeSerialNumber(serialNumber)).
+		First(&unit).Error
+	if err != nil {
+		return models.SoldUnit{}, err
+	}
+	units := []models.SoldUnit{unit}
+	setWarrantyStatus(units)
+	return units[0], nil
+}
+
+func setWarrantyStatus(units []models.SoldUnit) {
+	now := time.Now()
+	for i := range units {
+		switch {
+		case units[i].WarrantyMonths == 0:
+			units[i].WarrantyStatus = models.WarrantyStatusNone
+		case now.Before(units[i].WarrantyEndsAt):
+			units[i].WarrantyStatus = models.WarrantyStatusActive
+		default:
+			units[i].WarrantyStatus = models.WarrantyStatusExpired
+		}
+	}
+}
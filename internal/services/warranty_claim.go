@@ -0,0 +1,387 @@
+package services
+
+import (
+	"api_techstore/internal/models"
+	apperrors "api_techstore/pkg/errors"
+	"api_techstore/pkg/storage"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const MaxClaimAttachmentSize = 10 << 20 // 10MB
+
+// allowedAttachmentTypes maps accepted (sniffed) MIME types of claim attachments to the stored file extension
+var allowedAttachmentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+type WarrantyClaimService interface {
+	CreateClaim(ctx context.Context, userID uint, claim models.WarrantyClaim, serialNumber string, files []*multipart.FileHeader) (models.WarrantyClaim, error)
+	GetUserClaims(userID uint) ([]models.WarrantyClaim, error)
+	GetClaims(status string) ([]models.WarrantyClaim, error)
+	GetClaim(claimID, userID uint, isStaff bool) (models.WarrantyClaim, error)
+	AddAttachments(ctx context.Context, claimID, userID uint, isStaff bool, files []*multipart.FileHeader) (models.WarrantyClaim, error)
+	AddNote(claimID, authorID uint, content string, internal bool) (models.WarrantyClaim, error)
+	UpdateStatus(claimID, staffID uint, status, note string) (models.WarrantyClaim, error)
+}
+
+type warrantyClaimService struct {
+	db      *gorm.DB
+	storage storage.Storage
+}
+
+func NewWarrantyClaimService(db *gorm.DB, store storage.Storage) WarrantyClaimService {
+	return &warrantyClaimService{db: db, storage: store}
+}
+
+// oldestFirst orders the notes and attachments of a claim
+func oldestFirst(db *gorm.DB) *gorm.DB {
+	return db.Order("created_at, id")
+}
+
+// publicClaimNotes are the notes shown to the customer
+func publicClaimNotes(db *gorm.DB) *gorm.DB {
+	return oldestFirst(db).Where("internal = ?", false)
+}
+
+func (s *warrantyClaimService) preloadClaim(db *gorm.DB, isStaff bool) *gorm.DB {
+	notes := publicClaimNotes
+	if isStaff {
+		notes = oldestFirst
+	}
+	return db.Preload("OrderItem").Preload("OrderItem.Product").Preload("SoldUnit").
+		Preload("Notes", notes).Preload("Attachments", oldestFirst)
+}
+
+// CreateClaim opens a claim for an order line delivered to the user. With a serial number the
+// claim is about that unit of the line, and records whether its warranty is still active. A unit,
+// or a line without serial number, has one open claim at a time.
+func (s *warrantyClaimService) CreateClaim(ctx context.Context, userID uint, claim models.WarrantyClaim, serialNumber string, files []*multipart.FileHeader) (models.WarrantyClaim, error) {
+	if len(files) > models.MaxClaimAttachments {
+		return models.WarrantyClaim{}, apperrors.NewValidationFailed(fmt.Sprintf("A claim can have at most %d attachments", models.MaxClaimAttachments))
+	}
+
+	var item models.OrderItem
+	err := s.db.Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("order_items.id = ? AND orders.user_id = ?", claim.OrderItemID, userID).
+		Select("order_items.*").
+		First(&item).Error
+	if err == gorm.ErrRecordNotFound {
+		return models.WarrantyClaim{}, apperrors.NewNotFound("Order item")
+	}
+	if err != nil {
+		return models.WarrantyClaim{}, err
+	}
+	var order models.Order
+	if err := s.db.Select("id", "status").First(&order, item.OrderID).Error; err != nil {
+		return models.WarrantyClaim{}, err
+	}
+	if order.Status != "delivered" {
+		return models.WarrantyClaim{}, apperrors.NewValidationFailed("Claims can only be opened for delivered orders")
+	}
+
+	claim.UserID = userID
+	claim.SoldUnitID = nil
+	claim.UnderWarranty = false
+	if serialNumber = NormalizeSerialNumber(serialNumber); serialNumber != "" {
+		var unit models.SoldUnit
+		err := s.db.Where("order_item_id = ? AND serial_number = ?", item.ID, serialNumber).First(&unit).Error
+		if err == gorm.ErrRecordNotFound {
+			return models.WarrantyClaim{}, apperrors.NewNotFound("Serial number on this order item")
+		}
+		if err != nil {
+			return models.WarrantyClaim{}, err
+		}
+		units := []models.SoldUnit{unit}
+		setWarrantyStatus(units)
+		claim.SoldUnitID = &unit.ID
+		claim.UnderWarranty = units[0].WarrantyStatus == models.WarrantyStatusActive
+	}
+
+	open := s.db.Model(&models.WarrantyClaim{}).
+		Where("order_item_id = ? AND status IN ?", item.ID, openClaimStatuses())
+	if claim.SoldUnitID != nil {
+		open = open.Where("sold_unit_id = ?", *claim.SoldUnitID)
+	} else {
+		open = open.Where("sold_unit_id IS NULL")
+	}
+	var existing int64
+	if err := open.Count(&existing).Error; err != nil {
+		return models.WarrantyClaim{}, err
+	}
+	if existing > 0 {
+		return models.WarrantyClaim{}, apperrors.NewConflict("An open claim already exists for this item")
+	}
+
+	uploads, err := readClaimAttachments(files)
+	if err != nil {
+		return models.WarrantyClaim{}, err
+	}
+
+	claim.Status = models.ClaimStatusSubmitted
+	if err := s.db.Omit("Attachments", "Notes").Create(&claim).Error; err != nil {
+		return models.WarrantyClaim{}, err
+	}
+	if err := s.storeAttachments(ctx, s.db, claim.ID, userID, uploads); err != nil {
+		s.db.Delete(&claim)
+		return models.WarrantyClaim{}, err
+	}
+	return s.GetClaim(claim.ID, userID, false)
+}
+
+// GetUserClaims lists the claims of a user, newest first
+func (s *warrantyClaimService) GetUserClaims(userID uint) ([]models.WarrantyClaim, error) {
+	var claims []models.WarrantyClaim
+	err := s.preloadClaim(s.db, false).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Find(&claims).Error
+	return claims, err
+}
+
+// GetClaims is the service-center queue: claims in the given status (or all), oldest first
+func (s *warrantyClaimService) GetClaims(status string) ([]models.WarrantyClaim, error) {
+	query := s.preloadClaim(s.db, true)
+	if status != "" {
+		if !isClaimStatus(status) {
+			return nil, apperrors.NewValidationFailed("status must be one of submitted, received, diagnosing, repaired, replaced, rejected")
+		}
+		query = query.Where("status = ?", status)
+	}
+	var claims []models.WarrantyClaim
+	err := query.Order("created_at, id").Find(&claims).Error
+	return claims, err
+}
+
+// GetClaim returns a claim to staff, or to the customer who opened it without the internal notes
+func (s *warrantyClaimService) GetClaim(claimID, userID uint, isStaff bool) (models.WarrantyClaim, error) {
+	query := s.preloadClaim(s.db, isStaff)
+	if !isStaff {
+		query = query.Where("user_id = ?", userID)
+	}
+	var claim models.WarrantyClaim
+	if err := query.First(&claim, claimID).Error; err != nil {
+		return models.WarrantyClaim{}, err
+	}
+	return claim, nil
+}
+
+// AddAttachments attaches files to a claim, by its customer or by staff, until it is closed
+func (s *warrantyClaimService) AddAttachments(ctx context.Context, claimID, userID uint, isStaff bool, files []*multipart.FileHeader) (models.WarrantyClaim, error) {
+	if len(files) == 0 {
+		return models.WarrantyClaim{}, apperrors.NewValidationFailed("attachments are required")
+	}
+	claim, err := s.GetClaim(claimID, userID, isStaff)
+	if err != nil {
+		return models.WarrantyClaim{}, err
+	}
+	if isClaimClosed(claim.Status) {
+		return models.WarrantyClaim{}, apperrors.NewConflict(fmt.Sprintf("Claim is %s", claim.Status))
+	}
+	if len(claim.Attachments)+len(files) > models.MaxClaimAttachments {
+		return models.WarrantyClaim{}, apperrors.NewValidationFailed(fmt.Sprintf("A claim can have at most %d attachments", models.MaxClaimAttachments))
+	}
+
+	uploads, err := readClaimAttachments(files)
+	if err != nil {
+		return models.WarrantyClaim{}, err
+	}
+	if err := s.storeAttachments(ctx, s.db, claimID, userID, uploads); err != nil {
+		return models.WarrantyClaim{}, err
+	}
+	return s.GetClaim(claimID, userID, isStaff)
+}
+
+// AddNote adds a staff note to a claim without changing its state
+func (s *warrantyClaimService) AddNote(claimID, authorID uint, content string, internal bool) (models.WarrantyClaim, error) {
+	if err := s.db.Select("id").First(&models.WarrantyClaim{}, claimID).Error; err != nil {
+		return models.WarrantyClaim{}, err
+	}
+	note := models.WarrantyClaimNote{
+		ClaimID:  claimID,
+		AuthorID: authorID,
+		Content:  content,
+		Internal: internal,
+	}
+	if err := s.db.Create(&note).Error; err != nil {
+		return models.WarrantyClaim{}, err
+	}
+	return s.GetClaim(claimID, authorID, true)
+}
+
+// UpdateStatus moves a claim along the service-center workflow, records the change as a note
+// and notifies the customer
+func (s *warrantyClaimService) UpdateStatus(claimID, staffID uint, status, note string) (models.WarrantyClaim, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var claim models.WarrantyClaim
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&claim, claimID).Error; err != nil {
+			return err
+		}
+		if !canMoveClaim(claim.Status, status) {
+			return apperrors.NewConflict(fmt.Sprintf("Claim cannot move from %s to %s", claim.Status, status))
+		}
+		if err := tx.Model(&claim).Update("status", status).Error; err != nil {
+			return err
+		}
+		err := tx.Create(&models.WarrantyClaimNote{
+			ClaimID:  claimID,
+			AuthorID: staffID,
+			Content:  note,
+			Status:   status,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return notifyClaimStatus(tx, claim, status, note)
+	})
+	if err != nil {
+		return models.WarrantyClaim{}, err
+	}
+	return s.GetClaim(claimID, staffID, true)
+}
+
+// notifyClaimStatus tells the customer their claim moved to a new state
+func notifyClaimStatus(tx *gorm.DB, claim models.WarrantyClaim, status, note string) error {
+	message := fmt.Sprintf("Your warranty claim #%d is now %s.", claim.ID, status)
+	if note != "" {
+		message += " " + note
+	}
+	claimID := claim.ID
+	return notify(tx, models.Notification{
+		UserID:     claim.UserID,
+		Type:       models.NotificationClaimStatus,
+		Title:      "Your warranty claim was updated",
+		Message:    message,
+		EntityType: "warranty_claim",
+		EntityID:   &claimID,
+	})
+}
+
+type claimUpload struct {
+	name        string
+	data        []byte
+	contentType string
+}
+
+// readClaimAttachments reads every file before anything is stored, so an invalid file does not
+// leave uploads behind
+func readClaimAttachments(files []*multipart.FileHeader) ([]claimUpload, error) {
+	uploads := make([]claimUpload, 0, len(files))
+	for _, file := range files {
+		upload, err := readClaimAttachment(file)
+		if err != nil {
+			return nil, err
+		}
+		uploads = append(uploads, upload)
+	}
+	return uploads, nil
+}
+
+func readClaimAttachment(file *multipart.FileHeader) (claimUpload, error) {
+	tooLarge := apperrors.New(apperrors.ErrCodeFileTooLarge,
+		fmt.Sprintf("Attachments must not exceed %dMB", MaxClaimAttachmentSize>>20), http.StatusRequestEntityTooLarge)
+	if file.Size > MaxClaimAttachmentSize {
+		return claimUpload{}, tooLarge
+	}
+	src, err := file.Open()
+	if err != nil {
+		return claimUpload{}, apperrors.NewValidationFailed("Unable to read uploaded attachment")
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(src, MaxClaimAttachmentSize+1))
+	if err != nil {
+		return claimUpload{}, apperrors.NewValidationFailed("Unable to read uploaded attachment")
+	}
+	if len(data) > MaxClaimAttachmentSize {
+		return claimUpload{}, tooLarge
+	}
+	contentType := http.DetectContentType(data)
+	if _, ok := allowedAttachmentTypes[contentType]; !ok {
+		return claimUpload{}, apperrors.New(apperrors.ErrCodeUnsupportedFileType,
+			"Only JPEG, PNG, WebP and PDF attachments are allowed", http.StatusUnsupportedMediaType)
+	}
+	return claimUpload{name: filepath.Base(file.Filename), data: data, contentType: contentType}, nil
+}
+
+// storeAttachments writes the uploads to storage and records them; on failure the stored files are removed
+func (s *warrantyClaimService) storeAttachments(ctx context.Context, db *gorm.DB, claimID, uploadedBy uint, uploads []claimUpload) error {
+	if len(uploads) == 0 {
+		return nil
+	}
+	var storedKeys []string
+	cleanup := func() {
+		for _, key := range storedKeys {
+			s.storage.Delete(ctx, key)
+		}
+	}
+	attachments := make([]models.WarrantyClaimAttachment, 0, len(uploads))
+	for _, upload := range uploads {
+		key := fmt.Sprintf("warranty-claims/%d/%s%s", claimID, uuid.NewString(), allowedAttachmentTypes[upload.contentType])
+		url, err := s.storage.Put(ctx, key, bytes.NewReader(upload.data), int64(len(upload.data)), upload.contentType)
+		if err != nil {
+			cleanup()
+			return apperrors.NewStorageError(err)
+		}
+		storedKeys = append(storedKeys, key)
+		attachments = append(attachments, models.WarrantyClaimAttachment{
+			ClaimID:     claimID,
+			UploadedBy:  uploadedBy,
+			FileName:    truncate(upload.name, 255),
+			FileURL:     url,
+			StorageKey:  key,
+			ContentType: upload.contentType,
+		})
+	}
+	if err := db.Create(&attachments).Error; err != nil {
+		cleanup()
+		return err
+	}
+	return nil
+}
+
+func truncate(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	return strings.ToValidUTF8(value[:limit], "")
+}
+
+func openClaimStatuses() []string {
+	return []string{models.ClaimStatusSubmitted, models.ClaimStatusReceived, models.ClaimStatusDiagnosing}
+}
+
+func isClaimClosed(status string) bool {
+	return len(models.ClaimTransitions[status]) == 0
+}
+
+func canMoveClaim(from, to string) bool {
+	for _, next := range models.ClaimTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func isClaimStatus(status string) bool {
+	switch status {
+	case models.ClaimStatusSubmitted, models.ClaimStatusReceived, models.ClaimStatusDiagnosing,
+		models.ClaimStatusRepaired, models.ClaimStatusReplaced, models.ClaimStatusRejected:
+		return true
+	}
+	return false
+}
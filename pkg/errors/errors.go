@@ -29,6 +29,7 @@ const (
 	ErrCodeNotFound        ErrorCode = "NOT_FOUND"
 	ErrCodeAlreadyExists   ErrorCode = "ALREADY_EXISTS"
 	ErrCodeResourceDeleted ErrorCode = "RESOURCE_DELETED"
+	ErrCodeConflict        ErrorCode = "CONFLICT"
 
 	// Database errors
 	ErrCodeDatabaseError     ErrorCode = "DATABASE_ERROR"
@@ -41,10 +42,15 @@ const (
 	ErrCodeCartEmpty         ErrorCode = "CART_EMPTY"
 	ErrCodeOrderCancelled    ErrorCode = "ORDER_CANCELLED"
 
+	// File upload errors
+	ErrCodeFileTooLarge        ErrorCode = "FILE_TOO_LARGE"
+	ErrCodeUnsupportedFileType ErrorCode = "UNSUPPORTED_FILE_TYPE"
+
 	// External service errors
 	ErrCodeRedisError      ErrorCode = "REDIS_ERROR"
 	ErrCodePaymentFailed   ErrorCode = "PAYMENT_FAILED"
 	ErrCodeEmailSendFailed ErrorCode = "EMAIL_SEND_FAILED"
+	ErrCodeStorageError    ErrorCode = "STORAGE_ERROR"
 
 	// Internal errors
 	ErrCodeInternalError      ErrorCode = "INTERNAL_ERROR"
@@ -147,6 +153,10 @@ func NewAlreadyExists(resource string) *AppError {
 	return New(ErrCodeAlreadyExists, fmt.Sprintf("%s already exists", resource), http.StatusConflict)
 }
 
+func NewConflict(message string) *AppError {
+	return New(ErrCodeConflict, message, http.StatusConflict)
+}
+
 func NewValidationFailed(details string) *AppError {
 	return NewWithDetails(ErrCodeValidationFailed, "Validation failed", details, http.StatusBadRequest)
 }
@@ -159,6 +169,10 @@ func NewRedisError(err error) *AppError {
 	return NewWithError(ErrCodeRedisError, "Redis operation failed", http.StatusInternalServerError, err)
 }
 
+func NewStorageError(err error) *AppError {
+	return NewWithError(ErrCodeStorageError, "File storage operation failed", http.StatusInternalServerError, err)
+}
+
 func NewInternalError(err error) *AppError {
 	return NewWithError(ErrCodeInternalError, "Internal server error", http.StatusInternalServerError, err)
 }
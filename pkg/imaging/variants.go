@@ -0,0 +1,331 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+
+	// register the WebP decoder for image.Decode
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+
+	jpegQuality = 85
+	// MaxPixels is the most pixels an image is kept with: larger uploads are scaled down to it, which
+	// still covers a 12MP phone photo and bounds every later decode to 64MB of RGBA
+	MaxPixels = 16_000_000
+	// MaxOriginalSize is the largest original kept byte for byte; a heavier upload is re-encoded
+	MaxOriginalSize = 5 << 20
+	// maxDecodePixels guards against decompression bombs, a few MB that decode to gigabytes; it is
+	// well above what cameras take, so a real photo is always downscaled rather than refused
+	maxDecodePixels = 60_000_000
+)
+
+// Size is a named bounding box an image is scaled down to fit in
+type Size struct {
+	Name      string
+	MaxWidth  int
+	MaxHeight int
+}
+
+// DefaultSizes are the responsive sizes generated for every product image
+var DefaultSizes = []Size{
+	{Name: "thumbnail", MaxWidth: 150, MaxHeight: 150},
+	{Name: "medium", MaxWidth: 600, MaxHeight: 600},
+	{Name: "large", MaxWidth: 1200, MaxHeight: 1200},
+}
+
+// Variant is one encoded rendition of the source image
+type Variant struct {
+	Name        string
+	Format      string
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+// Dimensions reads the displayed width and height from the image header without decoding the
+// pixels; a JPEG turned by its EXIF orientation has them swapped
+func Dimensions(data []byte) (int, int, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, err
+	}
+	if orientation(data) >= 5 {
+		return cfg.Height, cfg.Width, nil
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// Downscale returns the upload as the original to keep: scaled down to MaxPixels when it has more,
+// and re-encoded upright when it is scaled or heavier than MaxOriginalSize, in the format
+// GenerateVariants would pick. ok is false when the upload can be kept as it is.
+func Downscale(data []byte) (original Variant, ok bool, err error) {
+	width, height, err := decodableDimensions(data)
+	if err != nil {
+		return Variant{}, false, err
+	}
+	if width*height <= MaxPixels && len(data) <= MaxOriginalSize {
+		return Variant{}, false, nil
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Variant{}, false, fmt.Errorf("failed to decode image: %w", err)
+	}
+	// the box is given upright, so a quarter turn swaps it like in GenerateVariants
+	maxWidth, maxHeight := width, height
+	if width*height > MaxPixels {
+		scale := math.Sqrt(float64(MaxPixels) / float64(width*height))
+		maxWidth, maxHeight = int(float64(width)*scale), int(float64(height)*scale)
+	}
+	turn := orientation(data)
+	if turn >= 5 {
+		maxWidth, maxHeight = maxHeight, maxWidth
+	}
+	resized := orient(Fit(src, maxWidth, maxHeight), turn)
+
+	out := baseFormat(format, src)
+	encoded, err := Encode(resized, out)
+	if err != nil {
+		return Variant{}, false, fmt.Errorf("failed to encode %s original: %w", out, err)
+	}
+	return Variant{
+		Name:        "original",
+		Format:      out,
+		ContentType: "image/" + out,
+		Extension:   extension(out),
+		Width:       resized.Bounds().Dx(),
+		Height:      resized.Bounds().Dy(),
+		Data:        encoded,
+	}, true, nil
+}
+
+// GenerateVariants decodes the source image and renders every size upright, in a raster format
+// (PNG for PNG sources and anything with transparency, JPEG otherwise) and in WebP, which browsers
+// prefer when they can. Images are never upscaled.
+func GenerateVariants(data []byte, sizes []Size) ([]Variant, error) {
+	if _, _, err := decodableDimensions(data); err != nil {
+		return nil, err
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	turn := orientation(data)
+	formats := []string{baseFormat(format, src), FormatWebP}
+
+	variants := make([]Variant, 0, len(sizes)*len(formats))
+	for _, size := range sizes {
+		// the stored pixels are turned after scaling, so a quarter turn swaps the box
+		maxWidth, maxHeight := size.MaxWidth, size.MaxHeight
+		if turn >= 5 {
+			maxWidth, maxHeight = maxHeight, maxWidth
+		}
+		resized := orient(Fit(src, maxWidth, maxHeight), turn)
+
+		for _, out := range formats {
+			encoded, err := Encode(resized, out)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode %s %s variant: %w", size.Name, out, err)
+			}
+			variants = append(variants, Variant{
+				Name:        size.Name,
+				Format:      out,
+				ContentType: "image/" + out,
+				Extension:   extension(out),
+				Width:       resized.Bounds().Dx(),
+				Height:      resized.Bounds().Dy(),
+				Data:        encoded,
+			})
+		}
+	}
+	return variants, nil
+}
+
+// decodableDimensions reads the dimensions from the header, refusing an image too large to decode
+func decodableDimensions(data []byte) (int, int, error) {
+	width, height, err := Dimensions(data)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read image header: %w", err)
+	}
+	if width*height > maxDecodePixels {
+		return 0, 0, fmt.Errorf("image is too large to process (%dx%d)", width, height)
+	}
+	return width, height, nil
+}
+
+// baseFormat is the raster format renditions are stored in: PNG keeps transparency, JPEG suits photos
+func baseFormat(sourceFormat string, src image.Image) string {
+	if sourceFormat == FormatPNG || !isOpaque(src) {
+		return FormatPNG
+	}
+	return FormatJPEG
+}
+
+// Fit scales src down to fit in maxWidth x maxHeight, keeping the aspect ratio
+func Fit(src image.Image, maxWidth, maxHeight int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	scale := 1.0
+	if width > maxWidth {
+		scale = float64(maxWidth) / float64(width)
+	}
+	if height > maxHeight && float64(maxHeight)/float64(height) < scale {
+		scale = float64(maxHeight) / float64(height)
+	}
+
+	targetWidth := max(1, int(float64(width)*scale+0.5))
+	targetHeight := max(1, int(float64(height)*scale+0.5))
+
+	dst := image.NewNRGBA(image.Rect(0, 0, targetWidth, targetHeight))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+	return dst
+}
+
+// Encode writes img in the given format
+func Encode(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case FormatJPEG:
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	case FormatPNG:
+		err = png.Encode(&buf, img)
+	case FormatWebP:
+		// nativewebp is a pure Go encoder, lossless (VP8L) only
+		err = nativewebp.Encode(&buf, img, nil)
+	default:
+		err = fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return true
+}
+
+func extension(format string) string {
+	if format == FormatJPEG {
+		return ".jpg"
+	}
+	return "." + format
+}
+
+// orientation reads the EXIF orientation of a JPEG, from 1 (upright) to 8; 1 when there is none
+func orientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	// walk the segments before the image data, looking for the EXIF one (APP1)
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xFF {
+		marker := data[pos+1]
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return 1
+		}
+		segment := data[pos+4 : pos+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		pos += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation reads the orientation tag from the first IFD of an EXIF TIFF block
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) != 0x0112 {
+			continue
+		}
+		if value := int(order.Uint16(tiff[entry+8:])); value >= 1 && value <= 8 {
+			return value
+		}
+		return 1
+	}
+	return 1
+}
+
+// orient turns an image upright according to its EXIF orientation
+func orient(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if orientation >= 5 {
+		width, height = height, width
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	w, h := bounds.Dx(), bounds.Dy()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored
+				dx, dy = w-1-x, y
+			case 3: // upside down
+				dx, dy = w-1-x, h-1-y
+			case 4: // upside down and mirrored
+				dx, dy = x, h-1-y
+			case 5: // mirrored and turned left
+				dx, dy = y, x
+			case 6: // turned left, shown turned right
+				dx, dy = h-1-y, x
+			case 7: // mirrored and turned right
+				dx, dy = h-1-y, w-1-x
+			case 8: // turned right, shown turned left
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,72 @@
+package slug
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength keeps generated slugs within the slug columns (varchar(100)),
+// leaving room for a collision suffix
+const MaxLength = 90
+
+// maxAttempts bounds the collision suffixes tried by Unique
+const maxAttempts = 1000
+
+var ErrNoFreeSlug = errors.New("slug: no free slug found")
+
+// Make turns a name into a URL slug: "Laptop Gaming Đồ Họa 15.6\"" -> "laptop-gaming-do-hoa-15-6".
+// Diacritics are stripped after NFD decomposition; đ/Đ has no decomposition and is mapped explicitly.
+func Make(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// combining mark left over from the decomposition (dấu)
+			continue
+		case r == 'đ' || r == 'Đ':
+			r = 'd'
+		}
+
+		r = unicode.ToLower(r)
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+
+	slug := b.String()
+	if len(slug) > MaxLength {
+		slug = slug[:MaxLength]
+		// don't cut a word in half when there is a word boundary to cut at
+		if i := strings.LastIndexByte(slug, '-'); i > 0 {
+			slug = slug[:i]
+		}
+	}
+	return slug
+}
+
+// Unique returns base, or base-2, base-3, ... for the first candidate taken reports as free
+func Unique(base string, taken func(candidate string) (bool, error)) (string, error) {
+	candidate := base
+	for i := 2; i <= maxAttempts; i++ {
+		exists, err := taken(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+		candidate = base + "-" + strconv.Itoa(i)
+	}
+	return "", ErrNoFreeSlug
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage keeps uploads on the local filesystem; the router serves Dir under URLPrefix
+type LocalStorage struct {
+	Dir       string
+	URLPrefix string
+	BaseURL   string
+}
+
+func NewLocalStorage(dir, urlPrefix, baseURL string) *LocalStorage {
+	return &LocalStorage{
+		Dir:       dir,
+		URLPrefix: "/" + strings.Trim(urlPrefix, "/"),
+		BaseURL:   strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error) {
+	target, cleaned, err := s.resolve(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	file, err := os.Create(target)
+	if err != nil {
+		return "", fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, body); err != nil {
+		os.Remove(target)
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+	return s.BaseURL + path.Join(s.URLPrefix, cleaned), nil
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	target, _, err := s.resolve(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+// resolve maps a key to a path inside Dir, rejecting keys that would escape it
+func (s *LocalStorage) resolve(key string) (string, string, error) {
+	// backslashes count as separators too, they are ones on Windows
+	for _, part := range strings.FieldsFunc(key, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if part == ".." {
+			return "", "", fmt.Errorf("invalid storage key %q", key)
+		}
+	}
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" {
+		return "", "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(cleaned)), cleaned, nil
+}
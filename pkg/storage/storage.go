@@ -0,0 +1,16 @@
+package storage
+
+import (
+	"context"
+	"io"
+)
+
+// Storage is the backend product images and other uploads are written to.
+// Keys are slash separated paths ("products/12/<uuid>.jpg") so the same key
+// works for the local filesystem and S3-compatible object stores.
+type Storage interface {
+	// Put stores the object under key and returns its public URL
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error)
+	// Delete removes the object; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+}
@@ -2,6 +2,8 @@ package mocks
 
 import (
 	"api_techstore/internal/models"
+	"context"
+	"mime/multipart"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -40,3 +42,27 @@ func (_m *BrandService) DeleteBrand(id string) error {
 	ret := _m.Called(id)
 	return ret.Error(0)
 }
+
+// ReassignAndDeleteBrand provides a mock function
+func (_m *BrandService) ReassignAndDeleteBrand(id string, targetID uint) error {
+	ret := _m.Called(id, targetID)
+	return ret.Error(0)
+}
+
+// GetBrandBySlug provides a mock function
+func (_m *BrandService) GetBrandBySlug(slug string) (models.Brand, error) {
+	ret := _m.Called(slug)
+	return ret.Get(0).(models.Brand), ret.Error(1)
+}
+
+// GetBrandPage provides a mock function
+func (_m *BrandService) GetBrandPage(slug string) (models.BrandPage, error) {
+	ret := _m.Called(slug)
+	return ret.Get(0).(models.BrandPage), ret.Error(1)
+}
+
+// SetBrandLogo provides a mock function
+func (_m *BrandService) SetBrandLogo(ctx context.Context, id uint, file *multipart.FileHeader) (models.Brand, error) {
+	ret := _m.Called(ctx, id, file)
+	return ret.Get(0).(models.Brand), ret.Error(1)
+}
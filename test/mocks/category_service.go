@@ -40,3 +40,33 @@ func (_m *CategoryService) DeleteCategory(id string) error {
 	ret := _m.Called(id)
 	return ret.Error(0)
 }
+
+// ReassignAndDeleteCategory provides a mock function
+func (_m *CategoryService) ReassignAndDeleteCategory(id string, targetID uint, dropAttributes bool) error {
+	ret := _m.Called(id, targetID, dropAttributes)
+	return ret.Error(0)
+}
+
+// MoveCategory provides a mock function
+func (_m *CategoryService) MoveCategory(id string, parentID *uint) (models.Category, error) {
+	ret := _m.Called(id, parentID)
+	return ret.Get(0).(models.Category), ret.Error(1)
+}
+
+// GetCategoryTree provides a mock function
+func (_m *CategoryService) GetCategoryTree() ([]models.Category, error) {
+	ret := _m.Called()
+	return ret.Get(0).([]models.Category), ret.Error(1)
+}
+
+// GetCategoryProducts provides a mock function
+func (_m *CategoryService) GetCategoryProducts(id string) ([]models.Product, error) {
+	ret := _m.Called(id)
+	return ret.Get(0).([]models.Product), ret.Error(1)
+}
+
+// GetCategoryBySlug provides a mock function
+func (_m *CategoryService) GetCategoryBySlug(slug string) (models.Category, error) {
+	ret := _m.Called(slug)
+	return ret.Get(0).(models.Category), ret.Error(1)
+}
@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"api_techstore/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// OrderService is a mock type for the OrderService type
+type OrderService struct {
+	mock.Mock
+}
+
+// GetAllOrders provides a mock function
+func (_m *OrderService) GetAllOrders() ([]models.Order, error) {
+	ret := _m.Called()
+	return ret.Get(0).([]models.Order), ret.Error(1)
+}
+
+// GetOrderByID provides a mock function
+func (_m *OrderService) GetOrderByID(id string) (models.Order, error) {
+	ret := _m.Called(id)
+	return ret.Get(0).(models.Order), ret.Error(1)
+}
+
+// CreateOrder provides a mock function
+func (_m *OrderService) CreateOrder(order models.Order, items []models.OrderItem, tradeInID *uint) (models.Order, error) {
+	ret := _m.Called(order, items, tradeInID)
+	return ret.Get(0).(models.Order), ret.Error(1)
+}
+
+// UpdateOrder provides a mock function
+func (_m *OrderService) UpdateOrder(id string, order models.Order) (models.Order, error) {
+	ret := _m.Called(id, order)
+	return ret.Get(0).(models.Order), ret.Error(1)
+}
+
+// DeleteOrder provides a mock function
+func (_m *OrderService) DeleteOrder(id string) error {
+	ret := _m.Called(id)
+	return ret.Error(0)
+}
+
+// GetOrdersByUserID provides a mock function
+func (_m *OrderService) GetOrdersByUserID(userID string) ([]models.Order, error) {
+	ret := _m.Called(userID)
+	return ret.Get(0).([]models.Order), ret.Error(1)
+}
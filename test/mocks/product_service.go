@@ -15,6 +15,11 @@ func (_m *ProductService) GetAllProducts() ([]models.Product, error) {
 	return ret.Get(0).([]models.Product), ret.Error(1)
 }
 
+func (_m *ProductService) GetAllProductsIncludingHidden() ([]models.Product, error) {
+	ret := _m.Called()
+	return ret.Get(0).([]models.Product), ret.Error(1)
+}
+
 func (_m *ProductService) GetProductById(id string) (models.Product, error) {
 	ret := _m.Called(id)
 	return ret.Get(0).(models.Product), ret.Error(1)
@@ -34,3 +39,8 @@ func (_m *ProductService) DeleteProduct(id string) error {
 	ret := _m.Called(id)
 	return ret.Error(0)
 }
+
+func (_m *ProductService) GetProductBySlug(slug string) (models.Product, error) {
+	ret := _m.Called(slug)
+	return ret.Get(0).(models.Product), ret.Error(1)
+}
@@ -0,0 +1,121 @@
+package unit
+
+import (
+	"api_techstore/internal/models"
+	"api_techstore/internal/services"
+	apperrors "api_techstore/pkg/errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// laptopAttributes are the spec attributes of a laptop category, in their sort order
+func laptopAttributes() []models.CategoryAttribute {
+	attribute := func(id uint, code, kind string, required bool, allowed ...string) models.CategoryAttribute {
+		a := models.CategoryAttribute{CategoryID: 3, Code: code, Name: code, Type: kind, IsRequired: required, AllowedValues: allowed}
+		a.ID = id
+		return a
+	}
+	return []models.CategoryAttribute{
+		attribute(1, "cpu", models.AttributeTypeString, true),
+		attribute(2, "ram_gb", models.AttributeTypeNumber, false),
+		attribute(3, "touchscreen", models.AttributeTypeBoolean, false),
+		attribute(4, "panel", models.AttributeTypeEnum, false, "IPS", "OLED"),
+	}
+}
+
+// specs renders merged values as code => value for comparisons
+func specs(values []models.ProductAttributeValue) map[string]string {
+	rendered := make(map[string]string, len(values))
+	for _, value := range values {
+		rendered[value.Attribute.Code] = services.AttributeValueString(value)
+	}
+	return rendered
+}
+
+func TestMergeProductAttributes_TypedValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		input map[string]interface{}
+		want  map[string]string
+	}{
+		{
+			name:  "JSON types",
+			input: map[string]interface{}{"cpu": " Core i7 ", "ram_gb": 16.0, "touchscreen": true, "panel": "oled"},
+			want:  map[string]string{"cpu": "Core i7", "ram_gb": "16", "touchscreen": "true", "panel": "OLED"},
+		},
+		{
+			name:  "numbers and flags as text, from CSV imports",
+			input: map[string]interface{}{"cpu": "Ryzen 7", "ram_gb": " 32 ", "touchscreen": "false"},
+			want:  map[string]string{"cpu": "Ryzen 7", "ram_gb": "32", "touchscreen": "false"},
+		},
+		{
+			name:  "numbers and flags as text attributes",
+			input: map[string]interface{}{"cpu": 5600.0},
+			want:  map[string]string{"cpu": "5600"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := services.MergeProductAttributes(laptopAttributes(), nil, tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, specs(values))
+		})
+	}
+}
+
+func TestMergeProductAttributes_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   map[string]interface{}
+		problem string
+	}{
+		{name: "unknown attribute", input: map[string]interface{}{"cpu": "i5", "gpu": "RTX"}, problem: "unknown attribute 'gpu'"},
+		{name: "text for a number", input: map[string]interface{}{"cpu": "i5", "ram_gb": "lots"}, problem: "ram_gb: must be a number"},
+		{name: "number for a flag", input: map[string]interface{}{"cpu": "i5", "touchscreen": 1.0}, problem: "touchscreen: must be true or false"},
+		{name: "value outside the enum", input: map[string]interface{}{"cpu": "i5", "panel": "TN"}, problem: "panel: must be one of IPS, OLED"},
+		{name: "blank text", input: map[string]interface{}{"cpu": "  "}, problem: "cpu: must not be empty"},
+		{name: "object for a text", input: map[string]interface{}{"cpu": map[string]interface{}{"brand": "Intel"}}, problem: "cpu: must be a text value"},
+		{name: "required attribute missing", input: map[string]interface{}{"ram_gb": 8.0}, problem: "cpu: is required"},
+		{name: "required attribute removed", input: map[string]interface{}{"cpu": nil}, problem: "cpu: is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := services.MergeProductAttributes(laptopAttributes(), nil, tt.input)
+			appErr := apperrors.GetAppError(err)
+			require.NotNil(t, appErr)
+			assert.Contains(t, appErr.Details, tt.problem)
+		})
+	}
+}
+
+func TestMergeProductAttributes_ReportsEveryProblem(t *testing.T) {
+	_, err := services.MergeProductAttributes(laptopAttributes(), nil, map[string]interface{}{"ram_gb": "lots", "gpu": "RTX"})
+
+	appErr := apperrors.GetAppError(err)
+	require.NotNil(t, appErr)
+	assert.Equal(t, "invalid attributes: cpu: is required; ram_gb: must be a number; unknown attribute 'gpu'", appErr.Details)
+}
+
+func TestMergeProductAttributes_MergesIntoCurrentValues(t *testing.T) {
+	ram, touch := 8.0, true
+	current := []models.ProductAttributeValue{
+		{ID: 10, AttributeID: 1, ValueText: "Core i5"},
+		{ID: 11, AttributeID: 2, ValueNumber: &ram},
+		{ID: 12, AttributeID: 3, ValueBool: &touch},
+		{ID: 13, AttributeID: 99, ValueText: "from the previous category"},
+	}
+
+	values, err := services.MergeProductAttributes(laptopAttributes(), current, map[string]interface{}{
+		"ram_gb":      16.0,
+		"touchscreen": nil,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"cpu": "Core i5", "ram_gb": "16"}, specs(values), "null removes, other values are kept")
+	for _, value := range values {
+		assert.Zero(t, value.ID, "values are written again")
+	}
+	assert.Equal(t, []uint{1, 2}, []uint{values[0].AttributeID, values[1].AttributeID}, "in the attribute order")
+}
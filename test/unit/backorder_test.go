@@ -0,0 +1,109 @@
+package unit
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"api_techstore/internal/models"
+	"api_techstore/internal/services"
+	apperrors "api_techstore/pkg/errors"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackorderAllowance(t *testing.T) {
+	tests := []struct {
+		name    string
+		product models.Product
+		want    int
+	}{
+		{"normal product", models.Product{StockPolicy: models.StockPolicyNormal, BackorderLimit: 10}, 0},
+		{"pre-order with room", models.Product{StockPolicy: models.StockPolicyPreorder, BackorderLimit: 10, BackorderedQuantity: 4}, 6},
+		{"backorder at its limit", models.Product{StockPolicy: models.StockPolicyBackorder, BackorderLimit: 5, BackorderedQuantity: 5}, 0},
+		{"limit lowered below waiting units", models.Product{StockPolicy: models.StockPolicyBackorder, BackorderLimit: 2, BackorderedQuantity: 5}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.product.BackorderAllowance())
+		})
+	}
+}
+
+func TestReceiveProductStock_InvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+	c.Request = httptest.NewRequest(http.MethodPost, "/products/abc/stock-receipts", nil)
+
+	handlers.ReceiveProductStock(c, &container.Container{})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSetStockPolicy_PreorderRequiresDate(t *testing.T) {
+	// the date is checked before the product is loaded
+	_, err := services.NewBackorderService(nil).SetStockPolicy(1, models.StockPolicyPreorder, nil, 10)
+
+	appErr := apperrors.GetAppError(err)
+	if assert.NotNil(t, appErr) {
+		assert.Equal(t, http.StatusBadRequest, appErr.HTTPStatus)
+	}
+}
+
+func TestReceiveStock_MatchesLines(t *testing.T) {
+	variantID := uint(2)
+	tests := []struct {
+		name      string
+		variantID *uint
+		variants  int64
+		filter    string
+	}{
+		{"product receipt skips variant lines", nil, 0, "order_items.variant_id IS NULL"},
+		{"variant receipt", &variantID, 1, "order_items.variant_id = $"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			fake.returns(`FROM "products"`, []string{"id", "name", "quantity", "backordered_quantity"},
+				[]driver.Value{int64(5), "ThinkPad T14", int64(0), int64(0)}).
+				returns(`SELECT count(*) FROM "product_variants"`, []string{"count"}, []driver.Value{tt.variants}).
+				returns(`FROM "product_variants" WHERE product_id`, []string{"id", "product_id", "quantity"},
+					[]driver.Value{int64(2), int64(5), int64(0)})
+
+			_, err := services.NewBackorderService(db).ReceiveStock(5, tt.variantID, 3)
+
+			require.NoError(t, err)
+			waiting := fake.executed("JOIN orders ON orders.id = order_items.order_id")
+			require.Len(t, waiting, 1)
+			assert.Contains(t, waiting[0].SQL, tt.filter)
+		})
+	}
+}
+
+func TestReceiveStock_OldestOrderFirst(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.returns(`FROM "products"`, []string{"id", "name", "quantity", "backordered_quantity"},
+		[]driver.Value{int64(5), "ThinkPad T14", int64(0), int64(6)}).
+		returns("JOIN orders ON orders.id = order_items.order_id", []string{"id", "order_id", "product_id", "quantity", "backordered_quantity"},
+			[]driver.Value{int64(40), int64(3), int64(5), int64(4), int64(4)},
+			[]driver.Value{int64(41), int64(7), int64(5), int64(2), int64(2)}).
+		returns(`FROM "orders"`, []string{"id", "user_id", "status"}, []driver.Value{int64(3), int64(9), models.OrderStatusAwaitingStock})
+
+	receipt, err := services.NewBackorderService(db).ReceiveStock(5, nil, 5)
+
+	require.NoError(t, err)
+	assert.Equal(t, 5, receipt.Allocated)
+	assert.Equal(t, 0, receipt.Stock)
+	allocations := fake.executed(`UPDATE "order_items" SET "backordered_quantity"`)
+	require.Len(t, allocations, 2)
+	assert.Equal(t, []interface{}{4, uint(40)}, allocations[0].Args, "the oldest order gets all it waits for")
+	assert.Equal(t, []interface{}{1, uint(41)}, allocations[1].Args, "the next one gets what is left")
+	assert.NotEmpty(t, fake.executed(`UPDATE "orders" SET "status"`), "the first order no longer waits")
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestGetAllBrands_Success(t *testing.T) {
@@ -56,3 +57,99 @@ func TestGetAllBrands_Success(t *testing.T) {
 	// Verify that the mock was called
 	mockService.AssertExpectations(t)
 }
+
+func TestGetBrandPage_RedirectsOldSlug(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(mocks.BrandService)
+	mockService.On("GetBrandPage", "old-apple").Return(models.BrandPage{Brand: models.Brand{Name: "Apple", Slug: "apple"}}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "slug", Value: "old-apple"}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/brands/old-apple", nil)
+
+	handlers.GetBrandPage(c, &container.Container{BrandService: mockService})
+
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/api/v1/brands/apple", w.Header().Get("Location"))
+	mockService.AssertExpectations(t)
+}
+
+func TestUploadBrandLogo_MissingFile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request = httptest.NewRequest(http.MethodPost, "/brands/1/logo", nil)
+
+	handlers.UploadBrandLogo(c, &container.Container{})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDeleteBrand_ReassignsProducts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(mocks.BrandService)
+	mockService.On("ReassignAndDeleteBrand", "1", uint(2)).Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request = httptest.NewRequest(http.MethodDelete, "/brands/1?reassign_to=2", nil)
+
+	handlers.DeleteBrand(c, &container.Container{BrandService: mockService})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateBrand_WarrantyMonths(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	months, newMonths := 24, 36
+	tests := []struct {
+		name string
+		req  models.BrandUpdateRequest
+		want *int
+	}{
+		{name: "kept when not provided", req: models.BrandUpdateRequest{Name: "Apple"}, want: &months},
+		{name: "changed", req: models.BrandUpdateRequest{WarrantyMonths: &newMonths}, want: &newMonths},
+		{name: "cleared", req: models.BrandUpdateRequest{ClearWarrantyMonths: true}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.BrandService)
+			mockService.On("GetBrandById", "1").Return(models.Brand{Name: "Apple", IsActive: true, WarrantyMonths: &months}, nil)
+			mockService.On("UpdateBrand", "1", mock.MatchedBy(func(brand models.Brand) bool {
+				return assert.ObjectsAreEqual(tt.want, brand.WarrantyMonths) && brand.IsActive
+			})).Return(models.Brand{}, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+			c.Set("validated_model", &tt.req)
+
+			handlers.UpdateBrand(c, &container.Container{BrandService: mockService})
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateBrand_ClearAndSetWarrantyMonths(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(mocks.BrandService)
+	mockService.On("GetBrandById", "1").Return(models.Brand{Name: "Apple"}, nil)
+	months := 12
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Set("validated_model", &models.BrandUpdateRequest{WarrantyMonths: &months, ClearWarrantyMonths: true})
+
+	handlers.UpdateBrand(c, &container.Container{BrandService: mockService})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "UpdateBrand", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"api_techstore/internal/models"
+	"api_techstore/internal/services"
+	apperrors "api_techstore/pkg/errors"
+	"database/sql/driver"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestReassignAndDeleteBrand_Target(t *testing.T) {
+	tests := []struct {
+		name     string
+		active   bool
+		wantCode int // 0 when the products move
+	}{
+		{name: "active brand", active: true},
+		{name: "inactive brand would hide the products", active: false, wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			fake.returnsFor(`FROM "brands"`, 2, []string{"id", "is_active"}, []driver.Value{int64(2), tt.active}).
+				returns(`FROM "brands"`, []string{"id", "name"}, []driver.Value{int64(1), "Acer"})
+
+			err := services.NewBrandService(db, &memoryStorage{}).ReassignAndDeleteBrand("1", 2)
+
+			if tt.wantCode != 0 {
+				appErr := apperrors.GetAppError(err)
+				require.NotNil(t, appErr, "%v", err)
+				assert.Equal(t, tt.wantCode, appErr.HTTPStatus)
+				assert.Empty(t, fake.executed(`UPDATE "products"`))
+				return
+			}
+			require.NoError(t, err)
+			moves := fake.executed(`UPDATE "products" SET "brand_id"`)
+			require.Len(t, moves, 1)
+			assert.Contains(t, moves[0].Args, uint(2))
+		})
+	}
+}
+
+func TestDeleteBrand_CountsTrashedProducts(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.returns(`SELECT count(*) FROM "products"`, []string{"count"}, []driver.Value{int64(1)}).
+		returns(`FROM "brands"`, []string{"id", "name"}, []driver.Value{int64(1), "Acer"})
+
+	err := services.NewBrandService(db, &memoryStorage{}).DeleteBrand("1")
+
+	appErr := apperrors.GetAppError(err)
+	require.NotNil(t, appErr, "%v", err)
+	assert.Equal(t, http.StatusConflict, appErr.HTTPStatus)
+	counts := fake.executed(`SELECT count(*) FROM "products"`)
+	require.Len(t, counts, 1)
+	assert.NotContains(t, counts[0].SQL, "deleted_at", "a trashed product can be restored and needs its brand")
+	assert.Empty(t, fake.executed(`UPDATE "brands" SET "deleted_at"`))
+}
+
+func TestGetBrandPage(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.returns("COUNT(*) AS product_count", []string{"id", "name", "slug", "product_count"},
+		[]driver.Value{int64(10), "Mice", "mice", int64(4)},
+		[]driver.Value{int64(11), "Keyboards", "keyboards", int64(2)}).
+		returns(`FROM "brands"`, []string{"id", "name", "slug", "is_active"}, []driver.Value{int64(3), "Logitech", "logitech", true}).
+		returns(`FROM "products"`, []string{"id", "name", "brand_id"},
+			[]driver.Value{int64(7), "MX Master 3S", int64(3)},
+			[]driver.Value{int64(3), "MX Keys", int64(3)})
+
+	page, err := services.NewBrandService(db, nil).GetBrandPage("logitech")
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), page.ProductCount, "the sum of the per category counts")
+	assert.Equal(t, []models.BrandCategoryCount{
+		{ID: 10, Name: "Mice", Slug: "mice", ProductCount: 4},
+		{ID: 11, Name: "Keyboards", Slug: "keyboards", ProductCount: 2},
+	}, page.Categories)
+	counts := fake.executed("COUNT(*) AS product_count")
+	require.Len(t, counts, 1)
+	assert.Contains(t, counts[0].SQL, "products.is_active = $", "only listed products count")
+	assert.Contains(t, counts[0].SQL, "ORDER BY product_count DESC")
+
+	require.Len(t, page.TopProducts, 2)
+	assert.Equal(t, uint(7), page.TopProducts[0].ID)
+	top := fake.executed(`SELECT * FROM "products"`)
+	require.NotEmpty(t, top)
+	assert.Regexp(t, `ORDER BY \(SELECT COALESCE\(SUM\(order_items.quantity\), 0\)(?s:.*)\) DESC, products.rating_average DESC`, top[0].SQL,
+		"best sellers first, then the best rated")
+	assert.Contains(t, top[0].Args, "cancelled", "cancelled orders are not sales")
+	assert.Equal(t, 8, top[0].Args[len(top[0].Args)-1], "the limit")
+}
+
+func TestGetBrandPage_InactiveBrand(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.returns(`FROM "brands"`, []string{"id", "name", "slug", "is_active"}, []driver.Value{int64(3), "Logitech", "logitech", false})
+
+	_, err := services.NewBrandService(db, nil).GetBrandPage("logitech")
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.Empty(t, fake.executed(`FROM "products"`))
+}
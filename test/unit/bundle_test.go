@@ -0,0 +1,139 @@
+package unit
+
+import (
+	"api_techstore/internal/models"
+	"api_techstore/internal/services"
+	apperrors "api_techstore/pkg/errors"
+	"database/sql/driver"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// bundleFake scripts bundle 20: one laptop (product 5) and two black mice (variant 2 of product 6)
+func bundleFake(t *testing.T, laptops, mice int64, laptopActive, mouseActive bool) *gorm.DB {
+	db, fake := newFakeDB(t)
+	fake.returns(`FROM "bundle_items"`, []string{"id", "bundle_id", "component_id", "component_variant_id", "quantity", "sort_order"},
+		[]driver.Value{int64(1), int64(20), int64(5), nil, int64(1), int64(0)},
+		[]driver.Value{int64(2), int64(20), int64(6), int64(2), int64(2), int64(1)}).
+		returns(`FROM "products" WHERE "products"."id" IN`, []string{"id", "name", "type", "price", "quantity", "is_active"},
+			[]driver.Value{int64(5), "ThinkPad T14", models.ProductTypeSimple, 1000.0, laptops, laptopActive},
+			[]driver.Value{int64(6), "MX Anywhere", models.ProductTypeSimple, 80.0, int64(0), true}).
+		returns(`FROM "product_variants" WHERE "product_variants"."id" =`, []string{"id", "product_id", "sku", "price", "quantity", "is_active"},
+			[]driver.Value{int64(2), int64(6), "MX-BLACK", 500.0, mice, mouseActive}).
+		returns(`FROM "products"`, []string{"id", "name", "type", "price", "quantity", "is_active"},
+			[]driver.Value{int64(20), "Office bundle", models.ProductTypeBundle, 1800.0, int64(99), true})
+	return db
+}
+
+func TestBundleAvailability(t *testing.T) {
+	tests := []struct {
+		name         string
+		laptops      int64
+		mice         int64
+		laptopActive bool
+		mouseActive  bool
+		want         int
+	}{
+		{name: "scarcest component", laptops: 7, mice: 9, laptopActive: true, mouseActive: true, want: 4},
+		{name: "component out of stock", laptops: 0, mice: 9, laptopActive: true, mouseActive: true, want: 0},
+		{name: "inactive component", laptops: 7, mice: 9, laptopActive: false, mouseActive: true, want: 0},
+		{name: "inactive component variant", laptops: 7, mice: 9, laptopActive: true, mouseActive: false, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := bundleFake(t, tt.laptops, tt.mice, tt.laptopActive, tt.mouseActive)
+
+			bundle, err := services.NewProductService(db).GetProductById("20")
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, bundle.Quantity, "the stored stock of a bundle is not used")
+		})
+	}
+}
+
+func TestExpandOrderItems(t *testing.T) {
+	db := bundleFake(t, 7, 9, true, true)
+
+	lines, err := services.NewBundleService(db).ExpandOrderItems([]models.OrderItem{
+		{OrderID: 3, ProductID: 20, Quantity: 2, UnitPrice: 1800},
+		{OrderID: 3, ProductID: 8, Quantity: 1, UnitPrice: 25},
+	})
+
+	require.NoError(t, err)
+	bundleID := uint(20)
+	variantID := uint(2)
+	assert.Equal(t, []models.OrderItem{
+		// 1800 shared in proportion to the regular prices: 1000 of 2000 and 2 x 500 of 2000
+		{OrderID: 3, ProductID: 5, Quantity: 2, UnitPrice: 900, BundleProductID: &bundleID},
+		{OrderID: 3, ProductID: 6, VariantID: &variantID, SKU: "MX-BLACK", Quantity: 4, UnitPrice: 450, BundleProductID: &bundleID},
+		{OrderID: 3, ProductID: 8, Quantity: 1, UnitPrice: 25},
+	}, lines)
+}
+
+func TestExpandOrderItems_NoBundle(t *testing.T) {
+	db, _ := newFakeDB(t)
+	items := []models.OrderItem{{OrderID: 3, ProductID: 8, Quantity: 1, UnitPrice: 25}}
+
+	lines, err := services.NewBundleService(db).ExpandOrderItems(items)
+
+	require.NoError(t, err)
+	assert.Equal(t, items, lines)
+}
+
+func TestExpandOrderItems_EmptyBundle(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.returns(`FROM "products"`, []string{"id", "name", "type"}, []driver.Value{int64(20), "Office bundle", models.ProductTypeBundle})
+
+	_, err := services.NewBundleService(db).ExpandOrderItems([]models.OrderItem{{ProductID: 20, Quantity: 1, UnitPrice: 1800}})
+
+	appErr := apperrors.GetAppError(err)
+	require.NotNil(t, appErr, "%v", err)
+	assert.Equal(t, http.StatusBadRequest, appErr.HTTPStatus)
+}
+
+func TestCreateOrder_BundleComponentStock(t *testing.T) {
+	bundleID := uint(20)
+	variantID := uint(2)
+	components := []models.OrderItem{
+		{ProductID: 5, Quantity: 2, UnitPrice: 900, BundleProductID: &bundleID},
+		{ProductID: 6, VariantID: &variantID, Quantity: 4, UnitPrice: 450, BundleProductID: &bundleID},
+	}
+
+	t.Run("taken from the components", func(t *testing.T) {
+		db, fake := newFakeDB(t)
+		// a pre-order component is still taken from stock: bundles are never backordered
+		fake.returns(`FROM "products"`, []string{"id", "name", "quantity", "stock_policy", "backorder_limit"},
+			[]driver.Value{int64(5), "ThinkPad T14", int64(7), models.StockPolicyPreorder, int64(10)}).
+			returns(`FROM "orders"`, orderColumns, []driver.Value{int64(3), int64(9), "pending"})
+
+		_, err := services.NewOrderService(db).CreateOrder(models.Order{UserID: 9}, append([]models.OrderItem{}, components...), nil)
+
+		require.NoError(t, err)
+		laptops := fake.executed(`UPDATE "products" SET "quantity"=quantity - $1`)
+		require.Len(t, laptops, 1)
+		assert.Equal(t, []interface{}{2, uint(5), 2}, laptops[0].Args)
+		mice := fake.executed(`UPDATE "product_variants" SET "quantity"=quantity - $1`)
+		require.Len(t, mice, 1)
+		assert.Equal(t, []interface{}{4, uint(2), 4}, mice[0].Args)
+		assert.Empty(t, fake.executed(`"backordered_quantity"=backordered_quantity +`))
+	})
+
+	t.Run("component out of stock", func(t *testing.T) {
+		db, fake := newFakeDB(t)
+		fake.returns(`FROM "products"`, []string{"id", "name", "quantity"}, []driver.Value{int64(6), "MX Anywhere", int64(0)}).
+			affects(`UPDATE "product_variants"`, 0)
+
+		_, err := services.NewOrderService(db).CreateOrder(models.Order{UserID: 9}, append([]models.OrderItem{}, components...), nil)
+
+		appErr := apperrors.GetAppError(err)
+		require.NotNil(t, appErr, "%v", err)
+		assert.Equal(t, http.StatusConflict, appErr.HTTPStatus)
+		assert.Contains(t, appErr.Message, "bundle component MX Anywhere")
+		assert.Empty(t, fake.executed(`INSERT INTO "order_items"`))
+		assert.NotEmpty(t, fake.executed("ROLLBACK"))
+	})
+}
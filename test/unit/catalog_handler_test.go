@@ -0,0 +1,36 @@
+package unit
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCatalogProducts_InvalidSort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/catalog/products?sort=stock", nil)
+
+	handlers.GetCatalogProducts(c, &container.Container{})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetCatalogProducts_InvalidPriceRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/catalog/products?min_price=500&max_price=100", nil)
+
+	handlers.GetCatalogProducts(c, &container.Container{})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
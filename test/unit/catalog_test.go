@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"api_techstore/internal/models"
+	"api_techstore/internal/services"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func catalogTestProduct() models.Product {
+	warranty := 24
+	available := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+	brandID := uint(4)
+	product := models.Product{
+		Name:                "ThinkPad T14",
+		Slug:                "thinkpad-t14",
+		Price:               1299,
+		Quantity:            17,
+		CategoryID:          7,
+		BrandID:             &brandID,
+		IsActive:            true,
+		Type:                models.ProductTypeSimple,
+		WarrantyMonths:      &warranty,
+		StockPolicy:         models.StockPolicyNormal,
+		ExpectedAvailableAt: &available,
+		BackorderLimit:      5,
+		BackorderedQuantity: 2,
+		Category:            models.Category{Name: "Laptops", Slug: "laptops"},
+		Brand:               &models.Brand{Name: "Lenovo", Slug: "lenovo", IsActive: true},
+	}
+	product.ID = 12
+	product.Category.ID = 7
+
+	blue := models.ProductVariant{SKU: "T14-BLUE", Price: 1299, Quantity: 3, IsActive: true}
+	blue.ID = 1
+	red := models.ProductVariant{SKU: "T14-RED", Price: 1249, IsActive: true}
+	red.ID = 2
+	retired := models.ProductVariant{SKU: "T14-GREEN", Price: 999, Quantity: 8}
+	retired.ID = 3
+	product.Variants = []models.ProductVariant{blue, red, retired}
+
+	product.Images = []models.ProductImage{
+		{ImageURL: "/main.jpg", IsMain: true},
+		{ImageURL: "/blue.jpg", VariantID: &blue.ID},
+		{ImageURL: "/green.jpg", VariantID: &retired.ID},
+	}
+	return product
+}
+
+func TestCatalogProduct_HidesInactiveVariants(t *testing.T) {
+	result := services.CatalogProduct(catalogTestProduct())
+
+	require.Len(t, result.Variants, 2)
+	assert.Equal(t, "T14-BLUE", result.Variants[0].SKU)
+	assert.True(t, result.Variants[0].InStock)
+	assert.Equal(t, "T14-RED", result.Variants[1].SKU)
+	assert.False(t, result.Variants[1].InStock)
+
+	var urls []string
+	for _, image := range result.Images {
+		urls = append(urls, image.URL)
+	}
+	assert.Equal(t, []string{"/main.jpg", "/blue.jpg"}, urls, "images of an inactive variant are hidden with it")
+}
+
+func TestCatalogProduct_HidesInternalFields(t *testing.T) {
+	result := services.CatalogProduct(catalogTestProduct())
+
+	assert.True(t, result.InStock)
+	assert.Nil(t, result.AvailableAt, "a shipping date is only shown for pre-orders and backorders")
+	require.NotNil(t, result.Brand)
+	assert.Equal(t, "Lenovo", result.Brand.Name)
+
+	body, err := json.Marshal(result)
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &fields))
+	for _, internal := range []string{"quantity", "is_active", "warranty_months", "backorder_limit", "backordered_quantity", "category_id", "brand_id", "created_at", "deleted_at"} {
+		assert.NotContains(t, fields, internal)
+	}
+	for _, variant := range fields["variants"].([]interface{}) {
+		assert.NotContains(t, variant, "quantity")
+		assert.NotContains(t, variant, "is_active")
+	}
+	assert.NotContains(t, fields["brand"], "is_active")
+}
+
+func TestCatalogProduct_AvailableAt(t *testing.T) {
+	for _, policy := range []string{models.StockPolicyPreorder, models.StockPolicyBackorder} {
+		t.Run(policy, func(t *testing.T) {
+			product := catalogTestProduct()
+			product.StockPolicy = policy
+			product.Quantity = 0
+
+			result := services.CatalogProduct(product)
+
+			assert.False(t, result.InStock)
+			assert.Equal(t, product.ExpectedAvailableAt, result.AvailableAt)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"api_techstore/internal/container"
 	"api_techstore/internal/handlers"
 	"api_techstore/internal/models"
+	apperrors "api_techstore/pkg/errors"
 	"api_techstore/test/mocks"
 	"encoding/json"
 	"net/http"
@@ -56,3 +57,64 @@ func TestGetAllCategories_Success(t *testing.T) {
 	// Verify that the mock was called
 	mockService.AssertExpectations(t)
 }
+
+func TestDeleteCategory_NotEmpty(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(mocks.CategoryService)
+
+	// the service refuses to delete a category that still has children or products
+	mockService.On("DeleteCategory", "1").Return(apperrors.NewConflict("Category is not empty"))
+
+	mockContainer := &container.Container{
+		CategoryService: mockService,
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handlers.DeleteCategory(c, mockContainer)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteCategory_InvalidReassignTo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request = httptest.NewRequest(http.MethodDelete, "/categories/1?reassign_to=abc", nil)
+
+	handlers.DeleteCategory(c, &container.Container{CategoryService: new(mocks.CategoryService)})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDeleteCategory_ReassignsProducts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		query          string
+		dropAttributes bool
+	}{
+		{query: "reassign_to=2"},
+		{query: "reassign_to=2&drop_attributes=true", dropAttributes: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			mockService := new(mocks.CategoryService)
+			mockService.On("ReassignAndDeleteCategory", "1", uint(2), tt.dropAttributes).Return(nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+			c.Request = httptest.NewRequest(http.MethodDelete, "/categories/1?"+tt.query, nil)
+
+			handlers.DeleteCategory(c, &container.Container{CategoryService: mockService})
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package unit
+
+import (
+	"api_techstore/internal/models"
+	"api_techstore/internal/services"
+	apperrors "api_techstore/pkg/errors"
+	"database/sql/driver"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var categoryAttributeColumns = []string{"id", "category_id", "code", "type", "allowed_values"}
+
+// reassignFake scripts category 3 being deleted into category 9, under category 2. Category 3 has
+// cpu, panel and gpu specs; the target has cpu and a panel enum without OLED.
+func reassignFake(t *testing.T, hiddenTargets int64) (*fakeDB, services.CategoryService) {
+	db, fake := newFakeDB(t)
+	fake.returns(`SELECT count(*) FROM "categories" WHERE (id IN`, []string{"count"}, []driver.Value{hiddenTargets}).
+		returns("parent_id =", []string{"count"}, []driver.Value{int64(0)}).
+		returnsFor(`FROM "categories"`, 9, []string{"id", "path"}, []driver.Value{int64(9), "/2/9/"}).
+		returns(`FROM "categories"`, []string{"id", "name", "path"}, []driver.Value{int64(3), "Notebooks", "/3/"}).
+		returnsFor(`FROM "category_attributes"`, 3, categoryAttributeColumns,
+			[]driver.Value{int64(1), int64(3), "cpu", models.AttributeTypeString, nil},
+			[]driver.Value{int64(4), int64(3), "panel", models.AttributeTypeEnum, `["IPS","OLED"]`},
+			[]driver.Value{int64(5), int64(3), "gpu", models.AttributeTypeString, nil}).
+		returnsFor(`FROM "category_attributes"`, 9, categoryAttributeColumns,
+			[]driver.Value{int64(11), int64(9), "cpu", models.AttributeTypeString, nil},
+			[]driver.Value{int64(14), int64(9), "panel", models.AttributeTypeEnum, `["ips","Mini-LED"]`},
+			[]driver.Value{int64(15), int64(9), "gpu_memory", models.AttributeTypeNumber, nil}).
+		returnsFor(`SELECT count(*) FROM "product_attribute_values"`, 4, []string{"count"}, []driver.Value{int64(2)}).
+		returnsFor(`SELECT count(*) FROM "product_attribute_values"`, 5, []string{"count"}, []driver.Value{int64(3)})
+	return fake, services.NewCategoryService(db)
+}
+
+func TestReassignAndDeleteCategory_HiddenTarget(t *testing.T) {
+	fake, service := reassignFake(t, 1)
+
+	err := service.ReassignAndDeleteCategory("3", 9, true)
+
+	appErr := apperrors.GetAppError(err)
+	require.NotNil(t, appErr, "%v", err)
+	assert.Equal(t, http.StatusBadRequest, appErr.HTTPStatus)
+	hidden := fake.executed(`SELECT count(*) FROM "categories" WHERE (id IN`)
+	require.Len(t, hidden, 1)
+	assert.Equal(t, []interface{}{uint(2), uint(9), false}, hidden[0].Args, "the target and every category above it")
+	assert.Empty(t, fake.executed(`UPDATE "products"`))
+}
+
+func TestReassignAndDeleteCategory_SpecValues(t *testing.T) {
+	t.Run("values that do not fit are listed", func(t *testing.T) {
+		fake, service := reassignFake(t, 0)
+
+		err := service.ReassignAndDeleteCategory("3", 9, false)
+
+		appErr := apperrors.GetAppError(err)
+		require.NotNil(t, appErr, "%v", err)
+		assert.Equal(t, http.StatusConflict, appErr.HTTPStatus)
+		assert.Equal(t, "spec values that do not fit the target category: panel (2), gpu (3); set drop_attributes to delete them", appErr.Details)
+		assert.NotEmpty(t, fake.executed("ROLLBACK"), "the values already moved go back")
+		assert.Empty(t, fake.executed(`DELETE FROM "product_attribute_values"`))
+		assert.Empty(t, fake.executed(`UPDATE "products"`))
+	})
+
+	t.Run("values that fit move, the others are dropped on request", func(t *testing.T) {
+		fake, service := reassignFake(t, 0)
+
+		require.NoError(t, service.ReassignAndDeleteCategory("3", 9, true))
+
+		moves := fake.executed(`UPDATE "product_attribute_values" SET`)
+		var moved [][]interface{}
+		for _, move := range moves {
+			moved = append(moved, move.Args)
+		}
+		assert.Equal(t, [][]interface{}{
+			{uint(11), uint(1)},
+			{uint(14), "ips", uint(4), "ips"},
+			{uint(14), "Mini-LED", uint(4), "mini-led"},
+		}, moved, "same code and type, enum values in the target's spelling")
+		require.Len(t, fake.executed(`DELETE FROM "product_attribute_values"`), 1)
+		assert.Less(t, fake.position(`DELETE FROM "product_attribute_values"`), fake.position(`UPDATE "products" SET "category_id"`))
+		assert.NotEmpty(t, fake.executed("COMMIT"))
+	})
+}
+
+func TestDeleteCategory_CountsTrashedProducts(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.returns(`SELECT count(*) FROM "products"`, []string{"count"}, []driver.Value{int64(1)}).
+		returns(`SELECT count(*) FROM "categories"`, []string{"count"}, []driver.Value{int64(0)}).
+		returns(`FROM "categories"`, []string{"id", "name", "path"}, []driver.Value{int64(3), "Notebooks", "/3/"})
+
+	err := services.NewCategoryService(db).DeleteCategory("3")
+
+	appErr := apperrors.GetAppError(err)
+	require.NotNil(t, appErr, "%v", err)
+	assert.Equal(t, http.StatusConflict, appErr.HTTPStatus)
+	for _, table := range []string{"products", "categories"} {
+		counts := fake.executed(`SELECT count(*) FROM "` + table + `"`)
+		require.Len(t, counts, 1, table)
+		assert.NotContains(t, counts[0].SQL, "deleted_at", "trashed %s can be restored and need the category", table)
+	}
+	assert.Empty(t, fake.executed(`UPDATE "categories" SET "deleted_at"`))
+}
+
+func TestMoveCategory_LocksInIDOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		parentID uint
+		order    []uint
+	}{
+		{"parent with a lower id", 3, []uint{3, 8}},
+		{"parent with a higher id", 12, []uint{8, 12}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			columns := []string{"id", "path", "depth"}
+			fake.returnsFor(`FROM "categories"`, uint(3), columns, []driver.Value{int64(3), "/3/", int64(0)}).
+				returnsFor(`FROM "categories"`, uint(12), columns, []driver.Value{int64(12), "/2/12/", int64(1)}).
+				returns(`FROM "categories"`, columns, []driver.Value{int64(8), "/8/", int64(0)})
+
+			category, err := services.NewCategoryService(db).MoveCategory("8", &tt.parentID)
+
+			require.NoError(t, err)
+			locks := fake.executed("FOR UPDATE")
+			require.Len(t, locks, 2)
+			for i, id := range tt.order {
+				assert.Equal(t, fmt.Sprint(id), fmt.Sprint(locks[i].Args[0]))
+			}
+			assert.Less(t, fake.position("FOR UPDATE"), fake.position(`UPDATE "categories"`))
+			assert.Equal(t, &tt.parentID, category.ParentID)
+		})
+	}
+}
+
+func TestMoveCategory_UnderDescendant(t *testing.T) {
+	db, fake := newFakeDB(t)
+	columns := []string{"id", "path", "depth"}
+	fake.returnsFor(`FROM "categories"`, uint(14), columns, []driver.Value{int64(14), "/8/14/", int64(1)}).
+		returns(`FROM "categories"`, columns, []driver.Value{int64(8), "/8/", int64(0)})
+	parentID := uint(14)
+
+	_, err := services.NewCategoryService(db).MoveCategory("8", &parentID)
+
+	appErr := apperrors.GetAppError(err)
+	require.NotNil(t, appErr, "%v", err)
+	assert.Equal(t, http.StatusBadRequest, appErr.HTTPStatus)
+	assert.Len(t, fake.executed("FOR UPDATE"), 2, "the parent path is read under lock")
+	assert.Empty(t, fake.executed(`UPDATE "categories"`))
+}
@@ -0,0 +1,130 @@
+package unit
+
+import (
+	"api_techstore/internal/models"
+	"api_techstore/internal/services"
+	apperrors "api_techstore/pkg/errors"
+	"database/sql/driver"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rowValues renders the values of a comparison row, "-" standing for a missing one
+func rowValues(row models.ComparisonRow) []string {
+	values := make([]string, 0, len(row.Values))
+	for _, value := range row.Values {
+		if value == nil {
+			values = append(values, "-")
+			continue
+		}
+		values = append(values, *value)
+	}
+	return values
+}
+
+func TestBuildComparison(t *testing.T) {
+	attributes := laptopAttributes()
+	ram16, ram8 := 16.0, 8.0
+	dell := models.Brand{Name: "Dell"}
+	first := models.Product{Name: "XPS 13", Slug: "xps-13", Price: 1299, Quantity: 3, Brand: &dell,
+		Category: models.Category{Name: "Laptops", Slug: "laptops"},
+		Images:   []models.ProductImage{{ImageURL: "https://cdn.example.com/xps.jpg"}},
+		Attributes: []models.ProductAttributeValue{
+			{AttributeID: 1, ValueText: "Core i7"},
+			{AttributeID: 2, ValueNumber: &ram16},
+		}}
+	second := models.Product{Name: "Inspiron 14", Slug: "inspiron-14", Price: 799.5, Brand: &dell,
+		Attributes: []models.ProductAttributeValue{
+			{AttributeID: 1, ValueText: "Core i7"},
+			{AttributeID: 2, ValueNumber: &ram8},
+			{AttributeID: 4, ValueText: "IPS"},
+		}}
+	third := models.Product{Name: "Unbranded", Slug: "unbranded", Price: 799.5,
+		Attributes: []models.ProductAttributeValue{{AttributeID: 1, ValueText: "Core i7"}}}
+
+	comparison := services.BuildComparison([]models.Product{first, second, third}, attributes)
+
+	assert.Equal(t, "Laptops", comparison.Category.Name)
+	require.Len(t, comparison.Products, 3)
+	assert.Equal(t, "https://cdn.example.com/xps.jpg", comparison.Products[0].ImageURL)
+	assert.Equal(t, "xps-13", comparison.Products[0].Slug)
+
+	tests := []struct {
+		code      string
+		group     string
+		values    []string
+		different bool
+	}{
+		{code: "price", group: models.ComparisonGroupGeneral, values: []string{"1299.00", "799.50", "799.50"}, different: true},
+		{code: "brand", group: models.ComparisonGroupGeneral, values: []string{"Dell", "Dell", "-"}, different: true},
+		{code: "availability", group: models.ComparisonGroupGeneral, values: []string{"in_stock", "out_of_stock", "out_of_stock"}, different: true},
+		{code: "cpu", group: models.ComparisonGroupSpecs, values: []string{"Core i7", "Core i7", "Core i7"}, different: false},
+		{code: "ram_gb", group: models.ComparisonGroupSpecs, values: []string{"16", "8", "-"}, different: true},
+		{code: "touchscreen", group: models.ComparisonGroupSpecs, values: []string{"-", "-", "-"}, different: false},
+		{code: "panel", group: models.ComparisonGroupSpecs, values: []string{"-", "IPS", "-"}, different: true},
+	}
+	require.Len(t, comparison.Rows, len(tests))
+	for i, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			row := comparison.Rows[i]
+			assert.Equal(t, tt.code, row.Code, "rows keep the general then attribute order")
+			assert.Equal(t, tt.group, row.Group)
+			assert.Equal(t, tt.values, rowValues(row))
+			assert.Equal(t, tt.different, row.Different)
+		})
+	}
+}
+
+func TestCompareProducts_NumberOfProducts(t *testing.T) {
+	for _, ids := range [][]uint{nil, {1}, {1, 1}, {1, 0}, {1, 2, 3, 4, 5}} {
+		_, err := services.NewComparisonService(nil).CompareProducts(ids)
+
+		appErr := apperrors.GetAppError(err)
+		require.NotNil(t, appErr, "%v", ids)
+		assert.Equal(t, http.StatusBadRequest, appErr.HTTPStatus, "%v", ids)
+	}
+}
+
+func TestCompareProducts_Categories(t *testing.T) {
+	tests := []struct {
+		name       string
+		rows       [][]driver.Value
+		wantStatus int
+	}{
+		{
+			name:       "other category",
+			rows:       [][]driver.Value{{int64(1), "XPS 13", int64(7)}, {int64(2), "Galaxy S24", int64(8)}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "inactive or missing product",
+			rows:       [][]driver.Value{{int64(1), "XPS 13", int64(7)}},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "same category",
+			rows: [][]driver.Value{{int64(2), "Inspiron 14", int64(7)}, {int64(1), "XPS 13", int64(7)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			fake.returns(`FROM "products"`, []string{"id", "name", "category_id"}, tt.rows...)
+
+			comparison, err := services.NewComparisonService(db).CompareProducts([]uint{1, 2})
+
+			if tt.wantStatus != 0 {
+				appErr := apperrors.GetAppError(err)
+				require.NotNil(t, appErr)
+				assert.Equal(t, tt.wantStatus, appErr.HTTPStatus)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, comparison.Products, 2)
+			assert.Equal(t, "XPS 13", comparison.Products[0].Name, "in the requested order")
+		})
+	}
+}
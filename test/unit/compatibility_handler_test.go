@@ -0,0 +1,38 @@
+package unit
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCompatibleAccessories_InvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/products/abc/compatible-accessories", nil)
+
+	handlers.GetCompatibleAccessories(c, &container.Container{})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDeleteCompatibilityRule_InvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "ruleId", Value: "abc"}}
+	c.Request = httptest.NewRequest(http.MethodDelete, "/compatibility-rules/abc", nil)
+
+	handlers.DeleteCompatibilityRule(c, &container.Container{})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,216 @@
+package unit
+
+import (
+	"api_techstore/internal/models"
+	"api_techstore/internal/services"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RAM modules (category 5) fit laptops (category 7) of the same memory type
+const (
+	ramCategory       = 5
+	laptopCategory    = 7
+	ramMemoryType     = 11 // attribute of the RAM category
+	laptopMemoryType  = 21 // attribute of the laptop category
+	laptopFormFactor  = 22
+	ramFormFactor     = 12
+	mouseCategory     = 9
+	unrelatedCategory = 3
+)
+
+func compatibilityProduct(id uint, name string, categoryID uint) models.Product {
+	product := models.Product{Name: name, CategoryID: categoryID}
+	product.ID = id
+	return product
+}
+
+func memoryTypeRule() models.CompatibilityRule {
+	return models.CompatibilityRule{
+		AccessoryAttributeID: ramMemoryType,
+		DeviceAttributeID:    laptopMemoryType,
+		AccessoryAttribute:   &models.CategoryAttribute{Name: "Memory type", CategoryID: ramCategory},
+		DeviceAttribute:      &models.CategoryAttribute{Name: "Memory type", CategoryID: laptopCategory},
+	}
+}
+
+func formFactorRule() models.CompatibilityRule {
+	return models.CompatibilityRule{
+		AccessoryAttributeID: ramFormFactor,
+		DeviceAttributeID:    laptopFormFactor,
+		AccessoryAttribute:   &models.CategoryAttribute{Name: "Form factor", CategoryID: ramCategory},
+		DeviceAttribute:      &models.CategoryAttribute{Name: "Form factor", CategoryID: laptopCategory},
+	}
+}
+
+func TestCompatibilityGraphFit(t *testing.T) {
+	ram := compatibilityProduct(1, "Kingston 16GB", ramCategory)
+	laptop := compatibilityProduct(2, "ThinkPad T14", laptopCategory)
+	rules := map[[2]uint][]models.CompatibilityRule{
+		{ramCategory, laptopCategory}: {memoryTypeRule(), formFactorRule()},
+	}
+
+	tests := []struct {
+		name       string
+		graph      services.CompatibilityGraph
+		accessory  models.Product
+		device     models.Product
+		want       int
+		wantReason string
+	}{
+		{
+			name: "matching specs fit, ignoring case and spaces",
+			graph: services.CompatibilityGraph{Rules: rules, Values: map[uint]map[uint]string{
+				1: {ramMemoryType: "DDR4", ramFormFactor: "SO-DIMM"},
+				2: {laptopMemoryType: " ddr4", laptopFormFactor: "so-dimm"},
+			}},
+			accessory: ram, device: laptop, want: services.FitYes,
+		},
+		{
+			name: "a mismatching spec does not fit",
+			graph: services.CompatibilityGraph{Rules: rules, Values: map[uint]map[uint]string{
+				1: {ramMemoryType: "DDR4", ramFormFactor: "SO-DIMM"},
+				2: {laptopMemoryType: "DDR5", laptopFormFactor: "SO-DIMM"},
+			}},
+			accessory: ram, device: laptop, want: services.FitNo,
+			wantReason: "Memory type DDR4 of Kingston 16GB does not match Memory type DDR5 of ThinkPad T14",
+		},
+		{
+			name: "an explicit link overrides the rules",
+			graph: services.CompatibilityGraph{
+				Linked: map[[2]uint]bool{{1, 2}: true},
+				Rules:  rules,
+				Values: map[uint]map[uint]string{
+					1: {ramMemoryType: "DDR4", ramFormFactor: "SO-DIMM"},
+					2: {laptopMemoryType: "DDR5", laptopFormFactor: "SO-DIMM"},
+				},
+			},
+			accessory: ram, device: laptop, want: services.FitYes,
+		},
+		{
+			name: "a missing accessory spec is unknown",
+			graph: services.CompatibilityGraph{Rules: rules, Values: map[uint]map[uint]string{
+				1: {ramFormFactor: "SO-DIMM"},
+				2: {laptopMemoryType: "DDR5", laptopFormFactor: "SO-DIMM"},
+			}},
+			accessory: ram, device: laptop, want: services.FitUnknown,
+		},
+		{
+			name: "a missing device spec is unknown",
+			graph: services.CompatibilityGraph{Rules: rules, Values: map[uint]map[uint]string{
+				1: {ramMemoryType: "DDR4", ramFormFactor: "SO-DIMM"},
+				2: {laptopFormFactor: "SO-DIMM"},
+			}},
+			accessory: ram, device: laptop, want: services.FitUnknown,
+		},
+		{
+			name: "a mismatch wins over a missing spec",
+			graph: services.CompatibilityGraph{Rules: rules, Values: map[uint]map[uint]string{
+				1: {ramMemoryType: "DDR4", ramFormFactor: "DIMM"},
+				2: {laptopFormFactor: "SO-DIMM"},
+			}},
+			accessory: ram, device: laptop, want: services.FitNo,
+			wantReason: "Form factor DIMM of Kingston 16GB does not match Form factor SO-DIMM of ThinkPad T14",
+		},
+		{
+			name:      "rules apply one way only",
+			graph:     services.CompatibilityGraph{Rules: rules},
+			accessory: laptop, device: ram, want: services.FitUnknown,
+		},
+		{
+			name:      "unrelated categories are unknown",
+			graph:     services.CompatibilityGraph{Rules: rules},
+			accessory: compatibilityProduct(3, "Laptop sleeve", unrelatedCategory), device: laptop, want: services.FitUnknown,
+		},
+		{
+			name:      "linked to other devices of the category only",
+			graph:     services.CompatibilityGraph{LinksToCategory: map[[2]uint]bool{{3, laptopCategory}: true}},
+			accessory: compatibilityProduct(3, "Dock", unrelatedCategory), device: laptop, want: services.FitNo,
+			wantReason: "Dock is not listed as compatible with ThinkPad T14",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome, reason := tt.graph.Fit(tt.accessory, tt.device)
+			assert.Equal(t, tt.want, outcome)
+			assert.Equal(t, tt.wantReason, reason)
+		})
+	}
+}
+
+func TestCompatibilityGraphWarnings(t *testing.T) {
+	ram := compatibilityProduct(1, "Kingston 16GB", ramCategory)
+	laptop := compatibilityProduct(2, "ThinkPad T14", laptopCategory)
+	mouse := compatibilityProduct(3, "MX Master", mouseCategory)
+	graph := services.CompatibilityGraph{
+		Rules: map[[2]uint][]models.CompatibilityRule{{ramCategory, laptopCategory}: {memoryTypeRule()}},
+		Values: map[uint]map[uint]string{
+			1: {ramMemoryType: "DDR4"},
+			2: {laptopMemoryType: "DDR5"},
+		},
+	}
+
+	warnings := graph.Warnings([]models.Product{ram, laptop, mouse})
+
+	require.Len(t, warnings, 1)
+	assert.Equal(t, uint(1), warnings[0].AccessoryID)
+	assert.Equal(t, uint(2), warnings[0].DeviceID)
+	assert.Equal(t, "ThinkPad T14", warnings[0].DeviceName)
+	assert.Empty(t, graph.Warnings([]models.Product{ram, mouse}))
+}
+
+func TestCheckCart(t *testing.T) {
+	tests := []struct {
+		name         string
+		laptopMemory string
+		linked       bool
+		wantWarnings int
+	}{
+		{name: "mismatching spec warns", laptopMemory: "DDR5", wantWarnings: 1},
+		{name: "matching spec", laptopMemory: "DDR4"},
+		{name: "explicit link overrides the spec", laptopMemory: "DDR5", linked: true},
+		{name: "missing spec does not warn", laptopMemory: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			fake.returns(`FROM "products"`, []string{"id", "name", "category_id"},
+				[]driver.Value{int64(1), "Kingston 16GB", int64(ramCategory)},
+				[]driver.Value{int64(2), "ThinkPad T14", int64(laptopCategory)})
+			var links [][]driver.Value
+			if tt.linked {
+				links = append(links, []driver.Value{int64(1), int64(2), int64(laptopCategory)})
+			}
+			fake.returns(`FROM "product_compatibilities"`, []string{"accessory_id", "device_id", "category_id"}, links...)
+			fake.returns(`FROM "compatibility_rules"`, []string{"id", "accessory_attribute_id", "device_attribute_id"},
+				[]driver.Value{int64(1), int64(ramMemoryType), int64(laptopMemoryType)})
+			fake.returnsFor(`FROM "category_attributes"`, ramMemoryType, []string{"id", "category_id", "name"},
+				[]driver.Value{int64(ramMemoryType), int64(ramCategory), "Memory type"})
+			fake.returnsFor(`FROM "category_attributes"`, laptopMemoryType, []string{"id", "category_id", "name"},
+				[]driver.Value{int64(laptopMemoryType), int64(laptopCategory), "Memory type"})
+			values := [][]driver.Value{{int64(1), int64(1), int64(ramMemoryType), "DDR4"}}
+			if tt.laptopMemory != "" {
+				values = append(values, []driver.Value{int64(2), int64(2), int64(laptopMemoryType), tt.laptopMemory})
+			}
+			fake.returns(`FROM "product_attribute_values"`, []string{"id", "product_id", "attribute_id", "value_text"}, values...)
+
+			warnings, err := services.NewCompatibilityService(db).CheckCart([]models.CartItem{{ProductID: 1}, {ProductID: 2}, {ProductID: 1}})
+			require.NoError(t, err)
+
+			assert.Len(t, warnings, tt.wantWarnings)
+		})
+	}
+
+	t.Run("a single product is not checked", func(t *testing.T) {
+		db, fake := newFakeDB(t)
+
+		warnings, err := services.NewCompatibilityService(db).CheckCart([]models.CartItem{{ProductID: 1}, {ProductID: 1}})
+		require.NoError(t, err)
+
+		assert.Empty(t, warnings)
+		assert.Empty(t, fake.executed("SELECT"))
+	})
+}
@@ -0,0 +1,215 @@
+package unit
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeDB is a database/sql driver answering gorm from a script, for the services whose rules
+// live in their queries. Every statement is recorded; the first rule whose fragment is part of a
+// query answers it, other queries read no rows and other statements affect one row.
+type fakeDB struct {
+	mu         sync.Mutex
+	statements []fakeStatement
+	rules      []fakeRule
+}
+
+type fakeStatement struct {
+	SQL  string
+	Args []interface{}
+}
+
+type fakeRule struct {
+	fragment     string
+	arg          interface{} // when set, the statement must also be given this argument
+	columns      []string
+	rows         [][]driver.Value
+	rowsAffected int64
+	err          error
+}
+
+// newFakeDB opens gorm with the postgres dialect on a fakeDB
+func newFakeDB(t *testing.T) (*gorm.DB, *fakeDB) {
+	t.Helper()
+	fake := &fakeDB{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(fakeConnector{fake})}), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	require.NoError(t, err)
+	return db, fake
+}
+
+// returns answers the queries containing fragment with the rows
+func (f *fakeDB) returns(fragment string, columns []string, rows ...[]driver.Value) *fakeDB {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = append(f.rules, fakeRule{fragment: fragment, columns: columns, rows: rows})
+	return f
+}
+
+// returnsFor answers the queries containing fragment and given arg with the rows, e.g. to
+// preload each association by its id
+func (f *fakeDB) returnsFor(fragment string, arg interface{}, columns []string, rows ...[]driver.Value) *fakeDB {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = append(f.rules, fakeRule{fragment: fragment, arg: arg, columns: columns, rows: rows})
+	return f
+}
+
+// affects sets the number of rows the statements containing fragment change
+func (f *fakeDB) affects(fragment string, rowsAffected int64) *fakeDB {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = append(f.rules, fakeRule{fragment: fragment, rowsAffected: rowsAffected})
+	return f
+}
+
+// fails makes the statements containing fragment fail with err
+func (f *fakeDB) fails(fragment string, err error) *fakeDB {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = append(f.rules, fakeRule{fragment: fragment, err: err})
+	return f
+}
+
+// executed lists the recorded statements containing fragment
+func (f *fakeDB) executed(fragment string) []fakeStatement {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var matches []fakeStatement
+	for _, statement := range f.statements {
+		if strings.Contains(statement.SQL, fragment) {
+			matches = append(matches, statement)
+		}
+	}
+	return matches
+}
+
+// position is the index of the first recorded statement containing fragment, -1 when none
+func (f *fakeDB) position(fragment string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, statement := range f.statements {
+		if strings.Contains(statement.SQL, fragment) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (f *fakeDB) record(query string, args []driver.NamedValue) *fakeRule {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	statement := fakeStatement{SQL: query}
+	for _, arg := range args {
+		statement.Args = append(statement.Args, arg.Value)
+	}
+	f.statements = append(f.statements, statement)
+	for i := range f.rules {
+		if strings.Contains(query, f.rules[i].fragment) && (f.rules[i].arg == nil || statement.given(f.rules[i].arg)) {
+			return &f.rules[i]
+		}
+	}
+	return nil
+}
+
+// given tells whether the statement was given arg, comparing numbers of any type by value
+func (s fakeStatement) given(arg interface{}) bool {
+	for _, value := range s.Args {
+		if fmt.Sprint(value) == fmt.Sprint(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+type fakeConnector struct{ db *fakeDB }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: c.db}, nil }
+func (c fakeConnector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("fakeDB is opened through its connector")
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fakeDB does not prepare statements")
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+func (c *fakeConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.db.record("BEGIN", nil)
+	return fakeTx{db: c.db}, nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rule := c.db.record(query, args)
+	if rule == nil {
+		return &fakeRows{}, nil
+	}
+	if rule.err != nil {
+		return nil, rule.err
+	}
+	return &fakeRows{columns: rule.columns, rows: rule.rows}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	rule := c.db.record(query, args)
+	if rule == nil {
+		return driver.RowsAffected(1), nil
+	}
+	if rule.err != nil {
+		return nil, rule.err
+	}
+	return driver.RowsAffected(rule.rowsAffected), nil
+}
+
+type fakeTx struct{ db *fakeDB }
+
+func (tx fakeTx) Commit() error {
+	tx.db.record("COMMIT", nil)
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.db.record("ROLLBACK", nil)
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
@@ -0,0 +1,169 @@
+package unit
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// fakeRedis answers go-redis over in-memory connections from a script, for the services which
+// keep counters in Redis. Every command is recorded; the replies queued for a command are used
+// in order, then GET reads nothing, SET answers OK, ZINCRBY answers 1 as a score, ZREVRANGE
+// reads an empty set and any other command answers 1. Commands between MULTI and EXEC are
+// answered as a transaction.
+type fakeRedis struct {
+	mu       sync.Mutex
+	commands [][]string
+	replies  map[string][]string
+}
+
+// newFakeRedis opens a client whose connections are served by a fakeRedis
+func newFakeRedis(t *testing.T) (*redis.Client, *fakeRedis) {
+	t.Helper()
+	fake := &fakeRedis{replies: make(map[string][]string)}
+	client := redis.NewClient(&redis.Options{
+		Dialer: func(context.Context, string, string) (net.Conn, error) {
+			server, conn := net.Pipe()
+			go fake.serve(server)
+			return conn, nil
+		},
+		MaxRetries: -1,
+	})
+	t.Cleanup(func() { client.Close() })
+	return client, fake
+}
+
+// replyInts queues integer replies to the next calls of command, e.g. EVALSHA
+func (f *fakeRedis) replyInts(command string, values ...int64) *fakeRedis {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, value := range values {
+		f.replies[command] = append(f.replies[command], fmt.Sprintf(":%d\r\n", value))
+	}
+	return f
+}
+
+// replyString queues a bulk string reply to the next call of command
+func (f *fakeRedis) replyString(command, value string) *fakeRedis {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.replies[command] = append(f.replies[command], fmt.Sprintf("$%d\r\n%s\r\n", len(value), value))
+	return f
+}
+
+// replyStrings queues an array reply to the next call of command, e.g. ZREVRANGE
+func (f *fakeRedis) replyStrings(command string, values ...string) *fakeRedis {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var reply strings.Builder
+	fmt.Fprintf(&reply, "*%d\r\n", len(values))
+	for _, value := range values {
+		fmt.Fprintf(&reply, "$%d\r\n%s\r\n", len(value), value)
+	}
+	f.replies[command] = append(f.replies[command], reply.String())
+	return f
+}
+
+// called lists the recorded calls of command with their arguments
+func (f *fakeRedis) called(command string) [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var matches [][]string
+	for _, args := range f.commands {
+		if args[0] == command {
+			matches = append(matches, args[1:])
+		}
+	}
+	return matches
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	var transaction []string // replies of the commands queued since MULTI, nil outside a transaction
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		var reply string
+		switch strings.ToUpper(args[0]) {
+		case "MULTI":
+			transaction, reply = []string{}, "+OK\r\n"
+		case "EXEC":
+			reply = fmt.Sprintf("*%d\r\n%s", len(transaction), strings.Join(transaction, ""))
+			transaction = nil
+		default:
+			reply = f.reply(args)
+			if transaction != nil {
+				transaction, reply = append(transaction, reply), "+QUEUED\r\n"
+			}
+		}
+		if _, err := conn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeRedis) reply(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	command := strings.ToUpper(args[0])
+	args[0] = command
+	f.commands = append(f.commands, args)
+	if queued := f.replies[command]; len(queued) > 0 {
+		f.replies[command] = queued[1:]
+		return queued[0]
+	}
+	switch command {
+	case "GET":
+		return "$-1\r\n"
+	case "SET":
+		return "+OK\r\n"
+	case "ZINCRBY":
+		return "$1\r\n1\r\n"
+	case "ZREVRANGE":
+		return "*0\r\n"
+	}
+	return ":1\r\n"
+}
+
+// readCommand reads a command sent by go-redis: an array of bulk strings
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	count, err := readLength(reader, '*')
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		size, err := readLength(reader, '$')
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
+func readLength(reader *bufio.Reader, prefix byte) (int, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return 0, err
+	}
+	if len(line) < 3 || line[0] != prefix {
+		return 0, fmt.Errorf("unexpected %q", line)
+	}
+	return strconv.Atoi(strings.TrimSuffix(line[1:], "\r\n"))
+}
@@ -0,0 +1,38 @@
+package unit
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFlashSaleByID_InvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/flash-sales/abc", nil)
+
+	handlers.GetFlashSaleByID(c, &container.Container{})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateFlashSale_Unauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/flash-sales", nil)
+
+	// without an authenticated user the service is never reached
+	handlers.CreateFlashSale(c, &container.Container{})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,295 @@
+package unit
+
+import (
+	"api_techstore/internal/models"
+	"api_techstore/internal/services"
+	apperrors "api_techstore/pkg/errors"
+	"context"
+	"database/sql/driver"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var flashSaleColumns = []string{"id", "product_id", "variant_id", "sale_price", "starts_at", "ends_at", "total_quantity", "sold_quantity", "per_user_limit", "is_active"}
+
+func flashSale(variantID *uint) models.FlashSale {
+	return models.FlashSale{
+		ProductID:     5,
+		VariantID:     variantID,
+		SalePrice:     700,
+		StartsAt:      time.Now().Add(time.Hour),
+		EndsAt:        time.Now().Add(3 * time.Hour),
+		TotalQuantity: 10,
+		PerUserLimit:  2,
+	}
+}
+
+func TestCreateFlashSale_Rules(t *testing.T) {
+	variantID := uint(8)
+	tests := []struct {
+		name     string
+		sale     func() models.FlashSale
+		product  []driver.Value // id, price, quantity, type, is_active
+		variants int64
+		variant  []driver.Value // id, product_id, price, quantity, is_active
+		overlaps int64
+		status   int
+		details  string
+	}{
+		{
+			name:    "ends before it starts",
+			sale:    func() models.FlashSale { s := flashSale(nil); s.EndsAt = s.StartsAt.Add(-time.Minute); return s },
+			status:  http.StatusBadRequest,
+			details: "ends_at must be after starts_at",
+		},
+		{
+			name: "already over",
+			sale: func() models.FlashSale {
+				s := flashSale(nil)
+				s.StartsAt, s.EndsAt = time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour)
+				return s
+			},
+			status:  http.StatusBadRequest,
+			details: "ends_at must be in the future",
+		},
+		{
+			name:    "inactive product",
+			sale:    func() models.FlashSale { return flashSale(nil) },
+			product: []driver.Value{int64(5), 1000.0, int64(50), models.ProductTypeSimple, false},
+			status:  http.StatusBadRequest,
+			details: "Flash sales are not available for inactive products",
+		},
+		{
+			name:    "bundle",
+			sale:    func() models.FlashSale { return flashSale(nil) },
+			product: []driver.Value{int64(5), 1000.0, int64(50), models.ProductTypeBundle, true},
+			status:  http.StatusBadRequest,
+			details: "Flash sales are not available for bundles",
+		},
+		{
+			name:     "variant required",
+			sale:     func() models.FlashSale { return flashSale(nil) },
+			product:  []driver.Value{int64(5), 1000.0, int64(50), models.ProductTypeSimple, true},
+			variants: 2,
+			status:   http.StatusBadRequest,
+			details:  "variant_id is required for a product with variants",
+		},
+		{
+			name:     "variant of another product",
+			sale:     func() models.FlashSale { return flashSale(&variantID) },
+			product:  []driver.Value{int64(5), 1000.0, int64(50), models.ProductTypeSimple, true},
+			variants: 2,
+			status:   http.StatusNotFound,
+		},
+		{
+			name:     "inactive variant",
+			sale:     func() models.FlashSale { return flashSale(&variantID) },
+			product:  []driver.Value{int64(5), 1000.0, int64(50), models.ProductTypeSimple, true},
+			variants: 2,
+			variant:  []driver.Value{int64(8), int64(5), 1200.0, int64(20), false},
+			status:   http.StatusBadRequest,
+			details:  "Flash sales are not available for inactive variants",
+		},
+		{
+			name:     "price of the variant",
+			sale:     func() models.FlashSale { s := flashSale(&variantID); s.SalePrice = 650; return s },
+			product:  []driver.Value{int64(5), 1000.0, int64(50), models.ProductTypeSimple, true},
+			variants: 2,
+			variant:  []driver.Value{int64(8), int64(5), 600.0, int64(20), true},
+			status:   http.StatusBadRequest,
+			details:  "sale_price must be lower than the regular price",
+		},
+		{
+			name:     "stock of the variant",
+			sale:     func() models.FlashSale { s := flashSale(&variantID); s.TotalQuantity = 25; return s },
+			product:  []driver.Value{int64(5), 1000.0, int64(50), models.ProductTypeSimple, true},
+			variants: 2,
+			variant:  []driver.Value{int64(8), int64(5), 1200.0, int64(20), true},
+			status:   http.StatusBadRequest,
+			details:  "total_quantity must not exceed the 20 units in stock",
+		},
+		{
+			name:     "overlapping sale",
+			sale:     func() models.FlashSale { return flashSale(nil) },
+			product:  []driver.Value{int64(5), 1000.0, int64(50), models.ProductTypeSimple, true},
+			overlaps: 1,
+			status:   http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			if tt.product != nil {
+				fake.returns(`FROM "products"`, []string{"id", "price", "quantity", "type", "is_active"}, tt.product)
+			}
+			fake.returns(`SELECT count(*) FROM "product_variants"`, []string{"count"}, []driver.Value{tt.variants})
+			if tt.variant != nil {
+				fake.returns(`FROM "product_variants"`, []string{"id", "product_id", "price", "quantity", "is_active"}, tt.variant)
+			}
+			fake.returns(`SELECT count(*) FROM "flash_sales"`, []string{"count"}, []driver.Value{tt.overlaps})
+
+			// the rules are checked before the counters are loaded
+			_, err := services.NewFlashSaleService(db, nil).CreateFlashSale(context.Background(), tt.sale())
+
+			appErr := apperrors.GetAppError(err)
+			require.NotNil(t, appErr, "%v", err)
+			assert.Equal(t, tt.status, appErr.HTTPStatus)
+			if tt.details != "" {
+				assert.Equal(t, tt.details, appErr.Details)
+			}
+			assert.Empty(t, fake.executed(`INSERT INTO "flash_sales"`))
+		})
+	}
+}
+
+func TestCreateFlashSale_LoadsCounters(t *testing.T) {
+	db, fake := newFakeDB(t)
+	client, redisFake := newFakeRedis(t)
+	fake.returns(`FROM "products"`, []string{"id", "price", "quantity", "type", "is_active"},
+		[]driver.Value{int64(5), 1000.0, int64(50), models.ProductTypeSimple, true}).
+		returns(`INSERT INTO "flash_sales"`, []string{"id"}, []driver.Value{int64(12)})
+
+	sale, err := services.NewFlashSaleService(db, client).CreateFlashSale(context.Background(), flashSale(nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, 10, sale.Remaining)
+	assert.True(t, sale.IsActive)
+	loads := redisFake.called("EVALSHA")
+	require.Len(t, loads, 1)
+	assert.Equal(t, []string{"2", "flashsale:12:stock", "flashsale:12:buyers", "10"}, loads[0][1:5])
+}
+
+func TestFlashSaleReserve(t *testing.T) {
+	now := time.Now()
+	sales := func(fake *fakeDB) {
+		fake.returns(`FROM "flash_sales"`, flashSaleColumns,
+			[]driver.Value{int64(1), int64(5), nil, 700.0, now.Add(-time.Hour), now.Add(time.Hour), int64(10), int64(0), int64(2), true},
+			[]driver.Value{int64(2), int64(6), nil, 300.0, now.Add(-time.Hour), now.Add(time.Hour), int64(10), int64(0), int64(2), true})
+	}
+	items := []models.CartItem{{ProductID: 5, Quantity: 1}, {ProductID: 9, Quantity: 1}, {ProductID: 6, Quantity: 2}}
+
+	t.Run("items on sale are reserved at the sale price", func(t *testing.T) {
+		db, fake := newFakeDB(t)
+		client, redisFake := newFakeRedis(t)
+		sales(fake)
+		redisFake.replyInts("EVALSHA", 9, 8)
+
+		reservations, err := services.NewFlashSaleService(db, client).Reserve(context.Background(), 4, items)
+		require.NoError(t, err)
+
+		require.Len(t, reservations, 3)
+		assert.Equal(t, &services.FlashSaleReservation{FlashSaleID: 1, Quantity: 1, SalePrice: 700}, reservations[0])
+		assert.Nil(t, reservations[1], "not on sale")
+		assert.Equal(t, &services.FlashSaleReservation{FlashSaleID: 2, Quantity: 2, SalePrice: 300}, reservations[2])
+	})
+
+	tests := []struct {
+		name    string
+		result  int64
+		message string
+	}{
+		{name: "sold out", result: -2, message: "Flash sale is sold out"},
+		{name: "over the per user limit", result: -3, message: "Flash sale is limited to 2 units per customer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+" releases the items already reserved", func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			client, redisFake := newFakeRedis(t)
+			sales(fake)
+			redisFake.replyInts("EVALSHA", 9, tt.result)
+
+			_, err := services.NewFlashSaleService(db, client).Reserve(context.Background(), 4, items)
+
+			appErr := apperrors.GetAppError(err)
+			require.NotNil(t, appErr)
+			assert.Equal(t, http.StatusConflict, appErr.HTTPStatus)
+			assert.Equal(t, tt.message, appErr.Message)
+			calls := redisFake.called("EVALSHA")
+			require.Len(t, calls, 3)
+			assert.Equal(t, []string{"2", "flashsale:1:stock", "flashsale:1:buyers", "4", "1"}, calls[2][1:], "the first item is given back")
+		})
+	}
+
+	t.Run("counters lost by Redis are loaded again", func(t *testing.T) {
+		db, fake := newFakeDB(t)
+		client, redisFake := newFakeRedis(t)
+		sales(fake)
+		fake.returns("SUM(order_items.quantity)", []string{"user_id", "quantity"},
+			[]driver.Value{int64(4), int64(1)}, []driver.Value{int64(7), int64(2)})
+		redisFake.replyInts("EVALSHA", -1, 1, 6)
+
+		reservations, err := services.NewFlashSaleService(db, client).Reserve(context.Background(), 4, items[:1])
+		require.NoError(t, err)
+
+		require.NotNil(t, reservations[0])
+		calls := redisFake.called("EVALSHA")
+		require.Len(t, calls, 3)
+		assert.Equal(t, []string{"7", "4", "1", "7", "2"}, append(calls[1][4:5], calls[1][6:]...), "stock left and the units bought per user")
+	})
+
+	t.Run("no live sale", func(t *testing.T) {
+		db, _ := newFakeDB(t)
+
+		reservations, err := services.NewFlashSaleService(db, nil).Reserve(context.Background(), 4, items)
+		require.NoError(t, err)
+
+		assert.Equal(t, []*services.FlashSaleReservation{nil, nil, nil}, reservations)
+	})
+}
+
+func TestCancelFlashSale(t *testing.T) {
+	t.Run("empties the stock", func(t *testing.T) {
+		db, _ := newFakeDB(t)
+		client, redisFake := newFakeRedis(t)
+
+		require.NoError(t, services.NewFlashSaleService(db, client).CancelFlashSale(context.Background(), 12))
+
+		sets := redisFake.called("SET")
+		require.Len(t, sets, 1)
+		assert.Equal(t, []string{"flashsale:12:stock", "0"}, sets[0][:2])
+	})
+
+	t.Run("already cancelled", func(t *testing.T) {
+		db, fake := newFakeDB(t)
+		fake.affects(`UPDATE "flash_sales"`, 0).
+			returns(`FROM "flash_sales"`, []string{"id"}, []driver.Value{int64(12)})
+
+		err := services.NewFlashSaleService(db, nil).CancelFlashSale(context.Background(), 12)
+
+		appErr := apperrors.GetAppError(err)
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusConflict, appErr.HTTPStatus)
+	})
+}
+
+func TestGetFlashSaleByID_Remaining(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name  string
+		stock string // held in Redis, empty when Redis lost it
+		want  int
+	}{
+		{name: "from Redis", stock: "3", want: 3},
+		{name: "from the orders", want: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			client, redisFake := newFakeRedis(t)
+			fake.returns(`FROM "flash_sales"`, flashSaleColumns,
+				[]driver.Value{int64(12), int64(5), nil, 700.0, now.Add(-time.Hour), now.Add(time.Hour), int64(10), int64(4), int64(2), true})
+			if tt.stock != "" {
+				redisFake.replyString("GET", tt.stock)
+			}
+
+			sale, err := services.NewFlashSaleService(db, client).GetFlashSaleByID(context.Background(), 12)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, sale.Remaining)
+		})
+	}
+}
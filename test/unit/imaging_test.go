@@ -0,0 +1,182 @@
+package unit
+
+import (
+	"api_techstore/pkg/imaging"
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+// withOrientation inserts an EXIF segment with the given orientation right after the JPEG SOI
+func withOrientation(data []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)      // one entry
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112) // orientation
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)      // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0) // padding and no next IFD
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	result := append([]byte{}, data[:2]...)
+	result = append(result, segment...)
+	return append(result, data[2:]...)
+}
+
+func variantSizes(variants []imaging.Variant) map[string][2]int {
+	sizes := make(map[string][2]int)
+	for _, variant := range variants {
+		sizes[variant.Name+"/"+variant.Format] = [2]int{variant.Width, variant.Height}
+	}
+	return sizes
+}
+
+func TestGenerateVariants_PhotoAsJPEGAndWebP(t *testing.T) {
+	variants, err := imaging.GenerateVariants(testJPEG(t, 1600, 800), imaging.DefaultSizes)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string][2]int{
+		"thumbnail/jpeg": {150, 75},
+		"thumbnail/webp": {150, 75},
+		"medium/jpeg":    {600, 300},
+		"medium/webp":    {600, 300},
+		"large/jpeg":     {1200, 600},
+		"large/webp":     {1200, 600},
+	}, variantSizes(variants))
+	for _, variant := range variants {
+		assert.Equal(t, "image/"+variant.Format, variant.ContentType)
+		assert.NotEmpty(t, variant.Data)
+		if variant.Format == imaging.FormatJPEG {
+			assert.Equal(t, ".jpg", variant.Extension)
+		} else {
+			assert.Equal(t, ".webp", variant.Extension)
+		}
+	}
+}
+
+func TestGenerateVariants_TransparentPNGNotUpscaled(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 100, 40))
+	img.Set(10, 10, color.NRGBA{R: 255, A: 128})
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	variants, err := imaging.GenerateVariants(buf.Bytes(), imaging.DefaultSizes)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string][2]int{
+		"thumbnail/png":  {100, 40},
+		"thumbnail/webp": {100, 40},
+		"medium/png":     {100, 40},
+		"medium/webp":    {100, 40},
+		"large/png":      {100, 40},
+		"large/webp":     {100, 40},
+	}, variantSizes(variants))
+}
+
+func TestGenerateVariants_FollowsEXIFOrientation(t *testing.T) {
+	// stored landscape with a red left half, shown portrait turned right: the red half is on top
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 400; x++ {
+			if x < 200 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	data := withOrientation(buf.Bytes(), 6)
+
+	width, height, err := imaging.Dimensions(data)
+	require.NoError(t, err)
+	assert.Equal(t, []int{200, 400}, []int{width, height})
+
+	variants, err := imaging.GenerateVariants(data, []imaging.Size{{Name: "medium", MaxWidth: 300, MaxHeight: 300}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][2]int{"medium/jpeg": {150, 300}, "medium/webp": {150, 300}}, variantSizes(variants))
+
+	upright, err := jpeg.Decode(bytes.NewReader(variants[0].Data))
+	require.NoError(t, err)
+	top, _, _, _ := upright.At(75, 20).RGBA()
+	bottom, _, _, _ := upright.At(75, 280).RGBA()
+	assert.Greater(t, top, uint32(0xC000))
+	assert.Less(t, bottom, uint32(0x4000))
+}
+
+func TestGenerateVariants_RejectsDecompressionBombs(t *testing.T) {
+	// a PNG header announcing 10000x10000 pixels; the pixels are never decoded
+	header := binary.BigEndian.AppendUint32(nil, 10000)
+	header = binary.BigEndian.AppendUint32(header, 10000)
+	header = append(header, 8, 6, 0, 0, 0)
+	chunk := append([]byte("IHDR"), header...)
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = binary.BigEndian.AppendUint32(data, uint32(len(header)))
+	data = append(data, chunk...)
+	data = binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(chunk))
+
+	_, err := imaging.GenerateVariants(data, imaging.DefaultSizes)
+	assert.ErrorContains(t, err, "too large")
+
+	_, _, err = imaging.Downscale(data)
+	assert.ErrorContains(t, err, "too large")
+}
+
+func TestDownscale(t *testing.T) {
+	t.Run("kept as uploaded", func(t *testing.T) {
+		_, ok, err := imaging.Downscale(testJPEG(t, 1600, 800))
+
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("too many pixels", func(t *testing.T) {
+		img := image.NewGray(image.Rect(0, 0, 6000, 3000))
+		var buf bytes.Buffer
+		require.NoError(t, jpeg.Encode(&buf, img, nil))
+
+		original, ok, err := imaging.Downscale(withOrientation(buf.Bytes(), 6))
+
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, imaging.FormatJPEG, original.Format)
+		assert.Equal(t, "image/jpeg", original.ContentType)
+		assert.LessOrEqual(t, original.Width*original.Height, imaging.MaxPixels)
+		assert.InDelta(t, 2.0, float64(original.Height)/float64(original.Width), 0.01, "turned upright, aspect kept")
+		width, height, err := imaging.Dimensions(original.Data)
+		require.NoError(t, err)
+		assert.Equal(t, []int{original.Width, original.Height}, []int{width, height})
+	})
+}
+
+func TestEncode_UnsupportedFormat(t *testing.T) {
+	_, err := imaging.Encode(image.NewNRGBA(image.Rect(0, 0, 1, 1)), "gif")
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,25 @@
+package unit
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetNotifications_Unauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/notifications", nil)
+
+	// without an authenticated user the service is never reached
+	handlers.GetNotifications(c, &container.Container{})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,55 @@
+package unit
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"api_techstore/internal/models"
+	"api_techstore/test/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateOrder_StatusByRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name    string
+		role    string
+		status  string
+		allowed bool
+	}{
+		{name: "customer cancels", role: "user", status: "cancelled", allowed: true},
+		{name: "customer keeps the status", role: "user", status: "confirmed", allowed: true},
+		{name: "customer changes the address only", role: "user", status: "", allowed: true},
+		{name: "customer marks delivered", role: "user", status: "delivered", allowed: false},
+		{name: "customer marks shipped", role: "user", status: "shipped", allowed: false},
+		{name: "admin marks delivered", role: "admin", status: "delivered", allowed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.OrderService)
+			mockService.On("GetOrderByID", "3").Return(models.Order{UserID: 9, Status: "confirmed"}, nil)
+			mockService.On("UpdateOrder", "3", mock.Anything).Return(models.Order{}, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: "3"}}
+			c.Set("role", tt.role)
+			c.Set("validated_model", &models.OrderUpdateRequest{Status: tt.status})
+
+			handlers.UpdateOrder(c, &container.Container{OrderService: mockService})
+
+			if !tt.allowed {
+				assert.Equal(t, http.StatusForbidden, w.Code)
+				mockService.AssertNotCalled(t, "UpdateOrder", mock.Anything, mock.Anything)
+				return
+			}
+			assert.Equal(t, http.StatusOK, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
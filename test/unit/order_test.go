@@ -0,0 +1,87 @@
+package unit
+
+import (
+	"api_techstore/internal/models"
+	"api_techstore/internal/services"
+	apperrors "api_techstore/pkg/errors"
+	"database/sql/driver"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var orderColumns = []string{"id", "user_id", "status"}
+
+func TestUpdateOrder_Cancel(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.returns(`FROM "orders"`, orderColumns, []driver.Value{int64(3), int64(9), "confirmed"}).
+		returns("stock_taken = ", []string{"id", "order_id", "product_id", "quantity", "stock_taken"},
+			[]driver.Value{int64(40), int64(3), int64(5), int64(2), true}).
+		returns(`FROM "products"`, []string{"id", "name", "quantity", "backordered_quantity"},
+			[]driver.Value{int64(5), "ThinkPad T14", int64(3), int64(0)})
+
+	_, err := services.NewOrderService(db).UpdateOrder("3", models.Order{Status: "cancelled"})
+
+	require.NoError(t, err)
+	restocked := fake.executed(`UPDATE "products" SET "quantity"`)
+	require.Len(t, restocked, 1)
+	assert.Contains(t, restocked[0].Args, 5, "the two units taken go back on the shelf")
+	assert.NotEmpty(t, fake.executed(`UPDATE "orders"`))
+}
+
+func TestUpdateOrder_Refused(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+	}{
+		{"reopen a cancelled order", "cancelled", "pending"},
+		{"put a cancelled order back in the stock queue", "cancelled", models.OrderStatusAwaitingStock},
+		{"ship a cancelled order", "cancelled", "shipped"},
+		{"cancel a shipped order", "shipped", "cancelled"},
+		{"cancel a delivered order", "delivered", "cancelled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			fake.returns(`FROM "orders"`, orderColumns, []driver.Value{int64(3), int64(9), tt.from})
+
+			_, err := services.NewOrderService(db).UpdateOrder("3", models.Order{Status: tt.to})
+
+			appErr := apperrors.GetAppError(err)
+			require.NotNil(t, appErr, "%v", err)
+			assert.Equal(t, http.StatusConflict, appErr.HTTPStatus)
+			assert.Empty(t, fake.executed(`UPDATE "orders"`))
+			assert.Empty(t, fake.executed(`UPDATE "products"`), "no stock moves")
+			assert.Empty(t, fake.executed(`UPDATE "order_items"`))
+		})
+	}
+}
+
+func TestDeleteOrder_Stock(t *testing.T) {
+	tests := []struct {
+		status    string
+		restocked bool
+	}{
+		{"pending", true},
+		{"shipped", false},
+		{"delivered", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			fake.returns(`FROM "orders"`, orderColumns, []driver.Value{int64(3), int64(9), tt.status}).
+				returns("stock_taken = ", []string{"id", "order_id", "product_id", "quantity", "stock_taken"},
+					[]driver.Value{int64(40), int64(3), int64(5), int64(2), true}).
+				returns(`FROM "products"`, []string{"id", "name", "quantity", "backordered_quantity"},
+					[]driver.Value{int64(5), "ThinkPad T14", int64(3), int64(0)})
+
+			require.NoError(t, services.NewOrderService(db).DeleteOrder("3"))
+
+			assert.Equal(t, tt.restocked, len(fake.executed(`UPDATE "products" SET "quantity"`)) > 0)
+			assert.NotEmpty(t, fake.executed(`UPDATE "orders" SET "deleted_at"`))
+		})
+	}
+}
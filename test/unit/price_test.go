@@ -0,0 +1,157 @@
+package unit
+
+import (
+	"api_techstore/internal/models"
+	"api_techstore/internal/services"
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	priceHistoryColumns   = []string{"id", "product_id", "old_price", "new_price", "source", "changed_at"}
+	scheduledPriceColumns = []string{"id", "product_id", "price", "starts_at", "ends_at", "status", "previous_price"}
+)
+
+type priceChange struct {
+	price float64
+	ago   time.Duration
+}
+
+func TestGetPriceHistory_LowestPrice(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		name    string
+		changes []priceChange // newest first, the first one sets the current price
+		want    float64
+	}{
+		{name: "first price only", changes: []priceChange{{800, 2 * day}}, want: 800},
+		{name: "sale after a higher price", changes: []priceChange{{800, 2 * day}, {1000, 10 * day}, {1100, 50 * day}}, want: 1000},
+		{name: "the price in force at the start of the period counts", changes: []priceChange{{800, 2 * day}, {1000, 10 * day}, {700, 40 * day}, {500, 60 * day}}, want: 700},
+		{name: "prices replaced before the period are left out", changes: []priceChange{{800, 2 * day}, {1000, 10 * day}, {950, 40 * day}, {500, 60 * day}}, want: 950},
+		{name: "a raise is compared with the sale before it", changes: []priceChange{{1200, day}, {800, 5 * day}, {1000, 50 * day}}, want: 800},
+		{name: "the period ends when the current price took effect", changes: []priceChange{{900, 40 * day}, {1000, 45 * day}, {1100, 72 * day}, {600, 120 * day}}, want: 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			now := time.Now()
+			var rows [][]driver.Value
+			for i, change := range tt.changes {
+				rows = append(rows, []driver.Value{int64(len(tt.changes) - i), int64(5), nil, change.price, models.PriceSourceManual, now.Add(-change.ago)})
+			}
+			fake.returns(`FROM "products"`, []string{"id", "price"}, []driver.Value{int64(5), tt.changes[0].price}).
+				returns(`FROM "price_history"`, priceHistoryColumns, rows...)
+
+			history, err := services.NewPriceService(db).GetPriceHistory(5)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.changes[0].price, history.CurrentPrice)
+			assert.Equal(t, tt.want, history.LowestPrice)
+			assert.WithinDuration(t, now.Add(-tt.changes[0].ago-services.LowestPricePeriod), history.LowestPriceSince, time.Second)
+			assert.Len(t, history.History, len(tt.changes))
+		})
+	}
+
+	t.Run("no history", func(t *testing.T) {
+		db, fake := newFakeDB(t)
+		fake.returns(`FROM "products"`, []string{"id", "price"}, []driver.Value{int64(5), 800.0})
+
+		history, err := services.NewPriceService(db).GetPriceHistory(5)
+		require.NoError(t, err)
+
+		assert.Equal(t, 800.0, history.LowestPrice)
+		assert.WithinDuration(t, time.Now().Add(-services.LowestPricePeriod), history.LowestPriceSince, time.Second)
+	})
+}
+
+func TestApplyScheduledPrices_Start(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		endsAt     interface{}
+		wantStatus string
+		wantPrice  bool
+	}{
+		{name: "one-off change", endsAt: nil, wantStatus: models.ScheduledPriceCompleted, wantPrice: true},
+		{name: "sale with an end", endsAt: now.Add(time.Hour), wantStatus: models.ScheduledPriceActive, wantPrice: true},
+		{name: "sale whose end passed before the job ran", endsAt: now.Add(-time.Minute), wantStatus: models.ScheduledPriceCompleted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			fake.returns("starts_at <=", scheduledPriceColumns,
+				[]driver.Value{int64(3), int64(5), 800.0, now.Add(-time.Hour), tt.endsAt, models.ScheduledPricePending, nil}).
+				returns(`FROM "products"`, []string{"id", "price"}, []driver.Value{int64(5), 1000.0})
+
+			require.NoError(t, services.NewPriceService(db).ApplyScheduledPrices(context.Background()))
+
+			claims := fake.executed(`UPDATE "scheduled_prices" SET`)
+			require.Len(t, claims, 1)
+			assert.Contains(t, claims[0].Args, tt.wantStatus)
+			prices := fake.executed(`UPDATE "products" SET "price"`)
+			if !tt.wantPrice {
+				assert.NotContains(t, claims[0].SQL, "previous_price", "nothing was replaced")
+				assert.Empty(t, prices, "the price is left alone")
+				assert.Empty(t, fake.executed(`INSERT INTO "price_history"`))
+				return
+			}
+			assert.Contains(t, claims[0].Args, 1000.0, "the replaced price is kept for the end")
+			require.Len(t, prices, 1)
+			assert.Contains(t, prices[0].Args, 800.0)
+			require.Len(t, fake.executed(`INSERT INTO "price_history"`), 1)
+		})
+	}
+
+	t.Run("claimed by another instance", func(t *testing.T) {
+		db, fake := newFakeDB(t)
+		fake.returns("starts_at <=", scheduledPriceColumns,
+			[]driver.Value{int64(3), int64(5), 800.0, now.Add(-time.Hour), nil, models.ScheduledPricePending, nil}).
+			returns(`FROM "products"`, []string{"id", "price"}, []driver.Value{int64(5), 1000.0}).
+			affects(`UPDATE "scheduled_prices" SET`, 0)
+
+		require.NoError(t, services.NewPriceService(db).ApplyScheduledPrices(context.Background()))
+
+		assert.Empty(t, fake.executed(`UPDATE "products" SET "price"`))
+	})
+}
+
+func TestApplyScheduledPrices_End(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name         string
+		currentPrice float64
+		wantRestored bool
+	}{
+		{name: "the previous price comes back", currentPrice: 800, wantRestored: true},
+		{name: "a price changed by hand during the sale is kept", currentPrice: 850},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			fake.returns("ends_at <=", scheduledPriceColumns,
+				[]driver.Value{int64(3), int64(5), 800.0, now.Add(-48 * time.Hour), now.Add(-time.Minute), models.ScheduledPriceActive, 1000.0}).
+				returns(`FROM "products"`, []string{"id", "price"}, []driver.Value{int64(5), tt.currentPrice})
+
+			require.NoError(t, services.NewPriceService(db).ApplyScheduledPrices(context.Background()))
+
+			ends := fake.executed(`UPDATE "scheduled_prices" SET`)
+			require.Len(t, ends, 1)
+			assert.Contains(t, ends[0].Args, models.ScheduledPriceCompleted)
+			prices := fake.executed(`UPDATE "products" SET "price"`)
+			if !tt.wantRestored {
+				assert.Empty(t, prices)
+				return
+			}
+			require.Len(t, prices, 1)
+			assert.Contains(t, prices[0].Args, 1000.0)
+			history := fake.executed(`INSERT INTO "price_history"`)
+			require.Len(t, history, 1)
+			assert.Contains(t, history[0].Args, models.PriceSourceSchedule)
+		})
+	}
+}
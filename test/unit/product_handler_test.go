@@ -3,15 +3,20 @@ package unit
 import (
 	"api_techstore/internal/container"
 	"api_techstore/internal/handlers"
+	"api_techstore/internal/middlewares"
 	"api_techstore/internal/models"
+	v1 "api_techstore/internal/routes/v1"
 	"api_techstore/test/mocks"
+	"bytes"
 	"encoding/json"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestGetAllProducts_Success(t *testing.T) {
@@ -46,3 +51,133 @@ func TestGetAllProducts_Success(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 }
+
+func TestCompareProducts_InvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/products/compare?ids=1,abc", nil)
+
+	// the service is never reached for malformed ids
+	handlers.CompareProducts(c, &container.Container{})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetRelatedProducts_InvalidLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/products/1/related?limit=0", nil)
+
+	handlers.GetRelatedProducts(c, &container.Container{})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetRecentlyViewed_NoViewer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/me/recently-viewed", nil)
+	c.Request.Header.Set(handlers.SessionIDHeader, "bad id")
+
+	// neither a user nor a valid guest session: there is no history to look up
+	handlers.GetRecentlyViewed(c, &container.Container{})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRecentlyViewedRoutes_OpenToGuests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctn := &container.Container{}
+	router := gin.New()
+	guests := router.Group("")
+	guests.Use(middlewares.OptionalJWTAuthMiddleware(ctn))
+	v1.SetupRecentlyViewedRoutes(guests, ctn)
+
+	// a guest without a token reaches the handler, which rejects the limit
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/me/recently-viewed?limit=abc", nil)
+	req.Header.Set(handlers.SessionIDHeader, "guest-session-1")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// a malformed token is still rejected
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/me/recently-viewed", nil)
+	req.Header.Set("Authorization", "Token abc")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestImportProducts_UnsupportedFileType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", "products.json")
+	part.Write([]byte(`[]`))
+	writer.Close()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/products/imports", body)
+	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	c.Set("user_id", uint(1))
+
+	// only CSV and XLSX files reach the import service
+	handlers.ImportProducts(c, &container.Container{})
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
+
+func TestGetProductPriceHistory_InvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/products/abc/price-history", nil)
+
+	handlers.GetProductPriceHistory(c, &container.Container{})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSetBundleItems_InvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+	c.Request = httptest.NewRequest(http.MethodPut, "/products/abc/bundle-items", nil)
+
+	handlers.SetBundleItems(c, &container.Container{})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUpdateProduct_ClearsWarrantyMonths(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	months := 24
+	mockService := new(mocks.ProductService)
+	mockService.On("GetProductById", "1").Return(models.Product{CategoryID: 3, WarrantyMonths: &months}, nil)
+	mockService.On("UpdateProduct", "1", mock.MatchedBy(func(product models.Product) bool {
+		return product.WarrantyMonths == nil
+	})).Return(models.Product{}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Set("validated_model", &models.ProductUpdateRequest{ClearWarrantyMonths: true})
+
+	handlers.UpdateProduct(c, &container.Container{ProductService: mockService})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,177 @@
+package unit
+
+import (
+	"api_techstore/internal/services"
+	apperrors "api_techstore/pkg/errors"
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// uploadedFile builds the header of a multipart file upload holding data
+func uploadedFile(t *testing.T, name string, data []byte) *multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("image", name)
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(int64(len(data)) + 1024)
+	require.NoError(t, err)
+	return form.File["image"][0]
+}
+
+func TestAddProductImage_TooLarge(t *testing.T) {
+	data := make([]byte, services.MaxProductImageSize+1)
+	copy(data, testJPEG(t, 10, 10))
+
+	// the upload is checked before the product is looked up
+	_, err := services.NewProductImageService(nil, nil).AddProductImage(context.Background(), 1, uploadedFile(t, "big.jpg", data), false)
+
+	appErr := apperrors.GetAppError(err)
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, appErr.HTTPStatus)
+}
+
+// memoryStorage keeps the stored objects in a map
+type memoryStorage struct {
+	objects map[string][]byte
+}
+
+func (s *memoryStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	if s.objects == nil {
+		s.objects = map[string][]byte{}
+	}
+	s.objects[key] = data
+	return "https://cdn.example.com/" + key, nil
+}
+
+func (s *memoryStorage) Delete(ctx context.Context, key string) error {
+	delete(s.objects, key)
+	return nil
+}
+
+func TestAddProductImage_RejectsOtherFileTypes(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "notes.jpg", data: []byte("plain text pretending to be a photo")},
+		{name: "animation.gif", data: []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;")},
+		{name: "doc.pdf", data: []byte("%PDF-1.7\n")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryStorage{}
+			_, err := services.NewProductImageService(nil, store).AddProductImage(context.Background(), 1, uploadedFile(t, tt.name, tt.data), false)
+
+			appErr := apperrors.GetAppError(err)
+			require.NotNil(t, appErr)
+			assert.Equal(t, http.StatusUnsupportedMediaType, appErr.HTTPStatus)
+			assert.Empty(t, store.objects)
+		})
+	}
+}
+
+func TestAddProductImage_KeepsASingleMainImage(t *testing.T) {
+	tests := []struct {
+		name        string
+		isMain      bool
+		existing    int64
+		wantMain    bool
+		wantUnset   bool
+		wantSortPos int
+	}{
+		{name: "first image becomes main", isMain: false, existing: 0, wantMain: true, wantUnset: true, wantSortPos: 0},
+		{name: "new main image replaces the old one", isMain: true, existing: 2, wantMain: true, wantUnset: true, wantSortPos: 2},
+		{name: "other images leave the main one", isMain: false, existing: 2, wantMain: false, wantUnset: false, wantSortPos: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			fake.returns(`FROM "products"`, []string{"id"}, []driver.Value{int64(1)})
+			var maxSort driver.Value
+			if tt.existing > 0 {
+				maxSort = tt.existing - 1
+			}
+			fake.returns("COUNT(*) AS total", []string{"total", "max_sort"}, []driver.Value{tt.existing, maxSort})
+			store := &memoryStorage{}
+
+			image, err := services.NewProductImageService(db, store).AddProductImage(context.Background(), 1, uploadedFile(t, "photo.jpg", testJPEG(t, 40, 30)), tt.isMain)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantMain, image.IsMain)
+			assert.Equal(t, tt.wantSortPos, image.SortOrder)
+			assert.Len(t, store.objects, 1+len(image.Variants))
+			unset := fake.position(`UPDATE "product_images" SET "is_main"`)
+			insert := fake.position(`INSERT INTO "product_images"`)
+			require.NotEqual(t, -1, insert)
+			if tt.wantUnset {
+				require.NotEqual(t, -1, unset, "the current main image is unset")
+				assert.Less(t, unset, insert, "before the new one is stored")
+			} else {
+				assert.Equal(t, -1, unset)
+			}
+		})
+	}
+}
+
+func TestAddProductImage_RemovesFilesWhenNotSaved(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.returns(`FROM "products"`, []string{"id"}, []driver.Value{int64(1)}).
+		fails(`INSERT INTO "product_images"`, errors.New("connection reset"))
+	store := &memoryStorage{}
+
+	_, err := services.NewProductImageService(db, store).AddProductImage(context.Background(), 1, uploadedFile(t, "photo.jpg", testJPEG(t, 40, 30)), false)
+
+	require.Error(t, err)
+	assert.Empty(t, store.objects)
+	assert.NotEmpty(t, fake.executed("ROLLBACK"))
+}
+
+func TestSetMainImage_UnsetsThePreviousOne(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.returns(`FROM "product_images"`, []string{"id", "product_id", "is_main"}, []driver.Value{int64(5), int64(1), false})
+
+	image, err := services.NewProductImageService(db, &memoryStorage{}).SetMainImage(1, 5)
+	require.NoError(t, err)
+
+	assert.True(t, image.IsMain)
+	updates := fake.executed(`UPDATE "product_images" SET "is_main"`)
+	require.Len(t, updates, 2)
+	assert.Contains(t, updates[0].SQL, "is_main = $")
+	assert.Equal(t, false, updates[0].Args[0], "the main image of the product is unset first")
+	assert.Equal(t, true, updates[1].Args[0])
+}
+
+func TestDeleteProductImage_PromotesNextImage(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.returns(`WHERE product_id = $1 AND "product_images"."id" = $2`, []string{"id", "product_id", "is_main", "storage_key"},
+		[]driver.Value{int64(5), int64(1), true, "products/1/a.jpg"}).
+		returns(`ORDER BY sort_order, id`, []string{"id", "product_id", "is_main"}, []driver.Value{int64(6), int64(1), false})
+	store := &memoryStorage{objects: map[string][]byte{"products/1/a.jpg": {1}}}
+
+	err := services.NewProductImageService(db, store).DeleteProductImage(context.Background(), 1, 5)
+	require.NoError(t, err)
+
+	promote := fake.executed(`UPDATE "product_images" SET "is_main"`)
+	require.Len(t, promote, 1)
+	assert.Equal(t, true, promote[0].Args[0])
+	assert.Equal(t, uint(6), promote[0].Args[len(promote[0].Args)-1])
+	assert.Empty(t, store.objects)
+}
@@ -0,0 +1,276 @@
+package unit
+
+import (
+	"api_techstore/internal/models"
+	"api_techstore/internal/services"
+	apperrors "api_techstore/pkg/errors"
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// importReport waits for the background import to save its final state and returns the
+// arguments of that update, printed
+func importReport(t *testing.T, fake *fakeDB) string {
+	t.Helper()
+	var report string
+	require.Eventually(t, func() bool {
+		for _, update := range fake.executed(`UPDATE "import_jobs"`) {
+			var args []string
+			for _, arg := range update.Args {
+				// row_errors is given as its JSON serializer
+				if valuer, ok := arg.(driver.Valuer); ok {
+					arg, _ = valuer.Value()
+				}
+				if data, ok := arg.([]byte); ok {
+					arg = string(data)
+				}
+				args = append(args, fmt.Sprint(arg))
+			}
+			if joined := strings.Join(args, " | "); strings.Contains(joined, models.ImportStatusCompleted) {
+				report = joined
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 5*time.Millisecond)
+	return report
+}
+
+func TestStartImport_Header(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		data    string
+		problem string // empty when the file is accepted
+	}{
+		{name: "required columns in any case", format: services.ProductFileCSV, data: "Name,PRICE,quantity,category_slug\nX1,10,1,laptops\n"},
+		{name: "byte order mark", format: services.ProductFileCSV, data: "\ufeffname,price,quantity,category_slug\nX1,10,1,laptops\n"},
+		{name: "attribute columns", format: services.ProductFileCSV, data: "name,price,quantity,category_slug,attr:ram_gb\nX1,10,1,laptops,16\n"},
+		{name: "unknown column", format: services.ProductFileCSV, data: "name,price,quantity,category_slug,colour\nX1,10,1,laptops,red\n", problem: "unknown column 'colour'"},
+		{name: "duplicate column", format: services.ProductFileCSV, data: "name,price,quantity,category_slug,Name\nX1,10,1,laptops,X1\n", problem: "duplicate column 'Name'"},
+		{name: "missing columns", format: services.ProductFileCSV, data: "name,quantity\nX1,1\n", problem: "missing columns: price, category_slug"},
+		{name: "empty file", format: services.ProductFileCSV, data: "", problem: "file is empty"},
+		{name: "blank rows only", format: services.ProductFileCSV, data: "name,price,quantity,category_slug\n,,,\n", problem: "file has no product rows"},
+		{name: "unknown format", format: "ods", data: "name", problem: "format must be csv or xlsx"},
+		{name: "not a spreadsheet", format: services.ProductFileXLSX, data: "name,price", problem: "Unable to read XLSX file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+
+			job, err := services.NewProductImportService(db).StartImport("products.csv", tt.format, []byte(tt.data), 1)
+
+			if tt.problem != "" {
+				appErr := apperrors.GetAppError(err)
+				require.NotNil(t, appErr, "%v", err)
+				assert.Equal(t, http.StatusBadRequest, appErr.HTTPStatus)
+				assert.Equal(t, tt.problem, appErr.Details)
+				assert.Empty(t, fake.executed(`INSERT INTO "import_jobs"`), "no job is recorded")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 1, job.TotalRows)
+			assert.Equal(t, models.ImportStatusPending, job.Status)
+			importReport(t, fake)
+		})
+	}
+}
+
+func TestStartImport_BadCells(t *testing.T) {
+	tests := []struct {
+		name    string
+		row     string
+		problem string
+	}{
+		{name: "price", row: "X1,ten,1,laptops,", problem: "price must be a number"},
+		{name: "quantity", row: "X1,10,1.5,laptops,", problem: "quantity must be a whole number"},
+		{name: "is_active", row: "X1,10,1,laptops,maybe", problem: "is_active must be true or false"},
+		{name: "missing name", row: ",10,1,laptops,", problem: "Name failed the 'required' rule"},
+		{name: "unknown category", row: "X1,10,1,tablets,", problem: "category 'tablets' not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			fake.returnsFor(`FROM "categories"`, "laptops", []string{"id"}, []driver.Value{int64(7)})
+
+			_, err := services.NewProductImportService(db).StartImport("products.csv", services.ProductFileCSV,
+				[]byte("name,price,quantity,category_slug,is_active\n"+tt.row+"\n"), 1)
+			require.NoError(t, err)
+
+			report := importReport(t, fake)
+			assert.Contains(t, report, `"row":2`)
+			assert.Contains(t, report, "completed | 1 | 0 | 0 | 1", "one processed row, failed")
+			assert.Contains(t, report, tt.problem)
+			assert.Empty(t, fake.executed(`INSERT INTO "products"`))
+			assert.Empty(t, fake.executed(`UPDATE "products"`))
+		})
+	}
+}
+
+// oldSlugImport scripts product 12, now "thinkpad-t14-gen-5", found by its old slug in a row that
+// raises its price and its stock from 3 to 10
+func oldSlugImport(t *testing.T, waitingLines int64) *fakeDB {
+	db, fake := newFakeDB(t)
+	fake.returns(`FROM "slug_redirects"`, []string{"id", "entity_type", "slug", "entity_id"},
+		[]driver.Value{int64(1), models.SlugEntityProduct, "thinkpad-t14", int64(12)}).
+		returns(`FROM "products" WHERE id = $1`, []string{"id", "name", "slug", "price", "quantity", "category_id", "is_active"},
+			[]driver.Value{int64(12), "ThinkPad T14", "thinkpad-t14-gen-5", 999.0, int64(3), int64(7), false}).
+		returns(`SELECT "id","quantity" FROM "products"`, []string{"id", "quantity"}, []driver.Value{int64(12), int64(3)}).
+		returns(`SELECT "id","name","quantity","backordered_quantity" FROM "products"`, []string{"id", "name", "quantity", "backordered_quantity"},
+			[]driver.Value{int64(12), "ThinkPad T14", int64(3), int64(0)}).
+		returns(`SELECT count(*) FROM "order_items"`, []string{"count"}, []driver.Value{waitingLines}).
+		returnsFor(`FROM "categories"`, "laptops", []string{"id"}, []driver.Value{int64(7)})
+
+	_, err := services.NewProductImportService(db).StartImport("products.csv", services.ProductFileCSV,
+		[]byte("slug,name,price,quantity,category_slug\nThinkPad T14,ThinkPad T14 Gen 5,1099,10,laptops\n"), 1)
+	require.NoError(t, err)
+	return fake
+}
+
+func TestStartImport_UpdatesByOldSlug(t *testing.T) {
+	fake := oldSlugImport(t, 0)
+
+	report := importReport(t, fake)
+	assert.NotContains(t, report, "errors")
+	assert.Empty(t, fake.executed(`INSERT INTO "products"`), "the product is updated, not created again")
+
+	updates := fake.executed(`"name"=`)
+	require.Len(t, updates, 1)
+	assert.NotContains(t, updates[0].SQL, `"slug"`, "the current slug is kept")
+	assert.NotContains(t, updates[0].SQL, `"quantity"`, "the stock goes through setStock")
+	assert.Contains(t, updates[0].Args, "ThinkPad T14 Gen 5")
+	assert.Contains(t, updates[0].Args, false, "is_active is kept when the file has no such column")
+	assert.Contains(t, updates[0].Args, uint(12))
+
+	history := fake.executed(`INSERT INTO "price_history"`)
+	require.Len(t, history, 1)
+	assert.Contains(t, history[0].Args, 1099.0)
+	assert.Contains(t, history[0].Args, models.PriceSourceImport)
+}
+
+func TestStartImport_Stock(t *testing.T) {
+	tests := []struct {
+		name     string
+		waiting  int64
+		received bool
+	}{
+		{name: "set as is", waiting: 0, received: false},
+		{name: "received for the waiting orders", waiting: 1, received: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := oldSlugImport(t, tt.waiting)
+
+			importReport(t, fake)
+			stock := fake.executed(`UPDATE "products" SET "quantity"`)
+			require.Len(t, stock, 1)
+			assert.Contains(t, stock[0].Args, 10)
+			assert.Equal(t, tt.received, len(fake.executed("ORDER BY orders.created_at")) > 0, "allocated oldest order first")
+		})
+	}
+}
+
+func TestFailStaleImports(t *testing.T) {
+	db, fake := newFakeDB(t)
+
+	require.NoError(t, services.NewProductImportService(db).FailStaleImports(context.Background()))
+
+	updates := fake.executed(`UPDATE "import_jobs"`)
+	require.Len(t, updates, 1)
+	assert.Contains(t, updates[0].SQL, "status IN ($")
+	assert.Contains(t, updates[0].SQL, "updated_at <")
+	assert.Contains(t, updates[0].Args, models.ImportStatusFailed)
+	assert.Contains(t, updates[0].Args, models.ImportStatusPending)
+	assert.Contains(t, updates[0].Args, models.ImportStatusRunning)
+	var cutoff time.Time
+	for _, arg := range updates[0].Args {
+		if at, ok := arg.(time.Time); ok && (cutoff.IsZero() || at.Before(cutoff)) {
+			cutoff = at
+		}
+	}
+	assert.WithinDuration(t, time.Now().Add(-10*time.Minute), cutoff, time.Minute, "jobs silent for ten minutes")
+}
+
+func TestStartImport_StopsWhenFailedMeanwhile(t *testing.T) {
+	db, fake := newFakeDB(t)
+	// the watchdog failed the job: its progress updates match no row anymore
+	fake.affects(`UPDATE "import_jobs"`, 0)
+
+	_, err := services.NewProductImportService(db).StartImport("products.csv", services.ProductFileCSV,
+		[]byte("name,price,quantity,category_slug\nX1,10,1,laptops\n"), 1)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return len(fake.executed(`UPDATE "import_jobs"`)) > 0 }, 5*time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	updates := fake.executed(`UPDATE "import_jobs"`)
+	require.Len(t, updates, 1, "the job is not saved again")
+	assert.Contains(t, updates[0].SQL, "status IN ($")
+	assert.Empty(t, fake.executed(`FROM "categories"`), "no row is imported")
+}
+
+func TestExportProducts_ImportsBack(t *testing.T) {
+	for _, format := range []string{services.ProductFileCSV, services.ProductFileXLSX} {
+		t.Run(format, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			fake.returns(`FROM "products"`, []string{"id", "slug", "name", "description", "price", "quantity", "category_id", "brand_id", "is_active"},
+				[]driver.Value{int64(1), "thinkpad-t14", "ThinkPad T14", "14\", 16GB", 1299.5, int64(3), int64(7), int64(4), true},
+				[]driver.Value{int64(2), "usb-c-dock", "USB-C Dock", "", 89.0, int64(0), int64(8), nil, false}).
+				returns(`FROM "categories"`, []string{"id", "slug"}, []driver.Value{int64(7), "laptops"}, []driver.Value{int64(8), "accessories"}).
+				returns(`FROM "brands"`, []string{"id", "slug"}, []driver.Value{int64(4), "lenovo"}).
+				returns(`FROM "product_attribute_values"`, []string{"id", "product_id", "attribute_id", "value_number"},
+					[]driver.Value{int64(1), int64(1), int64(11), 16.0}).
+				returns(`FROM "category_attributes"`, []string{"id", "code"}, []driver.Value{int64(11), "ram_gb"})
+
+			service := services.NewProductImportService(db)
+			data, err := service.ExportProducts(format)
+			require.NoError(t, err)
+
+			assert.Equal(t, [][]string{
+				{"slug", "name", "description", "price", "quantity", "category_slug", "brand_slug", "is_active", "attr:ram_gb"},
+				{"thinkpad-t14", "ThinkPad T14", "14\", 16GB", "1299.5", "3", "laptops", "lenovo", "true", "16"},
+				{"usb-c-dock", "USB-C Dock", "", "89", "0", "accessories", "", "false", ""},
+			}, exportedRecords(t, format, data))
+
+			importDB, importFake := newFakeDB(t)
+			job, err := services.NewProductImportService(importDB).StartImport("export."+format, format, data, 1)
+			require.NoError(t, err, "an export is a valid import file")
+			assert.Equal(t, 2, job.TotalRows)
+			importReport(t, importFake)
+		})
+	}
+}
+
+func exportedRecords(t *testing.T, format string, data []byte) [][]string {
+	t.Helper()
+	if format == services.ProductFileCSV {
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		require.NoError(t, err)
+		return records
+	}
+	file, err := excelize.OpenReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	defer file.Close()
+	rows, err := file.GetRows(file.GetSheetList()[0])
+	require.NoError(t, err)
+	// GetRows drops the empty cells ending a row, and Excel shows booleans in capitals
+	for i := range rows {
+		if i > 0 {
+			rows[i][7] = strings.ToLower(rows[i][7])
+		}
+		for len(rows[i]) < len(rows[0]) {
+			rows[i] = append(rows[i], "")
+		}
+	}
+	return rows
+}
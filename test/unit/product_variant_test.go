@@ -0,0 +1,30 @@
+package unit
+
+import (
+	"api_techstore/internal/models"
+	"api_techstore/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVariantOptionKey_IgnoresOrderCaseAndSpacing(t *testing.T) {
+	a := services.VariantOptionKey([]models.ProductVariantOption{
+		{Name: "Color", Value: "Silver"},
+		{Name: "Storage", Value: "512GB"},
+	})
+	b := services.VariantOptionKey([]models.ProductVariantOption{
+		{Name: " storage", Value: "512gb "},
+		{Name: "color", Value: "SILVER"},
+	})
+
+	assert.Equal(t, "color=silver;storage=512gb", a)
+	assert.Equal(t, a, b)
+}
+
+func TestVariantOptionKey_DifferentValues(t *testing.T) {
+	a := services.VariantOptionKey([]models.ProductVariantOption{{Name: "color", Value: "Silver"}})
+	b := services.VariantOptionKey([]models.ProductVariantOption{{Name: "color", Value: "Space Gray"}})
+
+	assert.NotEqual(t, a, b)
+}
@@ -0,0 +1,106 @@
+package unit
+
+import (
+	"api_techstore/internal/services"
+	apperrors "api_techstore/pkg/errors"
+	"context"
+	"database/sql/driver"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordView(t *testing.T) {
+	db, _ := newFakeDB(t)
+	client, redisFake := newFakeRedis(t)
+
+	err := services.NewProductViewService(db, client).RecordView(context.Background(), "user:12", 5)
+
+	require.NoError(t, err)
+	assert.Equal(t, "views:recent:user:12", redisFake.called("ZADD")[0][0])
+	assert.Equal(t, [][]string{{"views:recent:user:12", "0", "-51"}}, redisFake.called("ZREMRANGEBYRANK"), "only the last 50 products are kept")
+	daily := "views:daily:" + time.Now().UTC().Format("20060102")
+	assert.Equal(t, [][]string{{daily, "1", "5"}}, redisFake.called("ZINCRBY"))
+}
+
+func TestRecordView_NoViewer(t *testing.T) {
+	db, _ := newFakeDB(t)
+	client, redisFake := newFakeRedis(t)
+
+	require.NoError(t, services.NewProductViewService(db, client).RecordView(context.Background(), "", 5))
+
+	assert.Empty(t, redisFake.called("ZADD"), "there is no history to add to")
+	assert.Len(t, redisFake.called("ZINCRBY"), 1, "the view still counts for trending")
+}
+
+func TestGetRecentlyViewed(t *testing.T) {
+	db, fake := newFakeDB(t)
+	// product 9 was deactivated since it was viewed, the catalog query leaves it out
+	fake.returns(`FROM "products"`, []string{"id", "name"},
+		[]driver.Value{int64(3), "MX Keys"},
+		[]driver.Value{int64(7), "MX Master 3S"})
+	client, redisFake := newFakeRedis(t)
+	redisFake.replyStrings("ZREVRANGE", "7", "9", "3")
+
+	products, err := services.NewProductViewService(db, client).GetRecentlyViewed(context.Background(), "session:abc", 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"views:recent:session:abc", "0", "49"}}, redisFake.called("ZREVRANGE"))
+	require.Len(t, products, 2)
+	assert.Equal(t, uint(7), products[0].ID, "latest first, as kept in Redis")
+	assert.Equal(t, uint(3), products[1].ID)
+}
+
+func TestGetTrending(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.returns(`FROM "products"`, []string{"id", "name"},
+		[]driver.Value{int64(7), "MX Master 3S"},
+		[]driver.Value{int64(3), "MX Keys"})
+	client, redisFake := newFakeRedis(t)
+	redisFake.replyInts("EXISTS", 0).
+		replyStrings("ZREVRANGE", "3", "25", "9", "18", "7", "10", "4", "2")
+
+	trending, err := services.NewProductViewService(db, client).GetTrending(context.Background(), 3, 2)
+
+	require.NoError(t, err)
+	union := redisFake.called("ZUNIONSTORE")
+	require.Len(t, union, 1, "the daily counts are summed when the cache is cold")
+	now := time.Now().UTC()
+	assert.Equal(t, []string{"views:trending:3", "3",
+		"views:daily:" + now.Format("20060102"),
+		"views:daily:" + now.AddDate(0, 0, -1).Format("20060102"),
+		"views:daily:" + now.AddDate(0, 0, -2).Format("20060102")}, union[0])
+	assert.Equal(t, [][]string{{"views:trending:3", "0", "3", "withscores"}}, redisFake.called("ZREVRANGE"), "twice the limit is read to skip inactive products")
+
+	require.Len(t, trending, 2)
+	assert.Equal(t, uint(3), trending[0].ID)
+	assert.Equal(t, int64(25), trending[0].Views)
+	assert.Equal(t, uint(7), trending[1].ID, "inactive product 9 is skipped")
+	assert.Equal(t, int64(10), trending[1].Views)
+}
+
+func TestGetTrending_Cached(t *testing.T) {
+	db, _ := newFakeDB(t)
+	client, redisFake := newFakeRedis(t)
+
+	_, err := services.NewProductViewService(db, client).GetTrending(context.Background(), 0, 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"views:trending:7"}}, redisFake.called("EXISTS"), "a week by default")
+	assert.Empty(t, redisFake.called("ZUNIONSTORE"))
+}
+
+func TestGetTrending_TooManyDays(t *testing.T) {
+	db, _ := newFakeDB(t)
+	client, redisFake := newFakeRedis(t)
+
+	_, err := services.NewProductViewService(db, client).GetTrending(context.Background(), services.MaxTrendingDays+1, 0)
+
+	appErr := apperrors.GetAppError(err)
+	require.NotNil(t, appErr, "%v", err)
+	assert.Equal(t, http.StatusBadRequest, appErr.HTTPStatus)
+	assert.Empty(t, redisFake.called("EXISTS"))
+}
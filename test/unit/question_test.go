@@ -0,0 +1,136 @@
+package unit
+
+import (
+	"api_techstore/internal/models"
+	"api_techstore/internal/services"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var questionColumns = []string{"id", "product_id", "user_id", "content", "status"}
+
+func TestCreateAnswer_Author(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     uint
+		isStaff    bool
+		delivered  bool
+		authorType string
+		status     string
+		notified   bool
+	}{
+		{name: "staff", userID: 1, isStaff: true, authorType: models.AnswerAuthorStaff, status: models.QAStatusApproved, notified: true},
+		{name: "verified buyer", userID: 12, delivered: true, authorType: models.AnswerAuthorVerifiedBuyer, status: models.QAStatusPending},
+		{name: "customer", userID: 12, authorType: models.AnswerAuthorCustomer, status: models.QAStatusPending},
+		{name: "staff answering their own question", userID: 9, isStaff: true, authorType: models.AnswerAuthorStaff, status: models.QAStatusApproved},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			fake.returns(`FROM "product_questions"`, questionColumns,
+				[]driver.Value{int64(7), int64(5), int64(9), "Does it fit a 15 inch laptop?", models.QAStatusApproved}).
+				returns(`INSERT INTO "product_answers"`, []string{"id"}, []driver.Value{int64(30)}).
+				returns(`FROM "product_answers"`, []string{"id", "question_id", "user_id", "author_type", "status"},
+					[]driver.Value{int64(30), int64(7), int64(tt.userID), tt.authorType, tt.status}).
+				returns(`FROM "products"`, []string{"id", "name"}, []driver.Value{int64(5), "Laptop sleeve"})
+			if tt.delivered {
+				fake.returns(`FROM "order_items"`, []string{"id"}, []driver.Value{int64(40)})
+			}
+
+			_, err := services.NewQuestionService(db).CreateAnswer(5, 7, tt.userID, "Yes, up to 15.6 inch", tt.isStaff)
+
+			require.NoError(t, err)
+			inserted := fake.executed(`INSERT INTO "product_answers"`)
+			require.Len(t, inserted, 1)
+			assert.Contains(t, inserted[0].Args, tt.authorType)
+			assert.Contains(t, inserted[0].Args, tt.status)
+			assert.Equal(t, tt.isStaff, len(fake.executed(`FROM "order_items"`)) == 0, "staff are never looked up as buyers")
+
+			notifications := fake.executed(`INSERT INTO "notifications"`)
+			if !tt.notified {
+				assert.Empty(t, notifications, "only published answers from someone else notify the asker")
+				return
+			}
+			require.Len(t, notifications, 1)
+			assert.Contains(t, notifications[0].Args, uint(9), "the asker is notified")
+			assert.Contains(t, notifications[0].Args, models.NotificationQuestionAnswered)
+			assert.Contains(t, notifications[0].Args, "Your question about Laptop sleeve has a new answer: Yes, up to 15.6 inch")
+		})
+	}
+}
+
+func TestCreateAnswer_UnpublishedQuestion(t *testing.T) {
+	db, fake := newFakeDB(t)
+
+	_, err := services.NewQuestionService(db).CreateAnswer(5, 7, 1, "Yes", true)
+
+	require.Error(t, err)
+	lookup := fake.executed(`FROM "product_questions"`)
+	require.Len(t, lookup, 1)
+	assert.Contains(t, lookup[0].Args, models.QAStatusApproved, "pending and rejected questions cannot be answered")
+	assert.Empty(t, fake.executed(`INSERT INTO "product_answers"`))
+}
+
+func TestModerateAnswer_Notification(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		notified bool
+	}{
+		{"approved", models.QAStatusPending, models.QAStatusApproved, true},
+		{"rejected", models.QAStatusPending, models.QAStatusRejected, false},
+		{"approved again", models.QAStatusApproved, models.QAStatusApproved, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			fake.returns(`FROM "product_answers"`, []string{"id", "question_id", "user_id", "content", "author_type", "status"},
+				[]driver.Value{int64(30), int64(7), int64(12), "Yes, up to 15.6 inch", models.AnswerAuthorVerifiedBuyer, tt.from}).
+				returns(`FROM "product_questions"`, questionColumns,
+					[]driver.Value{int64(7), int64(5), int64(9), "Does it fit a 15 inch laptop?", models.QAStatusApproved}).
+				returns(`FROM "products"`, []string{"id", "name"}, []driver.Value{int64(5), "Laptop sleeve"})
+
+			_, err := services.NewQuestionService(db).ModerateAnswer(30, tt.to, "")
+
+			require.NoError(t, err)
+			updates := fake.executed(`UPDATE "product_answers"`)
+			require.Len(t, updates, 1)
+			assert.Contains(t, updates[0].Args, tt.to)
+			notifications := fake.executed(`INSERT INTO "notifications"`)
+			if !tt.notified {
+				assert.Empty(t, notifications)
+				return
+			}
+			require.Len(t, notifications, 1)
+			assert.Contains(t, notifications[0].Args, uint(9))
+		})
+	}
+}
+
+func TestGetProductQuestions_OnlyApproved(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.returns(`FROM "products"`, []string{"id"}, []driver.Value{int64(5)}).
+		returns(`FROM "product_questions"`, questionColumns,
+			[]driver.Value{int64(7), int64(5), int64(9), "Does it fit a 15 inch laptop?", models.QAStatusApproved}).
+		returns(`FROM "product_answers"`, []string{"id", "question_id", "user_id", "author_type", "status"},
+			[]driver.Value{int64(31), int64(7), int64(12), models.AnswerAuthorVerifiedBuyer, models.QAStatusApproved},
+			[]driver.Value{int64(30), int64(7), int64(12), models.AnswerAuthorCustomer, models.QAStatusApproved})
+
+	questions, err := services.NewQuestionService(db).GetProductQuestions(5)
+
+	require.NoError(t, err)
+	require.Len(t, questions, 1)
+	require.Len(t, questions[0].Answers, 2)
+	for _, fragment := range []string{`FROM "product_questions"`, `FROM "product_answers"`} {
+		statements := fake.executed(fragment)
+		require.Len(t, statements, 1, fragment)
+		assert.Contains(t, statements[0].SQL, "status = $")
+		assert.Contains(t, statements[0].Args, models.QAStatusApproved, "pending and rejected entries stay hidden: %s", fragment)
+	}
+	assert.Contains(t, fake.executed(`FROM "product_answers"`)[0].SQL, "CASE author_type WHEN 'staff' THEN 0 WHEN 'verified_buyer' THEN 1",
+		"staff answers first, then verified buyers")
+}
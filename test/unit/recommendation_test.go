@@ -0,0 +1,108 @@
+package unit
+
+import (
+	"api_techstore/internal/models"
+	"api_techstore/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recommendationList is the recommended product ids of one product and kind, by rank, with their sources
+func recommendationList(t *testing.T, rows []models.ProductRecommendation, productID uint, kind string) ([]uint, []string) {
+	t.Helper()
+	var ids []uint
+	var sources []string
+	for _, row := range rows {
+		if row.ProductID == productID && row.Kind == kind {
+			require.Equal(t, len(ids)+1, row.Rank, "ranks follow the list order")
+			ids = append(ids, row.RecommendedID)
+			sources = append(sources, row.Source)
+		}
+	}
+	return ids, sources
+}
+
+func TestBuildRecommendations(t *testing.T) {
+	dell, hp := uint(1), uint(2)
+	catalog := []services.CatalogEntry{
+		{ID: 1, CategoryID: 7, BrandID: &dell, Price: 1000},
+		{ID: 2, CategoryID: 7, BrandID: &dell, Price: 1500},
+		{ID: 3, CategoryID: 7, BrandID: &hp, Price: 1050},
+		{ID: 4, CategoryID: 7, BrandID: &hp, Price: 900},
+		{ID: 5, CategoryID: 9, BrandID: &dell, Price: 50},
+		{ID: 6, CategoryID: 10, Price: 40},
+	}
+	pairs := []services.CoPurchase{
+		{ProductID: 1, RecommendedID: 99, Together: 5}, // no longer active
+		{ProductID: 1, RecommendedID: 5, Together: 3},
+		{ProductID: 1, RecommendedID: 6, Together: 1},
+		{ProductID: 5, RecommendedID: 1, Together: 3},
+		{ProductID: 6, RecommendedID: 1, Together: 1},
+	}
+	now := time.Date(2026, 3, 1, 4, 0, 0, 0, time.UTC)
+
+	rows := services.BuildRecommendations(catalog, pairs, now)
+
+	tests := []struct {
+		name        string
+		productID   uint
+		kind        string
+		wantIDs     []uint
+		wantSources []string
+	}{
+		{
+			name: "bought together, most orders first", productID: 1, kind: models.RecommendationBoughtTogether,
+			wantIDs:     []uint{5, 6},
+			wantSources: []string{models.RecommendationSourceOrders, models.RecommendationSourceOrders},
+		},
+		{
+			name: "related tops up with the category, same brand first then closest in price", productID: 1, kind: models.RecommendationRelated,
+			wantIDs: []uint{5, 6, 2, 3, 4},
+			wantSources: []string{models.RecommendationSourceOrders, models.RecommendationSourceOrders,
+				models.RecommendationSourceCategory, models.RecommendationSourceCategory, models.RecommendationSourceCategory},
+		},
+		{
+			name: "related falls back to the brand", productID: 5, kind: models.RecommendationRelated,
+			wantIDs:     []uint{1, 2},
+			wantSources: []string{models.RecommendationSourceOrders, models.RecommendationSourceBrand},
+		},
+		{
+			name: "no brand and alone in its category", productID: 6, kind: models.RecommendationRelated,
+			wantIDs:     []uint{1},
+			wantSources: []string{models.RecommendationSourceOrders},
+		},
+		{
+			name: "never bought with anything", productID: 4, kind: models.RecommendationBoughtTogether,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, sources := recommendationList(t, rows, tt.productID, tt.kind)
+			assert.Equal(t, tt.wantIDs, ids)
+			assert.Equal(t, tt.wantSources, sources)
+		})
+	}
+	for _, row := range rows {
+		assert.NotEqual(t, row.ProductID, row.RecommendedID, "a product is not recommended for itself")
+		assert.Equal(t, now, row.ComputedAt)
+	}
+}
+
+func TestBuildRecommendations_KeepsTheClosestInPrice(t *testing.T) {
+	var catalog []services.CatalogEntry
+	for id := uint(1); id <= 30; id++ {
+		catalog = append(catalog, services.CatalogEntry{ID: id, CategoryID: 7, Price: float64(id) * 100})
+	}
+
+	rows := services.BuildRecommendations(catalog, nil, time.Now())
+
+	ids, _ := recommendationList(t, rows, 15, models.RecommendationRelated)
+	require.Len(t, ids, services.MaxRecommendations)
+	assert.Equal(t, []uint{14, 16, 13, 17}, ids[:4], "closest first, the cheaper one on a tie")
+	for _, id := range ids {
+		assert.InDelta(t, 15, float64(id), 10)
+	}
+}
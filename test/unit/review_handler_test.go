@@ -0,0 +1,29 @@
+package unit
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateProductReview_InvalidRating(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Set("user_id", uint(7))
+	c.Request = httptest.NewRequest(http.MethodPost, "/products/1/reviews", strings.NewReader("rating=6&title=Great"))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	// ratings outside 1-5 are rejected before the service is reached
+	handlers.CreateProductReview(c, &container.Container{})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
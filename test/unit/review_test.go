@@ -0,0 +1,131 @@
+package unit
+
+import (
+	"api_techstore/internal/models"
+	"api_techstore/internal/services"
+	apperrors "api_techstore/pkg/errors"
+	"database/sql/driver"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var reviewColumns = []string{"id", "product_id", "user_id", "rating", "status"}
+
+func TestModerateReview_RefreshesRating(t *testing.T) {
+	db, fake := newFakeDB(t)
+	// after the moderation the approved reviews of product 5 are two 5 star, one 3 star and one 1 star
+	fake.returns("COUNT(*) AS total", []string{"rating", "total"},
+		[]driver.Value{int64(1), int64(1)},
+		[]driver.Value{int64(3), int64(1)},
+		[]driver.Value{int64(5), int64(2)}).
+		returns(`FROM "reviews"`, reviewColumns, []driver.Value{int64(40), int64(5), int64(12), int64(5), models.ReviewStatusPending})
+
+	_, err := services.NewReviewService(db, nil).ModerateReview(40, models.ReviewStatusApproved, "")
+
+	require.NoError(t, err)
+	aggregate := fake.executed("COUNT(*) AS total")
+	require.Len(t, aggregate, 1)
+	assert.Contains(t, aggregate[0].Args, models.ReviewStatusApproved, "only approved reviews count")
+	assert.Less(t, fake.position(`UPDATE "reviews"`), fake.position("COUNT(*) AS total"), "the rating is computed after the status change")
+
+	refreshed := fake.executed(`UPDATE "products" SET "rating_average"`)
+	require.Len(t, refreshed, 1)
+	assert.Equal(t, []interface{}{3.5, int64(4), `{"1":1,"2":0,"3":1,"4":0,"5":2}`, uint(5)}, refreshed[0].Args)
+	assert.NotContains(t, refreshed[0].SQL, "updated_at")
+}
+
+func TestModerateReview_NoApprovedReviews(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.returns(`FROM "reviews"`, reviewColumns, []driver.Value{int64(40), int64(5), int64(12), int64(5), models.ReviewStatusApproved})
+
+	_, err := services.NewReviewService(db, nil).ModerateReview(40, models.ReviewStatusRejected, "Off topic")
+
+	require.NoError(t, err)
+	refreshed := fake.executed(`UPDATE "products" SET "rating_average"`)
+	require.Len(t, refreshed, 1)
+	assert.Equal(t, []interface{}{0.0, int64(0), `{"1":0,"2":0,"3":0,"4":0,"5":0}`, uint(5)}, refreshed[0].Args)
+}
+
+func TestVoteHelpful(t *testing.T) {
+	tests := []struct {
+		name    string
+		counted bool
+	}{
+		{"first vote", true},
+		{"second vote of the same user", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			fake.returns(`FROM "reviews"`, reviewColumns, []driver.Value{int64(40), int64(5), int64(12), int64(5), models.ReviewStatusApproved})
+			if tt.counted {
+				fake.returns(`INSERT INTO "review_votes"`, []string{"id"}, []driver.Value{int64(70)})
+			}
+
+			_, err := services.NewReviewService(db, nil).VoteHelpful(5, 40, 9)
+
+			require.NoError(t, err)
+			votes := fake.executed(`INSERT INTO "review_votes"`)
+			require.Len(t, votes, 1)
+			assert.Contains(t, votes[0].SQL, "ON CONFLICT DO NOTHING", "the unique index on review and user keeps one vote per user")
+			assert.Equal(t, tt.counted, len(fake.executed(`SET "helpful_count"=helpful_count + 1`)) == 1)
+		})
+	}
+}
+
+func TestVoteHelpful_Refused(t *testing.T) {
+	t.Run("own review", func(t *testing.T) {
+		db, fake := newFakeDB(t)
+		fake.returns(`FROM "reviews"`, reviewColumns, []driver.Value{int64(40), int64(5), int64(9), int64(5), models.ReviewStatusApproved})
+
+		_, err := services.NewReviewService(db, nil).VoteHelpful(5, 40, 9)
+
+		appErr := apperrors.GetAppError(err)
+		require.NotNil(t, appErr, "%v", err)
+		assert.Equal(t, http.StatusBadRequest, appErr.HTTPStatus)
+		assert.Empty(t, fake.executed(`INSERT INTO "review_votes"`))
+	})
+
+	t.Run("unpublished review", func(t *testing.T) {
+		db, fake := newFakeDB(t)
+
+		_, err := services.NewReviewService(db, nil).VoteHelpful(5, 40, 9)
+
+		require.Error(t, err)
+		lookup := fake.executed(`FROM "reviews"`)
+		require.NotEmpty(t, lookup)
+		assert.Contains(t, lookup[0].Args, models.ReviewStatusApproved)
+		assert.Empty(t, fake.executed(`INSERT INTO "review_votes"`))
+	})
+}
+
+func TestRemoveHelpfulVote(t *testing.T) {
+	tests := []struct {
+		name    string
+		deleted int64
+	}{
+		{"voted", 1},
+		{"never voted", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			fake.returns(`FROM "reviews"`, reviewColumns, []driver.Value{int64(40), int64(5), int64(12), int64(5), models.ReviewStatusApproved}).
+				affects(`DELETE FROM "review_votes"`, tt.deleted)
+
+			_, err := services.NewReviewService(db, nil).RemoveHelpfulVote(5, 40, 9)
+
+			require.NoError(t, err)
+			decrements := fake.executed(`SET "helpful_count"=helpful_count - 1`)
+			if tt.deleted == 0 {
+				assert.Empty(t, decrements)
+				return
+			}
+			require.Len(t, decrements, 1)
+			assert.Contains(t, decrements[0].SQL, "helpful_count > 0", "the count never goes negative")
+		})
+	}
+}
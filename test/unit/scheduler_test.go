@@ -0,0 +1,39 @@
+package unit
+
+import (
+	"api_techstore/internal/jobs"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler_RunsJobAtStart(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	ran := make(chan struct{}, 1)
+	scheduler := jobs.NewScheduler(logger)
+	scheduler.Add(jobs.Job{
+		Name:     "test",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			ran <- struct{}{}
+			return nil
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	scheduler.Start(ctx)
+
+	// the first run does not wait for the interval
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		assert.Fail(t, "job was not run at start")
+	}
+}
@@ -0,0 +1,192 @@
+package unit
+
+import (
+	"api_techstore/internal/models"
+	"api_techstore/internal/services"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB builds the statements without a database and records them with their values inlined.
+// Queries read through Scan fail in dry run mode, only the statements are checked then.
+func dryRunDB(t *testing.T) (*gorm.DB, func() []string) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var statements []string
+	record := func(tx *gorm.DB) {
+		mu.Lock()
+		defer mu.Unlock()
+		statements = append(statements, tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+	}
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:record", record))
+	require.NoError(t, db.Callback().Row().After("gorm:row").Register("test:record", record))
+
+	return db, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), statements...)
+	}
+}
+
+// pageStatement is the statement loading the page of products, the last one on products
+func pageStatement(t *testing.T, statements []string) string {
+	for i := len(statements) - 1; i >= 0; i-- {
+		if strings.HasPrefix(statements[i], `SELECT * FROM "products"`) {
+			return statements[i]
+		}
+	}
+	t.Fatalf("no page query in %v", statements)
+	return ""
+}
+
+func TestCatalogListProducts_Sort(t *testing.T) {
+	tests := []struct {
+		sort  string
+		order string
+	}{
+		{sort: "", order: "ORDER BY products.created_at DESC, products.id DESC"},
+		{sort: models.CatalogSortNewest, order: "ORDER BY products.created_at DESC, products.id DESC"},
+		{sort: models.CatalogSortPriceAsc, order: "ORDER BY products.price, products.id"},
+		{sort: models.CatalogSortPriceDesc, order: "ORDER BY products.price DESC, products.id DESC"},
+		{sort: models.CatalogSortRating, order: "ORDER BY products.rating_average DESC, products.rating_count DESC, products.id DESC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			db, statements := dryRunDB(t)
+
+			_, err := services.NewCatalogService(db).ListProducts(models.CatalogQuery{Sort: tt.sort, Page: 3, Limit: 10})
+			require.NoError(t, err)
+
+			page := pageStatement(t, statements())
+			assert.Contains(t, page, tt.order+" LIMIT 10 OFFSET 20")
+		})
+	}
+}
+
+func TestCatalogListProducts_Filters(t *testing.T) {
+	minPrice, maxPrice := 100.0, 500.0
+	inStock, outOfStock := true, false
+	tests := []struct {
+		name  string
+		query models.SearchQuery
+		want  []string
+	}{
+		{
+			name:  "keyword",
+			query: models.SearchQuery{Keyword: " laptop "},
+			want:  []string{`products.name ILIKE '%laptop%' ESCAPE '\'`, `products.description ILIKE '%laptop%' ESCAPE '\'`},
+		},
+		{
+			name:  "keyword wildcards are matched as typed",
+			query: models.SearchQuery{Keyword: `50%_off\`},
+			want:  []string{`products.name ILIKE '%50\%\_off\\%' ESCAPE '\'`},
+		},
+		{
+			name:  "categories and brands",
+			query: models.SearchQuery{CategoryIDs: []uint{1, 2}, BrandIDs: []uint{7}},
+			want: []string{
+				"products.category_id IN (SELECT categories.id FROM \"categories\" JOIN categories AS selected ON selected.id IN (1,2)",
+				"categories.path LIKE selected.path || '%'",
+				"products.brand_id IN (7)",
+			},
+		},
+		{
+			name:  "price range",
+			query: models.SearchQuery{MinPrice: &minPrice, MaxPrice: &maxPrice},
+			want:  []string{"products.price >= 100", "products.price <= 500"},
+		},
+		{
+			name:  "in stock",
+			query: models.SearchQuery{InStock: &inStock},
+			want:  []string{"products.quantity > 0"},
+		},
+		{
+			name:  "out of stock",
+			query: models.SearchQuery{InStock: &outOfStock},
+			want:  []string{"products.quantity <= 0"},
+		},
+		{
+			name:  "attribute values",
+			query: models.SearchQuery{Attributes: map[string]string{"ram_gb": "8, 16"}},
+			want: []string{
+				"ca.code = 'ram_gb'",
+				"pav.value_number = 8 OR LOWER(pav.value_text) = LOWER('8') OR pav.value_number = 16",
+			},
+		},
+		{
+			name:  "attribute range",
+			query: models.SearchQuery{Attributes: map[string]string{"screen_inch": "13..15.6"}},
+			want:  []string{"pav.value_number BETWEEN 13 AND 15.6"},
+		},
+		{
+			name:  "open attribute range",
+			query: models.SearchQuery{Attributes: map[string]string{"screen_inch": "..14"}},
+			want:  []string{"pav.value_number <= 14"},
+		},
+		{
+			name:  "attribute flag",
+			query: models.SearchQuery{Attributes: map[string]string{"touchscreen": "true"}},
+			want:  []string{"pav.value_bool = true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, statements := dryRunDB(t)
+
+			_, err := services.NewCatalogService(db).ListProducts(models.CatalogQuery{SearchQuery: tt.query})
+			require.NoError(t, err)
+
+			page := pageStatement(t, statements())
+			for _, want := range tt.want {
+				assert.Contains(t, page, want)
+			}
+			assert.Contains(t, page, "products.is_active = true", "hidden products stay out of the search")
+		})
+	}
+}
+
+func TestGetFilters_FacetsLeaveTheirOwnFilterOut(t *testing.T) {
+	db, statements := dryRunDB(t)
+	inStock := true
+	query := models.SearchQuery{CategoryIDs: []uint{1}, BrandIDs: []uint{7}, InStock: &inStock}
+
+	// dry run reads no rows, so the facets fail after their first statement
+	_, err := services.NewSearchService(db).GetFilters(query)
+	require.Error(t, err)
+
+	facets := map[string]string{}
+	for _, statement := range statements() {
+		switch {
+		case strings.Contains(statement, "AS in_stock"):
+			facets["availability"] = statement
+		case strings.Contains(statement, "JOIN categories ON"):
+			facets["category"] = statement
+		case strings.Contains(statement, "JOIN brands ON"):
+			facets["brand"] = statement
+		case strings.Contains(statement, "MIN(products.price)"):
+			facets["price"] = statement
+		}
+	}
+	require.Len(t, facets, 4, "every facet is counted")
+
+	assert.NotContains(t, facets["category"], "products.category_id IN")
+	assert.Contains(t, facets["category"], "products.brand_id IN (7)")
+	assert.NotContains(t, facets["brand"], "products.brand_id IN")
+	assert.Contains(t, facets["brand"], "selected.id IN (1)", "subcategories are counted with the selected category")
+	assert.NotContains(t, facets["availability"], "products.quantity > 0 AND")
+	assert.Contains(t, facets["availability"], "selected.id IN (1)")
+	for _, name := range []string{"category", "brand", "price"} {
+		assert.Contains(t, facets[name], "products.quantity > 0", name)
+	}
+}
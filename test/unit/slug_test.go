@@ -0,0 +1,43 @@
+package unit
+
+import (
+	"api_techstore/pkg/slug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakeSlug_Vietnamese(t *testing.T) {
+	cases := map[string]string{
+		"Laptop Gaming Đồ Họa":           "laptop-gaming-do-hoa",
+		"Điện thoại  Samsung Galaxy S24": "dien-thoai-samsung-galaxy-s24",
+		"Tai nghe không dây (Bluetooth)": "tai-nghe-khong-day-bluetooth",
+		"Màn hình 27\" 4K - Ưu đãi!":     "man-hinh-27-4k-uu-dai",
+		"  --MacBook Pro 14--  ":         "macbook-pro-14",
+	}
+	for input, expected := range cases {
+		assert.Equal(t, expected, slug.Make(input), input)
+	}
+}
+
+func TestMakeSlug_Truncates(t *testing.T) {
+	long := ""
+	for i := 0; i < 30; i++ {
+		long += "laptop "
+	}
+	result := slug.Make(long)
+
+	assert.LessOrEqual(t, len(result), slug.MaxLength)
+	assert.NotEqual(t, '-', rune(result[len(result)-1]))
+}
+
+func TestUniqueSlug_AddsSuffix(t *testing.T) {
+	taken := map[string]bool{"iphone-15": true, "iphone-15-2": true}
+
+	result, err := slug.Unique("iphone-15", func(candidate string) (bool, error) {
+		return taken[candidate], nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "iphone-15-3", result)
+}
@@ -0,0 +1,86 @@
+package unit
+
+import (
+	"api_techstore/pkg/storage"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorage_PutAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	store := storage.NewLocalStorage(dir, "uploads/", "http://localhost:8080/")
+
+	url, err := store.Put(context.Background(), "products/1/photo.jpg", strings.NewReader("data"), 4, "image/jpeg")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/uploads/products/1/photo.jpg", url)
+	stored, err := os.ReadFile(filepath.Join(dir, "products", "1", "photo.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(stored))
+
+	require.NoError(t, store.Delete(context.Background(), "products/1/photo.jpg"))
+	assert.NoFileExists(t, filepath.Join(dir, "products", "1", "photo.jpg"))
+	assert.NoError(t, store.Delete(context.Background(), "products/1/photo.jpg"), "deleting a missing key is not an error")
+}
+
+func TestLocalStorage_RejectsKeysOutsideDir(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "uploads")
+	store := storage.NewLocalStorage(dir, "/uploads", "")
+
+	for _, key := range []string{"", "/", "..", "../escaped.jpg", "products/../../escaped.jpg", `products\..\..\escaped.jpg`} {
+		t.Run(key, func(t *testing.T) {
+			_, err := store.Put(context.Background(), key, strings.NewReader("data"), 4, "image/jpeg")
+			assert.Error(t, err)
+			assert.Error(t, store.Delete(context.Background(), key))
+		})
+	}
+	assert.NoFileExists(t, filepath.Join(root, "escaped.jpg"))
+}
+
+func TestS3Storage_PutSignsPathStyleRequest(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	store := storage.NewS3Storage(storage.S3Options{
+		Endpoint:  server.URL + "/",
+		Bucket:    "media",
+		AccessKey: "AKID",
+		SecretKey: "secret",
+		PublicURL: "https://cdn.example.com/",
+	})
+	url, err := store.Put(context.Background(), "products/1/my photo.jpg", strings.NewReader("data"), 4, "image/jpeg")
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://cdn.example.com/products/1/my%20photo.jpg", url)
+	require.NotNil(t, got)
+	assert.Equal(t, http.MethodPut, got.Method)
+	assert.Equal(t, "/media/products/1/my%20photo.jpg", got.URL.EscapedPath())
+	assert.Equal(t, "image/jpeg", got.Header.Get("Content-Type"))
+	assert.Equal(t, "UNSIGNED-PAYLOAD", got.Header.Get("X-Amz-Content-Sha256"))
+	assert.Regexp(t, `^AWS4-HMAC-SHA256 Credential=AKID/\d{8}/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=[0-9a-f]{64}$`, got.Header.Get("Authorization"))
+}
+
+func TestS3Storage_ReportsErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	store := storage.NewS3Storage(storage.S3Options{Endpoint: server.URL, Bucket: "media"})
+	err := store.Delete(context.Background(), "products/1/photo.jpg")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+}
@@ -0,0 +1,126 @@
+package unit
+
+import (
+	"api_techstore/internal/models"
+	"api_techstore/internal/services"
+	apperrors "api_techstore/pkg/errors"
+	"database/sql/driver"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteTradeIn(t *testing.T) {
+	rule := models.TradeInPriceRule{
+		BaseValue:             500,
+		GoodPercent:           80,
+		FairPercent:           60,
+		PoorPercent:           30,
+		ScreenDamageDeduction: 90,
+		BodyDamageDeduction:   45.5,
+		NoPowerValue:          25,
+	}
+	tests := []struct {
+		name    string
+		tradeIn models.TradeIn
+		want    float64
+	}{
+		{name: "excellent", tradeIn: models.TradeIn{Condition: models.TradeInConditionExcellent, PowersOn: true}, want: 500},
+		{name: "good", tradeIn: models.TradeIn{Condition: models.TradeInConditionGood, PowersOn: true}, want: 400},
+		{name: "fair", tradeIn: models.TradeIn{Condition: models.TradeInConditionFair, PowersOn: true}, want: 300},
+		{name: "poor", tradeIn: models.TradeIn{Condition: models.TradeInConditionPoor, PowersOn: true}, want: 150},
+		{name: "screen damage", tradeIn: models.TradeIn{Condition: models.TradeInConditionGood, PowersOn: true, ScreenDamaged: true}, want: 310},
+		{name: "screen and body damage", tradeIn: models.TradeIn{Condition: models.TradeInConditionGood, PowersOn: true, ScreenDamaged: true, BodyDamaged: true}, want: 264.5},
+		{name: "poor with damage", tradeIn: models.TradeIn{Condition: models.TradeInConditionPoor, PowersOn: true, ScreenDamaged: true, BodyDamaged: true}, want: 14.5},
+		{name: "no power value ignores condition and damage", tradeIn: models.TradeIn{Condition: models.TradeInConditionExcellent, ScreenDamaged: true}, want: 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, services.QuoteTradeIn(rule, tt.tradeIn))
+		})
+	}
+
+	t.Run("never below zero", func(t *testing.T) {
+		cheap := rule
+		cheap.BaseValue = 100
+		value := services.QuoteTradeIn(cheap, models.TradeIn{Condition: models.TradeInConditionPoor, PowersOn: true, ScreenDamaged: true})
+		assert.Equal(t, 0.0, value)
+	})
+	t.Run("rounded to the cent", func(t *testing.T) {
+		odd := rule
+		odd.BaseValue = 333.33
+		value := services.QuoteTradeIn(odd, models.TradeIn{Condition: models.TradeInConditionPoor, PowersOn: true})
+		assert.Equal(t, 100.0, value)
+	})
+}
+
+func TestTradeInCredit(t *testing.T) {
+	tests := []struct {
+		name       string
+		order      models.Order
+		value      float64
+		wantCredit float64
+		wantTotal  float64
+	}{
+		{name: "below the total", order: models.Order{TotalAmount: 1000}, value: 300, wantCredit: 300, wantTotal: 700},
+		{name: "capped at the total", order: models.Order{TotalAmount: 250}, value: 300, wantCredit: 250, wantTotal: 0},
+		{name: "replaces the current credit", order: models.Order{TotalAmount: 700, TradeInCredit: 300}, value: 180.25, wantCredit: 180.25, wantTotal: 819.75},
+		{name: "capped at the total before credit", order: models.Order{TotalAmount: 0, TradeInCredit: 250}, value: 400, wantCredit: 250, wantTotal: 0},
+		{name: "zero value", order: models.Order{TotalAmount: 700, TradeInCredit: 300}, value: 0, wantCredit: 0, wantTotal: 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			credit, total := services.TradeInCredit(tt.order, tt.value)
+			assert.Equal(t, tt.wantCredit, credit)
+			assert.Equal(t, tt.wantTotal, total)
+		})
+	}
+}
+
+func TestInspectTradeIn_CreditedOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		orderStatus string
+		paid        int64
+		finalValue  float64
+		wantStatus  int // 0 when inspected
+		wantCredit  bool
+	}{
+		{name: "pending order follows the new value", orderStatus: "pending", finalValue: 150, wantCredit: true},
+		{name: "paid order is refused", orderStatus: "pending", paid: 1, finalValue: 150, wantStatus: http.StatusConflict},
+		{name: "confirmed order is refused", orderStatus: "confirmed", finalValue: 150, wantStatus: http.StatusConflict},
+		{name: "shipped order keeping its credit", orderStatus: "shipped", finalValue: 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			fake.returns(`FROM "trade_ins"`, []string{"id", "user_id", "status", "quoted_value", "order_id"},
+				[]driver.Value{int64(4), int64(9), models.TradeInStatusAccepted, 200.0, int64(31)}).
+				returns(`FROM "orders"`, []string{"id", "user_id", "status", "total_amount", "trade_in_credit"},
+					[]driver.Value{int64(31), int64(9), tt.orderStatus, 800.0, 200.0}).
+				returns(`FROM "payments"`, []string{"count"}, []driver.Value{tt.paid})
+
+			_, err := services.NewTradeInService(db).Inspect(4, 1, tt.finalValue, "scratched back")
+
+			if tt.wantStatus != 0 {
+				appErr := apperrors.GetAppError(err)
+				require.NotNil(t, appErr)
+				assert.Equal(t, tt.wantStatus, appErr.HTTPStatus)
+				assert.NotEmpty(t, fake.executed("ROLLBACK"), "the final value is not kept either")
+				return
+			}
+			require.NoError(t, err)
+			credits := fake.executed(`UPDATE "orders" SET`)
+			if !tt.wantCredit {
+				assert.Empty(t, credits)
+				return
+			}
+			require.Len(t, credits, 1)
+			assert.Contains(t, credits[0].SQL, `"total_amount"=`)
+			assert.Contains(t, credits[0].Args, 850.0)
+			assert.Contains(t, credits[0].Args, tt.finalValue)
+		})
+	}
+}
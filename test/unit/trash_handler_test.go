@@ -0,0 +1,38 @@
+package unit
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestoreProduct_InvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+	c.Request = httptest.NewRequest(http.MethodPost, "/products/abc/restore", nil)
+
+	handlers.RestoreProduct(c, &container.Container{})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRestoreCategory_InvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "-1"}}
+	c.Request = httptest.NewRequest(http.MethodPost, "/categories/-1/restore", nil)
+
+	handlers.RestoreCategory(c, &container.Container{})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
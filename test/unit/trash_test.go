@@ -0,0 +1,135 @@
+package unit
+
+import (
+	"api_techstore/internal/services"
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const trashRetention = 30 * 24 * time.Hour
+
+func TestPurgeExpired_Blockers(t *testing.T) {
+	db, fake := newFakeDB(t)
+
+	require.NoError(t, services.NewTrashService(db, &memoryStorage{}, trashRetention).PurgeExpired(context.Background()))
+
+	tests := []struct {
+		table    string
+		blockers []string
+	}{
+		{
+			table: "products",
+			blockers: []string{
+				"FROM order_items WHERE order_items.product_id = products.id OR order_items.bundle_product_id = products.id",
+				"FROM sold_units WHERE sold_units.product_id = products.id",
+				"FROM bundle_items WHERE bundle_items.component_id = products.id",
+				"FROM flash_sales WHERE flash_sales.product_id = products.id",
+				"FROM reviews WHERE reviews.product_id = products.id",
+				"FROM product_questions WHERE product_questions.product_id = products.id",
+			},
+		},
+		{
+			table: "brands",
+			blockers: []string{
+				"FROM products WHERE products.brand_id = brands.id",
+				"FROM trade_in_price_rules WHERE trade_in_price_rules.brand_id = brands.id",
+				"FROM trade_ins WHERE trade_ins.brand_id = brands.id",
+			},
+		},
+		{
+			table: "categories",
+			blockers: []string{
+				"FROM products WHERE products.category_id = categories.id",
+				"FROM categories AS children WHERE children.parent_id = categories.id",
+				"FROM compatibility_rules JOIN category_attributes",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			selections := fake.executed(tt.table + ".deleted_at < $1")
+			require.Len(t, selections, 1)
+			for _, blocker := range tt.blockers {
+				assert.Contains(t, selections[0].SQL, "NOT EXISTS (SELECT 1 "+blocker)
+			}
+			require.Len(t, selections[0].Args, 1)
+			cutoff, ok := selections[0].Args[0].(time.Time)
+			require.True(t, ok)
+			assert.WithinDuration(t, time.Now().Add(-trashRetention), cutoff, time.Minute)
+		})
+	}
+	assert.Empty(t, fake.executed("DELETE"), "nothing expired")
+}
+
+func TestPurgeExpired_Product(t *testing.T) {
+	db, fake := newFakeDB(t)
+	store := &memoryStorage{objects: map[string][]byte{
+		"products/5/a.jpg":       {1},
+		"products/5/a-thumb.jpg": {1},
+		"products/6/b.jpg":       {1},
+	}}
+	fake.returns("products.deleted_at <", []string{"id"}, []driver.Value{int64(5)}).
+		returns(`FROM "product_images"`, []string{"id", "product_id", "storage_key"}, []driver.Value{int64(30), int64(5), "products/5/a.jpg"}).
+		returnsFor(`FROM "product_image_variants"`, 30, []string{"id", "product_image_id", "storage_key"}, []driver.Value{int64(31), int64(30), "products/5/a-thumb.jpg"})
+
+	require.NoError(t, services.NewTrashService(db, store, trashRetention).PurgeExpired(context.Background()))
+
+	for _, table := range []string{
+		"product_image_variants", "product_images", "product_variant_options", "product_variants",
+		"product_attribute_values", "bundle_items", "cart_items", "product_recommendations",
+		"product_compatibilities", "price_history", "scheduled_prices",
+	} {
+		assert.NotEmpty(t, fake.executed(`DELETE FROM "`+table+`"`), table)
+	}
+	redirects := fake.executed(`DELETE FROM "slug_redirects"`)
+	require.Len(t, redirects, 1)
+	assert.Contains(t, redirects[0].Args, "product")
+	products := fake.executed(`DELETE FROM "products"`)
+	require.Len(t, products, 1)
+	assert.Contains(t, products[0].SQL, "WHERE id IN")
+	assert.Contains(t, products[0].Args, uint(5))
+
+	assert.Less(t, fake.position(`DELETE FROM "products"`), fake.position("brands.deleted_at <"), "brands of purged products can follow in the same run")
+	assert.Equal(t, []string{"products/6/b.jpg"}, storedKeys(store), "only the files of the purged product are removed")
+}
+
+func TestPurgeExpired_FailedPurgeKeepsFiles(t *testing.T) {
+	db, fake := newFakeDB(t)
+	store := &memoryStorage{objects: map[string][]byte{"products/5/a.jpg": {1}}}
+	fake.returns("products.deleted_at <", []string{"id"}, []driver.Value{int64(5)}).
+		returns(`FROM "product_images"`, []string{"id", "product_id", "storage_key"}, []driver.Value{int64(30), int64(5), "products/5/a.jpg"}).
+		fails(`DELETE FROM "products"`, assert.AnError)
+
+	err := services.NewTrashService(db, store, trashRetention).PurgeExpired(context.Background())
+
+	require.Error(t, err)
+	assert.NotEmpty(t, fake.executed("ROLLBACK"))
+	assert.Equal(t, []string{"products/5/a.jpg"}, storedKeys(store), "the product can still be restored with its images")
+}
+
+func storedKeys(store *memoryStorage) []string {
+	var keys []string
+	for key := range store.objects {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestPurgeExpired_Category(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.returns("categories.deleted_at <", []string{"id"}, []driver.Value{int64(8)})
+
+	require.NoError(t, services.NewTrashService(db, &memoryStorage{}, trashRetention).PurgeExpired(context.Background()))
+
+	attributes := fake.executed(`DELETE FROM "category_attributes"`)
+	require.Len(t, attributes, 1)
+	categories := fake.executed(`DELETE FROM "categories"`)
+	require.Len(t, categories, 1)
+	assert.Less(t, fake.position(`DELETE FROM "category_attributes"`), fake.position(`DELETE FROM "categories"`))
+	assert.NotContains(t, categories[0].SQL, "deleted_at", "deleted for good")
+}
@@ -0,0 +1,81 @@
+package unit
+
+import (
+	"api_techstore/internal/models"
+	"api_techstore/internal/services"
+	apperrors "api_techstore/pkg/errors"
+	"context"
+	"database/sql/driver"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateClaimStatus_Transitions(t *testing.T) {
+	statuses := []string{
+		models.ClaimStatusSubmitted, models.ClaimStatusReceived, models.ClaimStatusDiagnosing,
+		models.ClaimStatusRepaired, models.ClaimStatusReplaced, models.ClaimStatusRejected,
+	}
+	// the service-center workflow; repaired, replaced and rejected claims are closed
+	allowed := map[[2]string]bool{
+		{models.ClaimStatusSubmitted, models.ClaimStatusReceived}:  true,
+		{models.ClaimStatusSubmitted, models.ClaimStatusRejected}:  true,
+		{models.ClaimStatusReceived, models.ClaimStatusDiagnosing}: true,
+		{models.ClaimStatusReceived, models.ClaimStatusRejected}:   true,
+		{models.ClaimStatusDiagnosing, models.ClaimStatusRepaired}: true,
+		{models.ClaimStatusDiagnosing, models.ClaimStatusReplaced}: true,
+		{models.ClaimStatusDiagnosing, models.ClaimStatusRejected}: true,
+	}
+	for _, from := range statuses {
+		for _, to := range statuses {
+			t.Run(from+" to "+to, func(t *testing.T) {
+				db, fake := newFakeDB(t)
+				fake.returns(`FROM "warranty_claims"`, []string{"id", "user_id", "order_item_id", "status"},
+					[]driver.Value{int64(3), int64(9), int64(40), from})
+
+				_, err := services.NewWarrantyClaimService(db, nil).UpdateStatus(3, 1, to, "checked")
+
+				if !allowed[[2]string{from, to}] {
+					appErr := apperrors.GetAppError(err)
+					require.NotNil(t, appErr, "%v", err)
+					assert.Equal(t, http.StatusConflict, appErr.HTTPStatus)
+					assert.Empty(t, fake.executed(`UPDATE "warranty_claims"`))
+					assert.Empty(t, fake.executed(`INSERT INTO "notifications"`), "the customer is not told")
+					return
+				}
+				require.NoError(t, err)
+				updates := fake.executed(`UPDATE "warranty_claims" SET "status"`)
+				require.Len(t, updates, 1)
+				assert.Contains(t, updates[0].Args, to)
+				notes := fake.executed(`INSERT INTO "warranty_claim_notes"`)
+				require.Len(t, notes, 1)
+				assert.Contains(t, notes[0].Args, to, "the change is recorded as a note")
+				notifications := fake.executed(`INSERT INTO "notifications"`)
+				require.Len(t, notifications, 1)
+				assert.Contains(t, notifications[0].Args, "Your warranty claim #3 is now "+to+". checked")
+			})
+		}
+	}
+}
+
+func TestAddClaimAttachments_ClosedClaim(t *testing.T) {
+	for _, status := range []string{models.ClaimStatusRepaired, models.ClaimStatusReplaced, models.ClaimStatusRejected} {
+		t.Run(status, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			store := &memoryStorage{}
+			fake.returns(`FROM "warranty_claims"`, []string{"id", "user_id", "order_item_id", "status"},
+				[]driver.Value{int64(3), int64(9), int64(40), status})
+
+			_, err := services.NewWarrantyClaimService(db, store).AddAttachments(context.Background(), 3, 9, false,
+				[]*multipart.FileHeader{uploadedFile(t, "receipt.jpg", testJPEG(t, 10, 10))})
+
+			appErr := apperrors.GetAppError(err)
+			require.NotNil(t, appErr, "%v", err)
+			assert.Equal(t, http.StatusConflict, appErr.HTTPStatus)
+			assert.Empty(t, store.objects, "nothing is uploaded")
+		})
+	}
+}
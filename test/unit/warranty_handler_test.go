@@ -0,0 +1,62 @@
+package unit
+
+import (
+	"api_techstore/internal/container"
+	"api_techstore/internal/handlers"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetMyWarranties_Unauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/me/warranties", nil)
+
+	handlers.GetMyWarranties(c, &container.Container{})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRecordSerialNumbers_InvalidItemID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}, {Key: "itemId", Value: "abc"}}
+	c.Request = httptest.NewRequest(http.MethodPost, "/orders/1/items/abc/serial-numbers", nil)
+
+	handlers.RecordSerialNumbers(c, &container.Container{})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateWarrantyClaim_Unauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/warranty-claims", nil)
+
+	handlers.CreateWarrantyClaim(c, &container.Container{})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestGetWarrantyClaim_InvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/warranty-claims/abc", nil)
+
+	handlers.GetWarrantyClaim(c, &container.Container{})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}